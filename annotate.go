@@ -0,0 +1,55 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CertificateSummary returns a human-readable summary of the certificate's
+// subject, issuer, validity period, and SHA-256 fingerprint, as written by
+// [Store.BytesAnnotated]. Control characters in the subject and issuer are
+// escaped.
+func CertificateSummary(cert *x509.Certificate) string {
+	fp := Fingerprint(cert)
+	hex := make([]string, len(fp))
+	for i, b := range fp {
+		hex[i] = fmt.Sprintf("%02X", b)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Subject: %s\n", escapeText(cert.Subject.String()))
+	fmt.Fprintf(&sb, "Issuer: %s\n", escapeText(cert.Issuer.String()))
+	fmt.Fprintf(&sb, "Not Before: %s\n", cert.NotBefore.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Not After : %s\n", cert.NotAfter.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "SHA256 Fingerprint: %s\n", strings.Join(hex, ":"))
+	return sb.String()
+}
+
+// BytesAnnotated returns all crypto primitives in the [Store] as a single
+// byte slice containing the PEM-encoded versions of the crypto primitives
+// (see [Store.Bytes]), with each "CERTIFICATE" block preceded by a
+// human-readable summary (see [CertificateSummary]) as explanatory text.
+//
+// The annotated output can be decoded by [Decode], and the summaries
+// preserved using the [WithExplanatoryText] option. Returns an error when a
+// certificate's subject or issuer contains "-----".
+func (s Store) BytesAnnotated() ([]byte, error) {
+	z := s.Clone()
+	text, ok := z[ExplanatoryText].(explanatoryText)
+	if !ok {
+		text = make(explanatoryText)
+	}
+	for _, cert := range z.Certificates() {
+		summary := []byte(CertificateSummary(cert))
+		if err := checkText(summary); err != nil {
+			return nil, fmt.Errorf("certificate %q: %w", cert.Subject, err)
+		}
+		text[textKey(&pem.Block{Type: Certificate.String(), Bytes: cert.Raw})] = summary
+	}
+	if len(text) != 0 {
+		z[ExplanatoryText] = text
+	}
+	return z.Bytes()
+}
@@ -0,0 +1,82 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"strings"
+	"testing"
+)
+
+func TestBytesAnnotated(t *testing.T) {
+	s, err := LoadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, ok := s.Certificate()
+	if !ok {
+		t.Fatalf("expected certificate")
+	}
+	summary := CertificateSummary(cert)
+	for i, exp := range []string{
+		"Subject: CN=Go Daddy Root Certificate Authority - G2,O=GoDaddy.com\\, Inc.,L=Scottsdale,ST=Arizona,C=US\n",
+		"Not After : 2037-12-31T23:59:59Z\n",
+		"SHA256 Fingerprint: 45:14:0B:32:47:EB:9C:C8:C5:B4:F0:D7:B5:30:91:F7:32:92:08:9E:6E:5A:63:E2:74:9D:D3:AC:A9:19:8E:DA\n",
+	} {
+		if !strings.Contains(summary, exp) {
+			t.Errorf("test %d expected summary to contain %q, got:\n%s", i, exp, summary)
+		}
+	}
+	buf, err := s.BytesAnnotated()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.HasPrefix(buf, []byte(summary+"-----BEGIN CERTIFICATE-----\n")) {
+		t.Errorf("expected summary before certificate, got:\n%s", buf)
+	}
+	if _, ok := s[ExplanatoryText]; ok {
+		t.Errorf("expected store to be unmodified")
+	}
+	// decodes, and preserves the summary
+	z, err := DecodeBytes(buf, WithExplanatoryText())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b, err := z.Bytes(); err != nil || !bytes.Equal(b, buf) {
+		t.Errorf("expected round trip to match, got:\n%s", b)
+	}
+}
+
+func TestBytesAnnotatedHostileSubject(t *testing.T) {
+	tests := []struct {
+		cn  string
+		err bool
+	}{
+		{"a\nSubject: forged\r\n", false},
+		{"a\n-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n", true},
+	}
+	for i, test := range tests {
+		s, err := GenerateECKeySet(elliptic.P256())
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if _, err := s.SelfSign(RootCATemplate(test.cn)); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		buf, err := s.BytesAnnotated()
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d expected error, got:\n%s", i, buf)
+			continue
+		case test.err:
+			continue
+		case err != nil:
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if bytes.Contains(buf, []byte("\nSubject: forged")) {
+			t.Errorf("test %d expected escaped subject, got:\n%s", i, buf)
+		}
+		if exp := `Subject: CN=a\nSubject: forged\r\n` + "\n"; !bytes.Contains(buf, []byte(exp)) {
+			t.Errorf("test %d expected %q, got:\n%s", i, exp, buf)
+		}
+	}
+}
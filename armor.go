@@ -0,0 +1,60 @@
+package pemutil
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EncodeBlock PEM-encodes (armors) arbitrary data using the block type and
+// headers, formatted using the encode options, returning an error when the
+// block type or headers are not valid. When using [WithJavaCompat], the
+// output is checked with [CheckJavaCompat].
+func EncodeBlock(blockType string, headers map[string]string, data []byte, opts ...EncodeOption) ([]byte, error) {
+	o, err := newEncodeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if blockType == "" || !validLabel(blockType) {
+		return nil, fmt.Errorf("invalid block type %q", blockType)
+	}
+	for k, v := range headers {
+		if k == "" || strings.ContainsAny(k, ":\r\n") {
+			return nil, fmt.Errorf("invalid header key %q", k)
+		}
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, fmt.Errorf("invalid header %q value %q", k, v)
+		}
+	}
+	var buf bytes.Buffer
+	if err := o.encodeBlocks(&buf, []*pem.Block{{
+		Type:    blockType,
+		Headers: headers,
+		Bytes:   data,
+	}}); err != nil {
+		return nil, err
+	}
+	if o.java {
+		if err := CheckJavaCompat(buf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeBlock decodes (dearmors) the first PEM block in buf, returning the
+// block's headers, data, and the remaining data in buf. Returns an error if
+// buf does not contain a PEM block, or when the block's type is not
+// blockType.
+func DecodeBlock(blockType string, buf []byte) (map[string]string, []byte, []byte, error) {
+	block, rest := pem.Decode(buf)
+	if block == nil {
+		return nil, nil, buf, errors.New("invalid PEM data")
+	}
+	if block.Type != blockType {
+		return nil, nil, buf, fmt.Errorf("expected block type %s, got: %s", blockType, block.Type)
+	}
+	return block.Headers, block.Bytes, rest, nil
+}
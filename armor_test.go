@@ -0,0 +1,43 @@
+package pemutil
+
+import (
+	"testing"
+)
+
+func TestArmor(t *testing.T) {
+	data := []byte("license data")
+	headers := map[string]string{"Licensee": "example", "Expires": "2030-01-01"}
+	buf, err := EncodeBlock("LICENSE", headers, data)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	h, d, rest, err := DecodeBlock("LICENSE", buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if string(d) != string(data) {
+		t.Errorf("expected %q, got: %q", data, d)
+	}
+	if len(h) != len(headers) || h["Licensee"] != "example" || h["Expires"] != "2030-01-01" {
+		t.Errorf("expected headers %v, got: %v", headers, h)
+	}
+	if len(rest) != 0 {
+		t.Errorf("expected no remaining data, got: %q", rest)
+	}
+	if _, _, _, err := DecodeBlock("SEALED BLOB", buf); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	for i, test := range []struct {
+		typ     string
+		headers map[string]string
+	}{
+		{"", nil},
+		{"-LICENSE", nil},
+		{"LICENSE", map[string]string{"Bad:Key": "v"}},
+		{"LICENSE", map[string]string{"Key": "bad\nvalue"}},
+	} {
+		if _, err := EncodeBlock(test.typ, test.headers, data); err == nil {
+			t.Errorf("test %d expected error, got nil", i)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package pemutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"unicode/utf16"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+var (
+	// oidFriendlyName is the PKCS#9 friendlyName attribute object identifier.
+	oidFriendlyName = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+
+	// oidLocalKeyID is the PKCS#9 localKeyID attribute object identifier.
+	oidLocalKeyID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+)
+
+// Attribute is a PKCS#8 private key attribute.
+type Attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// Attributes are PKCS#8 private key attributes (such as the friendlyName and
+// localKeyID attributes set by openssl pkcs12).
+//
+// Attributes decoded with a "PRIVATE KEY" block are stored in a [Store] as
+// the [KeyAttributes] block type, and are re-emitted with the private key
+// when the [Store] is encoded.
+type Attributes []Attribute
+
+// FriendlyName returns the friendlyName attribute.
+func (attrs Attributes) FriendlyName() string {
+	v, ok := attrs.value(oidFriendlyName)
+	if !ok {
+		return ""
+	}
+	switch v.Tag {
+	case asn1.TagBMPString:
+		if len(v.Bytes)%2 != 0 {
+			return ""
+		}
+		s := make([]uint16, len(v.Bytes)/2)
+		for i := range s {
+			s[i] = uint16(v.Bytes[2*i])<<8 | uint16(v.Bytes[2*i+1])
+		}
+		return string(utf16.Decode(s))
+	case asn1.TagUTF8String, asn1.TagPrintableString, asn1.TagIA5String:
+		return string(v.Bytes)
+	}
+	return ""
+}
+
+// LocalKeyID returns the localKeyID attribute.
+func (attrs Attributes) LocalKeyID() []byte {
+	if v, ok := attrs.value(oidLocalKeyID); ok && v.Tag == asn1.TagOctetString {
+		return v.Bytes
+	}
+	return nil
+}
+
+// SetFriendlyName sets the friendlyName attribute.
+func (attrs *Attributes) SetFriendlyName(name string) {
+	s := utf16.Encode([]rune(name))
+	buf := make([]byte, 2*len(s))
+	for i, c := range s {
+		buf[2*i], buf[2*i+1] = byte(c>>8), byte(c)
+	}
+	attrs.set(oidFriendlyName, asn1.RawValue{Tag: asn1.TagBMPString, Bytes: buf})
+}
+
+// SetLocalKeyID sets the localKeyID attribute.
+func (attrs *Attributes) SetLocalKeyID(id []byte) {
+	attrs.set(oidLocalKeyID, asn1.RawValue{Tag: asn1.TagOctetString, Bytes: id})
+}
+
+// value returns the first value of the attribute.
+func (attrs Attributes) value(oid asn1.ObjectIdentifier) (asn1.RawValue, bool) {
+	for _, attr := range attrs {
+		if attr.Type.Equal(oid) && len(attr.Values) != 0 {
+			return attr.Values[0], true
+		}
+	}
+	return asn1.RawValue{}, false
+}
+
+// set sets the attribute's value, replacing any existing value.
+func (attrs *Attributes) set(oid asn1.ObjectIdentifier, v asn1.RawValue) {
+	v.FullBytes, _ = asn1.Marshal(v)
+	for i, attr := range *attrs {
+		if attr.Type.Equal(oid) {
+			(*attrs)[i].Values = []asn1.RawValue{v}
+			return
+		}
+	}
+	*attrs = append(*attrs, Attribute{Type: oid, Values: []asn1.RawValue{v}})
+}
+
+// pkcs8Attributes is a PKCS#8 private key with attributes.
+type pkcs8Attributes struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	Attributes []Attribute `asn1:"optional,tag:0,set"`
+}
+
+// ParsePKCS8Attributes parses the attributes of a PKCS#8 private key.
+func ParsePKCS8Attributes(der []byte) (Attributes, error) {
+	var v pkcs8Attributes
+	if _, err := asn1.Unmarshal(der, &v); err != nil {
+		return nil, err
+	}
+	return v.Attributes, nil
+}
+
+// AddPKCS8Attributes returns the PKCS#8 private key with the attributes,
+// replacing any existing attributes.
+func AddPKCS8Attributes(der []byte, attrs Attributes) ([]byte, error) {
+	var v pkcs8Attributes
+	if rest, err := asn1.Unmarshal(der, &v); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("trailing data after PKCS#8 private key")
+	}
+	v.Attributes = attrs
+	return asn1.Marshal(v)
+}
+
+// Attributes returns the private key's PKCS#8 attributes in the [Store].
+func (s Store) Attributes() (Attributes, bool) {
+	attrs, ok := s[KeyAttributes].(Attributes)
+	return attrs, ok && len(attrs) != 0
+}
+
+// headerAttributes returns the attributes in the PEM block headers set by
+// [pkcs12.ToPEM].
+func headerAttributes(headers map[string]string) Attributes {
+	var attrs Attributes
+	if name, ok := headers["friendlyName"]; ok {
+		attrs.SetFriendlyName(name)
+	}
+	if id, err := hex.DecodeString(headers["localKeyId"]); err == nil && len(id) != 0 {
+		attrs.SetLocalKeyID(id)
+	}
+	return attrs
+}
+
+// attributesBlock returns the PKCS#8 "PRIVATE KEY" block with the attributes
+// for the private key.
+func attributesBlock(key interface{}, attrs Attributes) (*pem.Block, error) {
+	der, err := marshalPKCS8(key)
+	if err != nil {
+		return nil, err
+	}
+	if der, err = AddPKCS8Attributes(der, attrs); err != nil {
+		return nil, err
+	}
+	return &pem.Block{
+		Type:  PrivateKey.String(),
+		Bytes: der,
+	}, nil
+}
+
+// isPKCS8Key determines if the private key can be encoded in PKCS#8 form.
+func isPKCS8Key(key interface{}) bool {
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey, ed448.PrivateKey:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package pemutil
+
+import (
+	"bytes"
+	"errors"
+)
+
+// maxBERDepth is the maximum nesting depth of BER encoded data.
+const maxBERDepth = 64
+
+// BERToDER re-encodes BER encoded ASN.1 data as DER, allowing data produced
+// by some HSMs and Java tools (ie, data with indefinite lengths, non-minimal
+// lengths, or constructed strings) to be parsed by Go's DER-strict parsers.
+//
+// DER encoded data is returned unchanged. SET OF elements are not sorted.
+func BERToDER(buf []byte) ([]byte, error) {
+	n, rest, err := parseBER(buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data after BER value")
+	}
+	var b bytes.Buffer
+	n.encode(&b)
+	return b.Bytes(), nil
+}
+
+// berNode is a BER encoded value.
+type berNode struct {
+	id          []byte
+	constructed bool
+	content     []byte
+	children    []*berNode
+}
+
+// parseBER parses a BER encoded value from buf, returning the value and the
+// remaining data.
+func parseBER(buf []byte, depth int) (*berNode, []byte, error) {
+	if depth > maxBERDepth {
+		return nil, nil, errors.New("BER value nested too deeply")
+	}
+	// identifier
+	if len(buf) < 2 {
+		return nil, nil, errors.New("truncated BER value")
+	}
+	i := 1
+	if buf[0]&0x1f == 0x1f {
+		for ; i < len(buf) && buf[i]&0x80 != 0; i++ {
+		}
+		if i++; i >= len(buf) {
+			return nil, nil, errors.New("truncated BER tag")
+		}
+	}
+	n := &berNode{
+		id:          buf[:i],
+		constructed: buf[0]&0x20 != 0,
+	}
+	// length
+	l := int(buf[i])
+	i++
+	switch {
+	case l == 0x80:
+		if !n.constructed {
+			return nil, nil, errors.New("indefinite length primitive BER value")
+		}
+		rest := buf[i:]
+		for {
+			if len(rest) < 2 {
+				return nil, nil, errors.New("missing BER end-of-contents")
+			}
+			if rest[0] == 0 && rest[1] == 0 {
+				return n, rest[2:], nil
+			}
+			child, r, err := parseBER(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			n.children, rest = append(n.children, child), r
+		}
+	case l > 0x80:
+		c := l & 0x7f
+		if c > 4 || len(buf) < i+c {
+			return nil, nil, errors.New("invalid BER length")
+		}
+		l = 0
+		for _, b := range buf[i : i+c] {
+			l = l<<8 | int(b)
+		}
+		i += c
+	}
+	if l < 0 || len(buf)-i < l {
+		return nil, nil, errors.New("truncated BER value")
+	}
+	n.content = buf[i : i+l]
+	if n.constructed {
+		for rest := n.content; len(rest) != 0; {
+			child, r, err := parseBER(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			n.children, rest = append(n.children, child), r
+		}
+	}
+	return n, buf[i+l:], nil
+}
+
+// isString determines if the value is a universal string type, that must be
+// primitive encoded in DER.
+func (n *berNode) isString() bool {
+	if n.id[0]&0xc0 != 0 || len(n.id) != 1 {
+		return false
+	}
+	switch n.id[0] & 0x1f {
+	case 3, 4, 12, 18, 19, 20, 21, 22, 25, 26, 27, 28, 30:
+		return true
+	}
+	return false
+}
+
+// flatten returns the concatenated content of a constructed string. For
+// BIT STRINGs, each segment's leading unused bits octet is removed, and the
+// last segment's unused bits octet is used.
+func (n *berNode) flatten() []byte {
+	if !n.constructed {
+		return n.content
+	}
+	bitString := n.id[0]&0x1f == 3
+	var b []byte
+	var unused byte
+	for _, child := range n.children {
+		buf := child.flatten()
+		if bitString && len(buf) != 0 {
+			unused, buf = buf[0], buf[1:]
+		}
+		b = append(b, buf...)
+	}
+	if bitString {
+		b = append([]byte{unused}, b...)
+	}
+	return b
+}
+
+// encode writes the DER encoding of the value to b.
+func (n *berNode) encode(b *bytes.Buffer) {
+	var content []byte
+	id := append([]byte(nil), n.id...)
+	switch {
+	case n.constructed && n.isString():
+		id[0] &^= 0x20
+		content = n.flatten()
+	case n.constructed:
+		var c bytes.Buffer
+		for _, child := range n.children {
+			child.encode(&c)
+		}
+		content = c.Bytes()
+	default:
+		content = n.content
+	}
+	b.Write(id)
+	writeDERLength(b, len(content))
+	b.Write(content)
+}
+
+// writeDERLength writes the minimal DER length encoding to b.
+func writeDERLength(b *bytes.Buffer, l int) {
+	if l < 0x80 {
+		b.WriteByte(byte(l))
+		return
+	}
+	var v []byte
+	for ; l > 0; l >>= 8 {
+		v = append([]byte{byte(l)}, v...)
+	}
+	b.WriteByte(0x80 | byte(len(v)))
+	b.Write(v)
+}
@@ -0,0 +1,80 @@
+package pemutil
+
+import (
+	"bytes"
+	"encoding/pem"
+	"os"
+	"testing"
+)
+
+func TestBERToDER(t *testing.T) {
+	tests := []struct {
+		ber, der []byte
+	}{
+		// der unchanged
+		{[]byte{0x30, 0x03, 0x02, 0x01, 0x01}, []byte{0x30, 0x03, 0x02, 0x01, 0x01}},
+		// indefinite length
+		{[]byte{0x30, 0x80, 0x02, 0x01, 0x01, 0x00, 0x00}, []byte{0x30, 0x03, 0x02, 0x01, 0x01}},
+		// non-minimal length
+		{[]byte{0x04, 0x82, 0x00, 0x01, 'a'}, []byte{0x04, 0x01, 'a'}},
+		// constructed octet string
+		{[]byte{0x24, 0x80, 0x04, 0x02, 'a', 'b', 0x04, 0x01, 'c', 0x00, 0x00}, []byte{0x04, 0x03, 'a', 'b', 'c'}},
+		// constructed bit string
+		{[]byte{0x23, 0x08, 0x03, 0x02, 0x00, 0xff, 0x03, 0x02, 0x04, 0xf0}, []byte{0x03, 0x03, 0x04, 0xff, 0xf0}},
+	}
+	for i, test := range tests {
+		der, err := BERToDER(test.ber)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !bytes.Equal(der, test.der) {
+			t.Errorf("test %d expected %x, got: %x", i, test.der, der)
+		}
+	}
+	for i, buf := range [][]byte{
+		{0x30, 0x80, 0x02, 0x01, 0x01},
+		{0x04, 0x80, 0x00, 0x00},
+		{0x04, 0x05, 'a'},
+		{0x02, 0x01, 0x01, 0x00},
+	} {
+		if _, err := BERToDER(buf); err == nil {
+			t.Errorf("test %d expected error, got nil", i)
+		}
+	}
+}
+
+func TestParseBERPrivateKey(t *testing.T) {
+	buf, err := os.ReadFile("testdata/pkcs8-private.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		t.Fatalf("expected PEM block")
+	}
+	ber := indefiniteBER(block.Bytes)
+	if _, err := ParsePKCSPrivateKey(ber); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	// pkcs12
+	buf, err = os.ReadFile("testdata/ec.p12")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := DecodePKCS12(indefiniteBER(buf), StaticPassword("secret"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := s.ECPrivateKey(); !ok {
+		t.Errorf("expected EC private key")
+	}
+}
+
+// indefiniteBER re-encodes the outer DER SEQUENCE with an indefinite length.
+func indefiniteBER(der []byte) []byte {
+	n, _, err := parseBER(der, 0)
+	if err != nil {
+		panic(err)
+	}
+	return append(append([]byte{0x30, 0x80}, n.content...), 0x00, 0x00)
+}
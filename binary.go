@@ -0,0 +1,126 @@
+package pemutil
+
+import (
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// binaryMagic is the prefix of the binary encoding of a [Store].
+const binaryMagic = "PEMUTIL\x01"
+
+// MarshalBinary satisfies the [encoding.BinaryMarshaler] interface, encoding
+// the exportable crypto primitives in the [Store] (see [Store.Bytes]) in a
+// compact binary form, as DER without the PEM base64 armoring. Useful for
+// passing a [Store] between processes, such as from a privilege separated key
+// loader over a pipe or socket.
+//
+// Key purposes (see [Store.SetPurposes]) are encoded with the private key,
+// and key attributes (see [KeyAttributes]) are encoded with PKCS#8 private
+// keys, as with [Store.Bytes]. Explanatory text (see [WithExplanatoryText]),
+// non-exportable private keys, and key attributes for other private keys are
+// not encoded. A [Store] without exportable crypto primitives is encoded as
+// an empty [Store].
+//
+// As a [Store] satisfies the [encoding.BinaryMarshaler] and
+// [encoding.BinaryUnmarshaler] interfaces, it can be directly sent and
+// received using [encoding/gob].
+func (s Store) MarshalBinary() ([]byte, error) {
+	blocks, err := s.blocks(false)
+	if err != nil && !errors.Is(err, errNoExportable) {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(binaryMagic)+blocksLen(blocks))
+	buf = append(buf, binaryMagic...)
+	buf = binary.AppendUvarint(buf, uint64(len(blocks)))
+	for _, block := range blocks {
+		switch BlockType(block.Type) {
+		case PrivateKey, RSAPrivateKey, ECPrivateKey:
+			block = s.purposeBlock(block)
+		}
+		buf = appendBinaryString(buf, block.Type)
+		keys := make([]string, 0, len(block.Headers))
+		for k := range block.Headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf = binary.AppendUvarint(buf, uint64(len(keys)))
+		for _, k := range keys {
+			buf = appendBinaryString(buf, k)
+			buf = appendBinaryString(buf, block.Headers[k])
+		}
+		buf = appendBinaryString(buf, string(block.Bytes))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary satisfies the [encoding.BinaryUnmarshaler] interface,
+// decoding the crypto primitives encoded by [Store.MarshalBinary] into the
+// [Store].
+func (s *Store) UnmarshalBinary(buf []byte) error {
+	if len(buf) < len(binaryMagic) || string(buf[:len(binaryMagic)]) != binaryMagic {
+		return errors.New("invalid binary store")
+	}
+	d := binaryDecoder(buf[len(binaryMagic):])
+	n := d.uvarint()
+	if *s == nil {
+		*s = make(Store)
+	}
+	for i := uint64(0); i < n && d != nil; i++ {
+		block := &pem.Block{
+			Type: d.string(),
+		}
+		if m := d.uvarint(); m != 0 {
+			block.Headers = make(map[string]string)
+			for j := uint64(0); j < m && d != nil; j++ {
+				k := d.string()
+				block.Headers[k] = d.string()
+			}
+		}
+		block.Bytes = []byte(d.string())
+		if d == nil {
+			break
+		}
+		if err := s.DecodeBlock(block); err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+	}
+	if d == nil || len(d) != 0 {
+		return errors.New("invalid binary store")
+	}
+	return nil
+}
+
+// appendBinaryString appends the length prefixed string to buf.
+func appendBinaryString(buf []byte, s string) []byte {
+	return append(binary.AppendUvarint(buf, uint64(len(s))), s...)
+}
+
+// binaryDecoder decodes the binary encoding of a [Store]. Set to nil when the
+// data is invalid.
+type binaryDecoder []byte
+
+// uvarint decodes a uvarint.
+func (d *binaryDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(*d)
+	if n <= 0 {
+		*d = nil
+		return 0
+	}
+	*d = (*d)[n:]
+	return v
+}
+
+// string decodes a length prefixed string.
+func (d *binaryDecoder) string() string {
+	n := d.uvarint()
+	if n > uint64(len(*d)) {
+		*d = nil
+		return ""
+	}
+	s := string((*d)[:n])
+	*d = (*d)[n:]
+	return s
+}
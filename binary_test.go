@@ -0,0 +1,84 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/gob"
+	"testing"
+	"time"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SelfSign(ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s.SetPurposes(PurposeTLSServer)
+	sym, err := GenerateSymmetricKeySet(32, WithTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, test := range []Store{s, sym} {
+		buf, err := test.MarshalBinary()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		var s0 Store
+		if err := s0.UnmarshalBinary(buf); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		exp, err := test.Bytes()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		actual, err := s0.Bytes()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !bytes.Equal(exp, actual) {
+			t.Errorf("test %d expected:\n%s\ngot:\n%s", i, exp, actual)
+		}
+		if pem, err := test.Bytes(); err == nil && len(buf) >= len(pem) {
+			t.Errorf("test %d expected binary encoding smaller than %d, got: %d", i, len(pem), len(buf))
+		}
+		// truncated
+		for _, n := range []int{0, len(binaryMagic), len(buf) - 1} {
+			var s1 Store
+			if err := s1.UnmarshalBinary(buf[:n]); err == nil {
+				t.Errorf("test %d expected error for %d bytes", i, n)
+			}
+		}
+	}
+	// gob
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(s); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var s0 Store
+	if err := gob.NewDecoder(&b).Decode(&s0); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !EqualKeys(s[PublicKey], s0[PublicKey]) || len(s0.Certificates()) != 1 {
+		t.Errorf("expected equal store, got: %v", keys(s0))
+	}
+	if purposes, _ := s0.Purposes(); len(purposes) != 1 || purposes[0] != PurposeTLSServer {
+		t.Errorf("expected tls server purpose, got: %v", purposes)
+	}
+	// empty
+	for i, test := range []Store{{}, {ExplanatoryText: explanatoryText{}}} {
+		var b bytes.Buffer
+		if err := gob.NewEncoder(&b).Encode(test); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		s0 := Store{}
+		if err := gob.NewDecoder(&b).Decode(&s0); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if len(s0) != 0 {
+			t.Errorf("test %d expected empty store, got: %v", i, keys(s0))
+		}
+	}
+}
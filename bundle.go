@@ -0,0 +1,63 @@
+package pemutil
+
+import (
+	"crypto/x509"
+)
+
+// Certificate bundles.
+//
+// Decoding PEM data containing more than one CERTIFICATE block (such as a
+// certificate chain, or a CA bundle) stores the certificates under the
+// [Certificate] block type as a []*x509.Certificate, in decoded order. A
+// single CERTIFICATE block continues to be stored as a *x509.Certificate.
+// Use [Store.Certificate] and [Store.Certificates] to retrieve certificates
+// regardless of how they are stored.
+
+// addCertificate adds a certificate to the [Store], appending to any
+// certificates already present.
+func (s Store) addCertificate(cert *x509.Certificate) {
+	switch v := s[Certificate].(type) {
+	case *x509.Certificate:
+		s[Certificate] = []*x509.Certificate{v, cert}
+	case []*x509.Certificate:
+		s[Certificate] = append(v, cert)
+	case *lazyCertificates:
+		v.addCertificate(cert)
+	default:
+		s[Certificate] = cert
+	}
+}
+
+// Certificate returns the X509 certificate contained within the [Store]. When
+// the [Store] contains multiple certificates, the first is returned.
+func (s Store) Certificate() (*x509.Certificate, bool) {
+	if l, ok := s[Certificate].(*lazyCertificates); ok {
+		if l.len() == 0 {
+			return nil, false
+		}
+		cert, err := l.at(0)
+		return cert, err == nil
+	}
+	certs := s.Certificates()
+	if len(certs) == 0 {
+		return nil, false
+	}
+	return certs[0], true
+}
+
+// Certificates returns all X509 certificates contained within the [Store].
+//
+// Lazily decoded certificates (see [WithLazyLoad]) that cannot be parsed are
+// omitted. Use [Store.Resolve] to check for parse errors.
+func (s Store) Certificates() []*x509.Certificate {
+	switch v := s[Certificate].(type) {
+	case *x509.Certificate:
+		return []*x509.Certificate{v}
+	case []*x509.Certificate:
+		return v
+	case *lazyCertificates:
+		certs, _ := v.all()
+		return certs
+	}
+	return nil
+}
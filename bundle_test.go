@@ -0,0 +1,90 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCertificateBundle(t *testing.T) {
+	s := Store{Certificate: testCertificates(t, 3)}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := strings.Count(string(buf), "BEGIN CERTIFICATE"); n != 3 {
+		t.Errorf("expected 3 certificates, got: %d", n)
+	}
+	s0, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	certs := s0.Certificates()
+	if len(certs) != 3 {
+		t.Fatalf("expected 3 certificates, got: %d", len(certs))
+	}
+	if cert, ok := s0.Certificate(); !ok || cert != certs[0] {
+		t.Errorf("expected first certificate")
+	}
+}
+
+func TestCertificateBundleDecode(t *testing.T) {
+	exp := testCertificates(t, 3)
+	for n := 1; n <= len(exp); n++ {
+		buf, err := Store{Certificate: exp[:n]}.Bytes()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", n, err)
+		}
+		s, err := DecodeBytes(buf)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", n, err)
+		}
+		switch v := s[Certificate].(type) {
+		case *x509.Certificate:
+			if n != 1 {
+				t.Errorf("test %d expected []*x509.Certificate, got: %T", n, v)
+			}
+		case []*x509.Certificate:
+			if n == 1 {
+				t.Errorf("test %d expected *x509.Certificate, got: %T", n, v)
+			}
+		default:
+			t.Fatalf("test %d expected certificate, got: %T", n, v)
+		}
+		certs := s.Certificates()
+		if len(certs) != n {
+			t.Fatalf("test %d expected %d certificates, got: %d", n, n, len(certs))
+		}
+		for i, cert := range certs {
+			if !cert.Equal(exp[i]) {
+				t.Errorf("test %d expected certificate %d in decoded order", n, i)
+			}
+		}
+		if cert, ok := s.Certificate(); !ok || !cert.Equal(exp[0]) {
+			t.Errorf("test %d expected first certificate", n)
+		}
+	}
+}
+
+func TestCertificateBundleRepeatedBlock(t *testing.T) {
+	buf, err := os.ReadFile("testdata/rsa-public.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	_, err = DecodeBytes(bytes.Join([][]byte{buf, buf}, nil))
+	if err == nil || !strings.Contains(err.Error(), "already present") {
+		t.Errorf("expected already present error, got: %v", err)
+	}
+}
+
+func TestCertificateBundleEmpty(t *testing.T) {
+	s := Store{}
+	if _, ok := s.Certificate(); ok {
+		t.Errorf("expected no certificate")
+	}
+	if certs := s.Certificates(); certs != nil {
+		t.Errorf("expected no certificates, got: %d", len(certs))
+	}
+}
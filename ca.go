@@ -0,0 +1,216 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// Default certificate validity periods.
+const (
+	// LeafValidity is the default validity for leaf certificates.
+	LeafValidity = 397 * 24 * time.Hour
+	// IntermediateValidity is the default validity for intermediate CA
+	// certificates.
+	IntermediateValidity = 5 * 365 * 24 * time.Hour
+	// RootValidity is the default validity for root CA certificates.
+	RootValidity = 10 * 365 * 24 * time.Hour
+)
+
+// backdate is the amount certificate validity is backdated, to allow for
+// clock skew.
+const backdate = 5 * time.Minute
+
+// ServerTemplate returns a TLS server certificate template for the hosts
+// (DNS names or IP addresses). The first host is used as the subject common
+// name.
+func ServerTemplate(hosts ...string) *x509.Certificate {
+	tpl := newTemplate("", LeafValidity)
+	if len(hosts) != 0 {
+		tpl.Subject.CommonName = hosts[0]
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			tpl.IPAddresses = append(tpl.IPAddresses, ip)
+		} else {
+			tpl.DNSNames = append(tpl.DNSNames, host)
+		}
+	}
+	tpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	return tpl
+}
+
+// ClientTemplate returns a TLS client certificate template for the common
+// name.
+func ClientTemplate(commonName string) *x509.Certificate {
+	tpl := newTemplate(commonName, LeafValidity)
+	tpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	return tpl
+}
+
+// CodeSigningTemplate returns a code signing certificate template for the
+// common name.
+func CodeSigningTemplate(commonName string) *x509.Certificate {
+	tpl := newTemplate(commonName, LeafValidity)
+	tpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}
+	return tpl
+}
+
+// IntermediateCATemplate returns a intermediate CA certificate template for
+// the common name, with the max path length and, when provided, the permitted
+// DNS domain name constraints.
+func IntermediateCATemplate(commonName string, maxPathLen int, permittedDNSDomains ...string) *x509.Certificate {
+	tpl := newTemplate(commonName, IntermediateValidity)
+	tpl.IsCA, tpl.BasicConstraintsValid = true, true
+	tpl.MaxPathLen, tpl.MaxPathLenZero = maxPathLen, maxPathLen == 0
+	tpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	if len(permittedDNSDomains) != 0 {
+		tpl.PermittedDNSDomains = permittedDNSDomains
+		tpl.PermittedDNSDomainsCritical = true
+	}
+	return tpl
+}
+
+// RootCATemplate returns a root CA certificate template for the common name.
+func RootCATemplate(commonName string) *x509.Certificate {
+	tpl := newTemplate(commonName, RootValidity)
+	tpl.IsCA, tpl.BasicConstraintsValid = true, true
+	tpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	return tpl
+}
+
+// newTemplate creates a certificate template, valid from the current time
+// ([DefaultClock]).
+func newTemplate(commonName string, validity time.Duration) *x509.Certificate {
+	tpl := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+	}
+	SetTemplateValidity(tpl, validity)
+	return tpl
+}
+
+// SetTemplateValidity sets the certificate template's validity period to
+// start at the current time ([DefaultClock], or the clock set with
+// [WithClock]), backdated to allow for clock skew, and end after validity.
+// Useful to set the validity of the templates (such as [ServerTemplate]) using
+// an explicit clock.
+func SetTemplateValidity(tpl *x509.Certificate, validity time.Duration, opts ...ValidityOption) {
+	now := newValidityOptions(opts...).clock.Now()
+	tpl.NotBefore, tpl.NotAfter = now.Add(-backdate), now.Add(validity)
+}
+
+// SelfSign creates a self-signed certificate from the template using the
+// private key in the [Store], adding the certificate to the [Store].
+func (s Store) SelfSign(tpl *x509.Certificate) (*x509.Certificate, error) {
+	signer, ok := s.Signer()
+	if !ok {
+		return nil, errors.New("store does not contain a private key")
+	}
+	if tpl.IsCA {
+		if err := s.CheckPurpose(PurposeCA); err != nil {
+			return nil, err
+		}
+	}
+	cert, err := createCertificate(tpl, tpl, signer.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+	s.addCertificate(cert)
+	return cert, nil
+}
+
+// Issue issues a certificate for the public key from the template, signed
+// by the CA certificate and private key in the [Store].
+func (s Store) Issue(tpl *x509.Certificate, pub crypto.PublicKey) (*x509.Certificate, error) {
+	signer, ok := s.Signer()
+	if !ok {
+		return nil, errors.New("store does not contain a private key")
+	}
+	if err := s.CheckPurpose(PurposeCA); err != nil {
+		return nil, err
+	}
+	ca, ok := s.Certificate()
+	if !ok {
+		return nil, errors.New("store does not contain a certificate")
+	}
+	if !ca.IsCA {
+		return nil, errors.New("store certificate is not a CA certificate")
+	}
+	return createCertificate(tpl, ca, pub, signer)
+}
+
+// CrossSign issues a certificate for the public key from the template under
+// each of the CA stores (ie, cross-signs the public key), returning a chain
+// for each CA store. Each chain contains the issued certificate followed by
+// the certificates in the CA store.
+//
+// The issued certificates share the same subject and subject key id, so that
+// either chain can be used to validate certificates issued by the public
+// key's private key.
+func CrossSign(tpl *x509.Certificate, pub crypto.PublicKey, cas ...Store) ([]Store, error) {
+	if len(cas) < 2 {
+		return nil, errors.New("cross-signing requires at least two CA stores")
+	}
+	z := *tpl
+	if len(z.SubjectKeyId) == 0 {
+		der, err := MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		var spki pkixPublicKey
+		if _, err := asn1.Unmarshal(der, &spki); err != nil {
+			return nil, err
+		}
+		h := sha1.Sum(spki.BitString.Bytes)
+		z.SubjectKeyId = h[:]
+	}
+	chains := make([]Store, len(cas))
+	for i, ca := range cas {
+		cert, err := ca.Issue(&z, pub)
+		if err != nil {
+			return nil, fmt.Errorf("CA %d: %w", i, err)
+		}
+		chains[i] = Store{Certificate: append([]*x509.Certificate{cert}, ca.Certificates()...)}
+	}
+	return chains, nil
+}
+
+// createCertificate creates a certificate from the template, assigning a
+// random serial number if the template does not have one.
+func createCertificate(tpl, parent *x509.Certificate, pub crypto.PublicKey, signer crypto.Signer) (*x509.Certificate, error) {
+	if tpl.SerialNumber == nil {
+		z := *tpl
+		serial, err := randomSerial()
+		if err != nil {
+			return nil, err
+		}
+		z.SerialNumber = serial
+		if tpl == parent {
+			parent = &z
+		}
+		tpl = &z
+	}
+	der, err := x509.CreateCertificate(Rand(), tpl, parent, pub, signer)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// randomSerial generates a random 128 bit serial number.
+func randomSerial() (*big.Int, error) {
+	return rand.Int(Rand(), new(big.Int).Lsh(big.NewInt(1), 128))
+}
@@ -0,0 +1,203 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTemplates(t *testing.T) {
+	root, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rootCert, err := root.SelfSign(RootCATemplate("root"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	inter, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	interCert, err := root.Issue(IntermediateCATemplate("intermediate", 0, "example.com"), inter[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !interCert.MaxPathLenZero || interCert.PermittedDNSDomains[0] != "example.com" {
+		t.Errorf("expected path length and name constraints")
+	}
+	if err := inter.AddX509(interCert); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	roots, inters := x509.NewCertPool(), x509.NewCertPool()
+	roots.AddCert(rootCert)
+	inters.AddCert(interCert)
+	leaf, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		tpl   *x509.Certificate
+		usage x509.ExtKeyUsage
+		err   bool
+	}{
+		{ServerTemplate("www.example.com", "127.0.0.1"), x509.ExtKeyUsageServerAuth, false},
+		{ServerTemplate("www.example.org"), x509.ExtKeyUsageServerAuth, true},
+		{ClientTemplate("client"), x509.ExtKeyUsageClientAuth, false},
+		{CodeSigningTemplate("signer"), x509.ExtKeyUsageCodeSigning, false},
+	}
+	for i, test := range tests {
+		cert, err := inter.Issue(test.tpl, leaf[PublicKey])
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		_, err = cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: inters,
+			KeyUsages:     []x509.ExtKeyUsage{test.usage},
+		})
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d expected error, got nil", i)
+		case !test.err && err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		}
+	}
+	// leaf cannot issue
+	if _, err := leaf.SelfSign(ClientTemplate("leaf")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := leaf.Issue(ClientTemplate("other"), leaf[PublicKey]); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestCrossSign(t *testing.T) {
+	var cas []Store
+	roots := make([]*x509.CertPool, 2)
+	for i := range roots {
+		ca, err := GenerateECKeySet(elliptic.P256())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cert, err := ca.SelfSign(RootCATemplate("root"))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		roots[i] = x509.NewCertPool()
+		roots[i].AddCert(cert)
+		cas = append(cas, ca)
+	}
+	inter, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	chains, err := CrossSign(IntermediateCATemplate("intermediate", 0), inter[PublicKey], cas...)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(chains) != 2 {
+		t.Fatalf("expected 2 chains, got: %d", len(chains))
+	}
+	a, _ := chains[0].Certificate()
+	b, _ := chains[1].Certificate()
+	if a.Subject.String() != b.Subject.String() || string(a.SubjectKeyId) != string(b.SubjectKeyId) {
+		t.Errorf("expected cross-signed certificates to have same subject and subject key id")
+	}
+	// leaf issued by the intermediate verifies under either root
+	if err := inter.AddX509(a); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leaf, err := inter.Issue(ServerTemplate("example.com"), inter[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, chain := range chains {
+		inters := x509.NewCertPool()
+		inters.AddCert(chain.Certificates()[0])
+		if _, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         roots[i],
+			Intermediates: inters,
+			DNSName:       "example.com",
+		}); err != nil {
+			t.Errorf("chain %d expected no error, got: %v", i, err)
+		}
+	}
+}
+
+func TestGenerateCRL(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	caCert, err := ca.SelfSign(RootCATemplate("root"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, err := ca.Issue(ServerTemplate("example.com"), ca[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var revs Revocations
+	revs.Revoke(cert.SerialNumber, ReasonUnspecified)
+	revs.Revoke(cert.SerialNumber, ReasonKeyCompromise)
+	for i := 1; i <= 2; i++ {
+		buf, err := GenerateCRL(ca, revs, time.Now().Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		s, err := DecodeBytes(buf)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		crl, ok := s.RevocationList()
+		if !ok {
+			t.Fatalf("expected revocation list")
+		}
+		if err := crl.CheckSignatureFrom(caCert); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+		if crl.Number.Int64() != int64(i) {
+			t.Errorf("expected CRL number %d, got: %d", i, crl.Number)
+		}
+		if len(crl.RevokedCertificateEntries) != 1 || crl.RevokedCertificateEntries[0].ReasonCode != ReasonKeyCompromise {
+			t.Errorf("expected 1 key compromise revocation, got: %v", crl.RevokedCertificateEntries)
+		}
+	}
+}
+
+func TestGenerateCRLClock(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ca.SelfSign(RootCATemplate("root")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := WithClock(ClockFunc(func() time.Time { return now }))
+	var revs Revocations
+	revs.Revoke(big.NewInt(1000), ReasonKeyCompromise, clock)
+	if !revs[0].RevocationTime.Equal(now) {
+		t.Errorf("expected revocation time %v, got: %v", now, revs[0].RevocationTime)
+	}
+	// next update is in the past according to the clock
+	if _, err := GenerateCRL(ca, revs, time.Now().Add(time.Hour), clock); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	if _, err := GenerateCRL(ca, revs, now.Add(24*time.Hour), clock); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	crl, ok := ca.RevocationList()
+	if !ok {
+		t.Fatalf("expected revocation list")
+	}
+	if !crl.ThisUpdate.Equal(now) {
+		t.Errorf("expected this update %v, got: %v", now, crl.ThisUpdate)
+	}
+	if len(crl.RevokedCertificateEntries) != 1 || !crl.RevokedCertificateEntries[0].RevocationTime.Equal(now) {
+		t.Errorf("expected revocation time %v, got: %v", now, crl.RevokedCertificateEntries)
+	}
+}
@@ -0,0 +1,104 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CAFileOption is a CAfile option.
+type CAFileOption func(*caFileOptions)
+
+// caFileOptions are CAfile options.
+type caFileOptions struct {
+	comments bool
+	expired  bool
+	clock    Clock
+	preds    []CertificatePredicate
+}
+
+// WithSubjectComments is a CAfile option to precede each certificate with a
+// comment containing the certificate's subject. Control characters in the
+// subject are escaped, and a subject containing "-----" is rejected.
+func WithSubjectComments() CAFileOption {
+	return func(opts *caFileOptions) {
+		opts.comments = true
+	}
+}
+
+// WithExpired is a CAfile option to include expired certificates.
+func WithExpired() CAFileOption {
+	return func(opts *caFileOptions) {
+		opts.expired = true
+	}
+}
+
+// WithCAFileClock is a CAfile option to set the clock used to determine the
+// current time when filtering expired certificates (default [DefaultClock]).
+func WithCAFileClock(clock Clock) CAFileOption {
+	return func(opts *caFileOptions) {
+		opts.clock = clock
+	}
+}
+
+// WithCAFileFilter is a CAfile option to only include the certificates
+// matching all of the predicates (see [Store.Filter]), such as [ValidFor] or
+// [KeyUsage].
+func WithCAFileFilter(preds ...CertificatePredicate) CAFileOption {
+	return func(opts *caFileOptions) {
+		opts.preds = append(opts.preds, preds...)
+	}
+}
+
+// CAFile returns an OpenSSL-compatible CAfile containing the certificates in
+// the stores, concatenated in order. Duplicate certificates and, unless the
+// [WithExpired] option is passed, expired certificates are omitted.
+func CAFile(stores []Store, opts ...CAFileOption) ([]byte, error) {
+	o := caFileOptions{
+		clock: DefaultClock(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	now := o.clock.Now()
+	var certs []*x509.Certificate
+	text := make(explanatoryText)
+	seen := make(map[[32]byte]bool)
+	for _, s := range stores {
+		for _, cert := range s.Certificates() {
+			fp := Fingerprint(cert)
+			if seen[fp] || (!o.expired && now.After(cert.NotAfter)) || !matchCertificate(cert, o.preds) {
+				continue
+			}
+			seen[fp] = true
+			certs = append(certs, cert)
+			if o.comments {
+				comment := []byte("# " + escapeText(cert.Subject.String()) + "\n")
+				if err := checkText(comment); err != nil {
+					return nil, fmt.Errorf("certificate %q: %w", cert.Subject, err)
+				}
+				text[textKey(&pem.Block{Type: Certificate.String(), Bytes: cert.Raw})] = comment
+			}
+		}
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates")
+	}
+	s := Store{Certificate: certs}
+	if len(text) != 0 {
+		s[ExplanatoryText] = text
+	}
+	return s.Bytes()
+}
+
+// WriteCAFile writes an OpenSSL-compatible CAfile (see [CAFile]) containing
+// the certificates in the stores to filename with mode 0644.
+func WriteCAFile(filename string, stores []Store, opts ...CAFileOption) error {
+	buf, err := CAFile(stores, opts...)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf, 0o644)
+}
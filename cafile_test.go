@@ -0,0 +1,121 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/pem"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCAFile(t *testing.T) {
+	ca, err := LoadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	certs := testCertificates(t, 3)
+	stores := []Store{
+		{Certificate: append(ca.Certificates(), certs[:2]...)},
+		{Certificate: certs[1:]},
+	}
+	later := ClockFunc(func() time.Time {
+		return time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+	tests := []struct {
+		opts []CAFileOption
+		exp  int
+	}{
+		{nil, 4},
+		{[]CAFileOption{WithCAFileClock(later)}, 1},
+		{[]CAFileOption{WithCAFileClock(later), WithExpired()}, 4},
+	}
+	for i, test := range tests {
+		buf, err := CAFile(stores, test.opts...)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		s, err := DecodeBytes(buf)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if n := len(s.Certificates()); n != test.exp {
+			t.Errorf("test %d expected %d certificates, got: %d", i, test.exp, n)
+		}
+		if bytes.Contains(buf, []byte("# ")) {
+			t.Errorf("test %d expected no comments", i)
+		}
+	}
+	// comments
+	filename := filepath.Join(t.TempDir(), "ca.pem")
+	if err := WriteCAFile(filename, stores, WithSubjectComments()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := LoadFile(filename, WithExplanatoryText())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, exp := range []string{
+		"# CN=Go Daddy Root Certificate Authority - G2,O=GoDaddy.com\\, Inc.,L=Scottsdale,ST=Arizona,C=US\n-----BEGIN CERTIFICATE-----\n",
+		"# CN=test 2\n-----BEGIN CERTIFICATE-----\n",
+	} {
+		if !bytes.Contains(buf, []byte(exp)) {
+			t.Errorf("test %d expected %q, got:\n%s", i, exp, buf)
+		}
+	}
+	if _, err := CAFile(nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestCAFileHostileSubject(t *testing.T) {
+	key, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expired := RootCATemplate("expired")
+	expired.NotBefore, expired.NotAfter = time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+	cert, err := key.Clone().SelfSign(expired)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	block := pem.EncodeToMemory(&pem.Block{Type: Certificate.String(), Bytes: cert.Raw})
+	tests := []struct {
+		cn  string
+		err bool
+	}{
+		{"line 1\nline 2\r\x00", false},
+		{"evil\n" + string(block) + "# ", true},
+		{"evil -----BEGIN", true},
+	}
+	for i, test := range tests {
+		s := key.Clone()
+		if _, err := s.SelfSign(RootCATemplate(test.cn)); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		buf, err := CAFile([]Store{s}, WithSubjectComments())
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d expected error, got:\n%s", i, buf)
+			continue
+		case test.err:
+			continue
+		case err != nil:
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		s0, err := DecodeBytes(buf)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if n := len(s0.Certificates()); n != 1 {
+			t.Errorf("test %d expected 1 certificate, got: %d", i, n)
+		}
+		if exp := "# CN=line 1\\nline 2\\r\\x00\n-----BEGIN CERTIFICATE-----\n"; !bytes.Contains(buf, []byte(exp)) {
+			t.Errorf("test %d expected %q, got:\n%s", i, exp, buf)
+		}
+	}
+}
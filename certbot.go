@@ -0,0 +1,93 @@
+package pemutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LoadCertbotLineage loads the private key and certificate chain from a
+// certbot (ACME) live lineage directory (ie, /etc/letsencrypt/live/<name>),
+// following the privkey.pem and fullchain.pem symlinks to the current
+// versions in the archive directory. When fullchain.pem does not exist,
+// cert.pem and chain.pem are loaded instead.
+func LoadCertbotLineage(dir string, opts ...LoadOption) (Store, error) {
+	return LoadFiles(certbotFiles(dir), opts...)
+}
+
+// LoadCertbotLive loads each certbot lineage (see [LoadCertbotLineage]) in
+// the certbot live directory (ie, /etc/letsencrypt/live), returning the
+// stores by lineage name.
+func LoadCertbotLive(liveDir string, opts ...LoadOption) (map[string]Store, error) {
+	lineages, err := certbotLineages(liveDir)
+	if err != nil {
+		return nil, err
+	}
+	stores := make(map[string]Store, len(lineages))
+	for _, name := range lineages {
+		s, err := LoadCertbotLineage(filepath.Join(liveDir, name), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("lineage %s: %w", name, err)
+		}
+		stores[name] = s
+	}
+	return stores, nil
+}
+
+// LoadCertbotMultiCertStore creates a multi certificate store (see
+// [LoadMultiCertStore]) for the certbot lineages in the certbot live
+// directory (ie, /etc/letsencrypt/live). Use [MultiCertStore.Watch] to pick
+// up renewals.
+//
+// Note: lineages created after the multi certificate store was loaded are
+// not picked up.
+func LoadCertbotMultiCertStore(liveDir string) (*MultiCertStore, error) {
+	lineages, err := certbotLineages(liveDir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([][]string, len(lineages))
+	for i, name := range lineages {
+		files[i] = certbotFiles(filepath.Join(liveDir, name))
+	}
+	return LoadMultiCertStore(files...)
+}
+
+// certbotLineages returns the sorted names of the certbot lineages in the
+// certbot live directory.
+func certbotLineages(liveDir string) ([]string, error) {
+	entries, err := os.ReadDir(liveDir)
+	if err != nil {
+		return nil, err
+	}
+	var lineages []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(liveDir, entry.Name(), "privkey.pem")); err == nil {
+			lineages = append(lineages, entry.Name())
+		}
+	}
+	if len(lineages) == 0 {
+		return nil, errors.New("no certbot lineages in " + liveDir)
+	}
+	sort.Strings(lineages)
+	return lineages, nil
+}
+
+// certbotFiles returns the files to load for a certbot lineage.
+func certbotFiles(dir string) []string {
+	fullchain := filepath.Join(dir, "fullchain.pem")
+	if _, err := os.Stat(fullchain); err == nil {
+		return []string{filepath.Join(dir, "privkey.pem"), fullchain}
+	}
+	return []string{
+		filepath.Join(dir, "privkey.pem"),
+		filepath.Join(dir, "cert.pem"),
+		filepath.Join(dir, "chain.pem"),
+	}
+}
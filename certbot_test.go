@@ -0,0 +1,123 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestCertbot(t *testing.T) {
+	root := t.TempDir()
+	live := filepath.Join(root, "live")
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ca.SelfSign(RootCATemplate("ca")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	chain, err := ca.Only(Certificate).Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// issue writes a certbot archive version for the lineage, and points the
+	// live symlinks at it
+	issue := func(name string, version int) Store {
+		t.Helper()
+		s, err := GenerateECKeySet(elliptic.P256())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cert, err := ca.Issue(ServerTemplate(name), s[PublicKey])
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if err := s.AddX509(cert); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		certBuf, err := s.Only(Certificate).Bytes()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		keyBuf, err := s.Only(ECPrivateKey).Bytes()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		archive, dir := filepath.Join(root, "archive", name), filepath.Join(live, name)
+		for _, d := range []string{archive, dir} {
+			if err := os.MkdirAll(d, 0o755); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+		}
+		for file, buf := range map[string][]byte{
+			"privkey":   keyBuf,
+			"cert":      certBuf,
+			"chain":     chain,
+			"fullchain": append(append([]byte(nil), certBuf...), chain...),
+		} {
+			target := filepath.Join(archive, file+strconv.Itoa(version)+".pem")
+			if err := os.WriteFile(target, buf, 0o600); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			link := filepath.Join(dir, file+".pem")
+			_ = os.Remove(link)
+			if err := os.Symlink(filepath.Join("..", "..", "archive", name, file+strconv.Itoa(version)+".pem"), link); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+		}
+		return s
+	}
+	a, b := issue("a.example.com", 1), issue("b.example.com", 1)
+	if err := os.WriteFile(filepath.Join(live, "README"), []byte("readme"), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	stores, err := LoadCertbotLive(live, WithDuplicateError())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(stores) != 2 {
+		t.Fatalf("expected 2 lineages, got: %d", len(stores))
+	}
+	for name, exp := range map[string]Store{"a.example.com": a, "b.example.com": b} {
+		s := stores[name]
+		if key, ok := s.ECPrivateKey(); !ok || !key.Equal(exp[ECPrivateKey]) {
+			t.Errorf("%s expected private key", name)
+		}
+		if n := len(s.Certificates()); n != 2 {
+			t.Errorf("%s expected 2 certificates, got: %d", name, n)
+		}
+	}
+	// renewal
+	m, err := LoadCertbotMultiCertStore(live)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	a = issue("a.example.com", 2)
+	if err := m.Reload(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tc, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "a.example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cert, _ := a.Certificate(); !tc.Leaf.Equal(cert) {
+		t.Errorf("expected renewed certificate")
+	}
+	// without fullchain
+	if err := os.Remove(filepath.Join(live, "b.example.com", "fullchain.pem")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := LoadCertbotLineage(filepath.Join(live, "b.example.com"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := len(s.Certificates()); n != 2 {
+		t.Errorf("expected 2 certificates, got: %d", n)
+	}
+	if _, err := LoadCertbotLive(t.TempDir()); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
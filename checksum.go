@@ -0,0 +1,177 @@
+package pemutil
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrChecksumMismatch is the error returned when decoding and loading with
+// the [WithChecksum] or [WithChecksumFile] options and the data does not
+// match the expected checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// WithChecksum is a decode and load option to require that the SHA-256
+// checksum of the data matches the hex-encoded checksum before any of the
+// data is decoded. Returns an error wrapping [ErrChecksumMismatch] when the
+// checksum does not match.
+func WithChecksum(sum string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.checksum = sum
+	}
+}
+
+// WithChecksumFile is a decode and load option to require that the SHA-256
+// checksum of a loaded file matches its entry in the detached checksum file,
+// before any of the data is decoded. The checksum file uses the format
+// written by sha256sum ("<checksum>  <name>", one file per line) or the BSD
+// format ("SHA256 (<name>) = <checksum>"). Relative names are relative to
+// the checksum file's directory, and are compared to the loaded file's
+// absolute path.
+//
+// Returns an error when the file does not have an entry in the checksum
+// file, or an error wrapping [ErrChecksumMismatch] when the checksum does not
+// match. Only usable when loading files.
+func WithChecksumFile(filename string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.sumsFile = filename
+	}
+}
+
+// WithDetachedSignature is a decode and load option to require a valid
+// detached signature of the data (see [DetachedSignature]) by one of the
+// Ed25519 public keys, before any of the data is decoded. The signature is
+// read from the signature file, or, when filename is empty, from the loaded
+// file's name with a ".sig" suffix.
+func WithDetachedSignature(filename string, pubs ...ed25519.PublicKey) LoadOption {
+	return func(opts *loadOptions) {
+		opts.sigFile, opts.sigKeys = filename, append([]ed25519.PublicKey{}, pubs...)
+	}
+}
+
+// checksIntegrity returns true when the checksum, checksum file, or detached
+// signature options are set.
+func (o *loadOptions) checksIntegrity() bool {
+	return o.checksum != "" || o.sumsFile != "" || o.sigKeys != nil
+}
+
+// checkIntegrity checks the data against the checksum, checksum file, and
+// detached signature options.
+func (o *loadOptions) checkIntegrity(buf []byte) error {
+	name := o.source
+	if name == "" {
+		name = "data"
+	}
+	if o.checksum != "" {
+		if err := checkSHA256(buf, o.checksum); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if o.sumsFile != "" {
+		if o.source == "" {
+			return errors.New("checksum file requires loading a file")
+		}
+		sum, err := lookupChecksum(o.sumsFile, o.source)
+		if err != nil {
+			return err
+		}
+		if err := checkSHA256(buf, sum); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if o.sigKeys != nil {
+		sigFile := o.sigFile
+		if sigFile == "" {
+			if o.source == "" {
+				return errors.New("detached signature requires a signature file or loading a file")
+			}
+			sigFile = o.source + ".sig"
+		}
+		sig, err := os.ReadFile(sigFile)
+		if err != nil {
+			return err
+		}
+		block, _ := pem.Decode(sig)
+		if block == nil || block.Type != Signature.String() {
+			return fmt.Errorf("%s: invalid signature block", sigFile)
+		}
+		for _, pub := range o.sigKeys {
+			if ed25519.Verify(pub, buf, block.Bytes) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: invalid signature", name)
+	}
+	return nil
+}
+
+// checkSHA256 checks that the SHA-256 checksum of buf matches the hex-encoded
+// checksum.
+func checkSHA256(buf []byte, sum string) error {
+	exp, err := hex.DecodeString(sum)
+	if err != nil || len(exp) != sha256.Size {
+		return fmt.Errorf("invalid checksum %q", sum)
+	}
+	actual := sha256.Sum256(buf)
+	if subtle.ConstantTimeCompare(exp, actual[:]) != 1 {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// lookupChecksum returns the checksum for filename in the checksum file.
+func lookupChecksum(sumsFile, filename string) (string, error) {
+	buf, err := os.ReadFile(sumsFile)
+	if err != nil {
+		return "", err
+	}
+	target, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(sumsFile)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var sum, name string
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "SHA256 ("):
+			// BSD format
+			i := strings.LastIndex(line, ") = ")
+			if i == -1 {
+				return "", fmt.Errorf("%s: invalid line %q", sumsFile, line)
+			}
+			name, sum = line[len("SHA256 ("):i], line[i+len(") = "):]
+		default:
+			var ok bool
+			if sum, name, ok = strings.Cut(line, " "); !ok {
+				return "", fmt.Errorf("%s: invalid line %q", sumsFile, line)
+			}
+			// binary mode is indicated by a "*" before the name
+			name = strings.TrimPrefix(name, " ")
+			name = strings.TrimPrefix(name, "*")
+		}
+		name = filepath.FromSlash(name)
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		if name, err = filepath.Abs(name); err == nil && name == target {
+			return sum, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s is not in %s", filename, sumsFile)
+}
@@ -0,0 +1,171 @@
+package pemutil
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithChecksum(t *testing.T) {
+	s := Store{Certificate: testCertificates(t, 1)}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	sum := sha256.Sum256(buf)
+	if _, err := DecodeBytes(buf, WithChecksum(hex.EncodeToString(sum[:]))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	sum[0] ^= 0xff
+	if _, err := DecodeBytes(buf, WithChecksum(hex.EncodeToString(sum[:]))); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("expected ErrChecksumMismatch, got: %v", err)
+	}
+	if _, err := DecodeBytes(buf, WithChecksum("abcd")); err == nil || errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("expected invalid checksum error, got: %v", err)
+	}
+}
+
+func TestWithChecksumFile(t *testing.T) {
+	dir := t.TempDir()
+	s := Store{Certificate: testCertificates(t, 1)}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, name := range []string{"a.pem", "b.pem", "c.pem", "tampered.pem", "missing.pem"} {
+		if err := os.WriteFile(filepath.Join(dir, name), buf, 0o644); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	sum := sha256.Sum256(buf)
+	good := hex.EncodeToString(sum[:])
+	sum[0] ^= 0xff
+	bad := hex.EncodeToString(sum[:])
+	sums := "# checksums\n" +
+		good + "  a.pem\n" +
+		good + " *b.pem\n" +
+		"SHA256 (c.pem) = " + good + "\n" +
+		bad + "  tampered.pem\n"
+	sumsFile := filepath.Join(dir, "SHA256SUMS")
+	if err := os.WriteFile(sumsFile, []byte(sums), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name     string
+		mismatch bool
+		err      bool
+	}{
+		{"a.pem", false, false},
+		{"b.pem", false, false},
+		{"c.pem", false, false},
+		{"tampered.pem", true, true},
+		{"missing.pem", false, true},
+	}
+	for i, test := range tests {
+		s, err := LoadFile(filepath.Join(dir, test.name), WithChecksumFile(sumsFile))
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d expected error", i)
+		case !test.err && err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		case test.mismatch != errors.Is(err, ErrChecksumMismatch):
+			t.Errorf("test %d expected mismatch %t, got: %v", i, test.mismatch, err)
+		case !test.err && len(s.Certificates()) != 1:
+			t.Errorf("test %d expected 1 certificate, got: %v", i, keys(s))
+		}
+	}
+	if _, err := DecodeBytes(buf, WithChecksumFile(sumsFile)); err == nil {
+		t.Errorf("expected error")
+	}
+	// memory mapped files are read normally when checked
+	if _, err := LoadFile(filepath.Join(dir, "a.pem"), WithMmap(), WithChecksumFile(sumsFile)); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if _, err := LoadFile(filepath.Join(dir, "tampered.pem"), WithMmap(), WithChecksumFile(sumsFile)); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("expected ErrChecksumMismatch, got: %v", err)
+	}
+	// names are relative to the checksum file's directory, not the current
+	// directory
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	subSums := filepath.Join(sub, "SHA256SUMS")
+	if err := os.WriteFile(subSums, []byte(good+"  a.pem\n"), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer os.Chdir(wd)
+	if _, err := LoadFile("a.pem", WithChecksumFile(subSums)); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, err := LoadFile("a.pem", WithChecksumFile(sumsFile)); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestWithDetachedSignature(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := t.TempDir()
+	s := Store{Certificate: testCertificates(t, 2)}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	sig, err := DetachedSignature(buf, key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	filename := filepath.Join(dir, "bundle.pem")
+	if err := os.WriteFile(filename, buf, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(filename+".sig", sig, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s0, err := LoadFile(filename, WithDetachedSignature("", other, pub))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(s0.Certificates()) != 2 {
+		t.Errorf("expected 2 certificates, got: %v", keys(s0))
+	}
+	if _, err := DecodeBytes(buf, WithDetachedSignature(filename+".sig", pub)); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		buf     []byte
+		sigFile string
+		pubs    []ed25519.PublicKey
+	}{
+		{buf, filename + ".sig", []ed25519.PublicKey{other}},
+		{buf, filename + ".sig", nil},
+		{append(buf, '\n'), filename + ".sig", []ed25519.PublicKey{pub}},
+		{buf, filename, []ed25519.PublicKey{pub}},
+		{buf, filepath.Join(dir, "missing.sig"), []ed25519.PublicKey{pub}},
+		{buf, "", []ed25519.PublicKey{pub}},
+	}
+	for i, test := range tests {
+		if _, err := DecodeBytes(test.buf, WithDetachedSignature(test.sigFile, test.pubs...)); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ClientBundleLayout is a single file client certificate and private key
+// layout, as used by database clients.
+type ClientBundleLayout int
+
+// Client bundle layouts.
+const (
+	// MongoDBClientBundle is the layout expected by MongoDB's
+	// tlsCertificateKeyFile: the private key, followed by the client
+	// certificate, followed by the intermediate certificates in chain order.
+	MongoDBClientBundle ClientBundleLayout = iota
+	// PostgresClientBundle is the layout expected when pointing libpq's
+	// sslcert and sslkey at the same file: the client certificate, followed
+	// by the intermediate certificates in chain order, followed by the private
+	// key.
+	PostgresClientBundle
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (layout ClientBundleLayout) String() string {
+	switch layout {
+	case MongoDBClientBundle:
+		return "mongodb"
+	case PostgresClientBundle:
+		return "postgres"
+	}
+	return fmt.Sprintf("ClientBundleLayout(%d)", int(layout))
+}
+
+// ClientBundle returns the client certificate chain and private key in the
+// [Store] as a single PEM-encoded file using the layout. The client
+// certificate must be valid for client authentication, and the private key
+// must be exportable. The private key is written unencrypted and without PEM
+// headers, as OpenSSL based clients reject PEM headers other than
+// "Proc-Type".
+func (s Store) ClientBundle(layout ClientBundleLayout) ([]byte, error) {
+	if layout != MongoDBClientBundle && layout != PostgresClientBundle {
+		return nil, fmt.Errorf("invalid client bundle layout %d", int(layout))
+	}
+	_, chain, err := s.fullChain()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkClientAuth(chain[0]); err != nil {
+		return nil, err
+	}
+	keys := s.Only(PrivateKey, RSAPrivateKey, ECPrivateKey)
+	if len(keys.exportable()) == 0 {
+		return nil, errors.New("private key is not exportable")
+	}
+	certBuf, err := Store{Certificate: chain}.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	keyBuf, err := keys.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var buf []byte
+	if layout == MongoDBClientBundle {
+		buf = append(keyBuf, certBuf...)
+	} else {
+		buf = append(certBuf, keyBuf...)
+	}
+	if err := CheckClientBundle(buf, layout); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// WriteClientBundle writes the client certificate chain and private key in
+// the [Store] to filename with mode 0600, using the layout (see
+// [Store.ClientBundle]).
+func (s Store) WriteClientBundle(filename string, layout ClientBundleLayout, opts ...WriteOption) error {
+	buf, err := s.ClientBundle(layout)
+	if err != nil {
+		return err
+	}
+	return WriteFile(filename, buf, 0o600, opts...)
+}
+
+// CheckClientBundle checks that buf is a single file client certificate and
+// private key using the layout: exactly one unencrypted private key without
+// PEM headers, in the position required by the layout, and a client
+// certificate valid for client authentication matching the private key,
+// followed by any intermediate certificates.
+func CheckClientBundle(buf []byte, layout ClientBundleLayout) error {
+	var blocks []*pem.Block
+	for {
+		var block *pem.Block
+		if block, buf = pem.Decode(buf); block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) < 2 {
+		return errors.New("client bundle must contain a private key and a certificate")
+	}
+	var keyBlock *pem.Block
+	var certs []*x509.Certificate
+	for i, block := range blocks {
+		switch BlockType(block.Type) {
+		case PrivateKey, RSAPrivateKey, ECPrivateKey:
+			switch {
+			case keyBlock != nil:
+				return errors.New("client bundle contains multiple private keys")
+			case layout == MongoDBClientBundle && i != 0:
+				return fmt.Errorf("%s client bundle must start with the private key", layout)
+			case layout == PostgresClientBundle && i != len(blocks)-1:
+				return fmt.Errorf("%s client bundle must end with the private key", layout)
+			case len(block.Headers) != 0:
+				return errors.New("client bundle private key must not have PEM headers")
+			}
+			keyBlock = block
+		case Certificate:
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, cert)
+		default:
+			return fmt.Errorf("client bundle contains unexpected %s block", block.Type)
+		}
+	}
+	if keyBlock == nil {
+		return errors.New("client bundle does not contain a private key")
+	}
+	if len(certs) == 0 {
+		return errors.New("client bundle does not contain a certificate")
+	}
+	key := Store{}
+	if err := key.DecodeBlock(keyBlock); err != nil {
+		return fmt.Errorf("client bundle private key: %w", err)
+	}
+	signer, ok := key.Signer()
+	if !ok || !EqualKeys(signer.Public(), certs[0].PublicKey) {
+		return errors.New("client bundle private key does not match the first certificate")
+	}
+	return checkClientAuth(certs[0])
+}
+
+// checkClientAuth checks that the certificate is valid for client
+// authentication.
+func checkClientAuth(cert *x509.Certificate) error {
+	if len(cert.ExtKeyUsage) != 0 && !hasExtKeyUsage(cert, x509.ExtKeyUsageClientAuth) {
+		return errors.New("certificate is not valid for client authentication")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientBundle(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ca.SelfSign(RootCATemplate("root")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	client, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, err := ca.Issue(ClientTemplate("client"), client[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := client.AddX509(cert); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	certBuf, err := EncodePrimitive(cert)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	keyBuf, err := EncodePrimitive(client[ECPrivateKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		layout ClientBundleLayout
+		exp    []byte
+		other  ClientBundleLayout
+	}{
+		{MongoDBClientBundle, append(append([]byte(nil), keyBuf...), certBuf...), PostgresClientBundle},
+		{PostgresClientBundle, append(append([]byte(nil), certBuf...), keyBuf...), MongoDBClientBundle},
+	}
+	dir := t.TempDir()
+	for i, test := range tests {
+		filename := filepath.Join(dir, test.layout.String()+".pem")
+		if err := client.WriteClientBundle(filename, test.layout); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		checkFile(t, filename, test.exp, 0o600)
+		buf, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if err := CheckClientBundle(buf, test.other); err == nil {
+			t.Errorf("test %d expected order error for %s layout", i, test.other)
+		}
+	}
+	// key with headers
+	headers := bytes.Replace(keyBuf, []byte("KEY-----\n"), []byte("KEY-----\nKey-Purpose: signing\n\n"), 1)
+	if err := CheckClientBundle(append(headers, certBuf...), MongoDBClientBundle); err == nil {
+		t.Errorf("expected error for private key with headers")
+	}
+	// server certificate
+	server, err := ca.Issue(ServerTemplate("example.com"), client[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z := Store{ECPrivateKey: client[ECPrivateKey]}
+	if err := z.AddX509(server); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := z.ClientBundle(MongoDBClientBundle); err == nil {
+		t.Errorf("expected error for server certificate")
+	}
+}
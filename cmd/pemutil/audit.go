@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// finding is an audit finding.
+type finding struct {
+	File    string `json:"file"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// runAudit runs the audit command.
+func runAudit(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "output findings as JSON")
+	lint := fs.Bool("lint", false, "lint certificates against the CA/Browser Forum baseline requirements")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil audit [--json] [--lint] <path>...")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 {
+		fs.Usage()
+		return errors.New("must specify a path")
+	}
+	a := &auditor{
+		now:    pemutil.DefaultClock().Now(),
+		lint:   *lint,
+		stores: make(map[string]pemutil.Store),
+	}
+	for _, path := range pos {
+		if err := a.walk(strings.TrimSuffix(path, "/...")); err != nil {
+			return err
+		}
+	}
+	findings := a.findings()
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if findings == nil {
+			findings = []finding{}
+		}
+		if err := enc.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Printf("%s: %s: %s\n", f.File, f.Kind, f.Message)
+		}
+	}
+	if len(findings) != 0 {
+		return fmt.Errorf("%d findings", len(findings))
+	}
+	return nil
+}
+
+// auditor audits files.
+type auditor struct {
+	now  time.Time
+	lint bool
+	list []finding
+	// stores are the loaded stores, by file name.
+	stores map[string]pemutil.Store
+}
+
+// walk audits all files in the path.
+func (a *auditor) walk(path string) error {
+	return filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case !d.Type().IsRegular():
+			return nil
+		}
+		return a.audit(name, d)
+	})
+}
+
+// audit audits a file.
+func (a *auditor) audit(name string, d fs.DirEntry) error {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(buf, []byte("-----BEGIN ")) {
+		return nil
+	}
+	s, err := pemutil.DecodeBytes(buf)
+	if err != nil {
+		a.add(name, "error", err.Error())
+		return nil
+	}
+	if _, ok := s.PrivateKey(); ok {
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if fi.Mode().Perm()&0o004 != 0 {
+			a.add(name, "world-readable", fmt.Sprintf("private key file is world-readable (%s)", fi.Mode().Perm()))
+		}
+	}
+	if key, notAfter, ok := s.SymmetricKey(); ok {
+		if len(key) < 16 {
+			a.add(name, "weak-key", fmt.Sprintf("%d bit symmetric key", 8*len(key)))
+		}
+		if !notAfter.IsZero() && a.now.After(notAfter) {
+			a.add(name, "expired", fmt.Sprintf("symmetric key expired at %s", notAfter.Format(time.RFC3339)))
+		}
+	}
+	a.stores[name] = s
+	if key, ok := s.Signer(); ok {
+		a.auditKey(name, "", key.Public())
+	} else if pub, ok := s.PublicKey(); ok {
+		a.auditKey(name, "", pub)
+	}
+	for _, cert := range s.Certificates() {
+		subject := fmt.Sprintf("certificate %q", cert.Subject)
+		if err := pemutil.CheckValidity(cert, pemutil.WithClock(pemutil.ClockFunc(func() time.Time { return a.now })), pemutil.WithClockSkew(0)); err != nil {
+			a.add(name, "expired", err.Error())
+		}
+		switch cert.SignatureAlgorithm {
+		case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+			a.add(name, "weak-signature", fmt.Sprintf("%s signed with %s", subject, cert.SignatureAlgorithm))
+		}
+		a.auditKey(name, subject+" ", cert.PublicKey)
+		if a.lint {
+			for _, f := range pemutil.Lint(cert) {
+				a.add(name, "lint-"+f.Rule, fmt.Sprintf("%s: %s", subject, f.Message))
+			}
+		}
+	}
+	return nil
+}
+
+// auditKey audits a public key.
+func (a *auditor) auditKey(name, prefix string, pub crypto.PublicKey) {
+	switch k := pub.(type) {
+	case []byte:
+		return
+	case *rsa.PublicKey:
+		if k.N.BitLen() < pemutil.MinRSABitLen {
+			a.add(name, "weak-key", fmt.Sprintf("%s%d bit RSA key", prefix, k.N.BitLen()))
+		}
+		if k.E < pemutil.DefaultRSAExponent {
+			a.add(name, "weak-key", fmt.Sprintf("%sRSA key with public exponent %d", prefix, k.E))
+		}
+	case *ecdsa.PublicKey:
+		if n := k.Params().BitSize; n < 256 {
+			a.add(name, "weak-key", fmt.Sprintf("%s%d bit EC key", prefix, n))
+		}
+	}
+}
+
+// add adds a finding.
+func (a *auditor) add(name, kind, msg string) {
+	a.list = append(a.list, finding{
+		File:    name,
+		Kind:    kind,
+		Message: msg,
+	})
+}
+
+// findings returns the findings, including duplicate keys.
+func (a *auditor) findings() []finding {
+	list := a.list
+	for _, dup := range pemutil.FindDuplicateKeys(a.stores) {
+		for _, name := range dup.Sources {
+			list = append(list, finding{
+				File:    name,
+				Kind:    "duplicate-key",
+				Message: fmt.Sprintf("key %x also in %s", dup.Fingerprint[:8], strings.Join(without(dup.Sources, name), ", ")),
+			})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].File < list[j].File
+	})
+	return list
+}
+
+// without returns the strings in v without s.
+func without(v []string, s string) []string {
+	var z []string
+	for _, x := range v {
+		if x != s {
+			z = append(z, x)
+		}
+	}
+	return z
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// runCA runs the ca command.
+func runCA(args []string) error {
+	if len(args) == 0 || args[0] != "sign" {
+		fmt.Fprintln(os.Stderr, "usage: pemutil ca sign --ca <file> --csr-dir <dir> --out-dir <dir> [--profile server|client|code-signing] [--days <days>] [--passin <source>] [--dry-run] [--backup]")
+		return errors.New("must specify a ca command")
+	}
+	return runCASign(args[1:])
+}
+
+// runCASign runs the ca sign command.
+func runCASign(args []string) error {
+	fs := flag.NewFlagSet("ca sign", flag.ExitOnError)
+	caFile := fs.String("ca", "", "CA file (private key and certificate)")
+	csrDir := fs.String("csr-dir", "", "directory containing certificate requests")
+	outDir := fs.String("out-dir", "", "directory to write issued certificates")
+	profile := fs.String("profile", "server", "certificate profile (server, client, code-signing)")
+	days := fs.Int("days", 0, "certificate validity in days (default profile validity)")
+	passin := fs.String("passin", "", "decryption password source for an encrypted CA private key (pass:<password>, env:<var>, file:<path>, prompt)")
+	dryRun := fs.Bool("dry-run", false, "report certificates that would be issued, without writing")
+	backup := fs.Bool("backup", false, "back up overwritten certificates to timestamped backup files")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil ca sign --ca <file> --csr-dir <dir> --out-dir <dir> [--profile server|client|code-signing] [--days <days>] [--passin <source>] [--dry-run] [--backup]")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 || *caFile == "" || *csrDir == "" || *outDir == "" {
+		fs.Usage()
+		return errors.New("must specify --ca, --csr-dir, and --out-dir")
+	}
+	if _, err := newTemplate(*profile, &x509.CertificateRequest{}); err != nil {
+		return err
+	}
+	ca, err := loadFile(*caFile, *passin)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(*csrDir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	var opts []pemutil.WriteOption
+	verb := "issued"
+	switch {
+	case *dryRun:
+		opts, verb = append(opts, pemutil.WithDryRun()), "would issue"
+	default:
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			return err
+		}
+	}
+	if *backup {
+		opts = append(opts, pemutil.WithBackup())
+	}
+	var failed int
+	for _, name := range names {
+		out, err := signCSR(ca, filepath.Join(*csrDir, name), *outDir, *profile, *days, opts...)
+		switch {
+		case errors.Is(err, errNotCSR):
+			continue
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: %s %s\n", name, verb, out)
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d certificate requests could not be signed", failed)
+	}
+	return nil
+}
+
+// errNotCSR is the not a certificate request error.
+var errNotCSR = errors.New("not a certificate request")
+
+// signCSR signs the certificate request in the file with the CA, writing the
+// issued certificate and CA chain to the output directory, returning the
+// written file name.
+func signCSR(ca pemutil.Store, filename, outDir, profile string, days int, opts ...pemutil.WriteOption) (string, error) {
+	s, err := pemutil.LoadFile(filename)
+	if err != nil {
+		return "", errNotCSR
+	}
+	csr, ok := s.CertificateRequest()
+	if !ok {
+		return "", errNotCSR
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return "", fmt.Errorf("invalid certificate request signature: %w", err)
+	}
+	tpl, err := newTemplate(profile, csr)
+	if err != nil {
+		return "", err
+	}
+	if days != 0 {
+		tpl.NotAfter = tpl.NotBefore.Add(time.Duration(days) * 24 * time.Hour)
+	}
+	cert, err := ca.Issue(tpl, csr.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	out := pemutil.Store{
+		pemutil.Certificate: append([]*x509.Certificate{cert}, ca.Certificates()...),
+	}
+	buf, err := out.Bytes()
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Join(outDir, strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))+".crt")
+	if err := pemutil.WriteFile(name, buf, 0o644, opts...); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// newTemplate creates a certificate template for the profile, using the
+// subject and names of the certificate request.
+func newTemplate(profile string, csr *x509.CertificateRequest) (*x509.Certificate, error) {
+	var tpl *x509.Certificate
+	switch profile {
+	case "server":
+		tpl = pemutil.ServerTemplate()
+		tpl.DNSNames, tpl.IPAddresses = csr.DNSNames, csr.IPAddresses
+		if len(tpl.DNSNames) == 0 && len(tpl.IPAddresses) == 0 && csr.Subject.CommonName != "" {
+			tpl = pemutil.ServerTemplate(csr.Subject.CommonName)
+		}
+	case "client":
+		tpl = pemutil.ClientTemplate(csr.Subject.CommonName)
+		tpl.EmailAddresses, tpl.URIs = csr.EmailAddresses, csr.URIs
+	case "code-signing":
+		tpl = pemutil.CodeSigningTemplate(csr.Subject.CommonName)
+	default:
+		return nil, fmt.Errorf("invalid profile %q", profile)
+	}
+	tpl.Subject = csr.Subject
+	return tpl, nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// runEmbed runs the embed command.
+func runEmbed(args []string) error {
+	fs := flag.NewFlagSet("embed", flag.ExitOnError)
+	pkg := fs.String("package", "", "Go package name")
+	name := fs.String("var", "", "Go variable name for the pre-parsed store")
+	out := fs.String("out", "", "output file (default: stdout)")
+	goEmbed := fs.Bool("go-embed", false, "use go:embed to include the file (the output must be in the same directory as the file)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil embed --package <name> --var <name> [--out <file>] [--go-embed] <file>")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(pos) != 1:
+		fs.Usage()
+		return errors.New("must specify a file")
+	case !token.IsIdentifier(*pkg):
+		fs.Usage()
+		return fmt.Errorf("invalid --package %q", *pkg)
+	case !token.IsIdentifier(*name):
+		fs.Usage()
+		return fmt.Errorf("invalid --var %q", *name)
+	}
+	buf, err := os.ReadFile(pos[0])
+	if err != nil {
+		return err
+	}
+	s, err := pemutil.DecodeBytes(buf)
+	if err != nil {
+		return err
+	}
+	if len(s) == 0 {
+		return fmt.Errorf("%s does not contain any PEM blocks", pos[0])
+	}
+	if _, ok := s.PrivateKey(); ok {
+		return fmt.Errorf("%s contains a private key", pos[0])
+	}
+	if *goEmbed && *out != "" {
+		abs, err := filepath.Abs(pos[0])
+		if err != nil {
+			return err
+		}
+		dir, err := filepath.Abs(filepath.Dir(*out))
+		if err != nil {
+			return err
+		}
+		if filepath.Dir(abs) != dir {
+			return errors.New("--go-embed requires --out to be in the same directory as the file")
+		}
+	}
+	src, err := generateEmbed(embedParams{
+		Package:  *pkg,
+		Var:      *name,
+		Data:     unexported(*name) + "PEM",
+		File:     filepath.Base(pos[0]),
+		PEM:      string(buf),
+		GoEmbed:  *goEmbed,
+		Pool:     len(s.Certificates()) != 0,
+		Contents: describeStore(s),
+	})
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
+
+// embedParams are the embed template parameters.
+type embedParams struct {
+	Package  string
+	Var      string
+	Data     string
+	File     string
+	PEM      string
+	GoEmbed  bool
+	Pool     bool
+	Contents string
+}
+
+// generateEmbed generates the Go source for the embed parameters.
+func generateEmbed(params embedParams) ([]byte, error) {
+	if !params.GoEmbed && strings.Contains(params.PEM, "`") {
+		return nil, errors.New("file contains a backtick")
+	}
+	var buf bytes.Buffer
+	if err := embedTemplate.Execute(&buf, params); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// embedTemplate is the embed Go source template.
+var embedTemplate = template.Must(template.New("embed").Parse(`// Code generated by pemutil embed from {{ .File }}; DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- if .Pool }}
+	"crypto/x509"
+{{- end }}
+{{- if .GoEmbed }}
+	_ "embed"
+{{- end }}
+
+	"github.com/kenshaw/pemutil"
+)
+
+{{ if .GoEmbed -}}
+// {{ .Data }} is the PEM-encoded data from {{ .File }}.
+//
+//go:embed {{ .File }}
+var {{ .Data }} []byte
+{{- else -}}
+// {{ .Data }} is the PEM-encoded data from {{ .File }}.
+const {{ .Data }} = ` + "`{{ .PEM }}`" + `
+{{- end }}
+
+// {{ .Var }} is the pre-parsed store for {{ .File }} ({{ .Contents }}).
+var {{ .Var }} = pemutil.MustDecodeBytes({{ if .GoEmbed }}{{ .Data }}{{ else }}[]byte({{ .Data }}){{ end }})
+{{- if .Pool }}
+
+// {{ .Var }}Pool is a certificate pool containing the certificates in
+// {{ .Var }}.
+var {{ .Var }}Pool *x509.CertPool = {{ .Var }}.CertPool()
+{{- end }}
+`))
+
+// describeStore returns a short description of the contents of the store.
+func describeStore(s pemutil.Store) string {
+	var v []string
+	if n := len(s.Certificates()); n != 0 {
+		v = append(v, plural(n, "certificate"))
+	}
+	for _, typ := range []pemutil.BlockType{pemutil.PublicKey, pemutil.CertificateRequest, pemutil.RevocationList} {
+		if _, ok := s[typ]; ok {
+			v = append(v, strings.ToLower(typ.String()))
+		}
+	}
+	if len(v) == 0 {
+		return "empty"
+	}
+	return strings.Join(v, ", ")
+}
+
+// plural returns n and the noun, pluralized when n is not 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// unexported returns the name with its first letter lower cased.
+func unexported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto"
+	_ "crypto/md5"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/kenshaw/pemutil"
+	"golang.org/x/crypto/ssh"
+)
+
+// runFingerprint runs the fingerprint command.
+func runFingerprint(args []string) error {
+	fs := flag.NewFlagSet("fingerprint", flag.ExitOnError)
+	hashName := fs.String("hash", "sha256", "hash (sha256, sha1, md5)")
+	format := fs.String("format", "hex", "format (hex, colon, ssh, jwk-thumbprint)")
+	passin := fs.String("passin", "", "decryption password source for encrypted private keys (pass:<password>, env:<var>, file:<path>, prompt)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil fingerprint <file> [--hash <hash>] [--format <format>] [--passin <source>]")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		fs.Usage()
+		return errors.New("must specify a file")
+	}
+	var h crypto.Hash
+	switch *hashName {
+	case "sha256":
+		h = crypto.SHA256
+	case "sha1":
+		h = crypto.SHA1
+	case "md5":
+		h = crypto.MD5
+	default:
+		return fmt.Errorf("unknown hash %q", *hashName)
+	}
+	s, err := loadFile(pos[0], *passin)
+	if err != nil {
+		return err
+	}
+	// fingerprint certificates, or the public key when there are none
+	certs := s.Certificates()
+	if len(certs) == 0 {
+		s.AddPublicKeys()
+		pub, ok := s.PublicKey()
+		if !ok {
+			return errors.New("file does not contain a public key or certificate")
+		}
+		fp, err := fingerprint(pub, nil, h, *format)
+		if err != nil {
+			return err
+		}
+		fmt.Println(fp)
+		return nil
+	}
+	for _, cert := range certs {
+		fp, err := fingerprint(cert.PublicKey, cert, h, *format)
+		if err != nil {
+			return err
+		}
+		fmt.Println(fp)
+	}
+	return nil
+}
+
+// fingerprint returns the fingerprint of the certificate (or the public key
+// when cert is nil) using the hash and format.
+func fingerprint(pub crypto.PublicKey, cert *x509.Certificate, h crypto.Hash, format string) (string, error) {
+	switch format {
+	case "hex", "colon":
+		var der []byte
+		if cert != nil {
+			der = cert.Raw
+		} else if b, ok := pub.([]byte); ok {
+			der = b
+		} else {
+			var err error
+			if der, err = pemutil.MarshalPKIXPublicKey(pub); err != nil {
+				return "", err
+			}
+		}
+		hh := h.New()
+		hh.Write(der)
+		sum := hh.Sum(nil)
+		if format == "hex" {
+			return hex.EncodeToString(sum), nil
+		}
+		return colonHex(sum), nil
+	case "ssh":
+		key, err := ssh.NewPublicKey(pub)
+		if err != nil {
+			return "", err
+		}
+		switch h {
+		case crypto.SHA256:
+			return ssh.FingerprintSHA256(key), nil
+		case crypto.MD5:
+			return "MD5:" + ssh.FingerprintLegacyMD5(key), nil
+		}
+		hh := h.New()
+		hh.Write(key.Marshal())
+		return "SHA1:" + base64.RawStdEncoding.EncodeToString(hh.Sum(nil)), nil
+	case "jwk-thumbprint":
+		tp, err := pemutil.JWKThumbprint(pub, h)
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(tp), nil
+	}
+	return "", fmt.Errorf("unknown format %q", format)
+}
+
+// colonHex returns buf as upper case, colon separated hex.
+func colonHex(buf []byte) string {
+	s := make([]string, len(buf))
+	for i, b := range buf {
+		s[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(s, ":")
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"crypto/elliptic"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// runGen runs the gen command.
+func runGen(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	encrypt := fs.Bool("encrypt", false, "encrypt the private key (PKCS8)")
+	passout := fs.String("passout", "", "encryption password source (pass:<password>, env:<var>, file:<path>, prompt)")
+	verbose := fs.Bool("v", false, "verbose (report key generation progress)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil gen <sym|rsa|ecc> <length|curve> [--encrypt --passout <source>] [-v]")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 {
+		fs.Usage()
+		return errors.New("must specify key type and key length or curve")
+	}
+	var keyLen int
+	var curveType string
+	if pos[0] == "ecc" {
+		curveType = pos[1]
+	} else if keyLen, err = strconv.Atoi(pos[1]); err != nil {
+		return fmt.Errorf("invalid key length %q", pos[1])
+	}
+	if *encrypt && *passout == "" {
+		return errors.New("must specify password source (--passout) with --encrypt")
+	}
+	keyset, err := generate(ctx, pos[0], keyLen, curveType, *verbose)
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	if *encrypt {
+		p, err := pemutil.ParsePasswordSource(*passout)
+		if err != nil {
+			return err
+		}
+		password, err := p.Password("private key")
+		if err != nil {
+			return err
+		}
+		if len(password) == 0 {
+			return errors.New("empty password")
+		}
+		buf, err = keyset.EncryptedBytes(password)
+		if err != nil {
+			return err
+		}
+	} else if buf, err = keyset.Bytes(); err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(buf)
+	return err
+}
+
+// generate generates a keyset.
+func generate(ctx context.Context, alg string, keyLen int, curveType string, verbose bool) (pemutil.Store, error) {
+	if (alg == "sym" || alg == "rsa") && keyLen == 0 {
+		return nil, fmt.Errorf("must specify key length for %s key types", alg)
+	}
+	var curve elliptic.Curve
+	if alg == "ecc" {
+		var err error
+		if curve, err = pemutil.CurveByName(curveType); err != nil {
+			return nil, err
+		}
+	}
+	switch alg {
+	case "sym":
+		return pemutil.GenerateSymmetricKeySet(keyLen)
+	case "rsa":
+		var opts []pemutil.GenerateOption
+		if verbose {
+			opts = append(opts, pemutil.WithProgress(time.Second, func(d time.Duration) {
+				fmt.Fprintf(os.Stderr, "generating %d bit rsa key (%s)\n", keyLen, d.Round(time.Second))
+			}))
+		}
+		return pemutil.GenerateRSAKeySetContext(ctx, keyLen, opts...)
+	case "ecc":
+		return pemutil.GenerateECKeySet(curve)
+	}
+	return nil, fmt.Errorf("unknown key type %q", alg)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// runList runs the list command.
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "output as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil list [--json] [algorithms|block-types]")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	what := "algorithms"
+	switch len(pos) {
+	case 0:
+	case 1:
+		what = pos[0]
+	default:
+		fs.Usage()
+		return errors.New("too many arguments")
+	}
+	var v interface{}
+	switch what {
+	case "algorithms":
+		v = pemutil.SupportedAlgorithms()
+	case "block-types":
+		v = pemutil.SupportedBlockTypes()
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown list %q", what)
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	switch z := v.(type) {
+	case []pemutil.Algorithm:
+		fmt.Fprintln(w, "NAME\tDESCRIPTION\tKEY SIZES\tCURVES\tENCRYPT")
+		for _, alg := range z {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", alg.Name, alg.Description, joinInts(alg.KeySizes), strings.Join(alg.Curves, ","), alg.Encrypt)
+		}
+	case []pemutil.BlockTypeInfo:
+		fmt.Fprintln(w, "TYPE\tDESCRIPTION\tPRIVATE\tENCRYPTED")
+		for _, info := range z {
+			fmt.Fprintf(w, "%s\t%s\t%t\t%t\n", info.Type, info.Description, info.Private, info.Encrypted)
+		}
+	}
+	return w.Flush()
+}
+
+// joinInts joins the ints with commas.
+func joinInts(v []int) string {
+	s := make([]string, len(v))
+	for i, n := range v {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
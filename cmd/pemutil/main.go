@@ -1,59 +1,75 @@
 // Command pemutil is a simple command line util making to generate suitable
 // keyset data for use with the pemutil package.
+//
+// Usage:
+//
+//	pemutil -t <sym|rsa|ecc> [-l <length>] [-c <curve>] [-v]
+//	pemutil gen <sym|rsa|ecc> <length|curve> [--encrypt --passout <source>] [-v]
+//	pemutil fingerprint <file> [--hash sha256|sha1|md5] [--format hex|colon|ssh|jwk-thumbprint] [--passin <source>]
+//	pemutil pubkey <file> [--ssh] [--passin <source>]
+//	pemutil transcode [--in pem|der|b64|hex] [--out pem|der|b64|hex] [--type <block type>] [--width <width>] [--crlf] [--java] [file]
+//	pemutil audit [--json] [--lint] <path>...
+//	pemutil report [--json] <path>...
+//	pemutil ca sign --ca <file> --csr-dir <dir> --out-dir <dir> [--profile server|client|code-signing] [--days <days>] [--passin <source>] [--dry-run] [--backup]
+//	pemutil list [--json] [algorithms|block-types]
+//	pemutil embed --package <name> --var <name> [--out <file>] [--go-embed] <file>
+//	pemutil migrate [--from pkcs1|sec1|pkcs8] --to pkcs1|sec1|pkcs8 <--in-place|--dry-run> [--backup=false] <path>...
 package main
 
 import (
-	"crypto/elliptic"
+	"context"
 	"flag"
 	"fmt"
 	"os"
-	"strings"
+	"os/signal"
 
 	"github.com/kenshaw/pemutil"
 )
 
 func main() {
-	flagAlg := flag.String("t", "", "key type (sym, rsa, ecc)")
-	flagKeyLen := flag.Int("l", 0, "key length for -t sym or -t rsa (512, 1024, 2048, 4096, ...)")
-	flagCurve := flag.String("c", "", "curve name for -t ecc (P224, P256, P384, P521)")
-	flag.Parse()
-	if err := run(*flagAlg, *flagKeyLen, *flagCurve); err != nil {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run(alg string, keyLen int, curveType string) error {
-	if (alg == "sym" || alg == "rsa") && keyLen == 0 {
-		return fmt.Errorf("must specify key length (-l) for %s key types", alg)
-	}
-	var curve elliptic.Curve
-	if alg == "ecc" {
-		switch strings.ToUpper(curveType) {
-		case "P224":
-			curve = elliptic.P224()
-		case "P256":
-			curve = elliptic.P256()
-		case "P384":
-			curve = elliptic.P384()
-		case "P521":
-			curve = elliptic.P521()
-		default:
-			return fmt.Errorf("unknown curve %q", curveType)
+// run runs the command for the args.
+func run(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		switch args[0] {
+		case "gen":
+			return runGen(ctx, args[1:])
+		case "fingerprint":
+			return runFingerprint(args[1:])
+		case "pubkey":
+			return runPubkey(args[1:])
+		case "transcode":
+			return runTranscode(args[1:])
+		case "audit":
+			return runAudit(args[1:])
+		case "report":
+			return runReport(args[1:])
+		case "ca":
+			return runCA(args[1:])
+		case "list":
+			return runList(args[1:])
+		case "embed":
+			return runEmbed(args[1:])
+		case "migrate":
+			return runMigrate(args[1:])
 		}
 	}
-	var keyset pemutil.Store
-	var err error
-	switch alg {
-	case "sym":
-		keyset, err = pemutil.GenerateSymmetricKeySet(keyLen)
-	case "rsa":
-		keyset, err = pemutil.GenerateRSAKeySet(keyLen)
-	case "ecc":
-		keyset, err = pemutil.GenerateECKeySet(curve)
-	default:
-		return fmt.Errorf("unknown key type %q", alg)
+	fs := flag.NewFlagSet("pemutil", flag.ExitOnError)
+	alg := fs.String("t", "", "key type (sym, rsa, ecc)")
+	keyLen := fs.Int("l", 0, "key length for -t sym or -t rsa (2048, 3072, 4096, ...)")
+	curveType := fs.String("c", "", "curve name for -t ecc (such as P-256, secp384r1, prime256v1, nistp521)")
+	verbose := fs.Bool("v", false, "verbose (report key generation progress)")
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
+	keyset, err := generate(ctx, *alg, *keyLen, *curveType, *verbose)
 	if err != nil {
 		return err
 	}
@@ -64,3 +80,32 @@ func run(alg string, keyLen int, curveType string) error {
 	_, err = os.Stdout.Write(buf)
 	return err
 }
+
+// parseArgs parses the flags in args, allowing flags to be interspersed with
+// positional arguments, returning the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if args = fs.Args(); len(args) == 0 {
+			return pos, nil
+		}
+		pos, args = append(pos, args[0]), args[1:]
+	}
+}
+
+// loadFile loads the file, using the password source (if any) to decrypt
+// encrypted private keys.
+func loadFile(filename, passin string) (pemutil.Store, error) {
+	var opts []pemutil.LoadOption
+	if passin != "" {
+		p, err := pemutil.ParsePasswordSource(passin)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, pemutil.WithPassword(p))
+	}
+	return pemutil.LoadFile(filename, opts...)
+}
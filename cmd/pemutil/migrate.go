@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// keyFormats are the private key formats, and their PEM block types.
+var keyFormats = map[string]pemutil.BlockType{
+	"pkcs1": pemutil.RSAPrivateKey,
+	"sec1":  pemutil.ECPrivateKey,
+	"pkcs8": pemutil.PrivateKey,
+}
+
+// runMigrate runs the migrate command.
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := fs.String("from", "", "private key format to convert (pkcs1, sec1, pkcs8; default: any convertible key)")
+	to := fs.String("to", "", "private key format to convert to (pkcs1, sec1, pkcs8)")
+	inPlace := fs.Bool("in-place", false, "rewrite files in place")
+	dryRun := fs.Bool("dry-run", false, "report files that would be rewritten, without writing")
+	backup := fs.Bool("backup", true, "back up rewritten files to timestamped backup files")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil migrate [--from <format>] --to <format> <--in-place|--dry-run> [--backup=false] <path>...")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	m := &migrator{
+		inPlace: *inPlace && !*dryRun,
+	}
+	if !m.inPlace {
+		m.opts = append(m.opts, pemutil.WithDryRun())
+	}
+	if *backup {
+		m.opts = append(m.opts, pemutil.WithBackup())
+	}
+	var ok bool
+	if m.to, ok = keyFormats[*to]; !ok {
+		fs.Usage()
+		return fmt.Errorf("invalid --to format %q", *to)
+	}
+	if *from != "" {
+		if m.from, ok = keyFormats[*from]; !ok {
+			fs.Usage()
+			return fmt.Errorf("invalid --from format %q", *from)
+		}
+	}
+	switch {
+	case !*inPlace && !*dryRun:
+		fs.Usage()
+		return errors.New("must specify --in-place or --dry-run")
+	case len(pos) == 0:
+		fs.Usage()
+		return errors.New("must specify a path")
+	}
+	for _, path := range pos {
+		if err := m.walk(strings.TrimSuffix(path, "/...")); err != nil {
+			return err
+		}
+	}
+	verb := "converted"
+	if !m.inPlace {
+		verb = "would convert"
+	}
+	fmt.Printf("%s %d keys in %d files\n", verb, m.keys, m.files)
+	if m.skipped != 0 {
+		return fmt.Errorf("%d keys could not be converted", m.skipped)
+	}
+	return nil
+}
+
+// migrator converts private keys in files.
+type migrator struct {
+	from, to pemutil.BlockType
+	inPlace  bool
+	opts     []pemutil.WriteOption
+	// files, keys, and skipped are the number of files rewritten, keys
+	// converted, and keys that could not be converted.
+	files, keys, skipped int
+}
+
+// walk converts the private keys in all files in the path.
+func (m *migrator) walk(path string) error {
+	return filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case !d.Type().IsRegular(), strings.HasSuffix(name, ".bak"):
+			return nil
+		}
+		return m.migrate(name, d)
+	})
+}
+
+// migrate converts the private keys in a file, preserving all other data in
+// the file.
+func (m *migrator) migrate(name string, d fs.DirEntry) error {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(buf, []byte("-----BEGIN ")) {
+		return nil
+	}
+	var out []byte
+	var n int
+	for rest := buf; ; {
+		block, next := pem.Decode(rest)
+		if block == nil {
+			out = append(out, rest...)
+			break
+		}
+		raw := rest[:len(rest)-len(next)]
+		start := bytes.LastIndex(raw, []byte("-----BEGIN "+block.Type))
+		out, rest = append(out, raw[:start]...), next
+		converted, err := m.convert(block)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s: %v\n", name, block.Type, err)
+			m.skipped++
+		}
+		if converted == nil {
+			out = append(out, raw[start:]...)
+			continue
+		}
+		out, n = append(out, pem.EncodeToMemory(converted)...), n+1
+	}
+	if n == 0 {
+		return nil
+	}
+	m.files, m.keys = m.files+1, m.keys+n
+	fi, err := d.Info()
+	if err != nil {
+		return err
+	}
+	opts := append(m.opts[:len(m.opts):len(m.opts)], pemutil.WithWriteReport(func(r pemutil.WriteReport) {
+		switch {
+		case r.DryRun:
+			fmt.Printf("%s: would convert %d keys\n", name, n)
+		case r.Backup != "":
+			fmt.Printf("%s: converted %d keys (backup: %s)\n", name, n, r.Backup)
+		default:
+			fmt.Printf("%s: converted %d keys\n", name, n)
+		}
+	}))
+	return pemutil.WriteFile(name, out, fi.Mode().Perm(), opts...)
+}
+
+// convert converts a private key block to the target format, returning nil
+// when the block is not a private key block to convert. When no --from format
+// was specified, keys that cannot be encoded in the target format (such as RSA
+// keys with --to sec1) are not converted, and are not an error.
+func (m *migrator) convert(block *pem.Block) (*pem.Block, error) {
+	typ := pemutil.BlockType(block.Type)
+	switch {
+	case typ != pemutil.RSAPrivateKey && typ != pemutil.ECPrivateKey && typ != pemutil.PrivateKey,
+		typ == m.to,
+		m.from != "" && typ != m.from:
+		return nil, nil
+	case x509.IsEncryptedPEMBlock(block):
+		return nil, errors.New("encrypted private keys cannot be converted")
+	}
+	var key interface{}
+	var err error
+	switch typ {
+	case pemutil.RSAPrivateKey:
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case pemutil.ECPrivateKey:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case pemutil.PrivateKey:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var der []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		switch m.to {
+		case pemutil.RSAPrivateKey:
+			der = x509.MarshalPKCS1PrivateKey(k)
+		case pemutil.PrivateKey:
+			der, err = x509.MarshalPKCS8PrivateKey(k)
+		}
+	case *ecdsa.PrivateKey:
+		switch m.to {
+		case pemutil.ECPrivateKey:
+			der, err = x509.MarshalECPrivateKey(k)
+		case pemutil.PrivateKey:
+			der, err = x509.MarshalPKCS8PrivateKey(k)
+		}
+	}
+	switch {
+	case err != nil:
+		return nil, err
+	case der == nil && m.from == "":
+		return nil, nil
+	case der == nil:
+		return nil, fmt.Errorf("%T cannot be encoded as a %s block", key, m.to)
+	}
+	return &pem.Block{
+		Type:    m.to.String(),
+		Headers: block.Headers,
+		Bytes:   der,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kenshaw/pemutil"
+	"golang.org/x/crypto/ssh"
+)
+
+// runPubkey runs the pubkey command.
+func runPubkey(args []string) error {
+	fs := flag.NewFlagSet("pubkey", flag.ExitOnError)
+	sshFormat := fs.Bool("ssh", false, "output in SSH authorized_keys format")
+	passin := fs.String("passin", "", "decryption password source for encrypted private keys (pass:<password>, env:<var>, file:<path>, prompt)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil pubkey <file> [--ssh] [--passin <source>]")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		fs.Usage()
+		return errors.New("must specify a file")
+	}
+	s, err := loadFile(pos[0], *passin)
+	if err != nil {
+		return err
+	}
+	// use the certificate's public key, otherwise the (derived) public key
+	var pub interface{}
+	if cert, ok := s.Certificate(); ok {
+		pub = cert.PublicKey
+	} else {
+		s.AddPublicKeys()
+		var ok bool
+		if pub, ok = s.PublicKey(); !ok {
+			return errors.New("file does not contain a public key or certificate")
+		}
+	}
+	if _, ok := pub.([]byte); ok {
+		return errors.New("file does not contain a public key or certificate")
+	}
+	var buf []byte
+	if *sshFormat {
+		key, err := ssh.NewPublicKey(pub)
+		if err != nil {
+			return err
+		}
+		buf = ssh.MarshalAuthorizedKey(key)
+	} else if buf, err = pemutil.EncodePrimitive(pub); err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(buf)
+	return err
+}
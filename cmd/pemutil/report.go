@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// runReport runs the report command.
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "output report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil report [--json] <path>...")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 {
+		fs.Usage()
+		return errors.New("must specify a path")
+	}
+	r, now := pemutil.NewReport(), pemutil.DefaultClock().Now()
+	for _, path := range pos {
+		if err := reportPath(r, now, strings.TrimSuffix(path, "/...")); err != nil {
+			return err
+		}
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "FILES\t%d\n", r.Stores)
+	fmt.Fprintf(w, "KEYS\t%d\n", r.Keys)
+	blocks := make(map[string]int, len(r.Blocks))
+	for typ, n := range r.Blocks {
+		blocks[typ.String()] = n
+	}
+	writeCounts(w, "BLOCK TYPE", blocks, nil)
+	writeCounts(w, "ALGORITHM", r.Algorithms, nil)
+	writeCounts(w, "KEY SIZE", r.KeySizes, nil)
+	writeCounts(w, "CURVE", r.Curves, nil)
+	writeCounts(w, "SIGNATURE", r.Signatures, nil)
+	writeCounts(w, "EXPIRY", r.Expiry, pemutil.ExpiryBuckets)
+	return w.Flush()
+}
+
+// reportPath adds all files in the path to the report.
+func reportPath(r *pemutil.Report, now time.Time, path string) error {
+	return filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case !d.Type().IsRegular():
+			return nil
+		}
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(buf, []byte("-----BEGIN ")) {
+			return nil
+		}
+		s, err := pemutil.DecodeBytes(buf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", name, err)
+			return nil
+		}
+		r.AddStore(s, now)
+		return nil
+	})
+}
+
+// writeCounts writes a section of counts, in the order of keys, or sorted by
+// name when keys is nil. Empty sections are not written.
+func writeCounts(w *tabwriter.Writer, title string, counts map[string]int, keys []string) {
+	if len(counts) == 0 {
+		return
+	}
+	if keys == nil {
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+	fmt.Fprintf(w, "\n%s\tCOUNT\n", title)
+	for _, k := range keys {
+		if n, ok := counts[k]; ok {
+			fmt.Fprintf(w, "%s\t%d\n", k, n)
+		}
+	}
+}
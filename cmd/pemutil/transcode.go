@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// runTranscode runs the transcode command.
+func runTranscode(args []string) error {
+	fs := flag.NewFlagSet("transcode", flag.ExitOnError)
+	in := fs.String("in", "pem", "input format (pem, der, b64, hex)")
+	out := fs.String("out", "der", "output format (pem, der, b64, hex)")
+	typ := fs.String("type", "", "PEM block type for der, b64, or hex input (default: detected)")
+	width := fs.Int("width", 64, "base64 line width for pem output")
+	crlf := fs.Bool("crlf", false, "use CRLF line endings for pem output")
+	java := fs.Bool("java", false, "check pem output is accepted by Java")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: pemutil transcode [--in <format>] [--out <format>] [--type <block type>] [--width <width>] [--crlf] [--java] [file]")
+		fs.PrintDefaults()
+	}
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	switch len(pos) {
+	case 0:
+		buf, err = io.ReadAll(os.Stdin)
+	case 1:
+		buf, err = os.ReadFile(pos[0])
+	default:
+		fs.Usage()
+		return errors.New("too many arguments")
+	}
+	if err != nil {
+		return err
+	}
+	blocks, err := decodeBlocks(buf, *in, *typ)
+	if err != nil {
+		return err
+	}
+	switch *out {
+	case "pem":
+		opts := []pemutil.EncodeOption{pemutil.WithLineWidth(*width)}
+		if *crlf {
+			opts = append(opts, pemutil.WithCRLF())
+		}
+		if *java {
+			opts = append(opts, pemutil.WithJavaCompat())
+		}
+		for _, block := range blocks {
+			buf, err := pemutil.EncodeBlock(block.Type, block.Headers, block.Bytes, opts...)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "der":
+		if len(blocks) != 1 {
+			return fmt.Errorf("der output requires a single block, got: %d", len(blocks))
+		}
+		_, err := os.Stdout.Write(blocks[0].Bytes)
+		return err
+	case "b64", "hex":
+		for _, block := range blocks {
+			s := hex.EncodeToString(block.Bytes)
+			if *out == "b64" {
+				s = base64.StdEncoding.EncodeToString(block.Bytes)
+			}
+			if _, err := fmt.Fprintln(os.Stdout, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", *out)
+}
+
+// decodeBlocks decodes the PEM blocks from buf in the format.
+func decodeBlocks(buf []byte, format, typ string) ([]*pem.Block, error) {
+	var der []byte
+	var err error
+	switch format {
+	case "pem":
+		var blocks []*pem.Block
+		for {
+			var block *pem.Block
+			if block, buf = pem.Decode(buf); block == nil {
+				break
+			}
+			blocks = append(blocks, block)
+		}
+		if len(blocks) == 0 {
+			return nil, errors.New("no PEM blocks")
+		}
+		return blocks, nil
+	case "der":
+		der = buf
+	case "b64":
+		der, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(buf)), ""))
+	case "hex":
+		der, err = hex.DecodeString(strings.ReplaceAll(strings.Join(strings.Fields(string(buf)), ""), ":", ""))
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if typ == "" {
+		if typ, err = detectBlockType(der); err != nil {
+			return nil, err
+		}
+	}
+	return []*pem.Block{{Type: typ, Bytes: der}}, nil
+}
+
+// detectBlockType detects the PEM block type for the DER encoded data.
+func detectBlockType(der []byte) (string, error) {
+	if _, err := x509.ParseCertificate(der); err == nil {
+		return pemutil.Certificate.String(), nil
+	}
+	if _, err := x509.ParseCertificateRequest(der); err == nil {
+		return pemutil.CertificateRequest.String(), nil
+	}
+	if _, err := x509.ParseRevocationList(der); err == nil {
+		return pemutil.RevocationList.String(), nil
+	}
+	if _, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return pemutil.RSAPrivateKey.String(), nil
+	}
+	if _, err := pemutil.ParseECPrivateKey(der); err == nil {
+		return pemutil.ECPrivateKey.String(), nil
+	}
+	if _, err := pemutil.ParsePKCSPrivateKey(der); err == nil {
+		return pemutil.PrivateKey.String(), nil
+	}
+	if _, err := pemutil.ParsePKIXPublicKey(der); err == nil {
+		return pemutil.PublicKey.String(), nil
+	}
+	if _, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return "RSA PUBLIC KEY", nil
+	}
+	return "", errors.New("unable to detect block type (use --type)")
+}
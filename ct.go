@@ -0,0 +1,326 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// oidSCTList is the embedded signed certificate timestamp list extension
+	// object identifier.
+	oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+	// oidCTPoison is the precertificate poison extension object identifier.
+	oidCTPoison = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3}
+)
+
+// SCT is a certificate transparency signed certificate timestamp (RFC 6962).
+type SCT struct {
+	// Version is the SCT version (0 for v1).
+	Version uint8
+	// LogID is the SHA-256 hash of the log's public key.
+	LogID [32]byte
+	// Timestamp is the SCT timestamp.
+	Timestamp time.Time
+	// Extensions are the SCT extensions.
+	Extensions []byte
+	// HashAlgorithm is the TLS hash algorithm of the signature.
+	HashAlgorithm uint8
+	// SignatureAlgorithm is the TLS signature algorithm of the signature.
+	SignatureAlgorithm uint8
+	// Signature is the SCT signature.
+	Signature []byte
+}
+
+// IsPrecertificate determines if the certificate is a certificate
+// transparency precertificate (ie, contains the poison extension).
+func IsPrecertificate(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidCTPoison) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmbeddedSCTs returns the signed certificate timestamps embedded in the
+// certificate.
+func EmbeddedSCTs(cert *x509.Certificate) ([]*SCT, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSCTList) {
+			continue
+		}
+		var buf []byte
+		if _, err := asn1.Unmarshal(ext.Value, &buf); err != nil {
+			return nil, fmt.Errorf("invalid SCT list extension: %w", err)
+		}
+		return parseSCTList(buf)
+	}
+	return nil, nil
+}
+
+// parseSCTList parses a TLS encoded signed certificate timestamp list.
+func parseSCTList(buf []byte) ([]*SCT, error) {
+	list, rest, ok := readVector16(buf)
+	if !ok || len(rest) != 0 {
+		return nil, errors.New("invalid SCT list")
+	}
+	var scts []*SCT
+	for len(list) != 0 {
+		var b []byte
+		if b, list, ok = readVector16(list); !ok {
+			return nil, errors.New("invalid SCT list entry")
+		}
+		sct, err := ParseSCT(b)
+		if err != nil {
+			return nil, err
+		}
+		scts = append(scts, sct)
+	}
+	return scts, nil
+}
+
+// ParseSCT parses a TLS encoded signed certificate timestamp.
+func ParseSCT(buf []byte) (*SCT, error) {
+	if len(buf) < 1+32+8 {
+		return nil, errors.New("invalid SCT")
+	}
+	sct := &SCT{Version: buf[0]}
+	if sct.Version != 0 {
+		return nil, fmt.Errorf("unsupported SCT version %d", sct.Version)
+	}
+	copy(sct.LogID[:], buf[1:33])
+	ms := binary.BigEndian.Uint64(buf[33:41])
+	sct.Timestamp = time.UnixMilli(int64(ms)).UTC()
+	var ok bool
+	var rest []byte
+	if sct.Extensions, rest, ok = readVector16(buf[41:]); !ok || len(rest) < 2 {
+		return nil, errors.New("invalid SCT")
+	}
+	sct.HashAlgorithm, sct.SignatureAlgorithm = rest[0], rest[1]
+	if sct.Signature, rest, ok = readVector16(rest[2:]); !ok || len(rest) != 0 {
+		return nil, errors.New("invalid SCT signature")
+	}
+	return sct, nil
+}
+
+// CTLog is a certificate transparency log.
+type CTLog struct {
+	// Description is the log description.
+	Description string
+	// LogID is the SHA-256 hash of the log's public key.
+	LogID [32]byte
+	// PublicKey is the log's public key.
+	PublicKey crypto.PublicKey
+	// URL is the log's base URL (used by [CTLog.VerifyInclusion]).
+	URL string
+}
+
+// NewCTLog creates a certificate transparency log for the public key.
+func NewCTLog(description string, pub crypto.PublicKey) (*CTLog, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return &CTLog{
+		Description: description,
+		LogID:       sha256.Sum256(der),
+		PublicKey:   pub,
+	}, nil
+}
+
+// ParseCTLogList parses a certificate transparency log list in the v3 JSON
+// format (as published at https://www.gstatic.com/ct/log_list/v3/log_list.json).
+func ParseCTLogList(buf []byte) ([]*CTLog, error) {
+	var v struct {
+		Operators []struct {
+			Logs []struct {
+				Description string `json:"description"`
+				Key         string `json:"key"`
+				URL         string `json:"url"`
+			} `json:"logs"`
+		} `json:"operators"`
+	}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+	var logs []*CTLog
+	for _, op := range v.Operators {
+		for _, l := range op.Logs {
+			der, err := base64.StdEncoding.DecodeString(l.Key)
+			if err != nil {
+				return nil, fmt.Errorf("log %q: invalid key: %w", l.Description, err)
+			}
+			pub, err := x509.ParsePKIXPublicKey(der)
+			if err != nil {
+				return nil, fmt.Errorf("log %q: invalid key: %w", l.Description, err)
+			}
+			logs = append(logs, &CTLog{
+				Description: l.Description,
+				LogID:       sha256.Sum256(der),
+				PublicKey:   pub,
+				URL:         l.URL,
+			})
+		}
+	}
+	return logs, nil
+}
+
+// VerifySCT verifies the signed certificate timestamp's signature for the
+// certificate, using the log in logs matching the SCT's log id.
+//
+// When issuer is not nil, the SCT is verified as an embedded SCT (ie, issued
+// for the precertificate). Otherwise, the SCT is verified as issued for the
+// certificate (ie, as delivered via TLS or OCSP).
+func VerifySCT(sct *SCT, cert, issuer *x509.Certificate, logs []*CTLog) error {
+	var log *CTLog
+	for _, l := range logs {
+		if l.LogID == sct.LogID {
+			log = l
+			break
+		}
+	}
+	if log == nil {
+		return fmt.Errorf("unknown CT log %s", base64.StdEncoding.EncodeToString(sct.LogID[:]))
+	}
+	data, err := sctSignedData(sct, cert, issuer)
+	if err != nil {
+		return err
+	}
+	if err := verifyCTSignature(log.PublicKey, sct.HashAlgorithm, sct.SignatureAlgorithm, sct.Signature, data); err != nil {
+		return fmt.Errorf("invalid SCT signature: %w", err)
+	}
+	return nil
+}
+
+// sctSignedData returns the data signed by the SCT for the certificate (see
+// [VerifySCT]). The signed data is also the certificate's Merkle tree leaf in
+// the log.
+func sctSignedData(sct *SCT, cert, issuer *x509.Certificate) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte(sct.Version)
+	b.WriteByte(0) // certificate_timestamp
+	_ = binary.Write(&b, binary.BigEndian, uint64(sct.Timestamp.UnixMilli()))
+	if issuer != nil {
+		tbs, err := removeTBSExtension(cert.RawTBSCertificate, oidSCTList)
+		if err != nil {
+			return nil, err
+		}
+		keyHash := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+		_ = binary.Write(&b, binary.BigEndian, uint16(1)) // precert_entry
+		b.Write(keyHash[:])
+		writeVector24(&b, tbs)
+	} else {
+		_ = binary.Write(&b, binary.BigEndian, uint16(0)) // x509_entry
+		writeVector24(&b, cert.Raw)
+	}
+	_ = binary.Write(&b, binary.BigEndian, uint16(len(sct.Extensions)))
+	b.Write(sct.Extensions)
+	return b.Bytes(), nil
+}
+
+// verifyCTSignature verifies a TLS digitally-signed signature made by a
+// certificate transparency log.
+func verifyCTSignature(pub crypto.PublicKey, hashAlg, sigAlg uint8, sig, data []byte) error {
+	if hashAlg != 4 {
+		return fmt.Errorf("unsupported hash algorithm %d", hashAlg)
+	}
+	digest := sha256.Sum256(data)
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		if sigAlg != 3 || !ecdsa.VerifyASN1(pub, digest[:], sig) {
+			return errors.New("verification failed")
+		}
+	case *rsa.PublicKey:
+		if sigAlg != 1 {
+			return errors.New("verification failed")
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("verification failed")
+		}
+	default:
+		return fmt.Errorf("unsupported CT log public key type %T", pub)
+	}
+	return nil
+}
+
+// removeTBSExtension removes the extension from the DER encoded TBS
+// certificate.
+func removeTBSExtension(tbs []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+	var seq asn1.RawValue
+	if rest, err := asn1.Unmarshal(tbs, &seq); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("trailing data after TBS certificate")
+	}
+	var fields []byte
+	for buf := seq.Bytes; len(buf) != 0; {
+		var field asn1.RawValue
+		var err error
+		if buf, err = asn1.Unmarshal(buf, &field); err != nil {
+			return nil, err
+		}
+		if field.Class != asn1.ClassContextSpecific || field.Tag != 3 {
+			fields = append(fields, field.FullBytes...)
+			continue
+		}
+		// extensions
+		var exts []asn1.RawValue
+		if _, err := asn1.Unmarshal(field.Bytes, &exts); err != nil {
+			return nil, err
+		}
+		var filtered []asn1.RawValue
+		for _, ext := range exts {
+			var id asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(ext.Bytes, &id); err != nil {
+				return nil, err
+			}
+			if !id.Equal(oid) {
+				filtered = append(filtered, ext)
+			}
+		}
+		if len(filtered) == 0 {
+			continue
+		}
+		b, err := asn1.Marshal(filtered)
+		if err != nil {
+			return nil, err
+		}
+		b, err = asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 3, IsCompound: true, Bytes: b})
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, b...)
+	}
+	return asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: fields})
+}
+
+// readVector16 reads a TLS vector with a 16 bit length prefix.
+func readVector16(buf []byte) ([]byte, []byte, bool) {
+	if len(buf) < 2 {
+		return nil, nil, false
+	}
+	n := int(binary.BigEndian.Uint16(buf))
+	if len(buf) < 2+n {
+		return nil, nil, false
+	}
+	return buf[2 : 2+n], buf[2+n:], true
+}
+
+// writeVector24 writes a TLS vector with a 24 bit length prefix.
+func writeVector24(b *bytes.Buffer, buf []byte) {
+	n := len(buf)
+	b.Write([]byte{byte(n >> 16), byte(n >> 8), byte(n)})
+	b.Write(buf)
+}
@@ -0,0 +1,144 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestEmbeddedSCTs(t *testing.T) {
+	cert, ca, log, _, sct := createSCTCert(t)
+	if IsPrecertificate(cert) {
+		t.Errorf("expected certificate to not be a precertificate")
+	}
+	scts, err := EmbeddedSCTs(cert)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(scts) != 1 {
+		t.Fatalf("expected 1 SCT, got: %d", len(scts))
+	}
+	if !scts[0].Timestamp.Equal(sct.Timestamp) || scts[0].LogID != sct.LogID {
+		t.Errorf("expected parsed SCT to equal SCT")
+	}
+	if err := VerifySCT(scts[0], cert, ca, []*CTLog{log}); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	scts[0].Timestamp = scts[0].Timestamp.Add(time.Millisecond)
+	if err := VerifySCT(scts[0], cert, ca, []*CTLog{log}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if err := VerifySCT(scts[0], cert, ca, nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+// createSCTCert creates a CA certificate and a certificate issued by the CA
+// with an embedded SCT from a generated log.
+func createSCTCert(t *testing.T) (*x509.Certificate, *x509.Certificate, *CTLog, *ecdsa.PrivateKey, *SCT) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	logKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	log, err := NewCTLog("test log", logKey.Public())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	caTpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTpl, caTpl, caKey.Public(), caKey)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// precertificate
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    caTpl.NotBefore,
+		NotAfter:     caTpl.NotAfter,
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidCTPoison, Critical: true, Value: asn1.NullBytes},
+		},
+	}
+	pre := createCert(t, tpl, ca, caKey)
+	if !IsPrecertificate(pre) {
+		t.Errorf("expected precertificate")
+	}
+	// sign sct over the tbs without the poison extension
+	tpl.ExtraExtensions = nil
+	tbs := createCert(t, tpl, ca, caKey).RawTBSCertificate
+	sct := &SCT{
+		LogID:              log.LogID,
+		Timestamp:          time.UnixMilli(time.Now().UnixMilli()).UTC(),
+		HashAlgorithm:      4,
+		SignatureAlgorithm: 3,
+	}
+	var b bytes.Buffer
+	b.Write([]byte{0, 0})
+	_ = binary.Write(&b, binary.BigEndian, uint64(sct.Timestamp.UnixMilli()))
+	_ = binary.Write(&b, binary.BigEndian, uint16(1))
+	keyHash := sha256.Sum256(ca.RawSubjectPublicKeyInfo)
+	b.Write(keyHash[:])
+	writeVector24(&b, tbs)
+	b.Write([]byte{0, 0})
+	digest := sha256.Sum256(b.Bytes())
+	if sct.Signature, err = ecdsa.SignASN1(rand.Reader, logKey, digest[:]); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// embed sct
+	var entry bytes.Buffer
+	entry.WriteByte(sct.Version)
+	entry.Write(sct.LogID[:])
+	_ = binary.Write(&entry, binary.BigEndian, uint64(sct.Timestamp.UnixMilli()))
+	entry.Write([]byte{0, 0, sct.HashAlgorithm, sct.SignatureAlgorithm})
+	_ = binary.Write(&entry, binary.BigEndian, uint16(len(sct.Signature)))
+	entry.Write(sct.Signature)
+	list := binary.BigEndian.AppendUint16(nil, uint16(entry.Len()+2))
+	list = binary.BigEndian.AppendUint16(list, uint16(entry.Len()))
+	list = append(list, entry.Bytes()...)
+	value, err := asn1.Marshal(list)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tpl.ExtraExtensions = []pkix.Extension{{Id: oidSCTList, Value: value}}
+	cert := createCert(t, tpl, ca, caKey)
+	return cert, ca, log, logKey, sct
+}
+
+func createCert(t *testing.T, tpl, parent *x509.Certificate, key *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parent, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return cert
+}
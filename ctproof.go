@@ -0,0 +1,224 @@
+package pemutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignedTreeHead is a certificate transparency log's signed tree head.
+type SignedTreeHead struct {
+	// TreeSize is the number of entries in the tree.
+	TreeSize uint64
+	// Timestamp is the tree head timestamp.
+	Timestamp time.Time
+	// RootHash is the Merkle tree root hash.
+	RootHash [32]byte
+}
+
+// CTLeafHash returns the Merkle tree leaf hash (RFC 6962) of the
+// certificate's entry in the log that issued the signed certificate
+// timestamp. See [VerifySCT] for the use of issuer.
+func CTLeafHash(sct *SCT, cert, issuer *x509.Certificate) ([32]byte, error) {
+	data, err := sctSignedData(sct, cert, issuer)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(append([]byte{0}, data...)), nil
+}
+
+// VerifyInclusionProof verifies the Merkle audit path (RFC 9162, section
+// 2.1.3.2) for the leaf hash at the index in a tree of the size with the root
+// hash.
+func VerifyInclusionProof(leafHash [32]byte, index, treeSize uint64, rootHash [32]byte, proof [][]byte) error {
+	if index >= treeSize {
+		return fmt.Errorf("leaf index %d is not less than tree size %d", index, treeSize)
+	}
+	fn, sn, r := index, treeSize-1, leafHash[:]
+	for _, p := range proof {
+		if sn == 0 {
+			return errors.New("inclusion proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = hashChildren(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			r = hashChildren(r, p)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	if sn != 0 {
+		return errors.New("inclusion proof is too short")
+	}
+	if !bytes.Equal(r, rootHash[:]) {
+		return errors.New("inclusion proof does not match root hash")
+	}
+	return nil
+}
+
+// hashChildren returns the Merkle tree interior node hash of the children.
+func hashChildren(l, r []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(l)
+	h.Write(r)
+	return h.Sum(nil)
+}
+
+// SignedTreeHead fetches and verifies the log's signed tree head.
+func (log *CTLog) SignedTreeHead(ctx context.Context, client *http.Client) (*SignedTreeHead, error) {
+	var res struct {
+		TreeSize          uint64 `json:"tree_size"`
+		Timestamp         int64  `json:"timestamp"`
+		SHA256RootHash    []byte `json:"sha256_root_hash"`
+		TreeHeadSignature []byte `json:"tree_head_signature"`
+	}
+	if err := log.get(ctx, client, "get-sth", nil, &res); err != nil {
+		return nil, err
+	}
+	if len(res.SHA256RootHash) != sha256.Size {
+		return nil, errors.New("invalid signed tree head root hash")
+	}
+	sig := res.TreeHeadSignature
+	if len(sig) < 2 {
+		return nil, errors.New("invalid signed tree head signature")
+	}
+	sigBuf, rest, ok := readVector16(sig[2:])
+	if !ok || len(rest) != 0 {
+		return nil, errors.New("invalid signed tree head signature")
+	}
+	var b bytes.Buffer
+	b.Write([]byte{0, 1}) // v1, tree_hash
+	_ = binary.Write(&b, binary.BigEndian, uint64(res.Timestamp))
+	_ = binary.Write(&b, binary.BigEndian, res.TreeSize)
+	b.Write(res.SHA256RootHash)
+	if err := verifyCTSignature(log.PublicKey, sig[0], sig[1], sigBuf, b.Bytes()); err != nil {
+		return nil, fmt.Errorf("invalid signed tree head signature: %w", err)
+	}
+	sth := &SignedTreeHead{
+		TreeSize:  res.TreeSize,
+		Timestamp: time.UnixMilli(res.Timestamp).UTC(),
+	}
+	copy(sth.RootHash[:], res.SHA256RootHash)
+	return sth, nil
+}
+
+// VerifyInclusion fetches the log's signed tree head and the inclusion proof
+// for the certificate's entry in the log that issued the signed certificate
+// timestamp, and verifies the certificate is included in the log. See
+// [VerifySCT] for the use of issuer.
+//
+// Uses [http.DefaultClient] when client is nil.
+func (log *CTLog) VerifyInclusion(ctx context.Context, client *http.Client, sct *SCT, cert, issuer *x509.Certificate) error {
+	if sct.LogID != log.LogID {
+		return errors.New("SCT was not issued by the log")
+	}
+	if err := VerifySCT(sct, cert, issuer, []*CTLog{log}); err != nil {
+		return err
+	}
+	leafHash, err := CTLeafHash(sct, cert, issuer)
+	if err != nil {
+		return err
+	}
+	sth, err := log.SignedTreeHead(ctx, client)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		LeafIndex uint64   `json:"leaf_index"`
+		AuditPath [][]byte `json:"audit_path"`
+	}
+	params := url.Values{
+		"hash":      []string{base64.StdEncoding.EncodeToString(leafHash[:])},
+		"tree_size": []string{strconv.FormatUint(sth.TreeSize, 10)},
+	}
+	if err := log.get(ctx, client, "get-proof-by-hash", params, &res); err != nil {
+		return err
+	}
+	return VerifyInclusionProof(leafHash, res.LeafIndex, sth.TreeSize, sth.RootHash, res.AuditPath)
+}
+
+// get performs a RFC 6962 API request to the log, decoding the JSON response
+// to v.
+func (log *CTLog) get(ctx context.Context, client *http.Client, method string, params url.Values, v interface{}) error {
+	if log.URL == "" {
+		return fmt.Errorf("log %q does not have a URL", log.Description)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	urlstr := strings.TrimSuffix(log.URL, "/") + "/ct/v1/" + method
+	if len(params) != 0 {
+		urlstr += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlstr, nil)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("log %q: %s returned status %d", log.Description, method, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// VerifyCTInclusion verifies that the certificate is included in the logs
+// that issued each of its embedded signed certificate timestamps (see
+// [CTLog.VerifyInclusion]), using the certificate's issuer. Returns an error
+// when the certificate does not have any embedded signed certificate
+// timestamps, or when any of them cannot be verified. Timestamps issued by
+// logs not in logs are ignored.
+func VerifyCTInclusion(ctx context.Context, client *http.Client, cert, issuer *x509.Certificate, logs []*CTLog) error {
+	scts, err := EmbeddedSCTs(cert)
+	if err != nil {
+		return err
+	}
+	verified := 0
+	for _, sct := range scts {
+		for _, log := range logs {
+			if log.LogID != sct.LogID {
+				continue
+			}
+			if err := log.VerifyInclusion(ctx, client, sct, cert, issuer); err != nil {
+				return fmt.Errorf("log %q: %w", log.Description, err)
+			}
+			verified++
+		}
+	}
+	if verified == 0 {
+		return errors.New("certificate does not have any SCTs from known logs")
+	}
+	return nil
+}
+
+// VerifyCTInclusion verifies that the first certificate in the [Store] is
+// included in the certificate transparency logs (see [VerifyCTInclusion]),
+// using its issuer contained in the [Store].
+func (s Store) VerifyCTInclusion(ctx context.Context, client *http.Client, logs []*CTLog) error {
+	cert, ok := s.Certificate()
+	if !ok {
+		return errors.New("store does not contain a certificate")
+	}
+	chain := buildChain(cert, s.Certificates())
+	if len(chain) < 2 {
+		return errors.New("store does not contain the certificate's issuer")
+	}
+	return VerifyCTInclusion(ctx, client, chain[0], chain[1], logs)
+}
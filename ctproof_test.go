@@ -0,0 +1,143 @@
+package pemutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerifyInclusionProof(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		leaves := make([][]byte, n)
+		for i := range leaves {
+			h := sha256.Sum256([]byte{0, byte(i)})
+			leaves[i] = h[:]
+		}
+		var root [32]byte
+		copy(root[:], merkleRoot(leaves))
+		for i := range leaves {
+			var leaf [32]byte
+			copy(leaf[:], leaves[i])
+			proof := merklePath(i, leaves)
+			if err := VerifyInclusionProof(leaf, uint64(i), uint64(n), root, proof); err != nil {
+				t.Errorf("tree size %d index %d expected no error, got: %v", n, i, err)
+			}
+			if n > 1 {
+				if err := VerifyInclusionProof(leaf, uint64((i+1)%n), uint64(n), root, proof); err == nil {
+					t.Errorf("tree size %d index %d expected error with wrong index, got nil", n, i)
+				}
+			}
+		}
+	}
+}
+
+func TestVerifyCTInclusion(t *testing.T) {
+	cert, ca, log, logKey, _ := createSCTCert(t)
+	scts, err := EmbeddedSCTs(cert)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leafHash, err := CTLeafHash(scts[0], cert, ca)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leaves := make([][]byte, 5)
+	for i := range leaves {
+		h := sha256.Sum256([]byte{0, byte(i)})
+		leaves[i] = h[:]
+	}
+	leaves[2] = leafHash[:]
+	root := merkleRoot(leaves)
+	ts := time.Now().UnixMilli()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var v interface{}
+		switch req.URL.Path {
+		case "/log/ct/v1/get-sth":
+			var b bytes.Buffer
+			b.Write([]byte{0, 1})
+			_ = binary.Write(&b, binary.BigEndian, uint64(ts))
+			_ = binary.Write(&b, binary.BigEndian, uint64(len(leaves)))
+			b.Write(root)
+			digest := sha256.Sum256(b.Bytes())
+			sig, err := ecdsa.SignASN1(rand.Reader, logKey, digest[:])
+			if err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+			v = map[string]interface{}{
+				"tree_size":           len(leaves),
+				"timestamp":           ts,
+				"sha256_root_hash":    root,
+				"tree_head_signature": append([]byte{4, 3, byte(len(sig) >> 8), byte(len(sig))}, sig...),
+			}
+		case "/log/ct/v1/get-proof-by-hash":
+			if req.URL.Query().Get("hash") != base64.StdEncoding.EncodeToString(leafHash[:]) {
+				http.NotFound(w, req)
+				return
+			}
+			v = map[string]interface{}{
+				"leaf_index": 2,
+				"audit_path": merklePath(2, leaves),
+			}
+		default:
+			http.NotFound(w, req)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(v)
+	}))
+	defer srv.Close()
+	log.URL = srv.URL + "/log/"
+	s := Store{Certificate: []*x509.Certificate{cert, ca}}
+	if err := s.VerifyCTInclusion(context.Background(), srv.Client(), []*CTLog{log}); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	// wrong root
+	root = merkleRoot(leaves[:4])
+	if err := s.VerifyCTInclusion(context.Background(), srv.Client(), []*CTLog{log}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if err := s.VerifyCTInclusion(context.Background(), srv.Client(), nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+// merkleRoot returns the Merkle tree hash of the leaf hashes (RFC 6962,
+// section 2.1).
+func merkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := merkleSplit(len(leaves))
+	return hashChildren(merkleRoot(leaves[:k]), merkleRoot(leaves[k:]))
+}
+
+// merklePath returns the Merkle audit path for the leaf (RFC 6962, section
+// 2.1.1).
+func merklePath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := merkleSplit(len(leaves))
+	if m < k {
+		return append(merklePath(m, leaves[:k]), merkleRoot(leaves[k:]))
+	}
+	return append(merklePath(m-k, leaves[k:]), merkleRoot(leaves[:k]))
+}
+
+// merkleSplit returns the largest power of 2 less than n.
+func merkleSplit(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
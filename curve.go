@@ -0,0 +1,109 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// oidP224 is the P-224 (secp224r1) named-curve object identifier.
+	oidP224 = asn1.ObjectIdentifier{1, 3, 132, 0, 33}
+
+	// oidP256 is the P-256 (secp256r1, prime256v1) named-curve object
+	// identifier.
+	oidP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+
+	// oidP384 is the P-384 (secp384r1) named-curve object identifier.
+	oidP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+
+	// oidP521 is the P-521 (secp521r1) named-curve object identifier.
+	oidP521 = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+)
+
+// curveAliases are the curve names (normalized, see [normalizeCurveName])
+// and their named-curve object identifiers.
+var curveAliases = map[string]asn1.ObjectIdentifier{
+	"p224":            oidP224,
+	"secp224r1":       oidP224,
+	"nistp224":        oidP224,
+	"p256":            oidP256,
+	"secp256r1":       oidP256,
+	"prime256v1":      oidP256,
+	"nistp256":        oidP256,
+	"p384":            oidP384,
+	"secp384r1":       oidP384,
+	"nistp384":        oidP384,
+	"p521":            oidP521,
+	"secp521r1":       oidP521,
+	"nistp521":        oidP521,
+	"secp256k1":       OIDSecp256k1,
+	"brainpoolp256r1": OIDBrainpoolP256r1,
+	"brainpoolp384r1": OIDBrainpoolP384r1,
+	"brainpoolp512r1": OIDBrainpoolP512r1,
+}
+
+// RegisterCurveAlias registers an additional name for the named-curve object
+// identifier, for use with [CurveByName]. Names are case-insensitive, and
+// dashes, underscores, and spaces are ignored.
+func RegisterCurveAlias(name string, oid asn1.ObjectIdentifier) {
+	curveMu.Lock()
+	defer curveMu.Unlock()
+	curveAliases[normalizeCurveName(name)] = oid
+}
+
+// CurveByName returns the elliptic curve for the name, accepting the common
+// NIST, SEC, ANSI X9.62, and OpenSSH forms (such as "P-256", "secp256r1",
+// "prime256v1", and "nistp256"), aliases registered via
+// [RegisterCurveAlias], and dotted object identifiers (such as
+// "1.3.132.0.34"). Names are case-insensitive, and dashes, underscores, and
+// spaces are ignored.
+//
+// Curves not supported by the standard library (such as secp256k1) must
+// first be registered via [RegisterCurve].
+func CurveByName(name string) (elliptic.Curve, error) {
+	oid, ok := parseCurveOID(name)
+	if !ok {
+		curveMu.RLock()
+		oid, ok = curveAliases[normalizeCurveName(name)]
+		curveMu.RUnlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown curve %q", name)
+	}
+	nc, ok := curveByOID(oid)
+	if !ok {
+		return nil, fmt.Errorf("curve %q (%v) is not registered", name, oid)
+	}
+	return nc.curve, nil
+}
+
+// normalizeCurveName normalizes the curve name.
+func normalizeCurveName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// parseCurveOID parses a dotted object identifier.
+func parseCurveOID(name string) (asn1.ObjectIdentifier, bool) {
+	parts := strings.Split(strings.TrimSpace(name), ".")
+	if len(parts) < 2 {
+		return nil, false
+	}
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		oid[i] = n
+	}
+	return oid, true
+}
@@ -0,0 +1,109 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+)
+
+// DecryptOption is a decryption option.
+type DecryptOption func(*decryptOptions)
+
+// decryptOptions are decryption options.
+type decryptOptions struct {
+	oaep    bool
+	hash    crypto.Hash
+	mgfHash crypto.Hash
+	label   []byte
+}
+
+// WithOAEP is a decryption option to use RSA-OAEP with the hash and label,
+// instead of PKCS#1 v1.5.
+func WithOAEP(hash crypto.Hash, label []byte) DecryptOption {
+	return func(opts *decryptOptions) {
+		opts.oaep, opts.hash, opts.label = true, hash, label
+	}
+}
+
+// WithMGFHash is a decryption option to set the RSA-OAEP MGF1 hash, when it
+// differs from the OAEP hash (such as SHA-256 with SHA-1 MGF1, as used by
+// some Java providers).
+func WithMGFHash(hash crypto.Hash) DecryptOption {
+	return func(opts *decryptOptions) {
+		opts.mgfHash = hash
+	}
+}
+
+// Decrypter returns the private key in the [Store] as a [crypto.Decrypter],
+// including non-exportable private keys (see [NonExportable]) whose signer
+// supports decryption.
+func (s Store) Decrypter() (crypto.Decrypter, bool) {
+	v, ok := s.PrivateKey()
+	if !ok {
+		return nil, false
+	}
+	z, ok := unwrapKey(v).(crypto.Decrypter)
+	return z, ok
+}
+
+// Decrypt decrypts the ciphertext with the RSA private key in the [Store],
+// using PKCS#1 v1.5, or RSA-OAEP when the [WithOAEP] option is passed.
+//
+// PKCS#1 v1.5 decryption errors can reveal information to an attacker (a
+// padding oracle). Use [Store.DecryptSessionKey] when decrypting session keys
+// with PKCS#1 v1.5.
+func (s Store) Decrypt(ciphertext []byte, opts ...DecryptOption) ([]byte, error) {
+	d, err := s.rsaDecrypter()
+	if err != nil {
+		return nil, err
+	}
+	var o decryptOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.oaep {
+		return d.Decrypt(Rand(), ciphertext, &rsa.PKCS1v15DecryptOptions{})
+	}
+	return d.Decrypt(Rand(), ciphertext, &rsa.OAEPOptions{
+		Hash:    o.hash,
+		MGFHash: o.mgfHash,
+		Label:   o.label,
+	})
+}
+
+// DecryptSessionKey decrypts a PKCS#1 v1.5 encrypted session key of keyLen
+// bytes with the RSA private key in the [Store], as used by legacy envelope
+// encryption protocols (such as TLS RSA key exchange and CMS).
+//
+// To avoid padding oracle attacks, a random key is returned instead of an
+// error when the ciphertext is not a valid encrypted key of keyLen bytes.
+// The returned key must then cause the protocol to fail later (such as when
+// decrypting the enveloped data).
+func (s Store) DecryptSessionKey(ciphertext []byte, keyLen int) ([]byte, error) {
+	d, err := s.rsaDecrypter()
+	if err != nil {
+		return nil, err
+	}
+	if keyLen <= 0 {
+		return nil, errors.New("invalid session key length")
+	}
+	return d.Decrypt(Rand(), ciphertext, &rsa.PKCS1v15DecryptOptions{
+		SessionKeyLen: keyLen,
+	})
+}
+
+// rsaDecrypter returns the RSA private key in the [Store] as a
+// [crypto.Decrypter].
+func (s Store) rsaDecrypter() (crypto.Decrypter, error) {
+	d, ok := s.Decrypter()
+	if !ok {
+		return nil, errors.New("store does not contain a private key that supports decryption")
+	}
+	if _, ok := d.Public().(*rsa.PublicKey); !ok {
+		return nil, errors.New("store does not contain a RSA private key")
+	}
+	if err := s.CheckPurpose(PurposeEncryption); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,63 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestDecrypt(t *testing.T) {
+	s, err := LoadFile("testdata/rsa.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	pub, ok := s.RSAPublicKey()
+	if !ok {
+		t.Fatalf("expected RSA public key")
+	}
+	msg := []byte("hello world")
+	// pkcs1v15
+	ct, err := rsa.EncryptPKCS1v15(rand.Reader, pub, msg)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := s.Decrypt(ct)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.Equal(buf, msg) {
+		t.Errorf("expected %q, got: %q", msg, buf)
+	}
+	// session key
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if ct, err = rsa.EncryptPKCS1v15(rand.Reader, pub, key); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if buf, err = s.DecryptSessionKey(ct, 16); err != nil || !bytes.Equal(buf, key) {
+		t.Errorf("expected session key, got: %x, %v", buf, err)
+	}
+	if buf, err = s.DecryptSessionKey(ct, 32); err != nil || len(buf) != 32 || bytes.Contains(buf, key) {
+		t.Errorf("expected random session key, got: %x, %v", buf, err)
+	}
+	// oaep with label
+	if ct, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, msg, []byte("label")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if buf, err = s.Decrypt(ct, WithOAEP(crypto.SHA256, []byte("label"))); err != nil || !bytes.Equal(buf, msg) {
+		t.Errorf("expected %q, got: %q, %v", msg, buf, err)
+	}
+	if _, err = s.Decrypt(ct, WithOAEP(crypto.SHA256, []byte("other"))); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	// non-exportable
+	s.MarkNonExportable()
+	if buf, err = s.Decrypt(ct, WithOAEP(crypto.SHA256, []byte("label"))); err != nil || !bytes.Equal(buf, msg) {
+		t.Errorf("expected %q, got: %q, %v", msg, buf, err)
+	}
+}
@@ -0,0 +1,68 @@
+package pemutil
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"strconv"
+)
+
+// CertificateSources are the locations (ie, "roots.pem:12") at which each
+// certificate was encountered when decoding and loading with the [WithDedup]
+// option, keyed by the certificate's SHA-256 fingerprint (see [Fingerprint]).
+// The number of times a certificate was encountered is the number of
+// locations.
+type CertificateSources map[[32]byte][]string
+
+// WithDedup is a decode and load option to skip identical certificates
+// (compared by SHA-256 fingerprint) that were already encountered or are
+// already present in the [Store], instead of storing each copy. Useful when
+// building large trust stores from overlapping sources, reducing memory use
+// and the size of the exported [x509.CertPool].
+//
+// When sources is not nil, the location of every certificate encountered
+// (including duplicates) is recorded in sources. Locations are tracked across
+// the files loaded by [LoadFiles] when the same sources are passed.
+func WithDedup(sources CertificateSources) LoadOption {
+	return func(opts *loadOptions) {
+		opts.dedup, opts.sources = true, sources
+	}
+}
+
+// isDuplicateCertificate determines if the certificate block is identical
+// to a certificate previously encountered or already present in the [Store],
+// recording its location in the certificate sources.
+func (o *loadOptions) isDuplicateCertificate(s Store, block *pem.Block, line func() int) bool {
+	if o.seen == nil {
+		o.seen = s.certificateFingerprints()
+	}
+	fp := sha256.Sum256(block.Bytes)
+	if o.sources != nil {
+		n := strconv.Itoa(line())
+		loc := "line " + n
+		if o.source != "" {
+			loc = o.source + ":" + n
+		}
+		o.sources[fp] = append(o.sources[fp], loc)
+	}
+	if o.seen[fp] {
+		return true
+	}
+	o.seen[fp] = true
+	return false
+}
+
+// certificateFingerprints returns the SHA-256 fingerprints of the
+// certificates in the [Store], without parsing lazily decoded certificates.
+func (s Store) certificateFingerprints() map[[32]byte]bool {
+	m := make(map[[32]byte]bool)
+	if l, ok := s[Certificate].(*lazyCertificates); ok {
+		for _, block := range l.pemBlocks() {
+			m[sha256.Sum256(block.Bytes)] = true
+		}
+		return m
+	}
+	for _, cert := range s.Certificates() {
+		m[Fingerprint(cert)] = true
+	}
+	return m
+}
@@ -0,0 +1,76 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDedup(t *testing.T) {
+	var certs [][]byte
+	for i := 0; i < 3; i++ {
+		s, err := GenerateECKeySet(elliptic.P256())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cert, err := s.SelfSign(RootCATemplate("root"))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		buf, err := EncodePrimitive(cert)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		certs = append(certs, buf)
+	}
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.pem"), filepath.Join(dir, "b.pem")
+	if err := os.WriteFile(a, append(append(append([]byte(nil), certs[0]...), certs[1]...), certs[0]...), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(b, append(append([]byte(nil), certs[1]...), certs[2]...), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		opts []LoadOption
+		exp  int
+	}{
+		{nil, 5},
+		{[]LoadOption{WithDedup(nil)}, 3},
+		{[]LoadOption{WithDedup(nil), WithLazyLoad()}, 3},
+		{[]LoadOption{WithDedup(nil), WithParallel(2)}, 3},
+	}
+	for i, test := range tests {
+		s, err := LoadFiles([]string{a, b}, test.opts...)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if n := len(s.Certificates()); n != test.exp {
+			t.Errorf("test %d expected %d certificates, got: %d", i, test.exp, n)
+		}
+	}
+	// sources
+	sources := make(CertificateSources)
+	s, err := LoadFiles([]string{a, b}, WithDedup(sources))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// line returns the line number following the certificates
+	line := func(v ...[]byte) string {
+		return strconv.Itoa(bytes.Count(bytes.Join(v, nil), []byte("\n")) + 1)
+	}
+	exp := [][]string{
+		{a + ":1", a + ":" + line(certs[0], certs[1])},
+		{a + ":" + line(certs[0]), b + ":1"},
+		{b + ":" + line(certs[1])},
+	}
+	for i, cert := range s.Certificates() {
+		if v := sources[Fingerprint(cert)]; !reflect.DeepEqual(v, exp[i]) {
+			t.Errorf("test %d expected sources %v, got: %v", i, exp[i], v)
+		}
+	}
+}
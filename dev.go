@@ -0,0 +1,64 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"log"
+)
+
+// EphemeralSource returns a [Source] that generates a ephemeral P-256 keyset
+// in memory, for use in local development. When hosts are provided, a
+// self-signed server certificate for the hosts is added to the keyset.
+//
+// The generated key's fingerprint (see [KeyFingerprint]) is logged using
+// logf, or [log.Printf] when logf is nil.
+func EphemeralSource(logf func(string, ...interface{}), hosts ...string) Source {
+	if logf == nil {
+		logf = log.Printf
+	}
+	return ephemeralSource{logf: logf, hosts: hosts}
+}
+
+// ephemeralSource is a ephemeral keyset source.
+type ephemeralSource struct {
+	logf  func(string, ...interface{})
+	hosts []string
+}
+
+// Load satisfies the [Source] interface.
+func (src ephemeralSource) Load(...LoadOption) (Store, error) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		return nil, err
+	}
+	if len(src.hosts) != 0 {
+		if _, err := s.SelfSign(ServerTemplate(src.hosts...)); err != nil {
+			return nil, err
+		}
+	}
+	pub, _ := s.PublicKey()
+	fp, err := KeyFingerprint(pub)
+	if err != nil {
+		return nil, err
+	}
+	src.logf("pemutil: using ephemeral development key %s", hex.EncodeToString(fp[:]))
+	return s, nil
+}
+
+// String satisfies the [Source] interface.
+func (src ephemeralSource) String() string {
+	return "ephemeral key"
+}
+
+// LoadFileDev loads crypto primitives from the file. When the file does not
+// exist and dev is true, a ephemeral keyset is generated instead (see
+// [EphemeralSource]), removing the need to generate a key before running a
+// service locally.
+func LoadFileDev(filename string, dev bool, logf func(string, ...interface{}), hosts []string, opts ...LoadOption) (Store, error) {
+	sources := []Source{FileSource(filename)}
+	if dev {
+		sources = append(sources, EphemeralSource(logf, hosts...))
+	}
+	s, _, err := LoadChain(sources, opts...)
+	return s, err
+}
@@ -0,0 +1,29 @@
+package pemutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFileDev(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := LoadFileDev(filename, false, nil, nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	var logged []string
+	logf := func(s string, v ...interface{}) {
+		logged = append(logged, fmt.Sprintf(s, v...))
+	}
+	s, err := LoadFileDev(filename, true, logf, []string{"localhost"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.TLSCertificate(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], "ephemeral") {
+		t.Errorf("expected fingerprint to be logged, got: %v", logged)
+	}
+}
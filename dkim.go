@@ -0,0 +1,151 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDKIMKeyRevoked is the error returned by [ParseDKIMRecord] when the DKIM
+// record's public key has been revoked (an empty "p=" tag).
+var ErrDKIMKeyRevoked = errors.New("DKIM key revoked")
+
+// DKIMRecord returns the DNS TXT record value for the public key (see
+// [CanonicalPublicKey]), such as "v=DKIM1; k=rsa; p=<base64>". RSA keys are
+// encoded as a DER-encoded SubjectPublicKeyInfo (RFC 6376), and Ed25519 keys
+// as the raw public key (RFC 8463).
+func DKIMRecord(v interface{}) (string, error) {
+	pub, err := CanonicalPublicKey(v)
+	if err != nil {
+		return "", err
+	}
+	var k string
+	var buf []byte
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if buf, err = x509.MarshalPKIXPublicKey(key); err != nil {
+			return "", err
+		}
+		k = "rsa"
+	case ed25519.PublicKey:
+		k, buf = "ed25519", key
+	default:
+		return "", fmt.Errorf("unsupported DKIM public key type %T", pub)
+	}
+	return "v=DKIM1; k=" + k + "; p=" + base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// DKIMRecord returns the DNS TXT record value for the public key in the
+// [Store] (see [DKIMRecord]).
+func (s Store) DKIMRecord() (string, error) {
+	return DKIMRecord(s)
+}
+
+// DKIMZoneRecord returns the DNS TXT resource record for the public key in
+// the [Store] for the selector and domain, in zone file presentation format,
+// such as:
+//
+//	selector._domainkey.example.com. IN TXT ( "v=DKIM1; k=rsa; p=..." "..." )
+//
+// The record value is split into strings of at most 255 bytes.
+func (s Store) DKIMZoneRecord(selector, domain string) (string, error) {
+	v, err := s.DKIMRecord()
+	if err != nil {
+		return "", err
+	}
+	var strs []string
+	for len(v) > 255 {
+		strs, v = append(strs, `"`+v[:255]+`"`), v[255:]
+	}
+	strs = append(strs, `"`+v+`"`)
+	return fqdn(selector+"._domainkey."+domain) + " IN TXT ( " + strings.Join(strs, " ") + " )", nil
+}
+
+// ParseDKIMRecord parses the public key from a DKIM DNS TXT record value.
+// The value may be split into multiple quoted strings, as in a zone file or
+// as returned by DNS tooling. Returns [ErrDKIMKeyRevoked] when the record's
+// public key has been revoked.
+func ParseDKIMRecord(s string) (crypto.PublicKey, error) {
+	s = unquoteTXT(s)
+	tags := make(map[string]string)
+	for i, tag := range strings.Split(s, ";") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid DKIM tag %q", tag)
+		}
+		name = strings.TrimSpace(name)
+		if _, ok := tags[name]; ok {
+			return nil, fmt.Errorf("duplicate DKIM tag %q", name)
+		}
+		value = strings.Join(strings.Fields(value), "")
+		if name == "v" && (i != 0 || value != "DKIM1") {
+			return nil, fmt.Errorf("invalid DKIM version %q", value)
+		}
+		tags[name] = value
+	}
+	p, ok := tags["p"]
+	switch {
+	case !ok:
+		return nil, errors.New("DKIM record missing public key")
+	case p == "":
+		return nil, ErrDKIMKeyRevoked
+	}
+	buf, err := base64.StdEncoding.DecodeString(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DKIM public key: %w", err)
+	}
+	switch k := tags["k"]; k {
+	case "", "rsa":
+		pub, err := x509.ParsePKIXPublicKey(buf)
+		if err != nil {
+			// some signers publish a PKCS#1 RSAPublicKey
+			if z, e := x509.ParsePKCS1PublicKey(buf); e == nil {
+				return z, nil
+			}
+			return nil, fmt.Errorf("invalid DKIM RSA public key: %w", err)
+		}
+		if _, ok := pub.(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("invalid DKIM RSA public key type %T", pub)
+		}
+		return pub, nil
+	case "ed25519":
+		if len(buf) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid DKIM Ed25519 public key")
+		}
+		return ed25519.PublicKey(buf), nil
+	default:
+		return nil, fmt.Errorf("unsupported DKIM key type %q", k)
+	}
+}
+
+// unquoteTXT joins the quoted strings of a DNS TXT record value, returning
+// the value unchanged when it is not quoted.
+func unquoteTXT(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(s, "("), ")"))
+	if !strings.HasPrefix(s, `"`) {
+		return s
+	}
+	var b strings.Builder
+	for quoted, i := false, 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\' && quoted && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case quoted:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
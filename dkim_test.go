@@ -0,0 +1,80 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDKIMRecord(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(Rand(), 2048)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(Rand())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		key    interface{}
+		prefix string
+	}{
+		{rsaKey, "v=DKIM1; k=rsa; p=MII"},
+		{edKey, "v=DKIM1; k=ed25519; p="},
+	}
+	for i, test := range tests {
+		s := Store{PrivateKey: test.key}
+		v, err := s.DKIMRecord()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !strings.HasPrefix(v, test.prefix) {
+			t.Errorf("test %d expected prefix %q, got: %q", i, test.prefix, v)
+		}
+		rr, err := s.DKIMZoneRecord("sel", "example.com")
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		name, value, _ := strings.Cut(rr, " IN TXT ")
+		if name != "sel._domainkey.example.com." {
+			t.Errorf("test %d expected name sel._domainkey.example.com., got: %q", i, name)
+		}
+		for _, z := range []string{v, value} {
+			pub, err := ParseDKIMRecord(z)
+			if err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+			if !EqualKeys(pub, test.key.(crypto.Signer).Public()) {
+				t.Errorf("test %d expected public key to round trip", i)
+			}
+		}
+	}
+}
+
+func TestParseDKIMRecord(t *testing.T) {
+	// RFC 8463 appendix A.2
+	pub, err := ParseDKIMRecord(`"v=DKIM1; k=ed25519; " "p=11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="`)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := pub.(ed25519.PublicKey); !ok {
+		t.Errorf("expected ed25519.PublicKey, got: %T", pub)
+	}
+	if _, err := ParseDKIMRecord("v=DKIM1; k=rsa; p="); !errors.Is(err, ErrDKIMKeyRevoked) {
+		t.Errorf("expected ErrDKIMKeyRevoked, got: %v", err)
+	}
+	for i, s := range []string{
+		"v=DKIM1; k=rsa",
+		"k=rsa; v=DKIM1; p=AAAA",
+		"v=DKIM1; k=dsa; p=AAAA",
+		"v=DKIM1; k=ed25519; p=AAAA",
+		"v=DKIM1; p=!!!!",
+	} {
+		if _, err := ParseDKIMRecord(s); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+	}
+}
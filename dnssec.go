@@ -0,0 +1,476 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// DNSSECAlgorithm is a DNSSEC algorithm number (RFC 8624).
+type DNSSECAlgorithm uint8
+
+// DNSSEC algorithms.
+const (
+	DNSSECRSASHA1         DNSSECAlgorithm = 5
+	DNSSECRSASHA1NSEC3    DNSSECAlgorithm = 7
+	DNSSECRSASHA256       DNSSECAlgorithm = 8
+	DNSSECRSASHA512       DNSSECAlgorithm = 10
+	DNSSECECDSAP256SHA256 DNSSECAlgorithm = 13
+	DNSSECECDSAP384SHA384 DNSSECAlgorithm = 14
+	DNSSECED25519         DNSSECAlgorithm = 15
+	DNSSECED448           DNSSECAlgorithm = 16
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (alg DNSSECAlgorithm) String() string {
+	switch alg {
+	case DNSSECRSASHA1:
+		return "RSASHA1"
+	case DNSSECRSASHA1NSEC3:
+		return "RSASHA1-NSEC3-SHA1"
+	case DNSSECRSASHA256:
+		return "RSASHA256"
+	case DNSSECRSASHA512:
+		return "RSASHA512"
+	case DNSSECECDSAP256SHA256:
+		return "ECDSAP256SHA256"
+	case DNSSECECDSAP384SHA384:
+		return "ECDSAP384SHA384"
+	case DNSSECED25519:
+		return "ED25519"
+	case DNSSECED448:
+		return "ED448"
+	}
+	return strconv.Itoa(int(alg))
+}
+
+// DNSSECAlgorithmFor returns the DNSSEC algorithm for the public key. RSA
+// keys use [DNSSECRSASHA256].
+func DNSSECAlgorithmFor(pub crypto.PublicKey) (DNSSECAlgorithm, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return DNSSECRSASHA256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return DNSSECECDSAP256SHA256, nil
+		case elliptic.P384():
+			return DNSSECECDSAP384SHA384, nil
+		}
+		return 0, fmt.Errorf("unsupported DNSSEC curve %s", k.Params().Name)
+	case ed25519.PublicKey:
+		return DNSSECED25519, nil
+	case ed448.PublicKey:
+		return DNSSECED448, nil
+	}
+	return 0, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// DNSKEY flags.
+const (
+	// DNSKEYFlagZone is the zone key flag.
+	DNSKEYFlagZone uint16 = 0x0100
+	// DNSKEYFlagRevoke is the revoke flag (RFC 5011).
+	DNSKEYFlagRevoke uint16 = 0x0080
+	// DNSKEYFlagSEP is the secure entry point flag.
+	DNSKEYFlagSEP uint16 = 0x0001
+	// DNSKEYFlagsZSK are the flags for a zone signing key.
+	DNSKEYFlagsZSK = DNSKEYFlagZone
+	// DNSKEYFlagsKSK are the flags for a key signing key.
+	DNSKEYFlagsKSK = DNSKEYFlagZone | DNSKEYFlagSEP
+)
+
+// DSDigestType is a DS record digest type.
+type DSDigestType uint8
+
+// DS record digest types.
+const (
+	DSDigestSHA1   DSDigestType = 1
+	DSDigestSHA256 DSDigestType = 2
+	DSDigestSHA384 DSDigestType = 4
+)
+
+// hash returns the hash for the digest type.
+func (typ DSDigestType) hash() (crypto.Hash, error) {
+	switch typ {
+	case DSDigestSHA1:
+		return crypto.SHA1, nil
+	case DSDigestSHA256:
+		return crypto.SHA256, nil
+	case DSDigestSHA384:
+		return crypto.SHA384, nil
+	}
+	return 0, fmt.Errorf("unsupported DS digest type %d", typ)
+}
+
+// DNSKEYRecord is a DNSSEC DNSKEY resource record (RFC 4034).
+type DNSKEYRecord struct {
+	// Name is the owner name.
+	Name string
+	// TTL is the time to live, in seconds. Omitted from the presentation
+	// format when 0.
+	TTL uint32
+	// Flags are the flags (see [DNSKEYFlagsZSK] and [DNSKEYFlagsKSK]).
+	Flags uint16
+	// Protocol is the protocol (always 3).
+	Protocol uint8
+	// Algorithm is the algorithm.
+	Algorithm DNSSECAlgorithm
+	// Key is the public key, in the algorithm's DNSKEY wire format.
+	Key []byte
+}
+
+// NewDNSKEYRecord creates a DNSKEY record for the public key.
+func NewDNSKEYRecord(name string, flags uint16, pub crypto.PublicKey) (*DNSKEYRecord, error) {
+	alg, err := DNSSECAlgorithmFor(pub)
+	if err != nil {
+		return nil, err
+	}
+	var key []byte
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		// RFC 3110 section 2
+		e := big.NewInt(int64(k.E)).Bytes()
+		if len(e) < 256 {
+			key = append(key, byte(len(e)))
+		} else {
+			key = append(key, 0, byte(len(e)>>8), byte(len(e)))
+		}
+		key = append(append(key, e...), k.N.Bytes()...)
+	case *ecdsa.PublicKey:
+		// RFC 6605 section 4
+		n := (k.Params().BitSize + 7) / 8
+		key = append(k.X.FillBytes(make([]byte, n)), k.Y.FillBytes(make([]byte, n))...)
+	case ed25519.PublicKey:
+		key = append(key, k...)
+	case ed448.PublicKey:
+		key = append(key, k...)
+	}
+	return &DNSKEYRecord{
+		Name:      fqdn(name),
+		Flags:     flags,
+		Protocol:  3,
+		Algorithm: alg,
+		Key:       key,
+	}, nil
+}
+
+// DNSKEY creates a DNSKEY record for the public key in the [Store] (see
+// [CanonicalPublicKey]).
+func (s Store) DNSKEY(name string, flags uint16) (*DNSKEYRecord, error) {
+	pub, err := CanonicalPublicKey(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewDNSKEYRecord(name, flags, pub)
+}
+
+// PublicKey returns the public key of the DNSKEY record.
+func (r *DNSKEYRecord) PublicKey() (crypto.PublicKey, error) {
+	switch r.Algorithm {
+	case DNSSECRSASHA1, DNSSECRSASHA1NSEC3, DNSSECRSASHA256, DNSSECRSASHA512:
+		buf := r.Key
+		if len(buf) < 1 {
+			return nil, errors.New("invalid DNSKEY RSA public key")
+		}
+		n := int(buf[0])
+		buf = buf[1:]
+		if n == 0 {
+			if len(buf) < 2 {
+				return nil, errors.New("invalid DNSKEY RSA public key")
+			}
+			n, buf = int(binary.BigEndian.Uint16(buf)), buf[2:]
+		}
+		if n == 0 || n > 4 || len(buf) <= n {
+			return nil, errors.New("invalid DNSKEY RSA public key")
+		}
+		return &rsa.PublicKey{
+			E: int(new(big.Int).SetBytes(buf[:n]).Int64()),
+			N: new(big.Int).SetBytes(buf[n:]),
+		}, nil
+	case DNSSECECDSAP256SHA256, DNSSECECDSAP384SHA384:
+		curve := elliptic.P256()
+		if r.Algorithm == DNSSECECDSAP384SHA384 {
+			curve = elliptic.P384()
+		}
+		n := (curve.Params().BitSize + 7) / 8
+		if len(r.Key) != 2*n {
+			return nil, errors.New("invalid DNSKEY ECDSA public key")
+		}
+		x, y := elliptic.Unmarshal(curve, append([]byte{4}, r.Key...))
+		if x == nil {
+			return nil, errors.New("invalid DNSKEY ECDSA public key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case DNSSECED25519:
+		if len(r.Key) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid DNSKEY Ed25519 public key")
+		}
+		return ed25519.PublicKey(append([]byte(nil), r.Key...)), nil
+	case DNSSECED448:
+		if len(r.Key) != ed448.PublicKeySize {
+			return nil, errors.New("invalid DNSKEY Ed448 public key")
+		}
+		return ed448.PublicKey(append([]byte(nil), r.Key...)), nil
+	}
+	return nil, fmt.Errorf("unsupported DNSSEC algorithm %s", r.Algorithm)
+}
+
+// Store returns a [Store] containing the public key of the DNSKEY record.
+func (r *DNSKEYRecord) Store() (Store, error) {
+	pub, err := r.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return Store{PublicKey: pub}, nil
+}
+
+// rdata returns the DNSKEY record's RDATA wire format.
+func (r *DNSKEYRecord) rdata() []byte {
+	buf := make([]byte, 4, 4+len(r.Key))
+	binary.BigEndian.PutUint16(buf, r.Flags)
+	buf[2], buf[3] = r.Protocol, byte(r.Algorithm)
+	return append(buf, r.Key...)
+}
+
+// KeyTag returns the key tag of the DNSKEY record (RFC 4034 appendix B).
+func (r *DNSKEYRecord) KeyTag() uint16 {
+	var ac uint32
+	for i, b := range r.rdata() {
+		if i&1 == 0 {
+			ac += uint32(b) << 8
+		} else {
+			ac += uint32(b)
+		}
+	}
+	ac += ac >> 16 & 0xffff
+	return uint16(ac)
+}
+
+// DS creates a DS record for the DNSKEY record, using the digest type.
+func (r *DNSKEYRecord) DS(typ DSDigestType) (*DSRecord, error) {
+	h, err := typ.hash()
+	if err != nil {
+		return nil, err
+	}
+	name, err := wireName(r.Name)
+	if err != nil {
+		return nil, err
+	}
+	hh := h.New()
+	hh.Write(name)
+	hh.Write(r.rdata())
+	return &DSRecord{
+		Name:       fqdn(r.Name),
+		TTL:        r.TTL,
+		KeyTag:     r.KeyTag(),
+		Algorithm:  r.Algorithm,
+		DigestType: typ,
+		Digest:     hh.Sum(nil),
+	}, nil
+}
+
+// String satisfies the [fmt.Stringer] interface, returning the DNSKEY
+// record's presentation format.
+func (r *DNSKEYRecord) String() string {
+	return fmt.Sprintf(
+		"%sIN DNSKEY %d %d %d %s",
+		rrPrefix(r.Name, r.TTL), r.Flags, r.Protocol, r.Algorithm,
+		base64.StdEncoding.EncodeToString(r.Key),
+	)
+}
+
+// ParseDNSKEYRecord parses a DNSKEY record in presentation format, such as
+// "example.com. 3600 IN DNSKEY 257 3 13 <base64>".
+func ParseDNSKEYRecord(s string) (*DNSKEYRecord, error) {
+	name, ttl, fields, err := parseRR(s, "DNSKEY")
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 4 {
+		return nil, errors.New("invalid DNSKEY record")
+	}
+	flags, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNSKEY flags %q", fields[0])
+	}
+	protocol, err := strconv.ParseUint(fields[1], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNSKEY protocol %q", fields[1])
+	}
+	alg, err := strconv.ParseUint(fields[2], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNSKEY algorithm %q", fields[2])
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.Join(fields[3:], ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNSKEY public key: %w", err)
+	}
+	return &DNSKEYRecord{
+		Name:      name,
+		TTL:       ttl,
+		Flags:     uint16(flags),
+		Protocol:  uint8(protocol),
+		Algorithm: DNSSECAlgorithm(alg),
+		Key:       key,
+	}, nil
+}
+
+// DSRecord is a DNSSEC DS (delegation signer) resource record (RFC 4034).
+type DSRecord struct {
+	// Name is the owner name.
+	Name string
+	// TTL is the time to live, in seconds. Omitted from the presentation
+	// format when 0.
+	TTL uint32
+	// KeyTag is the key tag of the DNSKEY record.
+	KeyTag uint16
+	// Algorithm is the algorithm of the DNSKEY record.
+	Algorithm DNSSECAlgorithm
+	// DigestType is the digest type.
+	DigestType DSDigestType
+	// Digest is the digest of the DNSKEY record.
+	Digest []byte
+}
+
+// Matches returns true when the DS record matches the DNSKEY record.
+func (r *DSRecord) Matches(key *DNSKEYRecord) bool {
+	ds, err := key.DS(r.DigestType)
+	return err == nil &&
+		r.KeyTag == ds.KeyTag &&
+		r.Algorithm == ds.Algorithm &&
+		strings.EqualFold(fqdn(r.Name), ds.Name) &&
+		string(r.Digest) == string(ds.Digest)
+}
+
+// String satisfies the [fmt.Stringer] interface, returning the DS record's
+// presentation format.
+func (r *DSRecord) String() string {
+	return fmt.Sprintf(
+		"%sIN DS %d %d %d %s",
+		rrPrefix(r.Name, r.TTL), r.KeyTag, r.Algorithm, r.DigestType,
+		strings.ToUpper(hex.EncodeToString(r.Digest)),
+	)
+}
+
+// ParseDSRecord parses a DS record in presentation format, such as
+// "example.com. 3600 IN DS 12345 13 2 <hex>".
+func ParseDSRecord(s string) (*DSRecord, error) {
+	name, ttl, fields, err := parseRR(s, "DS")
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 4 {
+		return nil, errors.New("invalid DS record")
+	}
+	keyTag, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DS key tag %q", fields[0])
+	}
+	alg, err := strconv.ParseUint(fields[1], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DS algorithm %q", fields[1])
+	}
+	typ, err := strconv.ParseUint(fields[2], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DS digest type %q", fields[2])
+	}
+	digest, err := hex.DecodeString(strings.Join(fields[3:], ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DS digest: %w", err)
+	}
+	return &DSRecord{
+		Name:       name,
+		TTL:        ttl,
+		KeyTag:     uint16(keyTag),
+		Algorithm:  DNSSECAlgorithm(alg),
+		DigestType: DSDigestType(typ),
+		Digest:     digest,
+	}, nil
+}
+
+// parseRR parses a resource record in presentation format, returning the
+// owner name, TTL, and RDATA fields. Comments and parentheses are ignored.
+func parseRR(s, typ string) (string, uint32, []string, error) {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line, _, _ = strings.Cut(line, ";")
+		lines = append(lines, line)
+	}
+	fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(strings.Join(lines, " ")))
+	i := 0
+	for ; i < len(fields) && !strings.EqualFold(fields[i], typ); i++ {
+	}
+	if i == len(fields) {
+		return "", 0, nil, fmt.Errorf("invalid %s record: missing type", typ)
+	}
+	var name string
+	var ttl uint32
+	for _, f := range fields[:i] {
+		switch n, err := strconv.ParseUint(f, 10, 32); {
+		case err == nil:
+			ttl = uint32(n)
+		case strings.EqualFold(f, "IN"):
+		case name == "":
+			name = fqdn(f)
+		default:
+			return "", 0, nil, fmt.Errorf("invalid %s record: unexpected %q", typ, f)
+		}
+	}
+	return name, ttl, fields[i+1:], nil
+}
+
+// rrPrefix returns the owner name and TTL prefix of a resource record's
+// presentation format.
+func rrPrefix(name string, ttl uint32) string {
+	var s string
+	if name != "" {
+		s = fqdn(name) + " "
+	}
+	if ttl != 0 {
+		s += strconv.FormatUint(uint64(ttl), 10) + " "
+	}
+	return s
+}
+
+// fqdn returns the fully qualified domain name for name.
+func fqdn(name string) string {
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// wireName returns the canonical (lowercase) wire format of the domain name.
+func wireName(name string) ([]byte, error) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	var buf []byte
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if len(label) == 0 || len(label) > 63 || strings.Contains(label, `\`) {
+				return nil, fmt.Errorf("invalid domain name %q", name)
+			}
+			buf = append(append(buf, byte(len(label))), label...)
+		}
+	}
+	buf = append(buf, 0)
+	if len(buf) > 255 {
+		return nil, fmt.Errorf("invalid domain name %q", name)
+	}
+	return buf, nil
+}
@@ -0,0 +1,129 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestDNSKEYRecord(t *testing.T) {
+	tests := []struct {
+		dnskey string
+		ds     string
+	}{
+		{ // RFC 6605 section 6.1
+			`example.net. 3600 IN DNSKEY 257 3 13 (
+				GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edb
+				krSqQpF64cYbcB7wNcP+e+MAnLr+Wi9xMWyQLc8NAA== )`,
+			`example.net. 3600 IN DS 55648 13 2 (
+				b4c8c1fe2e7477127b27115656ad6256f424625bf5c1
+				e2770ce6d6e37df61d17 )`,
+		},
+		{ // RFC 8080 section 6.1
+			`example.com. 3600 IN DNSKEY 257 3 15 (
+				l02Woi0iS8Aa25FQkUd9RMzZHJpBoRQwAQEX1SxZJA4= )`,
+			`example.com. 3600 IN DS 3613 15 2 (
+				3aa5ab37efce57f737fc1627013fee07
+				bdf241bd10f3b1964ab55c78e79a304b )`,
+		},
+	}
+	for i, test := range tests {
+		key, err := ParseDNSKEYRecord(test.dnskey)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		ds, err := ParseDSRecord(test.ds)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if tag := key.KeyTag(); tag != ds.KeyTag {
+			t.Errorf("test %d expected key tag %d, got: %d", i, ds.KeyTag, tag)
+		}
+		if !ds.Matches(key) {
+			t.Errorf("test %d expected DS to match DNSKEY", i)
+		}
+		z, err := key.DS(DSDigestSHA256)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s, exp := z.String(), ds.String(); s != exp {
+			t.Errorf("test %d expected %q, got: %q", i, exp, s)
+		}
+		// round trip through the store
+		s, err := key.Store()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		k, err := s.DNSKEY(key.Name, key.Flags)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		k.TTL = key.TTL
+		if s, exp := k.String(), key.String(); s != exp {
+			t.Errorf("test %d expected %q, got: %q", i, exp, s)
+		}
+	}
+}
+
+func TestStoreDNSKEY(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(Rand(), 2048)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), Rand())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(Rand())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		key interface{}
+		alg DNSSECAlgorithm
+	}{
+		{rsaKey, DNSSECRSASHA256},
+		{ecKey, DNSSECECDSAP384SHA384},
+		{edKey, DNSSECED25519},
+	}
+	for i, test := range tests {
+		s := Store{PrivateKey: test.key}
+		rr, err := s.DNSKEY("Example.COM", DNSKEYFlagsZSK)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if rr.Algorithm != test.alg {
+			t.Errorf("test %d expected algorithm %s, got: %s", i, test.alg, rr.Algorithm)
+		}
+		z, err := ParseDNSKEYRecord(rr.String())
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		pub, err := z.PublicKey()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !EqualKeys(pub, test.key.(crypto.Signer).Public()) {
+			t.Errorf("test %d expected public key to round trip", i)
+		}
+		for _, typ := range []DSDigestType{DSDigestSHA1, DSDigestSHA256, DSDigestSHA384} {
+			ds, err := rr.DS(typ)
+			if err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+			z, err := ParseDSRecord(ds.String())
+			if err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+			if !z.Matches(rr) {
+				t.Errorf("test %d expected DS digest type %d to match", i, typ)
+			}
+		}
+	}
+	if _, err := (Store{}).DNSKEY("example.com", DNSKEYFlagsKSK); err == nil {
+		t.Errorf("expected error for empty store")
+	}
+}
@@ -0,0 +1,139 @@
+package pemutil
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+)
+
+// DuplicateError is a duplicate block error, returned when decoding and
+// loading with the [WithDuplicateError] option.
+type DuplicateError struct {
+	// Type is the block type of the duplicate block.
+	Type BlockType
+	// Location is the location of the duplicate block (ie, "key.pem:12").
+	Location string
+	// Previous is the location of the block first encountered, or empty
+	// when the block was already present in the [Store].
+	Previous string
+}
+
+// Error satisfies the error interface.
+func (err *DuplicateError) Error() string {
+	if err.Previous == "" {
+		return fmt.Sprintf("duplicate %s block at %s (already present in store)", err.Type, err.Location)
+	}
+	return fmt.Sprintf("duplicate %s block at %s (first at %s)", err.Type, err.Location, err.Previous)
+}
+
+// WithDuplicateError is a decode and load option to return a
+// [*DuplicateError] naming both locations when a block is encountered more
+// than once, instead of silently combining them. Blocks are considered
+// duplicates when:
+//
+//   - more than one private key block is encountered, of any private key
+//     block type
+//   - the same certificate is encountered more than once
+//   - any other block type is encountered more than once
+//
+// Locations are tracked across the files loaded by [LoadFiles] and
+// [LoadPair], such as when combining a private key file with a full chain
+// file that both contain the leaf certificate.
+func WithDuplicateError() LoadOption {
+	return func(opts *loadOptions) {
+		opts.dupes = true
+		if opts.origins == nil {
+			opts.origins = make(map[string]string)
+		}
+	}
+}
+
+// withSource is a load option to set the name of the source being decoded.
+func withSource(name string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.source = name
+	}
+}
+
+// withOrigins is a load option to share the locations of encountered blocks
+// between multiple loads.
+func withOrigins(origins map[string]string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.origins = origins
+	}
+}
+
+// checkDuplicate checks if the block is a duplicate of a block previously
+// encountered or already present in the store, recording its location.
+func (o *loadOptions) checkDuplicate(s Store, block *pem.Block, line int) error {
+	loc := "line " + strconv.Itoa(line)
+	if o.source != "" {
+		loc = o.source + ":" + strconv.Itoa(line)
+	}
+	key := duplicateKey(BlockType(block.Type), block.Bytes)
+	if prev, ok := o.origins[key]; ok {
+		return &DuplicateError{Type: BlockType(block.Type), Location: loc, Previous: prev}
+	}
+	if s.hasDuplicate(key) {
+		return &DuplicateError{Type: BlockType(block.Type), Location: loc}
+	}
+	o.origins[key] = loc
+	return nil
+}
+
+// duplicateKey returns the key used to detect duplicate blocks.
+func duplicateKey(typ BlockType, der []byte) string {
+	switch typ {
+	case PrivateKey, RSAPrivateKey, ECPrivateKey, EncryptedPrivateKey:
+		return PrivateKey.String()
+	case Certificate:
+		return fmt.Sprintf("%s %x", typ, sha256.Sum256(der))
+	}
+	return typ.String()
+}
+
+// hasDuplicate returns true when the [Store] contains a block with the
+// duplicate key.
+func (s Store) hasDuplicate(key string) bool {
+	switch key {
+	case PrivateKey.String():
+		_, ok := s.PrivateKey()
+		return ok
+	}
+	if _, ok := s[BlockType(key)]; ok {
+		return true
+	}
+	if l, ok := s[Certificate].(*lazyCertificates); ok {
+		for _, block := range l.pemBlocks() {
+			if duplicateKey(Certificate, block.Bytes) == key {
+				return true
+			}
+		}
+		return false
+	}
+	for _, cert := range s.Certificates() {
+		if duplicateKey(Certificate, cert.Raw) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadFiles creates a store and loads any crypto primitives in the PEM
+// encoded data stored in the files, such as a private key file and a
+// certificate chain file. Use the [WithDuplicateError] option to return an
+// error naming both files when a block is encountered in more than one file.
+//
+// Note: calls [Store.AddPublicKeys] after successfully loading the files.
+func LoadFiles(filenames []string, opts ...LoadOption) (Store, error) {
+	s := make(Store)
+	opts = append(opts[:len(opts):len(opts)], withOrigins(make(map[string]string)))
+	for _, filename := range filenames {
+		if err := s.LoadFile(filename, opts...); err != nil {
+			return nil, err
+		}
+	}
+	s.AddPublicKeys()
+	return s, nil
+}
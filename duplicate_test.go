@@ -0,0 +1,95 @@
+package pemutil
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestDuplicateError(t *testing.T) {
+	key, err := os.ReadFile("testdata/ec256-private.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rsaKey, err := os.ReadFile("testdata/rsa-private.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ca, err := os.ReadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := DecodeBytes(key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, err := s.SelfSign(ServerTemplate("example.com"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leaf, err := EncodePrimitive(cert)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := t.TempDir()
+	write := func(name string, bufs ...[]byte) string {
+		var buf []byte
+		for _, b := range bufs {
+			buf = append(buf, b...)
+		}
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, buf, 0o600); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return filename
+	}
+	line := func(buf []byte) string {
+		return strconv.Itoa(bytes.Count(buf, []byte("\n")) + 1)
+	}
+	keyFile := write("key.pem", key, leaf)
+	chainFile := write("fullchain.pem", leaf, ca)
+	// without the option, the leaf certificate is silently duplicated
+	z, err := LoadFiles([]string{keyFile, chainFile})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := len(z.Certificates()); n != 3 {
+		t.Errorf("expected 3 certificates, got: %d", n)
+	}
+	tests := []struct {
+		files    []string
+		typ      BlockType
+		loc      string
+		previous string
+	}{
+		{[]string{keyFile, chainFile}, Certificate, chainFile + ":1", keyFile + ":" + line(key)},
+		{[]string{write("leaf2.pem", leaf, leaf)}, Certificate, filepath.Join(dir, "leaf2.pem") + ":" + line(leaf), filepath.Join(dir, "leaf2.pem") + ":1"},
+		{[]string{keyFile, write("rsa.pem", rsaKey)}, RSAPrivateKey, filepath.Join(dir, "rsa.pem") + ":1", keyFile + ":1"},
+	}
+	for i, test := range tests {
+		_, err := LoadFiles(test.files, WithDuplicateError())
+		var de *DuplicateError
+		if !errors.As(err, &de) {
+			t.Fatalf("test %d expected duplicate error, got: %v", i, err)
+		}
+		if de.Type != test.typ || de.Location != test.loc || de.Previous != test.previous {
+			t.Errorf("test %d expected %s at %s (first at %s), got: %v", i, test.typ, test.loc, test.previous, de)
+		}
+	}
+	// already present in the store
+	z = make(Store)
+	if err := z.Decode(key); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var de *DuplicateError
+	if err := z.Decode(rsaKey, WithDuplicateError()); !errors.As(err, &de) || de.Previous != "" || de.Location != "line 1" {
+		t.Errorf("expected duplicate error, got: %v", err)
+	}
+	// distinct certificates are not duplicates
+	if _, err := LoadFiles([]string{write("chain.pem", leaf, ca)}, WithDuplicateError(), WithLazyLoad()); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
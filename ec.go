@@ -0,0 +1,387 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+var (
+	// oidPublicKeyECDSA is the id-ecPublicKey object identifier.
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+
+	// oidPrimeField is the prime-field object identifier.
+	oidPrimeField = asn1.ObjectIdentifier{1, 2, 840, 10045, 1, 1}
+
+	// OIDSecp256k1 is the secp256k1 named-curve object identifier.
+	OIDSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+	// OIDBrainpoolP256r1 is the brainpoolP256r1 named-curve object identifier.
+	OIDBrainpoolP256r1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 7}
+
+	// OIDBrainpoolP384r1 is the brainpoolP384r1 named-curve object identifier.
+	OIDBrainpoolP384r1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 11}
+
+	// OIDBrainpoolP512r1 is the brainpoolP512r1 named-curve object identifier.
+	OIDBrainpoolP512r1 = asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 13}
+)
+
+// curveNames are the names of well-known curves that are not supported by
+// the standard library, and whose implementation must be provided via
+// [RegisterCurve].
+var curveNames = map[string]string{
+	OIDSecp256k1.String():       "secp256k1",
+	OIDBrainpoolP256r1.String(): "brainpoolP256r1",
+	OIDBrainpoolP384r1.String(): "brainpoolP384r1",
+	OIDBrainpoolP512r1.String(): "brainpoolP512r1",
+}
+
+// namedCurve is a elliptic curve and its named-curve object identifier.
+type namedCurve struct {
+	oid   asn1.ObjectIdentifier
+	curve elliptic.Curve
+	// std indicates the curve is supported by the standard library.
+	std bool
+}
+
+// namedCurves are the known named curves.
+var namedCurves = []namedCurve{
+	{oidP224, elliptic.P224(), true},
+	{oidP256, elliptic.P256(), true},
+	{oidP384, elliptic.P384(), true},
+	{oidP521, elliptic.P521(), true},
+}
+
+// curveMu guards the registered named curves.
+var curveMu sync.RWMutex
+
+// RegisterCurve registers a elliptic curve implementation with its
+// named-curve object identifier, allowing EC keys for curves not supported by
+// the standard library (such as secp256k1, or the Brainpool curves) to be
+// generated, parsed, and encoded.
+//
+// Registering an object identifier that is already registered replaces the
+// existing curve implementation.
+func RegisterCurve(oid asn1.ObjectIdentifier, curve elliptic.Curve) {
+	curveMu.Lock()
+	defer curveMu.Unlock()
+	for i, nc := range namedCurves {
+		if nc.oid.Equal(oid) {
+			namedCurves[i] = namedCurve{oid: oid, curve: curve}
+			return
+		}
+	}
+	namedCurves = append(namedCurves, namedCurve{oid: oid, curve: curve})
+}
+
+// curves returns the known named curves.
+func curves() []namedCurve {
+	curveMu.RLock()
+	defer curveMu.RUnlock()
+	return append([]namedCurve(nil), namedCurves...)
+}
+
+// curveByOID returns the named curve for the object identifier.
+func curveByOID(oid asn1.ObjectIdentifier) (namedCurve, bool) {
+	for _, nc := range curves() {
+		if nc.oid.Equal(oid) {
+			return nc, true
+		}
+	}
+	return namedCurve{}, false
+}
+
+// curveByCurve returns the named curve for the curve.
+func curveByCurve(curve elliptic.Curve) (namedCurve, bool) {
+	for _, nc := range curves() {
+		if nc.curve == curve {
+			return nc, true
+		}
+	}
+	return namedCurve{}, false
+}
+
+// ecPrivateKey is the SEC1 (RFC 5915) EC private key structure.
+type ecPrivateKey struct {
+	Version    int
+	PrivateKey []byte
+	Parameters asn1.RawValue  `asn1:"optional,explicit,tag:0"`
+	PublicKey  asn1.BitString `asn1:"optional,explicit,tag:1"`
+}
+
+// ecNamedPrivateKey is the SEC1 (RFC 5915) EC private key structure using a
+// named-curve.
+type ecNamedPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// ecParameters is the SEC1 explicit EC domain parameters structure.
+type ecParameters struct {
+	Version  int
+	FieldID  ecFieldID
+	Curve    ecCurve
+	Base     []byte
+	Order    *big.Int
+	Cofactor *big.Int `asn1:"optional"`
+}
+
+// ecFieldID is the SEC1 field identifier structure.
+type ecFieldID struct {
+	FieldType asn1.ObjectIdentifier
+	Prime     *big.Int
+}
+
+// ecCurve is the SEC1 curve coefficients structure.
+type ecCurve struct {
+	A, B []byte
+	Seed asn1.BitString `asn1:"optional"`
+}
+
+// pkcs8 is the PKCS#8 private key structure.
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// pkixPublicKey is the PKIX subject public key info structure.
+type pkixPublicKey struct {
+	Algo      pkix.AlgorithmIdentifier
+	BitString asn1.BitString
+}
+
+// ParseECPrivateKey parses a SEC1 encoded EC private key, accepting keys
+// encoded with either a named-curve or with explicit curve parameters that
+// match a known named-curve, including curves added via [RegisterCurve].
+//
+// Keys with explicit curve parameters are converted to their named-curve form,
+// and will be encoded using the named-curve object identifier.
+func ParseECPrivateKey(buf []byte) (*ecdsa.PrivateKey, error) {
+	key, err := x509.ParseECPrivateKey(buf)
+	if err == nil {
+		return key, nil
+	}
+	var k ecPrivateKey
+	if _, uerr := asn1.Unmarshal(buf, &k); uerr != nil {
+		return nil, err
+	}
+	// unwrap the explicitly tagged parameters
+	var params asn1.RawValue
+	if _, uerr := asn1.Unmarshal(k.Parameters.Bytes, &params); uerr != nil {
+		return nil, err
+	}
+	return parseECPrivateKey(k, params)
+}
+
+// ParsePKIXPublicKey parses a PKIX encoded public key, accepting EC public keys
+// encoded with explicit curve parameters that match a known named-curve, EC
+// public keys for curves added via [RegisterCurve], and Ed448 public keys.
+func ParsePKIXPublicKey(buf []byte) (interface{}, error) {
+	key, err := x509.ParsePKIXPublicKey(buf)
+	if err == nil {
+		return key, nil
+	}
+	var pub pkixPublicKey
+	if rest, uerr := asn1.Unmarshal(buf, &pub); uerr != nil || len(rest) != 0 {
+		return nil, err
+	}
+	switch {
+	case pub.Algo.Algorithm.Equal(oidPublicKeyEd448):
+		return ParseEd448PublicKey(buf)
+	case !pub.Algo.Algorithm.Equal(oidPublicKeyECDSA):
+		return nil, err
+	}
+	nc, nerr := lookupCurve(pub.Algo.Parameters)
+	if nerr != nil {
+		return nil, nerr
+	}
+	if !nc.std {
+		x, y := elliptic.Unmarshal(nc.curve, pub.BitString.RightAlign())
+		if x == nil {
+			return nil, errors.New("invalid EC public key")
+		}
+		return &ecdsa.PublicKey{Curve: nc.curve, X: x, Y: y}, nil
+	}
+	if pub.Algo.Parameters, nerr = oidRawValue(nc.oid); nerr != nil {
+		return nil, nerr
+	}
+	named, nerr := asn1.Marshal(pub)
+	if nerr != nil {
+		return nil, nerr
+	}
+	return x509.ParsePKIXPublicKey(named)
+}
+
+// MarshalECPrivateKey encodes the EC private key in SEC1 form, using the
+// named-curve object identifier of the key's curve. Supports curves added via
+// [RegisterCurve].
+func MarshalECPrivateKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	nc, ok := curveByCurve(key.Curve)
+	if !ok || nc.std {
+		return x509.MarshalECPrivateKey(key)
+	}
+	size := (nc.curve.Params().N.BitLen() + 7) / 8
+	return asn1.Marshal(ecNamedPrivateKey{
+		Version:       1,
+		PrivateKey:    key.D.FillBytes(make([]byte, size)),
+		NamedCurveOID: nc.oid,
+		PublicKey: asn1.BitString{
+			Bytes: elliptic.Marshal(nc.curve, key.X, key.Y),
+		},
+	})
+}
+
+// MarshalPKIXPublicKey encodes the public key in PKIX form. Supports EC public
+// keys for curves added via [RegisterCurve], and Ed448 public keys.
+func MarshalPKIXPublicKey(pub interface{}) ([]byte, error) {
+	if key, ok := pub.(ed448.PublicKey); ok {
+		return MarshalEd448PublicKey(key)
+	}
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return x509.MarshalPKIXPublicKey(pub)
+	}
+	nc, ok := curveByCurve(key.Curve)
+	if !ok || nc.std {
+		return x509.MarshalPKIXPublicKey(pub)
+	}
+	params, err := oidRawValue(nc.oid)
+	if err != nil {
+		return nil, err
+	}
+	buf := elliptic.Marshal(nc.curve, key.X, key.Y)
+	return asn1.Marshal(pkixPublicKey{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: params,
+		},
+		BitString: asn1.BitString{Bytes: buf, BitLength: 8 * len(buf)},
+	})
+}
+
+// parsePKCS8EC parses a PKCS#8 encoded EC private key that uses explicit curve
+// parameters, or that uses a curve added via [RegisterCurve].
+func parsePKCS8EC(buf []byte) (*ecdsa.PrivateKey, error) {
+	var p pkcs8
+	if _, err := asn1.Unmarshal(buf, &p); err != nil {
+		return nil, err
+	}
+	if !p.Algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, errors.New("not an EC private key")
+	}
+	var k ecPrivateKey
+	if _, err := asn1.Unmarshal(p.PrivateKey, &k); err != nil {
+		return nil, err
+	}
+	// the inner key may omit the parameters, so use the outer parameters
+	return parseECPrivateKey(k, p.Algo.Parameters)
+}
+
+// parseECPrivateKey parses the EC private key using the named or explicit
+// curve parameters.
+func parseECPrivateKey(k ecPrivateKey, params asn1.RawValue) (*ecdsa.PrivateKey, error) {
+	nc, err := lookupCurve(params)
+	if err != nil {
+		return nil, err
+	}
+	if nc.std {
+		named, err := asn1.Marshal(ecNamedPrivateKey{
+			Version:       k.Version,
+			PrivateKey:    k.PrivateKey,
+			NamedCurveOID: nc.oid,
+			PublicKey:     k.PublicKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return x509.ParseECPrivateKey(named)
+	}
+	if k.Version != 1 {
+		return nil, fmt.Errorf("unknown EC private key version %d", k.Version)
+	}
+	d, n := new(big.Int).SetBytes(k.PrivateKey), nc.curve.Params().N
+	if d.Sign() <= 0 || d.Cmp(n) >= 0 {
+		return nil, errors.New("invalid EC private key value")
+	}
+	key := &ecdsa.PrivateKey{D: d}
+	key.Curve = nc.curve
+	key.X, key.Y = nc.curve.ScalarBaseMult(k.PrivateKey)
+	return key, nil
+}
+
+// lookupCurve returns the named curve for the named-curve object identifier or
+// explicit curve parameters.
+func lookupCurve(raw asn1.RawValue) (namedCurve, error) {
+	if raw.Class == asn1.ClassUniversal && raw.Tag == asn1.TagOID {
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(raw.FullBytes, &oid); err != nil {
+			return namedCurve{}, err
+		}
+		nc, ok := curveByOID(oid)
+		switch name, known := curveNames[oid.String()]; {
+		case !ok && known:
+			return namedCurve{}, fmt.Errorf("named curve %s (%s) is not registered", name, oid)
+		case !ok:
+			return namedCurve{}, fmt.Errorf("unknown named curve %s", oid)
+		}
+		return nc, nil
+	}
+	return ecParametersCurve(raw)
+}
+
+// ecParametersCurve returns the named curve for the explicit EC parameters.
+func ecParametersCurve(raw asn1.RawValue) (namedCurve, error) {
+	if raw.Class != asn1.ClassUniversal || raw.Tag != asn1.TagSequence {
+		return namedCurve{}, errors.New("EC parameters are not explicit")
+	}
+	var params ecParameters
+	if _, err := asn1.Unmarshal(raw.FullBytes, &params); err != nil {
+		return namedCurve{}, err
+	}
+	if !params.FieldID.FieldType.Equal(oidPrimeField) {
+		return namedCurve{}, errors.New("unsupported EC field type")
+	}
+	if params.Cofactor != nil && params.Cofactor.Cmp(big.NewInt(1)) != 0 {
+		return namedCurve{}, errors.New("unsupported EC cofactor")
+	}
+	a, b := new(big.Int).SetBytes(params.Curve.A), new(big.Int).SetBytes(params.Curve.B)
+	for _, nc := range curves() {
+		p := nc.curve.Params()
+		if p.P.Cmp(params.FieldID.Prime) != 0 ||
+			p.N.Cmp(params.Order) != 0 ||
+			p.B.Cmp(b) != 0 ||
+			// standard curves are a = -3
+			nc.std && new(big.Int).Sub(p.P, big.NewInt(3)).Cmp(a) != 0 ||
+			!bytes.Equal(params.Base, elliptic.Marshal(nc.curve, p.Gx, p.Gy)) &&
+				!bytes.Equal(params.Base, elliptic.MarshalCompressed(nc.curve, p.Gx, p.Gy)) {
+			continue
+		}
+		return nc, nil
+	}
+	return namedCurve{}, errors.New("EC parameters do not match a known curve")
+}
+
+// oidRawValue encodes the object identifier as a raw value.
+func oidRawValue(oid asn1.ObjectIdentifier) (asn1.RawValue, error) {
+	buf, err := asn1.Marshal(oid)
+	if err != nil {
+		return asn1.RawValue{}, err
+	}
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(buf, &raw); err != nil {
+		return asn1.RawValue{}, err
+	}
+	return raw, nil
+}
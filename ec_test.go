@@ -0,0 +1,315 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExplicitEC(t *testing.T) {
+	for i, filename := range []string{"testdata/ecx256.pem", "testdata/ecx256-pkcs8.key"} {
+		s := Store{}
+		if err := s.LoadFile(filename); err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, filename, err)
+		}
+		var key *ecdsa.PrivateKey
+		for _, v := range s {
+			if k, ok := v.(*ecdsa.PrivateKey); ok {
+				key = k
+			}
+		}
+		if key == nil {
+			t.Fatalf("test %d (%s) expected EC private key", i, filename)
+		}
+		if key.Curve != elliptic.P256() {
+			t.Errorf("test %d (%s) expected P-256 curve, got: %s", i, filename, key.Curve.Params().Name)
+		}
+		// re-encode should use the named-curve form
+		buf, err := EncodePrimitive(key)
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, filename, err)
+		}
+		block, _ := pem.Decode(buf)
+		if _, err := x509.ParseECPrivateKey(block.Bytes); err != nil {
+			t.Errorf("test %d (%s) expected named-curve encoding, got: %v", i, filename, err)
+		}
+	}
+	// public key
+	buf, err := os.ReadFile("testdata/ecx256-public.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	pub, ok := s.ECPublicKey()
+	if !ok {
+		t.Fatalf("expected EC public key")
+	}
+	if pub.Curve != elliptic.P256() {
+		t.Errorf("expected P-256 curve, got: %s", pub.Curve.Params().Name)
+	}
+	if !bytes.Contains(buf, []byte("PUBLIC KEY")) {
+		t.Errorf("expected PUBLIC KEY block")
+	}
+}
+
+func TestRegisterCurve(t *testing.T) {
+	curve := secp256k1()
+	RegisterCurve(OIDSecp256k1, curve)
+	// load openssl generated keys
+	for i, filename := range []string{"testdata/secp256k1.key", "testdata/secp256k1-pkcs8.key"} {
+		s, err := LoadFile(filename)
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, filename, err)
+		}
+		key, ok := s.PrivateKey()
+		if !ok {
+			t.Fatalf("test %d (%s) expected private key", i, filename)
+		}
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve != curve {
+			t.Fatalf("test %d (%s) expected secp256k1 private key, got: %T", i, filename, key)
+		}
+		pub, ok := s.ECPublicKey()
+		if !ok || !pub.Equal(priv.Public()) {
+			t.Errorf("test %d (%s) expected matching public key", i, filename)
+		}
+	}
+	// generate, encode, and decode
+	s, err := GenerateECKeySet(curve)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s0, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	priv, ok := s0.ECPrivateKey()
+	if !ok {
+		t.Fatalf("expected EC private key")
+	}
+	pub, ok := s0.ECPublicKey()
+	if !ok {
+		t.Fatalf("expected EC public key")
+	}
+	if exp, _ := s.ECPrivateKey(); !priv.Equal(exp) {
+		t.Errorf("expected decoded private key to equal generated private key")
+	}
+	digest := sha256.Sum256([]byte("pemutil"))
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+		t.Errorf("expected signature to verify")
+	}
+}
+
+func TestBrainpool(t *testing.T) {
+	const filename = "testdata/brainpoolP256r1.key"
+	// unregistered
+	if _, err := LoadFile(filename); err == nil || !strings.Contains(err.Error(), "brainpoolP256r1") {
+		t.Errorf("expected unregistered brainpoolP256r1 error, got: %v", err)
+	}
+	curve := brainpoolP256r1()
+	RegisterCurve(OIDBrainpoolP256r1, curve)
+	s, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	priv, ok := s.ECPrivateKey()
+	if !ok || priv.Curve != curve {
+		t.Fatalf("expected brainpoolP256r1 private key")
+	}
+	pub, ok := s.ECPublicKey()
+	if !ok || !pub.Equal(priv.Public()) {
+		t.Errorf("expected matching public key")
+	}
+	// round trip
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s0, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if priv0, ok := s0.ECPrivateKey(); !ok || !priv0.Equal(priv) {
+		t.Errorf("expected decoded private key to equal loaded private key")
+	}
+}
+
+// testCurve is a generic short Weierstrass curve (y² = x³ + ax + b) used for
+// testing curves not supported by the standard library.
+//
+// Not constant time -- for testing only.
+type testCurve struct {
+	*elliptic.CurveParams
+	a *big.Int
+}
+
+// newTestCurve creates a test curve from the hex encoded curve parameters.
+func newTestCurve(name string, bitSize int, p, a, b, gx, gy, n string) *testCurve {
+	h := func(s string) *big.Int {
+		i, ok := new(big.Int).SetString(s, 16)
+		if !ok {
+			panic("invalid hex " + s)
+		}
+		return i
+	}
+	return &testCurve{
+		CurveParams: &elliptic.CurveParams{
+			Name:    name,
+			BitSize: bitSize,
+			P:       h(p),
+			B:       h(b),
+			Gx:      h(gx),
+			Gy:      h(gy),
+			N:       h(n),
+		},
+		a: h(a),
+	}
+}
+
+func secp256k1() *testCurve {
+	return newTestCurve(
+		"secp256k1", 256,
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f",
+		"0",
+		"7",
+		"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+	)
+}
+
+func brainpoolP256r1() *testCurve {
+	return newTestCurve(
+		"brainpoolP256r1", 256,
+		"a9fb57dba1eea9bc3e660a909d838d726e3bf623d52620282013481d1f6e5377",
+		"7d5a0975fc2c3057eef67530417affe7fb8055c126dc5c6ce94a4b44f330b5d9",
+		"26dc5c6ce94a4b44f330b5d9bbd77cbf958416295cf7e1ce6bccdc18ff8c07b6",
+		"8bd2aeb9cb7e57cb2c4b482ffc81b7afb9de27e1e3bd23c23a4453bd9ace3262",
+		"547ef835c3dac4fd97f8461a14611dc9c27745132ded8e545c1d54c72f046997",
+		"a9fb57dba1eea9bc3e660a909d838d718c397aa3b561a6f7901e0e82974856a7",
+	)
+}
+
+func (c *testCurve) Params() *elliptic.CurveParams {
+	return c.CurveParams
+}
+
+func (c *testCurve) IsOnCurve(x, y *big.Int) bool {
+	if x.Sign() < 0 || x.Cmp(c.P) >= 0 || y.Sign() < 0 || y.Cmp(c.P) >= 0 {
+		return false
+	}
+	y2 := new(big.Int).Mul(y, y)
+	x3 := new(big.Int).Mul(x, x)
+	x3.Mul(x3, x)
+	x3.Add(x3, new(big.Int).Mul(c.a, x))
+	x3.Add(x3, c.B)
+	return y2.Sub(y2, x3).Mod(y2, c.P).Sign() == 0
+}
+
+func (c *testCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	switch {
+	case x1.Sign() == 0 && y1.Sign() == 0:
+		return new(big.Int).Set(x2), new(big.Int).Set(y2)
+	case x2.Sign() == 0 && y2.Sign() == 0:
+		return new(big.Int).Set(x1), new(big.Int).Set(y1)
+	case x1.Cmp(x2) == 0:
+		if new(big.Int).Add(y1, y2).Mod(new(big.Int).Add(y1, y2), c.P).Sign() == 0 {
+			return new(big.Int), new(big.Int)
+		}
+		return c.Double(x1, y1)
+	}
+	l := new(big.Int).Sub(y2, y1)
+	l.Mul(l, new(big.Int).ModInverse(new(big.Int).Sub(x2, x1).Mod(new(big.Int).Sub(x2, x1), c.P), c.P))
+	return c.line(l, x1, y1, x2)
+}
+
+func (c *testCurve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	if y1.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	l := new(big.Int).Mul(x1, x1)
+	l.Mul(l, big.NewInt(3))
+	l.Add(l, c.a)
+	l.Mul(l, new(big.Int).ModInverse(new(big.Int).Lsh(y1, 1), c.P))
+	return c.line(l, x1, y1, x1)
+}
+
+func (c *testCurve) line(l, x1, y1, x2 *big.Int) (*big.Int, *big.Int) {
+	l.Mod(l, c.P)
+	x3 := new(big.Int).Mul(l, l)
+	x3.Sub(x3, x1).Sub(x3, x2).Mod(x3, c.P)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, l).Sub(y3, y1).Mod(y3, c.P)
+	return x3, y3
+}
+
+func (c *testCurve) ScalarMult(x1, y1 *big.Int, k []byte) (*big.Int, *big.Int) {
+	x, y := new(big.Int), new(big.Int)
+	for _, b := range k {
+		for i := 7; i >= 0; i-- {
+			x, y = c.Double(x, y)
+			if b>>uint(i)&1 == 1 {
+				x, y = c.Add(x, y, x1, y1)
+			}
+		}
+	}
+	return x, y
+}
+
+func (c *testCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.ScalarMult(c.Gx, c.Gy, k)
+}
+
+func TestCurveByName(t *testing.T) {
+	tests := []struct {
+		name string
+		exp  elliptic.Curve
+	}{
+		{"P-224", elliptic.P224()},
+		{"secp256r1", elliptic.P256()},
+		{"prime256v1", elliptic.P256()},
+		{"P256", elliptic.P256()},
+		{"nistp384", elliptic.P384()},
+		{"SECP384R1", elliptic.P384()},
+		{"p-521", elliptic.P521()},
+		{"secp521_r1", elliptic.P521()},
+		{"1.2.840.10045.3.1.7", elliptic.P256()},
+	}
+	for i, test := range tests {
+		curve, err := CurveByName(test.name)
+		switch {
+		case err != nil:
+			t.Errorf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		case curve != test.exp:
+			t.Errorf("test %d (%s) expected %s, got: %s", i, test.name, test.exp.Params().Name, curve.Params().Name)
+		}
+	}
+	for i, name := range []string{"", "p255", "curve25519", "1.2.3.4"} {
+		if _, err := CurveByName(name); err == nil {
+			t.Errorf("test %d (%s) expected error, got nil", i, name)
+		}
+	}
+	RegisterCurveAlias("ansip256", oidP256)
+	if curve, err := CurveByName("ANSI-P256"); err != nil || curve != elliptic.P256() {
+		t.Errorf("expected P-256 for registered alias, got: %v, %v", curve, err)
+	}
+}
@@ -0,0 +1,156 @@
+package pemutil
+
+import (
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// ECH (Encrypted Client Hello) constants.
+const (
+	// ECHVersion is the ECHConfig version (draft-ietf-tls-esni-18 and RFC
+	// 9849).
+	ECHVersion uint16 = 0xfe0d
+	// ECHKEMX25519 is the DHKEM(X25519, HKDF-SHA256) HPKE KEM id.
+	ECHKEMX25519 uint16 = 0x0020
+	// ECHKDFSHA256 is the HKDF-SHA256 HPKE KDF id.
+	ECHKDFSHA256 uint16 = 0x0001
+	// ECHAEADAES128GCM is the AES-128-GCM HPKE AEAD id.
+	ECHAEADAES128GCM uint16 = 0x0001
+	// ECHAEADChaCha20Poly1305 is the ChaCha20Poly1305 HPKE AEAD id.
+	ECHAEADChaCha20Poly1305 uint16 = 0x0003
+)
+
+// ECHConfigList is a serialized ECHConfigList, as distributed to clients
+// (such as via a DNS HTTPS record), stored in a [Store] as [ECHConfig].
+type ECHConfigList []byte
+
+// ECHCipherSuite is a HPKE symmetric cipher suite.
+type ECHCipherSuite struct {
+	// KDF is the HPKE KDF id.
+	KDF uint16
+	// AEAD is the HPKE AEAD id.
+	AEAD uint16
+}
+
+// ECHConfigContents are the parsed contents of an ECHConfig.
+type ECHConfigContents struct {
+	// Raw is the serialized ECHConfig.
+	Raw []byte
+	// ConfigID is the config id.
+	ConfigID uint8
+	// KEM is the HPKE KEM id.
+	KEM uint16
+	// PublicKey is the serialized HPKE public key.
+	PublicKey []byte
+	// CipherSuites are the supported HPKE cipher suites.
+	CipherSuites []ECHCipherSuite
+	// MaxNameLength is the maximum name length.
+	MaxNameLength uint8
+	// PublicName is the client-facing server's name.
+	PublicName string
+}
+
+// GenerateECHKeySet generates a X25519 private key and an ECHConfigList for
+// the client-facing server's public name, returning them as a [Store]. The
+// ECHConfigList is PEM-encoded as an "ECHCONFIG" block, following the ECH PEM
+// file format used by OpenSSL.
+func GenerateECHKeySet(publicName string, configID uint8) (Store, error) {
+	if len(publicName) == 0 || len(publicName) > 255 {
+		return nil, fmt.Errorf("invalid ECH public name %q", publicName)
+	}
+	key, err := ecdh.X25519().GenerateKey(Rand())
+	if err != nil {
+		return nil, err
+	}
+	var b cryptobyte.Builder
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16(ECHVersion)
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint8(configID)
+			b.AddUint16(ECHKEMX25519)
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddBytes(key.PublicKey().Bytes())
+			})
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				for _, aead := range []uint16{ECHAEADAES128GCM, ECHAEADChaCha20Poly1305} {
+					b.AddUint16(ECHKDFSHA256)
+					b.AddUint16(aead)
+				}
+			})
+			// maximum_name_length
+			b.AddUint8(0)
+			b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddBytes([]byte(publicName))
+			})
+			// extensions
+			b.AddUint16(0)
+		})
+	})
+	buf, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return Store{
+		PrivateKey: key,
+		PublicKey:  key.PublicKey(),
+		ECHConfig:  ECHConfigList(buf),
+	}, nil
+}
+
+// ECHConfigList returns the ECHConfigList in the [Store].
+func (s Store) ECHConfigList() (ECHConfigList, bool) {
+	v, ok := s[ECHConfig].(ECHConfigList)
+	return v, ok
+}
+
+// Configs parses the ECHConfigList, returning the contents of the configs
+// with a supported version (see [ECHVersion]).
+func (l ECHConfigList) Configs() ([]ECHConfigContents, error) {
+	s := cryptobyte.String(l)
+	var list cryptobyte.String
+	if !s.ReadUint16LengthPrefixed(&list) || !s.Empty() || list.Empty() {
+		return nil, errors.New("invalid ECHConfigList")
+	}
+	var configs []ECHConfigContents
+	for !list.Empty() {
+		raw := []byte(list)
+		var version uint16
+		var contents cryptobyte.String
+		if !list.ReadUint16(&version) || !list.ReadUint16LengthPrefixed(&contents) {
+			return nil, errors.New("invalid ECHConfig")
+		}
+		if version != ECHVersion {
+			continue
+		}
+		c := ECHConfigContents{
+			Raw: raw[:len(raw)-len(list)],
+		}
+		var pub, suites, name, exts cryptobyte.String
+		if !contents.ReadUint8(&c.ConfigID) ||
+			!contents.ReadUint16(&c.KEM) ||
+			!contents.ReadUint16LengthPrefixed(&pub) ||
+			!contents.ReadUint16LengthPrefixed(&suites) ||
+			!contents.ReadUint8(&c.MaxNameLength) ||
+			!contents.ReadUint8LengthPrefixed(&name) ||
+			!contents.ReadUint16LengthPrefixed(&exts) ||
+			!contents.Empty() {
+			return nil, errors.New("invalid ECHConfig contents")
+		}
+		for !suites.Empty() {
+			var cs ECHCipherSuite
+			if !suites.ReadUint16(&cs.KDF) || !suites.ReadUint16(&cs.AEAD) {
+				return nil, errors.New("invalid ECHConfig cipher suites")
+			}
+			c.CipherSuites = append(c.CipherSuites, cs)
+		}
+		c.PublicKey, c.PublicName = []byte(pub), string(name)
+		configs = append(configs, c)
+	}
+	if len(configs) == 0 {
+		return nil, errors.New("ECHConfigList does not contain a supported ECHConfig")
+	}
+	return configs, nil
+}
@@ -0,0 +1,72 @@
+//go:build go1.24
+
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/tls"
+	"errors"
+)
+
+// ECHKeys returns the ECH keys for the ECHConfigList and X25519 private key
+// in the [Store], for use as [tls.Config.EncryptedClientHelloKeys]. Only the
+// configs matching the private key are returned, and are marked to be sent
+// as retry configs.
+func (s Store) ECHKeys() ([]tls.EncryptedClientHelloKey, error) {
+	list, ok := s.ECHConfigList()
+	if !ok {
+		return nil, errors.New("store does not contain an ECHConfigList")
+	}
+	key, ok := s[PrivateKey].(*ecdh.PrivateKey)
+	if !ok || key.Curve() != ecdh.X25519() {
+		return nil, errors.New("store does not contain a X25519 private key")
+	}
+	configs, err := list.Configs()
+	if err != nil {
+		return nil, err
+	}
+	var keys []tls.EncryptedClientHelloKey
+	for _, c := range configs {
+		if c.KEM == ECHKEMX25519 && bytes.Equal(c.PublicKey, key.PublicKey().Bytes()) {
+			keys = append(keys, tls.EncryptedClientHelloKey{
+				Config:      c.Raw,
+				PrivateKey:  key.Bytes(),
+				SendAsRetry: true,
+			})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("ECHConfigList does not contain a config for the private key")
+	}
+	return keys, nil
+}
+
+// ConfigureECH configures the TLS server config to accept Encrypted Client
+// Hello using the ECH keys in the [Store] (see [Store.ECHKeys]). TLS 1.3 is
+// required when using ECH.
+func (s Store) ConfigureECH(cfg *tls.Config) error {
+	keys, err := s.ECHKeys()
+	if err != nil {
+		return err
+	}
+	cfg.EncryptedClientHelloKeys = keys
+	if cfg.MinVersion != 0 && cfg.MinVersion < tls.VersionTLS13 {
+		cfg.MinVersion = tls.VersionTLS13
+	}
+	return nil
+}
+
+// ConfigureECHClient configures the TLS client config to use Encrypted Client
+// Hello with the ECHConfigList in the [Store].
+func (s Store) ConfigureECHClient(cfg *tls.Config) error {
+	list, ok := s.ECHConfigList()
+	if !ok {
+		return errors.New("store does not contain an ECHConfigList")
+	}
+	cfg.EncryptedClientHelloConfigList = list
+	if cfg.MinVersion != 0 && cfg.MinVersion < tls.VersionTLS13 {
+		cfg.MinVersion = tls.VersionTLS13
+	}
+	return nil
+}
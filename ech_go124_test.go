@@ -0,0 +1,39 @@
+//go:build go1.24
+
+package pemutil
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestConfigureECH(t *testing.T) {
+	s, err := GenerateECHKeySet("public.example.com", 1)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	keys, err := s.ECHKeys()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(keys) != 1 || !keys[0].SendAsRetry || len(keys[0].PrivateKey) != 32 {
+		t.Fatalf("expected 1 retry key with 32 byte private key, got: %v", keys)
+	}
+	server := &tls.Config{MinVersion: tls.VersionTLS12}
+	if err := s.ConfigureECH(server); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if server.MinVersion != tls.VersionTLS13 {
+		t.Errorf("expected min version TLS 1.3, got: %#x", server.MinVersion)
+	}
+	client := new(tls.Config)
+	if err := s.ConfigureECHClient(client); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(client.EncryptedClientHelloConfigList) == 0 {
+		t.Errorf("expected ECH config list")
+	}
+	if _, err := (Store{}).ECHKeys(); err == nil {
+		t.Errorf("expected error for empty store")
+	}
+}
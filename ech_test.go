@@ -0,0 +1,74 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"testing"
+)
+
+func TestGenerateECHKeySet(t *testing.T) {
+	s, err := GenerateECHKeySet("public.example.com", 7)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.Contains(buf, []byte("-----BEGIN ECHCONFIG-----")) {
+		t.Errorf("expected ECHCONFIG block, got:\n%s", buf)
+	}
+	z, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	list, ok := z.ECHConfigList()
+	if !ok {
+		t.Fatalf("expected ECHConfigList")
+	}
+	configs, err := list.Configs()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(configs) != 1 {
+		t.Fatalf("expected 1 config, got: %d", len(configs))
+	}
+	c := configs[0]
+	if c.ConfigID != 7 {
+		t.Errorf("expected config id 7, got: %d", c.ConfigID)
+	}
+	if c.KEM != ECHKEMX25519 {
+		t.Errorf("expected kem %#x, got: %#x", ECHKEMX25519, c.KEM)
+	}
+	if c.PublicName != "public.example.com" {
+		t.Errorf("expected public name public.example.com, got: %q", c.PublicName)
+	}
+	if len(c.CipherSuites) != 2 {
+		t.Errorf("expected 2 cipher suites, got: %d", len(c.CipherSuites))
+	}
+	key, ok := z[PrivateKey].(*ecdh.PrivateKey)
+	if !ok {
+		t.Fatalf("expected *ecdh.PrivateKey, got: %T", z[PrivateKey])
+	}
+	if !bytes.Equal(c.PublicKey, key.PublicKey().Bytes()) {
+		t.Errorf("expected config public key to match private key")
+	}
+}
+
+func TestECHConfigListInvalid(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{0x00},
+		{0x00, 0x00},
+		{0x00, 0x04, 0xfe, 0x0d, 0x00, 0x01},
+		{0x00, 0x04, 0x12, 0x34, 0x00, 0x00},
+	}
+	for i, test := range tests {
+		if _, err := ECHConfigList(test).Configs(); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+	}
+	if _, err := GenerateECHKeySet("", 0); err == nil {
+		t.Errorf("expected error for empty public name")
+	}
+}
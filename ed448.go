@@ -0,0 +1,94 @@
+package pemutil
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// oidPublicKeyEd448 is the id-Ed448 object identifier.
+var oidPublicKeyEd448 = asn1.ObjectIdentifier{1, 3, 101, 113}
+
+// GenerateEd448KeySet generates a Ed448 private and public key crypto
+// primitives, returning them as a [Store].
+func GenerateEd448KeySet() (Store, error) {
+	pub, key, err := ed448.GenerateKey(Rand())
+	if err != nil {
+		return nil, err
+	}
+	return Store{
+		PrivateKey: key,
+		PublicKey:  pub,
+	}, nil
+}
+
+// MarshalEd448PrivateKey encodes the Ed448 private key in PKCS8 form.
+func MarshalEd448PrivateKey(key ed448.PrivateKey) ([]byte, error) {
+	if len(key) != ed448.PrivateKeySize {
+		return nil, errors.New("invalid Ed448 private key length")
+	}
+	seed, err := asn1.Marshal(key.Seed())
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs8{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm: oidPublicKeyEd448,
+		},
+		PrivateKey: seed,
+	})
+}
+
+// MarshalEd448PublicKey encodes the Ed448 public key in PKIX form.
+func MarshalEd448PublicKey(pub ed448.PublicKey) ([]byte, error) {
+	if len(pub) != ed448.PublicKeySize {
+		return nil, errors.New("invalid Ed448 public key length")
+	}
+	return asn1.Marshal(pkixPublicKey{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm: oidPublicKeyEd448,
+		},
+		BitString: asn1.BitString{
+			Bytes:     pub,
+			BitLength: 8 * len(pub),
+		},
+	})
+}
+
+// ParseEd448PrivateKey parses a PKCS8 encoded Ed448 private key.
+func ParseEd448PrivateKey(buf []byte) (ed448.PrivateKey, error) {
+	var p pkcs8
+	if _, err := asn1.Unmarshal(buf, &p); err != nil {
+		return nil, err
+	}
+	if !p.Algo.Algorithm.Equal(oidPublicKeyEd448) {
+		return nil, errors.New("not an Ed448 private key")
+	}
+	var seed []byte
+	if _, err := asn1.Unmarshal(p.PrivateKey, &seed); err != nil {
+		return nil, err
+	}
+	if len(seed) != ed448.SeedSize {
+		return nil, errors.New("invalid Ed448 private key length")
+	}
+	return ed448.NewKeyFromSeed(seed), nil
+}
+
+// ParseEd448PublicKey parses a PKIX encoded Ed448 public key.
+func ParseEd448PublicKey(buf []byte) (ed448.PublicKey, error) {
+	var pub pkixPublicKey
+	if rest, err := asn1.Unmarshal(buf, &pub); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("trailing data after Ed448 public key")
+	}
+	if !pub.Algo.Algorithm.Equal(oidPublicKeyEd448) {
+		return nil, errors.New("not an Ed448 public key")
+	}
+	if pub.BitString.BitLength != 8*ed448.PublicKeySize {
+		return nil, errors.New("invalid Ed448 public key length")
+	}
+	return ed448.PublicKey(pub.BitString.Bytes), nil
+}
@@ -0,0 +1,55 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/rand"
+	"testing"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+func TestEd448(t *testing.T) {
+	loaded, err := LoadFile("testdata/ed448.key")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	generated, err := GenerateEd448KeySet()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, s := range []Store{loaded, generated} {
+		key, ok := s.Ed448PrivateKey()
+		if !ok {
+			t.Fatalf("test %d expected Ed448 private key", i)
+		}
+		pub, ok := s.Ed448PublicKey()
+		if !ok || !pub.Equal(key.Public()) {
+			t.Fatalf("test %d expected matching Ed448 public key", i)
+		}
+		// round trip
+		buf, err := s.Bytes()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		s0, err := DecodeBytes(buf)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if key0, ok := s0.Ed448PrivateKey(); !ok || !key0.Equal(key) {
+			t.Errorf("test %d expected decoded private key to equal private key", i)
+		}
+		// sign
+		signer, ok := s0.Signer()
+		if !ok {
+			t.Fatalf("test %d expected signer", i)
+		}
+		msg := []byte("pemutil")
+		sig, err := signer.Sign(rand.Reader, msg, crypto.Hash(0))
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !ed448.Verify(pub, msg, sig, "") {
+			t.Errorf("test %d expected signature to verify", i)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package pemutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EncodeOption is a PEM encode option.
+type EncodeOption func(*encodeOptions)
+
+// encodeOptions are PEM encode options.
+type encodeOptions struct {
+	width      int
+	crlf       bool
+	noTrailing bool
+	java       bool
+	text       explanatoryText
+}
+
+// WithLineWidth is a encode option to set the base64 line width. Must be a
+// positive multiple of 4. Defaults to 64, as required by RFC 7468. Some
+// consumers expect the MIME line width of 76.
+func WithLineWidth(width int) EncodeOption {
+	return func(opts *encodeOptions) {
+		opts.width = width
+	}
+}
+
+// WithCRLF is a encode option to end lines with CRLF ("\r\n") instead of LF
+// ("\n"), as expected by some Windows tooling.
+func WithCRLF() EncodeOption {
+	return func(opts *encodeOptions) {
+		opts.crlf = true
+	}
+}
+
+// WithoutTrailingNewline is a encode option to omit the line ending after the
+// last END line.
+func WithoutTrailingNewline() EncodeOption {
+	return func(opts *encodeOptions) {
+		opts.noTrailing = true
+	}
+}
+
+// newEncodeOptions builds the encode options.
+func newEncodeOptions(opts ...EncodeOption) (encodeOptions, error) {
+	o := encodeOptions{
+		width: 64,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.width <= 0 || o.width%4 != 0 {
+		return encodeOptions{}, fmt.Errorf("invalid line width %d", o.width)
+	}
+	if o.java && (o.width != 64 || o.crlf) {
+		return encodeOptions{}, errors.New("Java compatibility requires 64 character lines ending with LF")
+	}
+	return o, nil
+}
+
+// encodeBlocks PEM-encodes the blocks to buf using the encode options.
+func (o encodeOptions) encodeBlocks(buf *bytes.Buffer, blocks []*pem.Block) error {
+	if o.width == 64 && !o.crlf && !o.noTrailing && len(o.text) == 0 {
+		return encodeBlocks(buf, blocks)
+	}
+	eol := "\n"
+	if o.crlf {
+		eol = "\r\n"
+	}
+	for i, block := range blocks {
+		if text := o.text.text(block); len(text) != 0 {
+			if err := checkText(text); err != nil {
+				return err
+			}
+			buf.Write(bytes.ReplaceAll(text, []byte("\n"), []byte(eol)))
+		}
+		if err := o.encodeBlock(buf, block, eol); err != nil {
+			return err
+		}
+		if i != len(blocks)-1 || !o.noTrailing {
+			buf.WriteString(eol)
+		}
+	}
+	return nil
+}
+
+// encodeBlock PEM-encodes the block to buf using the encode options, without
+// the line ending following the END line.
+func (o encodeOptions) encodeBlock(buf *bytes.Buffer, block *pem.Block, eol string) error {
+	// same header ordering as pem.Encode
+	var keys []string
+	for k, v := range block.Headers {
+		if k == "" || strings.ContainsAny(k, ":\r\n") || strings.ContainsAny(v, "\r\n") {
+			return errors.New("invalid PEM header")
+		}
+		if k != "Proc-Type" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := block.Headers["Proc-Type"]; ok {
+		keys = append([]string{"Proc-Type"}, keys...)
+	}
+	buf.WriteString("-----BEGIN " + block.Type + "-----" + eol)
+	for _, k := range keys {
+		buf.WriteString(k + ": " + block.Headers[k] + eol)
+	}
+	if len(keys) != 0 {
+		buf.WriteString(eol)
+	}
+	b64 := base64.StdEncoding.EncodeToString(block.Bytes)
+	for len(b64) > 0 {
+		n := min(o.width, len(b64))
+		buf.WriteString(b64[:n] + eol)
+		b64 = b64[n:]
+	}
+	buf.WriteString("-----END " + block.Type + "-----")
+	return nil
+}
+
+// Rearmor re-encodes the PEM blocks in buf using the encode options, such as
+// to change the line width or line endings expected by a consumer. Block
+// types, headers, and data are preserved. Any data outside of the PEM
+// blocks is discarded.
+//
+// When using [WithJavaCompat], the output is checked with [CheckJavaCompat].
+func Rearmor(buf []byte, opts ...EncodeOption) ([]byte, error) {
+	o, err := newEncodeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []*pem.Block
+	for {
+		var block *pem.Block
+		if block, buf = pem.Decode(buf); block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("invalid PEM data")
+	}
+	var out bytes.Buffer
+	out.Grow(blocksLen(blocks))
+	if err := o.encodeBlocks(&out, blocks); err != nil {
+		return nil, err
+	}
+	if o.java {
+		if err := CheckJavaCompat(out.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
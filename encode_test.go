@@ -0,0 +1,69 @@
+package pemutil
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEncodeOptions(t *testing.T) {
+	buf, err := os.ReadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// crlf, with the default width
+	crlf, err := s.Encode(WithCRLF())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.Equal(bytes.ReplaceAll(crlf, []byte("\r\n"), []byte("\n")), exp) {
+		t.Errorf("expected crlf encoding to match, got:\n%s", crlf)
+	}
+	// width and trailing newline
+	z, err := s.Encode(WithLineWidth(76), WithoutTrailingNewline())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	lines := strings.Split(string(z), "\n")
+	if n := len(lines[1]); n != 76 {
+		t.Errorf("expected line width 76, got: %d", n)
+	}
+	if !strings.HasSuffix(string(z), "-----END CERTIFICATE-----") {
+		t.Errorf("expected no trailing newline")
+	}
+	// round trip
+	for i, b := range [][]byte{crlf, z} {
+		if _, err := DecodeBytes(b); err != nil {
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		}
+		r, err := Rearmor(b)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !bytes.Equal(r, exp) {
+			t.Errorf("test %d expected rearmored data to match, got:\n%s", i, r)
+		}
+	}
+	// headers
+	b, err := EncodeBlock("TEST", map[string]string{"b": "2", "Proc-Type": "4,ENCRYPTED", "a": "1"}, []byte("test"), WithCRLF())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if str := "-----BEGIN TEST-----\r\nProc-Type: 4,ENCRYPTED\r\na: 1\r\nb: 2\r\n\r\ndGVzdA==\r\n-----END TEST-----\r\n"; string(b) != str {
+		t.Errorf("expected %q, got: %q", str, b)
+	}
+	for i, width := range []int{0, -4, 63} {
+		if _, err := s.Encode(WithLineWidth(width)); err == nil {
+			t.Errorf("test %d expected error, got nil", i)
+		}
+	}
+}
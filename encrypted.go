@@ -0,0 +1,288 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	// oidPBES2 is the PKCS#5 PBES2 object identifier.
+	oidPBES2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+
+	// oidPBKDF2 is the PKCS#5 PBKDF2 object identifier.
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	// oidHMACWithSHA1 is the HMAC-SHA1 object identifier.
+	oidHMACWithSHA1 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+
+	// oidHMACWithSHA256 is the HMAC-SHA256 object identifier.
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+
+	// oidAES128CBC is the AES-128-CBC object identifier.
+	oidAES128CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+
+	// oidAES192CBC is the AES-192-CBC object identifier.
+	oidAES192CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+
+	// oidAES256CBC is the AES-256-CBC object identifier.
+	oidAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// pbkdf2Iterations is the PBKDF2 iteration count used when encrypting private
+// keys.
+const pbkdf2Iterations = 100000
+
+// maxPBKDF2Iterations is the maximum PBKDF2 iteration count accepted when
+// decrypting private keys, to prevent crafted data from using excessive time.
+const maxPBKDF2Iterations = 10000000
+
+// encryptedPKCS8 is the PKCS#8 encrypted private key info structure.
+type encryptedPKCS8 struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params are the PBES2 parameters.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params are the PBKDF2 parameters.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// EncryptPKCS8PrivateKey encodes the private key in PKCS8 form, encrypted with
+// the password using PBES2 (PBKDF2 with HMAC-SHA256, and AES-256-CBC), as used
+// by the "ENCRYPTED PRIVATE KEY" block type.
+func EncryptPKCS8PrivateKey(key interface{}, password []byte) ([]byte, error) {
+	der, err := marshalPKCS8(key)
+	if err != nil {
+		return nil, err
+	}
+	salt, iv := make([]byte, 16), make([]byte, aes.BlockSize)
+	if err := readRand(Rand(), salt); err != nil {
+		return nil, err
+	}
+	if err := readRand(Rand(), iv); err != nil {
+		return nil, err
+	}
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pbkdf2Iterations,
+		PRF: pkix.AlgorithmIdentifier{
+			Algorithm:  oidHMACWithSHA256,
+			Parameters: asn1.NullRawValue,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBKDF2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  oidAES256CBC,
+			Parameters: asn1.RawValue{FullBytes: ivParam},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(pbkdf2.Key(password, salt, pbkdf2Iterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	// pkcs7 pad
+	n := aes.BlockSize - len(der)%aes.BlockSize
+	data := append(der, bytes.Repeat([]byte{byte(n)}, n)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+	return asn1.Marshal(encryptedPKCS8{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBES2,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedData: data,
+	})
+}
+
+// DecryptPKCS8PrivateKey decrypts and parses a password encrypted PKCS8
+// private key. Supports PBES2 encrypted keys using PBKDF2 (with HMAC-SHA1 or
+// HMAC-SHA256) and AES-CBC, as generated by [EncryptPKCS8PrivateKey] and
+// OpenSSL. Keys using more than 10,000,000 PBKDF2 iterations are rejected.
+func DecryptPKCS8PrivateKey(buf, password []byte) (interface{}, error) {
+	der, err := decryptPKCS8(buf, password)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePKCSPrivateKey(der)
+}
+
+// decryptPKCS8 decrypts a password encrypted PKCS8 private key, returning the
+// PKCS8 encoded private key.
+func decryptPKCS8(buf, password []byte) ([]byte, error) {
+	if der, err := BERToDER(buf); err == nil {
+		buf = der
+	}
+	var epk encryptedPKCS8
+	if _, err := asn1.Unmarshal(buf, &epk); err != nil {
+		return nil, err
+	}
+	if !epk.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported encryption algorithm %v", epk.Algo.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(epk.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation func %v", params.KeyDerivationFunc.Algorithm)
+	}
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, err
+	}
+	if kdfParams.IterationCount <= 0 || kdfParams.IterationCount > maxPBKDF2Iterations {
+		return nil, fmt.Errorf("invalid PBKDF2 iteration count %d", kdfParams.IterationCount)
+	}
+	var h func() hash.Hash
+	switch prf := kdfParams.PRF.Algorithm; {
+	case len(prf) == 0, prf.Equal(oidHMACWithSHA1):
+		h = sha1.New
+	case prf.Equal(oidHMACWithSHA256):
+		h = sha256.New
+	default:
+		return nil, fmt.Errorf("unsupported PBKDF2 PRF %v", prf)
+	}
+	var keyLen int
+	switch alg := params.EncryptionScheme.Algorithm; {
+	case alg.Equal(oidAES128CBC):
+		keyLen = 16
+	case alg.Equal(oidAES192CBC):
+		keyLen = 24
+	case alg.Equal(oidAES256CBC):
+		keyLen = 32
+	default:
+		return nil, fmt.Errorf("unsupported encryption scheme %v", alg)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize || len(epk.EncryptedData) == 0 || len(epk.EncryptedData)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid encrypted private key")
+	}
+	block, err := aes.NewCipher(pbkdf2.Key(password, kdfParams.Salt, kdfParams.IterationCount, keyLen, h))
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, len(epk.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(data, epk.EncryptedData)
+	// pkcs7 unpad
+	n := int(data[len(data)-1])
+	if n == 0 || n > aes.BlockSize || !hmac.Equal(data[len(data)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, errors.New("invalid password")
+	}
+	der := data[:len(data)-n]
+	if _, err := ParsePKCSPrivateKey(der); err != nil {
+		return nil, errors.New("invalid password")
+	}
+	return der, nil
+}
+
+// marshalPKCS8 encodes the private key in PKCS8 form. Supports EC private keys
+// for curves added via [RegisterCurve], and Ed448 private keys.
+func marshalPKCS8(key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte, *SymmetricKey:
+		return nil, errors.New("raw keys cannot be encoded in PKCS8 form")
+	case NonExportable:
+		return nil, errors.New("private key is not exportable")
+	case ed448.PrivateKey:
+		return MarshalEd448PrivateKey(k)
+	case *ecdsa.PrivateKey:
+		if nc, ok := curveByCurve(k.Curve); ok && !nc.std {
+			der, err := MarshalECPrivateKey(k)
+			if err != nil {
+				return nil, err
+			}
+			params, err := oidRawValue(nc.oid)
+			if err != nil {
+				return nil, err
+			}
+			return asn1.Marshal(pkcs8{
+				Algo: pkix.AlgorithmIdentifier{
+					Algorithm:  oidPublicKeyECDSA,
+					Parameters: params,
+				},
+				PrivateKey: der,
+			})
+		}
+	}
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// EncryptedBytes returns all crypto primitives in the [Store] as a single
+// byte slice containing the PEM-encoded versions of the crypto primitives
+// (see [Store.Bytes]), with the private key encrypted with the password as a
+// "ENCRYPTED PRIVATE KEY" block.
+func (s Store) EncryptedBytes(password []byte) ([]byte, error) {
+	key, ok := s.PrivateKey()
+	if !ok {
+		return nil, errors.New("store does not contain a private key")
+	}
+	if _, ok := key.(NonExportable); ok {
+		return nil, errors.New("private key is not exportable")
+	}
+	der, err := EncryptPKCS8PrivateKey(key, password)
+	if err != nil {
+		return nil, err
+	}
+	block := &pem.Block{
+		Type:  EncryptedPrivateKey.String(),
+		Bytes: der,
+	}
+	text := s.encodeText()
+	if err := checkText(text.text(block)); err != nil {
+		return nil, err
+	}
+	buf := append(append([]byte(nil), text.text(block)...), pem.EncodeToMemory(block)...)
+	z := make(Store, len(s))
+	for k, v := range s {
+		z[k] = v
+	}
+	z.Remove(PrivateKey, RSAPrivateKey, ECPrivateKey, KeyAttributes, KeyPurposes)
+	if len(z.Only(encOrder...)) == 0 {
+		return buf, nil
+	}
+	rest, err := z.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, rest...), nil
+}
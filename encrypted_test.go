@@ -0,0 +1,138 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/pem"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEncryptPKCS8PrivateKey(t *testing.T) {
+	password := []byte("secret")
+	for i, gen := range []func() (Store, error){
+		func() (Store, error) { return GenerateRSAKeySet(2048) },
+		func() (Store, error) { return GenerateECKeySet(elliptic.P256()) },
+		GenerateEd448KeySet,
+		func() (Store, error) {
+			curve := secp256k1()
+			RegisterCurve(OIDSecp256k1, curve)
+			return GenerateECKeySet(curve)
+		},
+	} {
+		s, err := gen()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		buf, err := s.EncryptedBytes(password)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		block, rest := pem.Decode(buf)
+		if block == nil || block.Type != EncryptedPrivateKey.String() {
+			t.Fatalf("test %d expected encrypted private key block", i)
+		}
+		pub, err := DecodeBytes(rest)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if len(pub) != 1 || pub[PublicKey] == nil {
+			t.Errorf("test %d expected only public key, got: %v", i, keys(pub))
+		}
+		if _, err := DecryptPKCS8PrivateKey(block.Bytes, []byte("wrong")); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+		key, err := DecryptPKCS8PrivateKey(block.Bytes, password)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		signer, _ := s.Signer()
+		if !key.(crypto.Signer).Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(signer.Public()) {
+			t.Errorf("test %d expected decrypted key to match", i)
+		}
+	}
+}
+
+func TestEncryptPKCS8PrivateKeyUnsupported(t *testing.T) {
+	s, err := GenerateSymmetricKeySet(32)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.EncryptedBytes([]byte("secret")); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, err := EncryptPKCS8PrivateKey(&SymmetricKey{Key: []byte("key")}, []byte("secret")); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestDecryptPKCS8PrivateKeyOpenSSL(t *testing.T) {
+	buf, err := os.ReadFile("testdata/ec-encrypted.key")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		t.Fatalf("expected PEM block")
+	}
+	key, err := DecryptPKCS8PrivateKey(block.Bytes, []byte("secret"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := key.(*ecdsa.PrivateKey); !ok {
+		t.Errorf("expected *ecdsa.PrivateKey, got: %T", key)
+	}
+}
+
+func TestDecryptPKCS8PrivateKeyIterations(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	der, err := EncryptPKCS8PrivateKey(s[ECPrivateKey], []byte("secret"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, n := range []int{0, -1, maxPBKDF2Iterations + 1} {
+		buf := testPBKDF2Iterations(t, der, n)
+		if _, err := DecryptPKCS8PrivateKey(buf, []byte("secret")); err == nil || !strings.Contains(err.Error(), "iteration count") {
+			t.Errorf("test %d expected iteration count error, got: %v", i, err)
+		}
+	}
+	if _, err := DecryptPKCS8PrivateKey(testPBKDF2Iterations(t, der, pbkdf2Iterations), []byte("secret")); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+// testPBKDF2Iterations rewrites the PBKDF2 iteration count of the encrypted
+// PKCS8 private key.
+func testPBKDF2Iterations(t *testing.T, der []byte, n int) []byte {
+	t.Helper()
+	var epk encryptedPKCS8
+	if _, err := asn1.Unmarshal(der, &epk); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(epk.Algo.Parameters.FullBytes, &params); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	kdfParams.IterationCount = n
+	var err error
+	if params.KeyDerivationFunc.Parameters.FullBytes, err = asn1.Marshal(kdfParams); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if epk.Algo.Parameters.FullBytes, err = asn1.Marshal(params); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if der, err = asn1.Marshal(epk); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return der
+}
@@ -0,0 +1,71 @@
+package pemutil
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DecodeError is a decode error, with the position in the PEM-encoded data
+// where the error occurred.
+type DecodeError struct {
+	// Offset is the byte offset.
+	Offset int
+	// Line is the 1-based line number.
+	Line int
+	// Label is the nearest BEGIN label at or preceding the offset, if any.
+	Label string
+	// Length is the length of the skipped region, when salvaging (see
+	// [WithSalvage]).
+	Length int
+	// Err is the underlying error.
+	Err error
+}
+
+// newDecodeError creates a decode error for the offset in buf.
+func newDecodeError(buf []byte, offset int, err error) *DecodeError {
+	const pre = "-----BEGIN "
+	de := &DecodeError{
+		Offset: offset,
+		Line:   bytes.Count(buf[:offset], []byte("\n")) + 1,
+		Err:    err,
+	}
+	end := offset + len(pre)
+	if end > len(buf) {
+		end = len(buf)
+	}
+	if i := bytes.LastIndex(buf[:end], []byte(pre)); i != -1 {
+		label := buf[i+len(pre):]
+		if j := bytes.IndexByte(label, '\n'); j != -1 {
+			label = label[:j]
+		}
+		if j := bytes.Index(label, []byte("-----")); j != -1 {
+			label = label[:j]
+		}
+		de.Label = string(bytes.TrimSpace(label))
+	}
+	return de
+}
+
+// Error satisfies the error interface.
+func (err *DecodeError) Error() string {
+	if err.Label != "" {
+		return fmt.Sprintf("line %d (offset %d, near %s): %v", err.Line, err.Offset, err.Label, err.Err)
+	}
+	return fmt.Sprintf("line %d (offset %d): %v", err.Line, err.Offset, err.Err)
+}
+
+// Unwrap satisfies the [errors.Unwrap] interface.
+func (err *DecodeError) Unwrap() error {
+	return err.Err
+}
+
+// blockOffset returns the offset of the last BEGIN line of the block of type
+// typ in buf, or of the first non-whitespace character in buf.
+func blockOffset(buf []byte, typ string) int {
+	if typ != "" {
+		if i := bytes.LastIndex(buf, []byte("-----BEGIN "+typ+"-----")); i != -1 {
+			return i
+		}
+	}
+	return len(buf) - len(bytes.TrimLeft(buf, " \t\r\n"))
+}
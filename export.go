@@ -0,0 +1,202 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// Export algorithms.
+const (
+	// exportRSA is the RSA-OAEP (SHA-256) key wrapping, with AES-256-GCM
+	// content encryption.
+	exportRSA = "RSA-OAEP-256+A256GCM"
+	// exportECDH is the ephemeral-static ECDH key agreement (HKDF-SHA256),
+	// with AES-256-GCM content encryption.
+	exportECDH = "ECDH-ES+A256GCM"
+)
+
+// AlgorithmHeader is the PEM header used to record the algorithm of an
+// "ENCRYPTED KEYSET" block.
+const AlgorithmHeader = "Algorithm"
+
+// Export exports all crypto primitives in the [Store] encrypted to the
+// recipient's public key (hybrid encryption), as a PEM-encoded "ENCRYPTED
+// KEYSET" block, for use in escrow or backups. Use [Import] with the
+// recipient's private key to import the exported data.
+//
+// The recipient's public key must be a RSA or a NIST curve ECDSA public key
+// (or any value accepted by [CanonicalPublicKey]).
+func (s Store) Export(recipient interface{}) ([]byte, error) {
+	pub, err := CanonicalPublicKey(recipient)
+	if err != nil {
+		return nil, err
+	}
+	fp, err := KeyFingerprint(pub)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	// wrap content encryption key
+	var alg string
+	var wrapped, cek []byte
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		alg, cek = exportRSA, make([]byte, 32)
+		if err := readRand(Rand(), cek); err != nil {
+			return nil, err
+		}
+		if wrapped, err = rsa.EncryptOAEP(sha256.New(), Rand(), k, cek, []byte(alg)); err != nil {
+			return nil, err
+		}
+	case *ecdsa.PublicKey:
+		remote, err := k.ECDH()
+		if err != nil {
+			return nil, err
+		}
+		eph, err := remote.Curve().GenerateKey(Rand())
+		if err != nil {
+			return nil, err
+		}
+		alg, wrapped = exportECDH, eph.PublicKey().Bytes()
+		if cek, err = exportECDHKey(eph, remote, wrapped); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported recipient public key type %T", pub)
+	}
+	// encrypt
+	headers := map[string]string{
+		AlgorithmHeader:      alg,
+		KeyFingerprintHeader: hex.EncodeToString(fp[:]),
+	}
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if err := readRand(Rand(), nonce); err != nil {
+		return nil, err
+	}
+	buf := binary.BigEndian.AppendUint16(nil, uint16(len(wrapped)))
+	buf = append(append(buf, wrapped...), nonce...)
+	buf = gcm.Seal(buf, nonce, plaintext, exportAAD(headers))
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    EncryptedKeyset.String(),
+		Headers: headers,
+		Bytes:   buf,
+	}), nil
+}
+
+// Import imports the crypto primitives from data exported with
+// [Store.Export], decrypting it with the private key in the recipient
+// [Store].
+func Import(recipient Store, data []byte, opts ...LoadOption) (Store, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != EncryptedKeyset.String() {
+		return nil, errors.New("missing encrypted keyset block")
+	}
+	key, ok := recipient.Signer()
+	if !ok {
+		return nil, errors.New("store does not contain a private key")
+	}
+	fp, err := KeyFingerprint(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	if block.Headers[KeyFingerprintHeader] != hex.EncodeToString(fp[:]) {
+		return nil, errors.New("encrypted keyset was not exported for the private key")
+	}
+	buf := block.Bytes
+	if len(buf) < 2 || len(buf) < 2+int(binary.BigEndian.Uint16(buf)) {
+		return nil, errors.New("invalid encrypted keyset")
+	}
+	n := int(binary.BigEndian.Uint16(buf))
+	wrapped, buf := buf[2:2+n], buf[2+n:]
+	// unwrap content encryption key
+	var cek []byte
+	switch alg := block.Headers[AlgorithmHeader]; alg {
+	case exportRSA:
+		k, ok := unwrapKey(key).(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("encrypted keyset requires a RSA private key")
+		}
+		if cek, err = rsa.DecryptOAEP(sha256.New(), nil, k, wrapped, []byte(alg)); err != nil {
+			return nil, err
+		}
+	case exportECDH:
+		k, ok := unwrapKey(key).(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("encrypted keyset requires a ECDSA private key")
+		}
+		priv, err := k.ECDH()
+		if err != nil {
+			return nil, err
+		}
+		eph, err := priv.Curve().NewPublicKey(wrapped)
+		if err != nil {
+			return nil, err
+		}
+		if cek, err = exportECDHKey(priv, eph, wrapped); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encrypted keyset algorithm %q", alg)
+	}
+	// decrypt
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted keyset")
+	}
+	plaintext, err := gcm.Open(nil, buf[:gcm.NonceSize()], buf[gcm.NonceSize():], exportAAD(block.Headers))
+	if err != nil {
+		return nil, errors.New("invalid encrypted keyset")
+	}
+	return DecodeBytes(plaintext, opts...)
+}
+
+// exportECDHKey derives the content encryption key from the ECDH shared
+// secret.
+func exportECDHKey(priv *ecdh.PrivateKey, pub *ecdh.PublicKey, ephemeral []byte) ([]byte, error) {
+	secret, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	cek := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(crypto.SHA256.New, secret, ephemeral, []byte(exportECDH)), cek); err != nil {
+		return nil, err
+	}
+	return cek, nil
+}
+
+// exportAAD returns the additional authenticated data for the headers.
+func exportAAD(headers map[string]string) []byte {
+	return []byte(headers[AlgorithmHeader] + "\n" + headers[KeyFingerprintHeader])
+}
+
+// newGCM creates a AES-GCM cipher for the key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,58 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SelfSign(ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rsaKey, err := GenerateRSAKeySet(2048)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ecKey, err := GenerateECKeySet(elliptic.P384())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, recipient := range []Store{rsaKey, ecKey} {
+		buf, err := s.Export(recipient[PublicKey])
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if bytes.Contains(buf, exp[30:60]) {
+			t.Errorf("test %d expected exported data to be encrypted", i)
+		}
+		s0, err := Import(recipient, buf)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		b, err := s0.Bytes()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !bytes.Equal(b, exp) {
+			t.Errorf("test %d expected imported store to match", i)
+		}
+		// wrong recipient
+		if _, err := Import(s, buf); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+		// tampered
+		buf[len(buf)/2]++
+		if _, err := Import(recipient, buf); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// CertificatePredicate is a certificate predicate, used to select the
+// certificates in a [Store] (see [Store.Filter]).
+type CertificatePredicate func(*x509.Certificate) bool
+
+// Filter returns a copy of the [Store] containing only the certificates
+// matching all of the predicates. Other crypto primitives are retained.
+func (s Store) Filter(preds ...CertificatePredicate) Store {
+	z := s.Clone()
+	if _, ok := z[Certificate]; !ok {
+		return z
+	}
+	var certs []*x509.Certificate
+	for _, cert := range s.Certificates() {
+		if matchCertificate(cert, preds) {
+			certs = append(certs, cert)
+		}
+	}
+	delete(z, Certificate)
+	if len(certs) != 0 {
+		z[Certificate] = certs
+	}
+	return z
+}
+
+// matchCertificate determines if the certificate matches all of the
+// predicates.
+func matchCertificate(cert *x509.Certificate, preds []CertificatePredicate) bool {
+	for _, pred := range preds {
+		if !pred(cert) {
+			return false
+		}
+	}
+	return true
+}
+
+// NotExpired is a certificate predicate matching certificates that have not
+// expired at now.
+func NotExpired(now time.Time) CertificatePredicate {
+	return func(cert *x509.Certificate) bool {
+		return !now.After(cert.NotAfter)
+	}
+}
+
+// ValidFor is a certificate predicate matching certificates that do not
+// expire within d of the current time ([DefaultClock]), such as to exclude
+// certificates expiring within the next 30 days. The current time is
+// determined when ValidFor is called.
+func ValidFor(d time.Duration) CertificatePredicate {
+	return NotExpired(DefaultClock().Now().Add(d))
+}
+
+// KeyUsage is a certificate predicate matching certificates valid for the
+// extended key usage, such as [x509.ExtKeyUsageServerAuth]. Certificates
+// without extended key usages, or with [x509.ExtKeyUsageAny], are valid for
+// any usage.
+func KeyUsage(usage x509.ExtKeyUsage) CertificatePredicate {
+	return func(cert *x509.Certificate) bool {
+		return len(cert.ExtKeyUsage) == 0 || hasExtKeyUsage(cert, usage)
+	}
+}
@@ -0,0 +1,71 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	ca, err := LoadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	key, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := key.SelfSign(RootCATemplate("root")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	server, err := key.Issue(ServerTemplate("example.com"), key[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	client, err := key.Issue(ClientTemplate("client"), key[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	certs := testCertificates(t, 2)
+	s := Store{
+		Certificate:  append(ca.Certificates(), append(certs, server, client)...),
+		ECPrivateKey: key[ECPrivateKey],
+	}
+	tests := []struct {
+		preds []CertificatePredicate
+		exp   int
+	}{
+		{nil, 5},
+		{[]CertificatePredicate{NotExpired(time.Now())}, 5},
+		{[]CertificatePredicate{NotExpired(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))}, 1},
+		{[]CertificatePredicate{ValidFor(time.Hour)}, 5},
+		{[]CertificatePredicate{ValidFor(48 * time.Hour)}, 3},
+		{[]CertificatePredicate{KeyUsage(x509.ExtKeyUsageServerAuth)}, 4},
+		{[]CertificatePredicate{ValidFor(48 * time.Hour), KeyUsage(x509.ExtKeyUsageClientAuth)}, 2},
+		{[]CertificatePredicate{NotExpired(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))}, 0},
+	}
+	for i, test := range tests {
+		z := s.Filter(test.preds...)
+		if n := len(z.Certificates()); n != test.exp {
+			t.Errorf("test %d expected %d certificates, got: %d", i, test.exp, n)
+		}
+		if _, ok := z[ECPrivateKey]; !ok {
+			t.Errorf("test %d expected private key to be retained", i)
+		}
+	}
+	if n := len(s.Certificates()); n != 5 {
+		t.Errorf("expected original store to be unmodified, got: %d certificates", n)
+	}
+	buf, err := CAFile([]Store{s}, WithCAFileFilter(ValidFor(48*time.Hour)))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := len(z.Certificates()); n != 3 {
+		t.Errorf("expected 3 certificates, got: %d", n)
+	}
+}
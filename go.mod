@@ -1,3 +1,10 @@
 module github.com/kenshaw/pemutil
 
-go 1.19
+go 1.22.0
+
+require (
+	github.com/cloudflare/circl v1.6.3
+	golang.org/x/crypto v0.30.0
+	golang.org/x/sys v0.28.0
+	golang.org/x/term v0.27.0
+)
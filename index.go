@@ -0,0 +1,190 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Fingerprint returns the SHA-256 fingerprint of the certificate.
+func Fingerprint(cert *x509.Certificate) [32]byte {
+	return sha256.Sum256(cert.Raw)
+}
+
+// KeyFingerprint returns the SHA-256 fingerprint of the PKIX encoded
+// canonical public key (see [CanonicalPublicKey]), or of the raw key bytes for
+// raw (symmetric) keys.
+func KeyFingerprint(pub crypto.PublicKey) ([32]byte, error) {
+	switch k := pub.(type) {
+	case []byte:
+		return sha256.Sum256(k), nil
+	case *SymmetricKey:
+		return sha256.Sum256(k.Key), nil
+	}
+	pub, err := CanonicalPublicKey(pub)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	der, err := MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(der), nil
+}
+
+// DuplicateKey is a key that is present in multiple sources.
+type DuplicateKey struct {
+	// Fingerprint is the key fingerprint (see [KeyFingerprint]).
+	Fingerprint [32]byte
+	// Sources are the names of the sources containing the key, in sorted
+	// order.
+	Sources []string
+}
+
+// FindDuplicateKeys finds the keys that are present in more than one of the
+// named stores (ie, loaded from multiple files or for multiple services),
+// which often indicates credentials that have been copied between services.
+//
+// Private keys are matched by their public key, and raw keys by their value.
+// Certificates are not considered, as they are commonly shared. Duplicates
+// are returned in order of their first source.
+func FindDuplicateKeys(stores map[string]Store) []DuplicateKey {
+	sources := make(map[[32]byte][]string)
+	for name, s := range stores {
+		seen := make(map[[32]byte]bool)
+		var keys []interface{}
+		if pub, ok := s.PublicKey(); ok {
+			keys = append(keys, pub)
+		}
+		if key, ok := s.PrivateKey(); ok {
+			if v, ok := key.(interface{ Public() crypto.PublicKey }); ok {
+				key = v.Public()
+			}
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			fp, err := KeyFingerprint(key)
+			if err != nil || seen[fp] {
+				continue
+			}
+			seen[fp] = true
+			sources[fp] = append(sources[fp], name)
+		}
+	}
+	var dups []DuplicateKey
+	for fp, names := range sources {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		dups = append(dups, DuplicateKey{
+			Fingerprint: fp,
+			Sources:     names,
+		})
+	}
+	sort.Slice(dups, func(i, j int) bool {
+		return dups[i].Sources[0] < dups[j].Sources[0] ||
+			(dups[i].Sources[0] == dups[j].Sources[0] && string(dups[i].Fingerprint[:]) < string(dups[j].Fingerprint[:]))
+	})
+	return dups
+}
+
+// Index is a certificate index, for looking up certificates by fingerprint,
+// subject, subject alternative name, and serial number.
+type Index struct {
+	fingerprints map[[32]byte]*x509.Certificate
+	subjects     map[string][]*x509.Certificate
+	names        map[string][]*x509.Certificate
+	serials      map[string][]*x509.Certificate
+}
+
+// NewIndex creates a certificate index for the certificates.
+func NewIndex(certs ...*x509.Certificate) *Index {
+	idx := &Index{
+		fingerprints: make(map[[32]byte]*x509.Certificate, len(certs)),
+		subjects:     make(map[string][]*x509.Certificate, len(certs)),
+		names:        make(map[string][]*x509.Certificate),
+		serials:      make(map[string][]*x509.Certificate, len(certs)),
+	}
+	for _, cert := range certs {
+		fp := Fingerprint(cert)
+		if _, ok := idx.fingerprints[fp]; ok {
+			continue
+		}
+		idx.fingerprints[fp] = cert
+		subject := cert.Subject.String()
+		idx.subjects[subject] = append(idx.subjects[subject], cert)
+		if cert.SerialNumber != nil {
+			serial := cert.SerialNumber.String()
+			idx.serials[serial] = append(idx.serials[serial], cert)
+		}
+		var names []string
+		for _, name := range cert.DNSNames {
+			names = append(names, strings.ToLower(name))
+		}
+		for _, ip := range cert.IPAddresses {
+			names = append(names, ip.String())
+		}
+		for _, email := range cert.EmailAddresses {
+			names = append(names, strings.ToLower(email))
+		}
+		for _, u := range cert.URIs {
+			names = append(names, u.String())
+		}
+		for _, name := range names {
+			idx.names[name] = append(idx.names[name], cert)
+		}
+	}
+	return idx
+}
+
+// Index creates a certificate index for the certificates in the [Store].
+func (s Store) Index() *Index {
+	return NewIndex(s.Certificates()...)
+}
+
+// ByFingerprint returns the certificate with the SHA-256 fingerprint.
+func (idx *Index) ByFingerprint(fp [32]byte) (*x509.Certificate, bool) {
+	cert, ok := idx.fingerprints[fp]
+	return cert, ok
+}
+
+// BySubject returns the certificates with the subject, in the form returned
+// by [pkix.Name.String] (ie, "CN=example.com,O=Example").
+func (idx *Index) BySubject(subject string) []*x509.Certificate {
+	return idx.subjects[subject]
+}
+
+// ByName returns the certificates with a subject alternative name (DNS name,
+// IP address, email address, or URI) matching name. DNS names are matched
+// case-insensitively, and also match certificates with a wildcard for the
+// first label (ie, "api.example.com" matches "*.example.com").
+func (idx *Index) ByName(name string) []*x509.Certificate {
+	name = strings.ToLower(name)
+	certs := idx.names[name]
+	if i := strings.IndexByte(name, '.'); i > 0 && !strings.ContainsAny(name, ":@/") {
+		if wildcard := idx.names["*"+name[i:]]; len(wildcard) != 0 {
+			certs = append(append([]*x509.Certificate(nil), certs...), wildcard...)
+		}
+	}
+	return certs
+}
+
+// BySerial returns the certificates with the serial number.
+func (idx *Index) BySerial(serial *big.Int) []*x509.Certificate {
+	return idx.serials[serial.String()]
+}
+
+// FindByFingerprint returns the certificate in the [Store] with the SHA-256
+// fingerprint. Use [Store.Index] when performing many lookups.
+func (s Store) FindByFingerprint(fp [32]byte) (*x509.Certificate, bool) {
+	for _, cert := range s.Certificates() {
+		if Fingerprint(cert) == fp {
+			return cert, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,113 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ca.SelfSign(RootCATemplate("root")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := Store{}
+	for _, hosts := range [][]string{
+		{"api.example.com", "10.0.0.1"},
+		{"*.example.com"},
+		{"www.example.org"},
+	} {
+		cert, err := ca.Issue(ServerTemplate(hosts...), ca[PublicKey])
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		s.addCertificate(cert)
+	}
+	certs := s.Certificates()
+	idx := s.Index()
+	for i, cert := range certs {
+		fp := Fingerprint(cert)
+		if c, ok := idx.ByFingerprint(fp); !ok || c != cert {
+			t.Errorf("test %d expected certificate by fingerprint", i)
+		}
+		if c, ok := s.FindByFingerprint(fp); !ok || c != cert {
+			t.Errorf("test %d expected certificate by fingerprint", i)
+		}
+		if c := idx.BySerial(cert.SerialNumber); len(c) != 1 || c[0] != cert {
+			t.Errorf("test %d expected certificate by serial", i)
+		}
+		if c := idx.BySubject(cert.Subject.String()); len(c) != 1 || c[0] != cert {
+			t.Errorf("test %d expected certificate by subject", i)
+		}
+	}
+	tests := []struct {
+		name string
+		exp  []int
+	}{
+		{"API.example.com", []int{0, 1}},
+		{"10.0.0.1", []int{0}},
+		{"foo.example.com", []int{1}},
+		{"www.example.org", []int{2}},
+		{"example.com", nil},
+		{"a.b.example.com", nil},
+	}
+	for i, test := range tests {
+		c := idx.ByName(test.name)
+		if len(c) != len(test.exp) {
+			t.Fatalf("test %d expected %d certificates, got: %d", i, len(test.exp), len(c))
+		}
+		for j, k := range test.exp {
+			if c[j] != certs[k] {
+				t.Errorf("test %d expected certificate %d at %d", i, k, j)
+			}
+		}
+	}
+	if _, ok := idx.ByFingerprint([32]byte{}); ok {
+		t.Errorf("expected no certificate")
+	}
+	if c := idx.BySerial(big.NewInt(0)); len(c) != 0 {
+		t.Errorf("expected no certificates, got: %d", len(c))
+	}
+}
+
+func TestFindDuplicateKeys(t *testing.T) {
+	a, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	sym, err := GenerateSymmetricKeySet(32)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dups := FindDuplicateKeys(map[string]Store{
+		"a":       a,
+		"a-copy":  {ECPrivateKey: a[ECPrivateKey]},
+		"a-pub":   {PublicKey: a[PublicKey]},
+		"b":       b,
+		"sym":     sym,
+		"sym-dup": {PrivateKey: sym[PrivateKey]},
+	})
+	if len(dups) != 2 {
+		t.Fatalf("expected 2 duplicates, got: %d", len(dups))
+	}
+	for i, exp := range [][]string{{"a", "a-copy", "a-pub"}, {"sym", "sym-dup"}} {
+		if strings.Join(dups[i].Sources, ",") != strings.Join(exp, ",") {
+			t.Errorf("test %d expected %v, got: %v", i, exp, dups[i].Sources)
+		}
+	}
+	fp, err := KeyFingerprint(a[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if dups[0].Fingerprint != fp {
+		t.Errorf("expected fingerprint %x, got: %x", fp, dups[0].Fingerprint)
+	}
+}
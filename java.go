@@ -0,0 +1,201 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// javaBlockTypes are the block types accepted by Java's standard parsers
+// (CertificateFactory, KeyFactory with PKCS#8 and X.509 key specs,
+// EncryptedPrivateKeyInfo, and keytool).
+var javaBlockTypes = map[BlockType]bool{
+	Certificate:         true,
+	CertificateRequest:  true,
+	RevocationList:      true,
+	PrivateKey:          true,
+	EncryptedPrivateKey: true,
+	PublicKey:           true,
+}
+
+// WithJavaCompat is a encode option to only produce output accepted by
+// Java's standard parsers: private keys are encoded as PKCS#8 v1 "PRIVATE
+// KEY" blocks (without attributes), public keys as X.509 "PUBLIC KEY" blocks,
+// and certificates, certificate requests, and CRLs as-is, all without PEM
+// headers or explanatory text, using 64 character lines ending with LF.
+//
+// Crypto primitives Java cannot parse (such as raw symmetric keys, and EC
+// keys on curves other than P-256, P-384, and P-521) cause an error instead
+// of being silently emitted. See [CheckJavaCompat].
+func WithJavaCompat() EncodeOption {
+	return func(opts *encodeOptions) {
+		opts.java = true
+	}
+}
+
+// javaBlocks returns the Java compatible PEM blocks for the crypto
+// primitive.
+func javaBlocks(p interface{}) ([]*pem.Block, error) {
+	if err := checkJavaKey(p); err != nil {
+		return nil, err
+	}
+	switch p.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey, ed448.PrivateKey, *ecdh.PrivateKey:
+		der, err := marshalPKCS8(p)
+		if err != nil {
+			return nil, err
+		}
+		return []*pem.Block{{
+			Type:  PrivateKey.String(),
+			Bytes: der,
+		}}, nil
+	case []byte, *SymmetricKey, SessionTicketKeys:
+		return nil, errors.New("symmetric keys are not supported by Java")
+	}
+	blocks, err := primitiveBlocks(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		if !javaBlockTypes[BlockType(block.Type)] {
+			return nil, fmt.Errorf("%s blocks are not supported by Java", block.Type)
+		}
+		block.Headers = nil
+	}
+	return blocks, nil
+}
+
+// checkJavaKey checks that EC keys use a curve supported by Java.
+func checkJavaKey(key interface{}) error {
+	var curve elliptic.Curve
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		curve = k.Curve
+	case *ecdsa.PublicKey:
+		curve = k.Curve
+	default:
+		return nil
+	}
+	switch curve {
+	case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		return nil
+	}
+	return fmt.Errorf("curve %s is not supported by Java", curve.Params().Name)
+}
+
+// CheckJavaCompat checks that the PEM data in buf is accepted by Java's
+// standard parsers (see [WithJavaCompat]), returning an error describing the
+// first problem found, such as traditional "RSA PRIVATE KEY" and "EC PRIVATE
+// KEY" blocks, PEM headers (including legacy "Proc-Type" encryption),
+// explanatory text, PKCS#8 v2 keys or keys with attributes, EC keys with
+// explicit parameters or unsupported curves, CRLF line endings, and lines
+// that are not 64 characters.
+func CheckJavaCompat(buf []byte) error {
+	if bytes.Contains(buf, []byte("\r")) {
+		return errors.New("CRLF line endings are not supported by Java")
+	}
+	// check lines
+	inBlock, n, short := false, 0, false
+	for i, line := range bytes.Split(buf, []byte("\n")) {
+		switch {
+		case !inBlock && bytes.HasPrefix(line, []byte("-----BEGIN ")):
+			inBlock, n, short = true, n+1, false
+		case !inBlock && len(bytes.TrimSpace(line)) != 0:
+			return fmt.Errorf("line %d: text outside of PEM blocks is not supported by Java", i+1)
+		case inBlock && bytes.HasPrefix(line, []byte("-----END ")):
+			inBlock = false
+		case inBlock && bytes.Contains(line, []byte(":")):
+			return fmt.Errorf("line %d: PEM headers are not supported by Java", i+1)
+		case inBlock && (short || len(line) > 64 || len(line) == 0):
+			return fmt.Errorf("line %d: base64 lines must be 64 characters", i+1)
+		case inBlock && len(line) < 64:
+			short = true
+		}
+	}
+	if n == 0 {
+		return errors.New("invalid PEM data")
+	}
+	for i := 0; ; i++ {
+		var block *pem.Block
+		if block, buf = pem.Decode(buf); block == nil {
+			break
+		}
+		if err := checkJavaBlock(block); err != nil {
+			return fmt.Errorf("block %d (%s): %w", i, block.Type, err)
+		}
+	}
+	return nil
+}
+
+// checkJavaBlock checks that the block is accepted by Java's standard
+// parsers.
+func checkJavaBlock(block *pem.Block) error {
+	typ := BlockType(block.Type)
+	switch {
+	case !javaBlockTypes[typ]:
+		return errors.New("block type is not supported by Java")
+	case len(block.Headers) != 0:
+		return errors.New("PEM headers are not supported by Java")
+	}
+	var key interface{}
+	var err error
+	switch typ {
+	case PrivateKey:
+		if err := checkPKCS8v1(block.Bytes); err != nil {
+			return err
+		}
+		if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+			if key, err = ParseEd448PrivateKey(block.Bytes); err != nil {
+				return errors.New("private key is not supported by Java")
+			}
+		}
+	case PublicKey:
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			if key, err = ParseEd448PublicKey(block.Bytes); err != nil {
+				return errors.New("public key is not supported by Java")
+			}
+		}
+	case Certificate:
+		_, err = x509.ParseCertificate(block.Bytes)
+	case CertificateRequest:
+		_, err = x509.ParseCertificateRequest(block.Bytes)
+	case RevocationList:
+		_, err = x509.ParseRevocationList(block.Bytes)
+	}
+	if err != nil {
+		return err
+	}
+	return checkJavaKey(key)
+}
+
+// checkPKCS8v1 checks that the DER-encoded PKCS#8 private key is a version 1
+// (RFC 5208) private key without attributes.
+func checkPKCS8v1(der []byte) error {
+	var version int
+	var s cryptobyte.String
+	in := cryptobyte.String(der)
+	if !in.ReadASN1(&s, asn1.SEQUENCE) ||
+		!s.ReadASN1Integer(&version) ||
+		!s.SkipASN1(asn1.SEQUENCE) ||
+		!s.SkipASN1(asn1.OCTET_STRING) {
+		return errors.New("invalid PKCS#8 private key")
+	}
+	switch {
+	case version != 0:
+		return errors.New("PKCS#8 v2 private keys are not supported by Java")
+	case !s.Empty():
+		return errors.New("PKCS#8 private key attributes are not supported by Java")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+//go:build js && wasm
+
+package pemutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall/js"
+)
+
+// DecodeJS decodes the PEM-encoded data in the JavaScript value into a new
+// store. The value can be a Uint8Array (or any other typed array), an
+// ArrayBuffer, a DataView, or a string.
+func DecodeJS(v js.Value, opts ...LoadOption) (Store, error) {
+	buf, err := bytesFromJS(v)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBytes(buf, opts...)
+}
+
+// LoadResponse loads the crypto primitives from the body of a JavaScript
+// fetch Response. Returns an error wrapping [fs.ErrNotExist] when the
+// response status is 404, or an error for any other non-successful status.
+//
+// Blocks until the response body has been read, and must not be called from
+// a JavaScript callback (see [js.FuncOf]).
+func LoadResponse(res js.Value, opts ...LoadOption) (Store, error) {
+	if res.Type() != js.TypeObject || res.Get("arrayBuffer").Type() != js.TypeFunction {
+		return nil, errors.New("value is not a fetch Response")
+	}
+	if !res.Get("ok").Bool() {
+		status := res.Get("status").Int()
+		if status == 404 {
+			return nil, fmt.Errorf("%s: %w", res.Get("url").String(), fs.ErrNotExist)
+		}
+		return nil, fmt.Errorf("%s: status %d", res.Get("url").String(), status)
+	}
+	v, err := awaitJS(res.Call("arrayBuffer"))
+	if err != nil {
+		return nil, err
+	}
+	return DecodeJS(v, opts...)
+}
+
+// FetchSource returns a [Source] for the URL, loaded using the JavaScript
+// fetch API (see [LoadResponse]).
+func FetchSource(url string) Source {
+	return fetchSource(url)
+}
+
+// fetchSource is a fetch source.
+type fetchSource string
+
+// Load satisfies the [Source] interface.
+func (src fetchSource) Load(opts ...LoadOption) (Store, error) {
+	fetch := js.Global().Get("fetch")
+	if fetch.Type() != js.TypeFunction {
+		return nil, errors.New("fetch is not available")
+	}
+	res, err := awaitJS(fetch.Invoke(string(src)))
+	if err != nil {
+		return nil, err
+	}
+	return LoadResponse(res, opts...)
+}
+
+// String satisfies the [Source] interface.
+func (src fetchSource) String() string {
+	return "fetch " + string(src)
+}
+
+// Uint8Array returns all crypto primitives in the [Store] PEM-encoded (see
+// [Store.Bytes]) as a JavaScript Uint8Array.
+func (s Store) Uint8Array() (js.Value, error) {
+	buf, err := s.Bytes()
+	if err != nil {
+		return js.Undefined(), err
+	}
+	v := js.Global().Get("Uint8Array").New(len(buf))
+	js.CopyBytesToJS(v, buf)
+	return v, nil
+}
+
+// bytesFromJS copies the data in a JavaScript typed array, ArrayBuffer,
+// DataView, or string.
+func bytesFromJS(v js.Value) ([]byte, error) {
+	if v.Type() == js.TypeString {
+		return []byte(v.String()), nil
+	}
+	if v.Type() != js.TypeObject {
+		return nil, fmt.Errorf("unsupported JavaScript value type %s", v.Type())
+	}
+	uint8Array := js.Global().Get("Uint8Array")
+	switch {
+	case v.InstanceOf(uint8Array):
+	case v.InstanceOf(js.Global().Get("ArrayBuffer")):
+		v = uint8Array.New(v)
+	case js.Global().Get("ArrayBuffer").Call("isView", v).Bool():
+		v = uint8Array.New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+	default:
+		return nil, errors.New("unsupported JavaScript value (expected a typed array, ArrayBuffer, or string)")
+	}
+	buf := make([]byte, v.Get("length").Int())
+	js.CopyBytesToGo(buf, v)
+	return buf, nil
+}
+
+// awaitJS waits for the JavaScript promise to settle, returning its value,
+// or an error when rejected.
+func awaitJS(promise js.Value) (js.Value, error) {
+	type result struct {
+		v   js.Value
+		err error
+	}
+	ch := make(chan result, 1)
+	resolve := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		ch <- result{v: arg(args)}
+		return nil
+	})
+	defer resolve.Release()
+	reject := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		ch <- result{err: fmt.Errorf("promise rejected: %s", js.Global().Get("String").Invoke(arg(args)).String())}
+		return nil
+	})
+	defer reject.Release()
+	promise.Call("then", resolve, reject)
+	r := <-ch
+	return r.v, r.err
+}
+
+// arg returns the first argument, or undefined.
+func arg(args []js.Value) js.Value {
+	if len(args) == 0 {
+		return js.Undefined()
+	}
+	return args[0]
+}
@@ -0,0 +1,71 @@
+//go:build js && wasm
+
+package pemutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall/js"
+	"testing"
+)
+
+func TestDecodeJS(t *testing.T) {
+	buf, err := os.ReadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	arr := js.Global().Get("Uint8Array").New(len(buf))
+	js.CopyBytesToJS(arr, buf)
+	tests := []js.Value{
+		arr,
+		arr.Get("buffer"),
+		js.Global().Get("DataView").New(arr.Get("buffer")),
+		js.ValueOf(string(buf)),
+	}
+	for i, v := range tests {
+		s, err := DecodeJS(v)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if len(s.Certificates()) != 1 {
+			t.Errorf("test %d expected 1 certificate, got: %d", i, len(s.Certificates()))
+		}
+	}
+	if _, err := DecodeJS(js.ValueOf(1)); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	s, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	v, err := s.Uint8Array()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if v.Get("length").Int() != len(buf) {
+		t.Errorf("expected length %d, got: %d", len(buf), v.Get("length").Int())
+	}
+}
+
+func TestLoadResponse(t *testing.T) {
+	if js.Global().Get("Response").Type() != js.TypeFunction {
+		t.Skip("Response is not available")
+	}
+	buf, err := os.ReadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	res := js.Global().Get("Response").New(string(buf))
+	s, err := LoadResponse(res)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(s.Certificates()) != 1 {
+		t.Errorf("expected 1 certificate, got: %d", len(s.Certificates()))
+	}
+	notFound := js.Global().Get("Response").New(js.Null(), map[string]interface{}{"status": 404})
+	if _, err := LoadResponse(notFound); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got: %v", err)
+	}
+}
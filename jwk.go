@@ -0,0 +1,56 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// JWKThumbprint returns the JSON Web Key thumbprint (RFC 7638) of the public
+// key, using the hash (usually [crypto.SHA256]). Use
+// [base64.RawURLEncoding] to encode the thumbprint for use as a JWK key id.
+func JWKThumbprint(pub crypto.PublicKey, h crypto.Hash) ([]byte, error) {
+	if !h.Available() {
+		return nil, fmt.Errorf("hash %v is not available", h)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	var s string
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		s = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, b64(big.NewInt(int64(k.E)).Bytes()), b64(k.N.Bytes()))
+	case *ecdsa.PublicKey:
+		crv, err := jwkCurve(k)
+		if err != nil {
+			return nil, err
+		}
+		n := (k.Params().BitSize + 7) / 8
+		s = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, crv, b64(k.X.FillBytes(make([]byte, n))), b64(k.Y.FillBytes(make([]byte, n))))
+	case ed25519.PublicKey:
+		s = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, b64(k))
+	case ed448.PublicKey:
+		s = fmt.Sprintf(`{"crv":"Ed448","kty":"OKP","x":%q}`, b64(k))
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	hh := h.New()
+	hh.Write([]byte(s))
+	return hh.Sum(nil), nil
+}
+
+// jwkCurve returns the JSON Web Key curve name for the EC public key.
+func jwkCurve(pub *ecdsa.PublicKey) (string, error) {
+	switch name := pub.Params().Name; name {
+	case "P-256", "P-384", "P-521":
+		return name, nil
+	}
+	if c, ok := curveByCurve(pub.Curve); ok && c.oid.Equal(OIDSecp256k1) {
+		return "secp256k1", nil
+	}
+	return "", fmt.Errorf("unsupported JWK curve %s", pub.Params().Name)
+}
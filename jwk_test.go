@@ -0,0 +1,42 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+)
+
+func TestJWKThumbprint(t *testing.T) {
+	// RFC 7638, section 3.1
+	buf, _ := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(buf), E: 65537}
+	tp, err := JWKThumbprint(pub, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s, exp := base64.RawURLEncoding.EncodeToString(tp), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; s != exp {
+		t.Errorf("expected %s, got: %s", exp, s)
+	}
+	for i, gen := range []func() (Store, error){
+		func() (Store, error) { return GenerateECKeySet(elliptic.P256()) },
+		GenerateEd448KeySet,
+	} {
+		s, err := gen()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if _, err := JWKThumbprint(s[PublicKey], crypto.SHA256); err != nil {
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		}
+	}
+	s, err := GenerateECKeySet(elliptic.P224())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := JWKThumbprint(s[PublicKey], crypto.SHA256); err == nil {
+		t.Errorf("expected error for P-224")
+	}
+}
@@ -0,0 +1,171 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// JWK is a JSON Web Key (RFC 7517) for a public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// NewJWK creates a JSON Web Key for the public key, using the SHA-256 JWK
+// thumbprint (see [JWKThumbprint]) as the key id.
+func NewJWK(pub crypto.PublicKey) (*JWK, error) {
+	pub, err := CanonicalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	key := &JWK{Use: "sig"}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		key.Kty, key.Alg = "RSA", "RS256"
+		key.N, key.E = b64(k.N.Bytes()), b64(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		if key.Crv, err = jwkCurve(k); err != nil {
+			return nil, err
+		}
+		key.Kty, key.Alg = "EC", map[string]string{
+			"P-256":     "ES256",
+			"P-384":     "ES384",
+			"P-521":     "ES512",
+			"secp256k1": "ES256K",
+		}[key.Crv]
+		n := (k.Params().BitSize + 7) / 8
+		key.X, key.Y = b64(k.X.FillBytes(make([]byte, n))), b64(k.Y.FillBytes(make([]byte, n)))
+	case ed25519.PublicKey:
+		key.Kty, key.Alg, key.Crv, key.X = "OKP", "EdDSA", "Ed25519", b64(k)
+	case ed448.PublicKey:
+		key.Kty, key.Alg, key.Crv, key.X = "OKP", "EdDSA", "Ed448", b64(k)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	kid, err := JWKThumbprint(pub, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	key.Kid = b64(kid)
+	return key, nil
+}
+
+// JWKSet is a JSON Web Key set.
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// NewJWKSet creates a JSON Web Key set containing the public keys of the
+// stores. Stores without a public key are skipped, as are duplicate keys.
+func NewJWKSet(stores ...Store) (*JWKSet, error) {
+	set := &JWKSet{Keys: []*JWK{}}
+	seen := make(map[string]bool)
+	for _, s := range stores {
+		pub, err := CanonicalPublicKey(s)
+		if err != nil {
+			continue
+		}
+		key, err := NewJWK(pub)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[key.Kid] {
+			set.Keys, seen[key.Kid] = append(set.Keys, key), true
+		}
+	}
+	return set, nil
+}
+
+// JWKSHandler returns a [http.Handler] serving the public keys of the stores
+// returned by f as a JSON Web Key set, or, when requested with a format=pem
+// query parameter or an Accept header of application/x-pem-file, as
+// concatenated PEM-encoded public keys.
+//
+// f is called for each request, so rotated keys are served immediately.
+// Responses carry an ETag (honoring If-None-Match) and, when maxAge is
+// non-zero, a Cache-Control max-age.
+func JWKSHandler(f func() []Store, maxAge time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		stores := f()
+		var buf []byte
+		var err error
+		contentType := "application/jwk-set+json"
+		if req.URL.Query().Get("format") == "pem" || strings.Contains(req.Header.Get("Accept"), "application/x-pem-file") {
+			contentType = "application/x-pem-file"
+			buf, err = publicKeysPEM(stores)
+		} else {
+			var set *JWKSet
+			if set, err = NewJWKSet(stores...); err == nil {
+				buf, err = json.Marshal(set)
+			}
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		h := sha256.Sum256(buf)
+		etag := `"` + base64.RawURLEncoding.EncodeToString(h[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Vary", "Accept")
+		if maxAge != 0 {
+			w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge/time.Second)))
+		}
+		if req.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(buf)))
+		if req.Method != http.MethodHead {
+			_, _ = w.Write(buf)
+		}
+	})
+}
+
+// publicKeysPEM returns the PEM-encoded public keys of the stores. Stores
+// without a public key are skipped.
+func publicKeysPEM(stores []Store) ([]byte, error) {
+	var b bytes.Buffer
+	for _, s := range stores {
+		pub, err := CanonicalPublicKey(s)
+		if err != nil {
+			continue
+		}
+		der, err := MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		if err := pem.Encode(&b, &pem.Block{Type: PublicKey.String(), Bytes: der}); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,71 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	a, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b := Store{PrivateKey: priv}
+	b.AddPublicKeys()
+	stores := []Store{a}
+	h := JWKSHandler(func() []Store { return stores }, time.Hour)
+	get := func(target, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	// jwks
+	w := get("/", "")
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "public, max-age=3600" {
+		t.Fatalf("expected 200 with cache control, got: %d %v", w.Code, w.Header())
+	}
+	var set JWKSet
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].Kty != "EC" || set.Keys[0].Alg != "ES256" || set.Keys[0].Kid == "" {
+		t.Errorf("expected EC key, got: %+v", set.Keys)
+	}
+	// not modified
+	etag := w.Header().Get("ETag")
+	if w := get("/", etag); w.Code != http.StatusNotModified {
+		t.Errorf("expected %d, got: %d", http.StatusNotModified, w.Code)
+	}
+	// rotation
+	stores = []Store{b, a}
+	w = get("/", etag)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, w.Code)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(set.Keys) != 2 || set.Keys[0].Crv != "Ed25519" || set.Keys[1].Crv != "P-256" {
+		t.Errorf("expected Ed25519 and P-256 keys, got: %+v", set.Keys)
+	}
+	// pem
+	w = get("/?format=pem", "")
+	if n := bytes.Count(w.Body.Bytes(), []byte("BEGIN PUBLIC KEY")); w.Header().Get("Content-Type") != "application/x-pem-file" || n != 2 {
+		t.Errorf("expected PEM public keys, got: %q", w.Body.String())
+	}
+}
@@ -0,0 +1,210 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// JWTIssuer is a JWT (RFC 7519) issuer, bundling the signing key, its key
+// id and JWS algorithm, a verifier for the issued tokens, and the JSON Web
+// Key set to publish for relying parties.
+type JWTIssuer struct {
+	// Store is the store containing the private key.
+	Store Store
+	// Kid is the key id (the SHA-256 JWK thumbprint, see [NewJWK]).
+	Kid string
+	// Alg is the JWS algorithm.
+	Alg string
+	// Verifier verifies signatures made by the issuer.
+	Verifier Verifier
+	// JWKS is the JSON Web Key set containing the issuer's public key.
+	JWKS *JWKSet
+}
+
+// NewJWTIssuer creates a JWT issuer for the private key in the [Store], using
+// the JWS algorithm (such as "RS256", "PS256", "ES256", or "EdDSA"). When alg
+// is empty, the default algorithm for the key's JWK is used (see [NewJWK]).
+func NewJWTIssuer(s Store, alg string) (*JWTIssuer, error) {
+	signer, ok := s.Signer()
+	if !ok {
+		return nil, errors.New("store does not contain a private key")
+	}
+	if err := s.CheckPurpose(PurposeSigning); err != nil {
+		return nil, err
+	}
+	key, err := NewJWK(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	if alg == "" {
+		alg = key.Alg
+	}
+	v, err := newJWSVerifier(signer.Public(), alg)
+	if err != nil {
+		return nil, err
+	}
+	key.Alg = alg
+	return &JWTIssuer{
+		Store:    s,
+		Kid:      key.Kid,
+		Alg:      alg,
+		Verifier: v,
+		JWKS:     &JWKSet{Keys: []*JWK{key}},
+	}, nil
+}
+
+// GenerateJWTIssuer generates a private key for the JWS algorithm ("RS256",
+// "ES256", "ES384", "ES512", or "EdDSA"), returning a JWT issuer for it (see
+// [NewJWTIssuer]). RSA keys are 2048 bits, and EdDSA keys are Ed25519 keys.
+func GenerateJWTIssuer(alg string) (*JWTIssuer, error) {
+	var s Store
+	var err error
+	switch alg {
+	case "RS256":
+		s, err = GenerateRSAKeySet(2048)
+	case "ES256":
+		s, err = GenerateECKeySet(elliptic.P256())
+	case "ES384":
+		s, err = GenerateECKeySet(elliptic.P384())
+	case "ES512":
+		s, err = GenerateECKeySet(elliptic.P521())
+	case "EdDSA":
+		var pub ed25519.PublicKey
+		var key ed25519.PrivateKey
+		if pub, key, err = ed25519.GenerateKey(Rand()); err == nil {
+			s = Store{PrivateKey: key, PublicKey: pub}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewJWTIssuer(s, alg)
+}
+
+// Sign returns the JWS signature of the signing input.
+func (i *JWTIssuer) Sign(signingInput []byte) ([]byte, error) {
+	signer, ok := i.Store.Signer()
+	if !ok {
+		return nil, errors.New("store does not contain a private key")
+	}
+	// the hash and padding are those used by the verifier (ie, SHA-256 for
+	// ES256K)
+	v, err := newJWSVerifier(signer.Public(), i.Alg)
+	if err != nil {
+		return nil, err
+	}
+	jv := v.(*jwsVerifier)
+	h := jv.hash
+	digest := signingInput
+	var opts crypto.SignerOpts = h
+	if h != 0 {
+		hh := h.New()
+		_, _ = hh.Write(signingInput)
+		digest = hh.Sum(nil)
+		if jv.pss {
+			opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: h}
+		}
+	}
+	sig, err := i.Store.Sign(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	if pub, ok := signer.Public().(*ecdsa.PublicKey); ok {
+		// convert the ASN.1 signature to the JWS r || s form
+		r, z := new(big.Int), new(big.Int)
+		in := cryptobyte.String(sig)
+		var seq cryptobyte.String
+		if !in.ReadASN1(&seq, asn1.SEQUENCE) ||
+			!seq.ReadASN1Integer(r) ||
+			!seq.ReadASN1Integer(z) {
+			return nil, errors.New("invalid ECDSA signature")
+		}
+		n := (pub.Params().BitSize + 7) / 8
+		sig = append(r.FillBytes(make([]byte, n)), z.FillBytes(make([]byte, n))...)
+	}
+	return sig, nil
+}
+
+// SignJWT returns a compact serialized JWT for the claims, with a header
+// containing the issuer's algorithm and key id.
+func (i *JWTIssuer) SignJWT(claims interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": i.Alg,
+		"kid": i.Kid,
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	signingInput := b64(header) + "." + b64(payload)
+	sig, err := i.Sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + b64(sig), nil
+}
+
+// VerifyJWT verifies the signature of a compact serialized JWT issued by the
+// issuer, decoding its claims into v. Only the header and signature are
+// checked: validating the claims (such as "exp" and "aud") is left to the
+// caller.
+func (i *JWTIssuer) VerifyJWT(token string, v interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("invalid JWT")
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid JWT header: %w", err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return fmt.Errorf("invalid JWT header: %w", err)
+	}
+	switch {
+	case header.Alg != i.Alg:
+		return fmt.Errorf("unexpected JWT algorithm %q", header.Alg)
+	case header.Kid != "" && header.Kid != i.Kid:
+		return fmt.Errorf("unknown key id %q", header.Kid)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid JWT signature: %w", err)
+	}
+	if err := i.Verifier.Verify([]byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// PublicStore returns a public store containing the issuer's public key, for
+// use by services verifying the issued tokens.
+func (i *JWTIssuer) PublicStore() (*PublicStore, error) {
+	return NewPublicStore(i.Store)
+}
@@ -0,0 +1,110 @@
+package pemutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJWTIssuer(t *testing.T) {
+	for i, alg := range []string{"RS256", "ES256", "ES384", "ES512", "EdDSA"} {
+		iss, err := GenerateJWTIssuer(alg)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if iss.Alg != alg || len(iss.JWKS.Keys) != 1 || iss.JWKS.Keys[0].Kid != iss.Kid || iss.JWKS.Keys[0].Alg != alg {
+			t.Errorf("test %d expected JWKS with key id %s and algorithm %s", i, iss.Kid, alg)
+		}
+		token, err := iss.SignJWT(map[string]string{"sub": "user"})
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		var claims map[string]string
+		if err := iss.VerifyJWT(token, &claims); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if claims["sub"] != "user" {
+			t.Errorf("test %d expected sub user, got: %q", i, claims["sub"])
+		}
+		// verify using the public store
+		p, err := iss.PublicStore()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		v, err := p.VerifierFor(iss.Kid, alg)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		n := strings.LastIndex(token, ".")
+		sig, err := iss.Sign([]byte(token[:n]))
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if err := v.Verify([]byte(token[:n]), sig); err != nil {
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		}
+		// tampered payload
+		if err := iss.VerifyJWT(token[:n-1]+"x"+token[n:], &claims); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+	}
+}
+
+func TestNewJWTIssuer(t *testing.T) {
+	s, err := GenerateRSAKeySet(2048)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	iss, err := NewJWTIssuer(s, "PS384")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	token, err := iss.SignJWT(map[string]int{"n": 1})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var claims map[string]int
+	if err := iss.VerifyJWT(token, &claims); err != nil || claims["n"] != 1 {
+		t.Errorf("expected claims to round trip, got: %v %v", claims, err)
+	}
+	for i, alg := range []string{"HS256", "none", "ES256"} {
+		if _, err := NewJWTIssuer(s, alg); err == nil {
+			t.Errorf("test %d expected error for algorithm %s", i, alg)
+		}
+	}
+	if _, err := GenerateJWTIssuer("HS256"); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestJWTIssuerES256K(t *testing.T) {
+	curve := secp256k1()
+	RegisterCurve(OIDSecp256k1, curve)
+	s, err := GenerateECKeySet(curve)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	iss, err := NewJWTIssuer(s, "ES256K")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	token, err := iss.SignJWT(map[string]string{"sub": "user"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var claims map[string]string
+	if err := iss.VerifyJWT(token, &claims); err != nil || claims["sub"] != "user" {
+		t.Errorf("expected claims to round trip, got: %v %v", claims, err)
+	}
+	// signature is 64 bytes, over the SHA-256 digest
+	n := strings.LastIndex(token, ".")
+	sig, err := iss.Sign([]byte(token[:n]))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(sig) != 64 {
+		t.Errorf("expected 64 byte signature, got: %d", len(sig))
+	}
+	if err := iss.Verifier.Verify([]byte(token[:n]), sig); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/kenshaw/pemutil/keychain
+
+go 1.22.0
+
+require (
+	github.com/kenshaw/pemutil v0.0.0
+	github.com/zalando/go-keyring v0.2.6
+)
+
+require (
+	al.essio.dev/pkg/shellescape v1.5.1 // indirect
+	github.com/cloudflare/circl v1.6.3 // indirect
+	github.com/danieljoos/wincred v1.2.2 // indirect
+	github.com/godbus/dbus/v5 v5.1.0 // indirect
+	golang.org/x/crypto v0.30.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/term v0.27.0 // indirect
+)
+
+replace github.com/kenshaw/pemutil => ../
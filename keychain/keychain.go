@@ -0,0 +1,72 @@
+// Package keychain provides a [pemutil.KeyStore] that persists stores in the
+// operating system's credential store (the macOS Keychain, the Windows
+// Credential Manager, or a Secret Service provider such as GNOME Keyring via
+// libsecret's D-Bus API), instead of plain files.
+//
+// The package is a separate module, so that programs using pemutil do not
+// depend on github.com/zalando/go-keyring:
+//
+//	go get github.com/kenshaw/pemutil/keychain
+package keychain
+
+import (
+	"errors"
+
+	"github.com/kenshaw/pemutil"
+	"github.com/zalando/go-keyring"
+)
+
+// KeyStore is a [pemutil.KeyStore] backed by the operating system's
+// credential store. Each [pemutil.Store] is PEM-encoded and stored as a
+// secret for the service and name.
+//
+// Credential stores limit the size of secrets (notably, the Windows
+// Credential Manager limits secrets to 2560 bytes), which can be too small
+// for large RSA keys or certificate chains.
+type KeyStore struct {
+	service string
+}
+
+// New creates a key store for the service (usually the application name).
+func New(service string) *KeyStore {
+	return &KeyStore{
+		service: service,
+	}
+}
+
+// Get satisfies the [pemutil.KeyStore] interface.
+func (ks *KeyStore) Get(name string) (pemutil.Store, error) {
+	v, err := keyring.Get(ks.service, name)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	s, err := pemutil.DecodeBytes([]byte(v))
+	if err != nil {
+		return nil, err
+	}
+	s.AddPublicKeys()
+	return s, nil
+}
+
+// Put satisfies the [pemutil.KeyStore] interface.
+func (ks *KeyStore) Put(name string, s pemutil.Store) error {
+	buf, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	return convertErr(keyring.Set(ks.service, name, string(buf)))
+}
+
+// Delete satisfies the [pemutil.KeyStore] interface.
+func (ks *KeyStore) Delete(name string) error {
+	return convertErr(keyring.Delete(ks.service, name))
+}
+
+// convertErr converts credential store not found errors to
+// [pemutil.ErrKeyNotFound].
+func convertErr(err error) error {
+	if errors.Is(err, keyring.ErrNotFound) {
+		return pemutil.ErrKeyNotFound
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package keychain
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/kenshaw/pemutil"
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyStore(t *testing.T) {
+	keyring.MockInit()
+	ks := New("pemutil-test")
+	if _, err := ks.Get("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got: %v", err)
+	}
+	s, err := pemutil.GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := ks.Put("key", s); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z, err := ks.Get("key")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !pemutil.EqualKeys(s[pemutil.ECPrivateKey], z[pemutil.ECPrivateKey]) {
+		t.Errorf("expected keys to be equal")
+	}
+	if err := ks.Delete("key"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ks.Get("key"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got: %v", err)
+	}
+}
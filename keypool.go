@@ -0,0 +1,181 @@
+package pemutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrPoolClosed is the error returned by [KeyPool.Get] when the pool has been
+// closed.
+var ErrPoolClosed = errors.New("key pool closed")
+
+// KeyPool is a pool of pre-generated keysets, generated in the background and
+// handed out on demand (see [GeneratePool]).
+type KeyPool struct {
+	gen    func(context.Context) (Store, error)
+	keys   chan Store
+	errs   chan error
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// GeneratePool creates a pool of n pre-generated keysets for the algorithm,
+// generated in the background using the key generation options. The
+// algorithm is one of the supported algorithm names (see
+// [SupportedAlgorithms]), optionally followed by a colon and the key size
+// (for "sym" and "rsa") or curve name (for "ecc"), such as "rsa:3072" or
+// "ecc:P-384". The default key sizes are 32 bytes for "sym" and 2048 bits for
+// "rsa", and the default curve is P-256.
+//
+// Keysets are generated until the pool contains n keysets, and are replaced
+// as they are handed out by [KeyPool.Get]. Generation stops when the context
+// is canceled or the pool is closed.
+func GeneratePool(ctx context.Context, alg string, opts []GenerateOption, n int) (*KeyPool, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid key pool size %d", n)
+	}
+	gen, err := keyGenerator(alg, opts)
+	if err != nil {
+		return nil, err
+	}
+	p := &KeyPool{
+		gen:  gen,
+		keys: make(chan Store, n),
+		errs: make(chan error, 1),
+	}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.run()
+	}
+	return p, nil
+}
+
+// run generates keysets until the pool is closed.
+func (p *KeyPool) run() {
+	defer p.wg.Done()
+	for {
+		s, err := p.gen(p.ctx)
+		if p.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			select {
+			case p.errs <- err:
+			case <-p.ctx.Done():
+				return
+			}
+			continue
+		}
+		select {
+		case p.keys <- s:
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// Get returns a pre-generated keyset from the pool, waiting for one to be
+// generated when the pool is empty. Returns [ErrPoolClosed] when the pool
+// has been closed, or the key generation error when key generation failed.
+func (p *KeyPool) Get(ctx context.Context) (Store, error) {
+	select {
+	case s := <-p.keys:
+		return s, nil
+	default:
+	}
+	select {
+	case s := <-p.keys:
+		return s, nil
+	case err := <-p.errs:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.ctx.Done():
+		return nil, ErrPoolClosed
+	}
+}
+
+// Len returns the number of pre-generated keysets in the pool.
+func (p *KeyPool) Len() int {
+	return len(p.keys)
+}
+
+// Close stops generating keysets, discarding any pre-generated keysets.
+//
+// Note: an in-progress RSA key generation is not interrupted, and continues in
+// the background until the key has been generated (see
+// [GenerateRSAKeySetContext]).
+func (p *KeyPool) Close() error {
+	p.cancel()
+	p.wg.Wait()
+	for {
+		select {
+		case <-p.keys:
+		default:
+			return nil
+		}
+	}
+}
+
+// keyGenerator returns a key generator for the algorithm.
+func keyGenerator(alg string, opts []GenerateOption) (func(context.Context) (Store, error), error) {
+	name, param, _ := strings.Cut(alg, ":")
+	size := func(def int) (int, error) {
+		if param == "" {
+			return def, nil
+		}
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid %s key size %q", name, param)
+		}
+		return n, nil
+	}
+	switch name {
+	case "sym":
+		keyLen, err := size(32)
+		if err != nil {
+			return nil, err
+		}
+		return func(context.Context) (Store, error) {
+			return GenerateSymmetricKeySet(keyLen, opts...)
+		}, nil
+	case "rsa":
+		bitLen, err := size(2048)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) (Store, error) {
+			return GenerateRSAKeySetContext(ctx, bitLen, opts...)
+		}, nil
+	case "ecc":
+		if param == "" {
+			param = "P-256"
+		}
+		curve, err := CurveByName(param)
+		if err != nil {
+			return nil, err
+		}
+		return func(context.Context) (Store, error) {
+			return GenerateECKeySet(curve)
+		}, nil
+	case "ed448":
+		if param != "" {
+			return nil, fmt.Errorf("invalid ed448 parameter %q", param)
+		}
+		return func(context.Context) (Store, error) {
+			return GenerateEd448KeySet()
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", name)
+}
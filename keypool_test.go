@@ -0,0 +1,74 @@
+package pemutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGeneratePool(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		alg string
+		typ BlockType
+	}{
+		{"sym", PrivateKey},
+		{"sym:16", PrivateKey},
+		{"ecc", ECPrivateKey},
+		{"ecc:P-384", ECPrivateKey},
+		{"ed448", PrivateKey},
+		{"rsa:1024", RSAPrivateKey},
+	}
+	for i, test := range tests {
+		p, err := GeneratePool(ctx, test.alg, []GenerateOption{WithInsecure()}, 2)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		for j := 0; j < 3; j++ {
+			s, err := p.Get(ctx)
+			if err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+			if _, ok := s[test.typ]; !ok {
+				t.Errorf("test %d expected %s, got: %v", i, test.typ, s)
+			}
+		}
+		if err := p.Close(); err != nil {
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		}
+		if _, err := p.Get(ctx); !errors.Is(err, ErrPoolClosed) {
+			t.Errorf("test %d expected pool closed error, got: %v", i, err)
+		}
+	}
+	for i, alg := range []string{"bogus", "sym:x", "rsa:-1", "ecc:P-999", "ed448:1"} {
+		if _, err := GeneratePool(ctx, alg, nil, 1); err == nil {
+			t.Errorf("test %d expected error, got: nil", i)
+		}
+	}
+	if _, err := GeneratePool(ctx, "sym", nil, 0); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
+
+func TestGeneratePoolFill(t *testing.T) {
+	p, err := GeneratePool(context.Background(), "ecc", nil, 4)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer p.Close()
+	for start := time.Now(); p.Len() != 4; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("expected pool to fill, got: %d", p.Len())
+		}
+	}
+	// generation errors are returned
+	p, err = GeneratePool(context.Background(), "rsa:1024", nil, 1)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer p.Close()
+	if _, err := p.Get(context.Background()); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
@@ -0,0 +1,327 @@
+// Package keyserver provides a server and client for performing private key
+// operations over a unix socket, allowing a privileged loader process to hold
+// the private keys and perform signing operations for unprivileged workers
+// (such as web workers), so that workers never hold the key files.
+//
+// Access to the keys is controlled by the socket file's permissions (see
+// [Listen]).
+package keyserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// Operations.
+const (
+	opPublic = "public"
+	opSign   = "sign"
+)
+
+// request is a key server request.
+type request struct {
+	Op   string
+	Name string
+	// Digest is the digest to sign.
+	Digest []byte
+	// Hash is the hash used to create the digest.
+	Hash crypto.Hash
+	// PSS indicates RSA-PSS signing with the salt length.
+	PSS        bool
+	SaltLength int
+}
+
+// response is a key server response.
+type response struct {
+	// Store is the binary encoded public store (see
+	// [pemutil.Store.MarshalBinary]).
+	Store     []byte
+	Signature []byte
+	Err       string
+}
+
+// Server is a key server, performing private key operations for clients with
+// the private keys in its stores.
+type Server struct {
+	stores map[string]crypto.Signer
+	public map[string][]byte
+	wg     sync.WaitGroup
+}
+
+// New creates a key server for the named stores. Each [pemutil.Store] must
+// contain a private key that may be used for signing (see
+// [pemutil.Store.CheckPurpose]). Certificates and public keys in the stores
+// are sent to clients.
+func New(stores map[string]pemutil.Store) (*Server, error) {
+	srv := &Server{
+		stores: make(map[string]crypto.Signer),
+		public: make(map[string][]byte),
+	}
+	for name, s := range stores {
+		signer, ok := s.Signer()
+		if !ok {
+			return nil, fmt.Errorf("store %q does not contain a private key", name)
+		}
+		if err := s.CheckPurpose(pemutil.PurposeSigning); err != nil {
+			return nil, fmt.Errorf("store %q: %w", name, err)
+		}
+		pub := pemutil.Store{
+			pemutil.PublicKey: signer.Public(),
+		}
+		if certs := s.Certificates(); len(certs) != 0 {
+			pub[pemutil.Certificate] = certs
+		}
+		buf, err := pub.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("store %q: %w", name, err)
+		}
+		srv.stores[name], srv.public[name] = signer, buf
+	}
+	return srv, nil
+}
+
+// Listen listens on the unix socket, removing a stale socket file (ie, left
+// by a previous server that is no longer accepting connections), and setting
+// the socket file's permissions to mode (such as 0o660, to allow only the
+// workers' group to connect). Returns an error when another server is
+// listening on the socket.
+//
+// The socket file's permissions are set after the socket is created. Place
+// the socket in a directory accessible only to the server and its workers to
+// prevent other users from connecting before the permissions are set.
+func Listen(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		conn, err := net.Dial("unix", path)
+		switch {
+		case err == nil:
+			conn.Close()
+			return nil, fmt.Errorf("%s: another server is listening on the socket", path)
+		case !isRefused(err):
+			return nil, err
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Serve accepts connections on the listener, serving each connection's
+// requests until the context is closed. The listener is closed when Serve
+// returns. Returns the context's error, or the listener's error.
+func (srv *Server) Serve(ctx context.Context, l net.Listener) error {
+	defer srv.wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		srv.wg.Add(1)
+		go func() {
+			defer srv.wg.Done()
+			srv.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// serveConn serves the connection's requests.
+func (srv *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	dec, enc := gob.NewDecoder(conn), gob.NewEncoder(conn)
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		res, err := srv.handle(req)
+		if err != nil {
+			res = response{Err: err.Error()}
+		}
+		if err := enc.Encode(res); err != nil {
+			return
+		}
+	}
+}
+
+// handle handles the request.
+func (srv *Server) handle(req request) (response, error) {
+	signer, ok := srv.stores[req.Name]
+	if !ok {
+		return response{}, fmt.Errorf("unknown key %q", req.Name)
+	}
+	switch req.Op {
+	case opPublic:
+		return response{Store: srv.public[req.Name]}, nil
+	case opSign:
+		var opts crypto.SignerOpts = req.Hash
+		if req.PSS {
+			opts = &rsa.PSSOptions{SaltLength: req.SaltLength, Hash: req.Hash}
+		}
+		sig, err := signer.Sign(pemutil.Rand(), req.Digest, opts)
+		if err != nil {
+			return response{}, err
+		}
+		return response{Signature: sig}, nil
+	}
+	return response{}, fmt.Errorf("unknown operation %q", req.Op)
+}
+
+// Client is a key server client.
+type Client struct {
+	path string
+
+	mu   sync.Mutex
+	conn net.Conn
+	enc  *gob.Encoder
+	dec  *gob.Decoder
+}
+
+// NewClient creates a key server client for the unix socket. The connection
+// is made when needed, and is re-established when the server is restarted.
+// Requests are sent sequentially over the connection. Signing requests that
+// fail due to a connection error are not resent (see [Client.Load]).
+func NewClient(path string) *Client {
+	return &Client{
+		path: path,
+	}
+}
+
+// Close closes the client's connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// Load returns a [pemutil.Store] containing the certificates, public key, and
+// private key for the named key on the server. The private key is a
+// [pemutil.NonExportable] signer that performs signing operations on the
+// server. Signing returns an error when the connection fails (ie, when the
+// server was restarted), without resending the request, as the server may
+// have already signed the digest. The next signing operation uses a new
+// connection.
+func (c *Client) Load(name string) (pemutil.Store, error) {
+	res, err := c.call(request{Op: opPublic, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	var s pemutil.Store
+	if err := s.UnmarshalBinary(res.Store); err != nil {
+		return nil, err
+	}
+	pub, ok := s.PublicKey()
+	if !ok {
+		return nil, errors.New("server did not send a public key")
+	}
+	s[pemutil.PrivateKey] = pemutil.NonExportable{
+		Signer: &signer{c: c, name: name, pub: pub},
+	}
+	return s, nil
+}
+
+// call sends the request to the server, returning the response. When the
+// connection fails (ie, the server was restarted), public key requests are
+// retried once with a new connection. Signing requests are never retried, as
+// the server may have already received the request, and instead return the
+// error, with the next request using a new connection.
+func (c *Client) call(req request) (response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var res response
+	for i := 0; ; i++ {
+		if c.conn == nil {
+			conn, err := net.Dial("unix", c.path)
+			if err != nil {
+				return response{}, err
+			}
+			c.conn, c.enc, c.dec = conn, gob.NewEncoder(conn), gob.NewDecoder(conn)
+		}
+		err := c.enc.Encode(req)
+		if err == nil {
+			err = c.dec.Decode(&res)
+		}
+		if err == nil {
+			break
+		}
+		c.conn.Close()
+		c.conn = nil
+		if i != 0 || req.Op != opPublic {
+			return response{}, err
+		}
+	}
+	if res.Err != "" {
+		return response{}, errors.New(res.Err)
+	}
+	return res, nil
+}
+
+// signer is a [crypto.Signer] that signs using a key server.
+type signer struct {
+	c    *Client
+	name string
+	pub  crypto.PublicKey
+}
+
+// Public satisfies the [crypto.Signer] interface.
+func (s *signer) Public() crypto.PublicKey {
+	return s.pub
+}
+
+// Sign satisfies the [crypto.Signer] interface.
+func (s *signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	req := request{
+		Op:     opSign,
+		Name:   s.name,
+		Digest: digest,
+		Hash:   opts.HashFunc(),
+	}
+	if pss, ok := opts.(*rsa.PSSOptions); ok {
+		req.PSS, req.SaltLength = true, pss.SaltLength
+	}
+	res, err := s.c.call(req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Signature, nil
+}
@@ -0,0 +1,179 @@
+package keyserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kenshaw/pemutil"
+)
+
+func TestKeyServer(t *testing.T) {
+	ec, err := pemutil.GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ec.SelfSign(pemutil.ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rsaKey, err := pemutil.GenerateRSAKeySet(2048)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "keys.sock")
+	start := func() func() {
+		srv, err := New(map[string]pemutil.Store{"ec": ec, "rsa": rsaKey})
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		l, err := Listen(path, 0o600)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- srv.Serve(ctx, l)
+		}()
+		return func() {
+			cancel()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got: %v", err)
+			}
+		}
+	}
+	stop := start()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected mode 0600, got: %o", perm)
+	}
+	c := NewClient(path)
+	defer c.Close()
+	s, err := c.Load("ec")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := s[pemutil.PrivateKey].(pemutil.NonExportable); !ok {
+		t.Errorf("expected non-exportable private key, got: %T", s[pemutil.PrivateKey])
+	}
+	if _, err := s.TLSCertificate(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	digest := sha256.Sum256([]byte("test"))
+	signer, _ := s.Signer()
+	sig, err := signer.Sign(nil, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !ecdsa.VerifyASN1(signer.Public().(*ecdsa.PublicKey), digest[:], sig) {
+		t.Errorf("expected valid signature")
+	}
+	// restart the server, signing requests are not resent
+	stop()
+	stop = start()
+	if _, err := signer.Sign(nil, digest[:], crypto.SHA256); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	if _, err := signer.Sign(nil, digest[:], crypto.SHA256); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	// restart the server, and reconnect
+	stop()
+	stop = start()
+	defer stop()
+	s, err = c.Load("rsa")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	signer, _ = s.Signer()
+	opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+	sig, err = signer.Sign(nil, digest[:], opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := rsa.VerifyPSS(signer.Public().(*rsa.PublicKey), crypto.SHA256, digest[:], sig, opts); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if _, err := c.Load("missing"); err == nil {
+		t.Errorf("expected error")
+	}
+	// tls handshake using the remote key
+	s, err = c.Load("ec")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tc, err := s.TLSCertificate()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	clientConn, serverConn := net.Pipe()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- tls.Server(serverConn, &tls.Config{Certificates: []tls.Certificate{tc}}).Handshake()
+	}()
+	cfg := &tls.Config{ServerName: "example.com", RootCAs: s.CertPool()}
+	if err := tls.Client(clientConn, cfg).Handshake(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.sock")
+	// leave a stale socket file
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	l, err := Listen(path, 0o660)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer l.Close()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o660 {
+		t.Errorf("expected mode 0660, got: %o", perm)
+	}
+	// the socket is in use
+	if _, err := Listen(path, 0o660); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestNewPurpose(t *testing.T) {
+	s, err := pemutil.GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s.SetPurposes(pemutil.PurposeEncryption)
+	var perr *pemutil.PurposeError
+	if _, err := New(map[string]pemutil.Store{"ec": s}); !errors.As(err, &perr) {
+		t.Errorf("expected purpose error, got: %v", err)
+	}
+	s.SetPurposes(pemutil.PurposeEncryption, pemutil.PurposeSigning)
+	if _, err := New(map[string]pemutil.Store{"ec": s}); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package keyserver
+
+// isRefused returns true when the dial error is a refused connection (ie, a
+// stale socket file without a listening server). Refused connections are not
+// detected on this platform.
+func isRefused(err error) bool {
+	return false
+}
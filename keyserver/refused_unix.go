@@ -0,0 +1,14 @@
+//go:build unix
+
+package keyserver
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isRefused returns true when the dial error is a refused connection (ie, a
+// stale socket file without a listening server).
+func isRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,65 @@
+package pemutil
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeyStore is a persistent store of named [Store]s.
+//
+// Get returns an error wrapping [fs.ErrNotExist] (such as [ErrKeyNotFound])
+// when the named [Store] is not present.
+type KeyStore interface {
+	Get(name string) (Store, error)
+	Put(name string, s Store) error
+	Delete(name string) error
+}
+
+// DirKeyStore is a [KeyStore] that persists each [Store] as a PEM-encoded
+// file in a directory, with mode 0600.
+type DirKeyStore string
+
+// Get satisfies the [KeyStore] interface.
+func (dir DirKeyStore) Get(name string) (Store, error) {
+	filename, err := dir.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(filename)
+}
+
+// Put satisfies the [KeyStore] interface.
+func (dir DirKeyStore) Put(name string, s Store) error {
+	filename, err := dir.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(string(dir), 0o700); err != nil {
+		return err
+	}
+	return s.WriteFile(filename)
+}
+
+// Delete satisfies the [KeyStore] interface.
+func (dir DirKeyStore) Delete(name string) error {
+	filename, err := dir.path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
+
+// path returns the file path for the name.
+func (dir DirKeyStore) path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid key store name %q", name)
+	}
+	return filepath.Join(string(dir), name+".pem"), nil
+}
+
+// ErrKeyNotFound is the error returned by a [KeyStore] when the named [Store]
+// is not present. Wraps [fs.ErrNotExist].
+var ErrKeyNotFound = fmt.Errorf("key not found: %w", fs.ErrNotExist)
@@ -0,0 +1,39 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirKeyStore(t *testing.T) {
+	var ks KeyStore = DirKeyStore(filepath.Join(t.TempDir(), "keys"))
+	if _, err := ks.Get("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got: %v", err)
+	}
+	if _, err := ks.Get("../escape"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := ks.Put("key", s); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z, err := ks.Get("key")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !EqualKeys(s[ECPrivateKey], z[ECPrivateKey]) {
+		t.Errorf("expected keys to be equal")
+	}
+	if err := ks.Delete("key"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ks.Get("key"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package pemutil
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+// serverBundle returns the PEM-encoded full certificate chain and private key
+// in the [Store], for use by web servers.
+func (s Store) serverBundle() ([]byte, []byte, error) {
+	_, chain, err := s.fullChain()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := s.Only(PrivateKey, RSAPrivateKey, ECPrivateKey)
+	if len(keys.exportable()) == 0 {
+		return nil, nil, errors.New("private key is not exportable")
+	}
+	certBuf, err := Store{Certificate: chain}.Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBuf, err := s.Only(PrivateKey, RSAPrivateKey, ECPrivateKey, KeyAttributes).Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return certBuf, keyBuf, nil
+}
+
+// WriteHAProxyFile writes the certificate chain and private key in the
+// [Store] to filename with mode 0600, using the single file layout expected
+// by HAProxy's "crt" option: the leaf certificate, followed by the
+// intermediate certificates in chain order, followed by the private key.
+func (s Store) WriteHAProxyFile(filename string, opts ...WriteOption) error {
+	certBuf, keyBuf, err := s.serverBundle()
+	if err != nil {
+		return err
+	}
+	return WriteFile(filename, append(certBuf, keyBuf...), 0o600, opts...)
+}
+
+// WriteNginxFiles writes the certificate chain and private key in the
+// [Store] to dir, using the layout expected by nginx's "ssl_certificate" and
+// "ssl_certificate_key" directives: the leaf certificate followed by the
+// intermediate certificates in chain order to fullchain.pem with mode 0644,
+// and the private key to privkey.pem with mode 0600.
+func (s Store) WriteNginxFiles(dir string, opts ...WriteOption) error {
+	certBuf, keyBuf, err := s.serverBundle()
+	if err != nil {
+		return err
+	}
+	o := newWriteOptions(opts...)
+	if err := o.writeFile(filepath.Join(dir, "privkey.pem"), keyBuf, 0o600); err != nil {
+		return err
+	}
+	return o.writeFile(filepath.Join(dir, "fullchain.pem"), certBuf, 0o644)
+}
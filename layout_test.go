@@ -0,0 +1,95 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServerLayouts(t *testing.T) {
+	root, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := root.SelfSign(RootCATemplate("root")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	inter, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	interCert, err := root.Issue(IntermediateCATemplate("intermediate", 0), inter[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := inter.AddX509(interCert); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leaf, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leafCert, err := inter.Issue(ServerTemplate("example.com"), leaf[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// intermediate before leaf
+	if err := leaf.AddX509(interCert); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := leaf.AddX509(leafCert); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	chain, err := EncodePrimitive([]*x509.Certificate{leafCert, interCert})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	key, err := EncodePrimitive(leaf[ECPrivateKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := t.TempDir()
+	// haproxy
+	filename := filepath.Join(dir, "haproxy.pem")
+	if err := leaf.WriteHAProxyFile(filename); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkFile(t, filename, append(append([]byte(nil), chain...), key...), 0o600)
+	// nginx
+	if err := leaf.WriteNginxFiles(dir); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkFile(t, filepath.Join(dir, "fullchain.pem"), chain, 0o644)
+	checkFile(t, filepath.Join(dir, "privkey.pem"), key, 0o600)
+	// non-exportable
+	leaf.MarkNonExportable()
+	if err := leaf.WriteNginxFiles(t.TempDir()); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	// no certificate for the private key
+	if err := inter.Only(ECPrivateKey).WriteHAProxyFile(filename); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+// checkFile checks the contents and mode of filename.
+func checkFile(t *testing.T, filename string, exp []byte, mode os.FileMode) {
+	t.Helper()
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.Equal(buf, exp) {
+		t.Errorf("expected %s to contain:\n%s\ngot:\n%s", filename, exp, buf)
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if fi.Mode().Perm() != mode {
+		t.Errorf("expected %s mode %o, got: %o", filename, mode, fi.Mode().Perm())
+	}
+}
@@ -0,0 +1,123 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+)
+
+// lazyCertificates are lazily parsed certificates.
+type lazyCertificates struct {
+	mu     sync.Mutex
+	blocks []*pem.Block
+	certs  []*x509.Certificate
+	errs   []error
+}
+
+// newLazyCertificates creates lazily parsed certificates containing the
+// already parsed certificates.
+func newLazyCertificates(certs ...*x509.Certificate) *lazyCertificates {
+	l := new(lazyCertificates)
+	for _, cert := range certs {
+		l.blocks = append(l.blocks, &pem.Block{
+			Type:  Certificate.String(),
+			Bytes: cert.Raw,
+		})
+		l.certs, l.errs = append(l.certs, cert), append(l.errs, nil)
+	}
+	return l
+}
+
+// add adds a unparsed certificate block.
+func (l *lazyCertificates) add(block *pem.Block) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.blocks = append(l.blocks, block)
+	l.certs, l.errs = append(l.certs, nil), append(l.errs, nil)
+}
+
+// addCertificate adds a parsed certificate.
+func (l *lazyCertificates) addCertificate(cert *x509.Certificate) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.blocks = append(l.blocks, &pem.Block{
+		Type:  Certificate.String(),
+		Bytes: cert.Raw,
+	})
+	l.certs, l.errs = append(l.certs, cert), append(l.errs, nil)
+}
+
+// clone returns a copy of the lazily parsed certificates.
+func (l *lazyCertificates) clone() *lazyCertificates {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return &lazyCertificates{
+		blocks: append([]*pem.Block(nil), l.blocks...),
+		certs:  append([]*x509.Certificate(nil), l.certs...),
+		errs:   append([]error(nil), l.errs...),
+	}
+}
+
+// len returns the number of certificates.
+func (l *lazyCertificates) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.blocks)
+}
+
+// pemBlocks returns the certificate blocks.
+func (l *lazyCertificates) pemBlocks() []*pem.Block {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*pem.Block(nil), l.blocks...)
+}
+
+// at parses and returns the i'th certificate.
+func (l *lazyCertificates) at(i int) (*x509.Certificate, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.certs[i] == nil && l.errs[i] == nil {
+		l.certs[i], l.errs[i] = x509.ParseCertificate(l.blocks[i].Bytes)
+	}
+	return l.certs[i], l.errs[i]
+}
+
+// all parses and returns all certificates, skipping any certificate that
+// could not be parsed.
+func (l *lazyCertificates) all() ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	var err error
+	for i, n := 0, l.len(); i < n; i++ {
+		cert, cerr := l.at(i)
+		if cerr != nil {
+			if err == nil {
+				err = cerr
+			}
+			continue
+		}
+		certs = append(certs, cert)
+	}
+	return certs, err
+}
+
+// Resolve parses any lazily decoded entries in the [Store] (see
+// [WithLazyLoad]), returning the first error encountered. When all entries
+// are parsed, the lazily decoded certificates are replaced with the parsed
+// certificates, stored as a *x509.Certificate or []*x509.Certificate (see
+// [Store]).
+func (s Store) Resolve() error {
+	l, ok := s[Certificate].(*lazyCertificates)
+	if !ok {
+		return nil
+	}
+	certs, err := l.all()
+	switch {
+	case err != nil:
+		return err
+	case len(certs) == 1:
+		s[Certificate] = certs[0]
+	default:
+		s[Certificate] = certs
+	}
+	return nil
+}
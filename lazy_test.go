@@ -0,0 +1,64 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestLazyLoad(t *testing.T) {
+	buf, err := Store{Certificate: testCertificates(t, 3)}.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := DecodeBytes(buf, WithLazyLoad())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	l, ok := s[Certificate].(*lazyCertificates)
+	if !ok {
+		t.Fatalf("expected lazy certificates, got: %T", s[Certificate])
+	}
+	if _, ok := s.Certificate(); !ok {
+		t.Fatalf("expected certificate")
+	}
+	if l.certs[0] == nil || l.certs[1] != nil || l.certs[2] != nil {
+		t.Errorf("expected only first certificate to be parsed")
+	}
+	if certs := s.Certificates(); len(certs) != 3 {
+		t.Errorf("expected 3 certificates, got: %d", len(certs))
+	}
+	// encode unchanged
+	buf0, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if string(buf0) != string(buf) {
+		t.Errorf("expected encoded lazy store to equal original")
+	}
+	// resolve materializes the certificates
+	if err := s.Resolve(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if certs, ok := s[Certificate].([]*x509.Certificate); !ok || len(certs) != 3 {
+		t.Errorf("expected 3 certificates, got: %T", s[Certificate])
+	}
+	// bad certificate is reported on resolve
+	bad := append(buf, pem.EncodeToMemory(&pem.Block{
+		Type:  Certificate.String(),
+		Bytes: []byte("bad"),
+	})...)
+	if _, err := DecodeBytes(bad); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	s, err = DecodeBytes(bad, WithLazyLoad())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := s.Resolve(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if certs := s.Certificates(); len(certs) != 3 {
+		t.Errorf("expected 3 certificates, got: %d", len(certs))
+	}
+}
@@ -0,0 +1,245 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// MaxLeafValidity is the maximum validity period of a publicly trusted TLS
+// server certificate, as required by the CA/Browser Forum Baseline
+// Requirements.
+const MaxLeafValidity = 398 * 24 * time.Hour
+
+// LintSeverity is the severity of a lint finding.
+type LintSeverity int
+
+// Lint severities.
+const (
+	// LintWarning is a finding that does not violate the CA/Browser Forum
+	// Baseline Requirements, but is likely to cause problems.
+	LintWarning LintSeverity = iota
+	// LintError is a finding that violates the CA/Browser Forum Baseline
+	// Requirements.
+	LintError
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (sev LintSeverity) String() string {
+	switch sev {
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+	return fmt.Sprintf("LintSeverity(%d)", int(sev))
+}
+
+// LintFinding is a certificate lint finding.
+type LintFinding struct {
+	// Certificate is the linted certificate.
+	Certificate *x509.Certificate
+	// Severity is the finding's severity.
+	Severity LintSeverity
+	// Rule is the name of the violated rule (ie, "leaf-missing-san").
+	Rule string
+	// Message is a description of the finding.
+	Message string
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s: certificate %q: %s", f.Severity, f.Rule, f.Certificate.Subject, f.Message)
+}
+
+// Lint checks the certificate against the basic CA/Browser Forum Baseline
+// Requirements for TLS certificates, returning the findings. The
+// requirements checked are:
+//
+//   - server certificates have at least one subject alternative name, and
+//     the subject common name (if any) is one of the names
+//   - wildcards are only used as the entire leftmost label of a DNS name
+//   - server certificates are valid for at most [MaxLeafValidity]
+//   - leaf certificates have extended key usages, other than anyExtendedKeyUsage
+//   - leaf certificates do not have the certSign key usage, and CA
+//     certificates have the certSign key usage and valid basic constraints
+//   - RSA keys are at least [MinRSABitLen] bits with a public exponent of at
+//     least [DefaultRSAExponent], EC keys use the P-256, P-384, or P-521
+//     curves, and DSA keys are not used
+//   - the signature algorithm does not use MD5 or SHA-1
+//   - the serial number is positive, no more than 20 octets, and has at
+//     least 64 bits
+//
+// Certificate templates (see [ServerTemplate]) can be linted before they are
+// signed, in which case the public key, signature algorithm, and serial
+// number are checked only when set.
+func Lint(cert *x509.Certificate) []LintFinding {
+	l := &linter{cert: cert}
+	l.lintNames()
+	l.lintValidity()
+	l.lintUsage()
+	if cert.PublicKey != nil {
+		l.lintKey(cert.PublicKey)
+	}
+	switch cert.SignatureAlgorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.DSAWithSHA256, x509.ECDSAWithSHA1:
+		l.add(LintError, "weak-signature", "signed with %s", cert.SignatureAlgorithm)
+	}
+	if n := cert.SerialNumber; n != nil {
+		switch {
+		case n.Sign() <= 0:
+			l.add(LintError, "invalid-serial", "serial number is not positive")
+		case len(n.Bytes()) > 20:
+			l.add(LintError, "invalid-serial", "serial number is longer than 20 octets")
+		case n.BitLen() < 64:
+			l.add(LintWarning, "short-serial", "serial number has %d bits, expected at least 64", n.BitLen())
+		}
+	}
+	return l.findings
+}
+
+// Lint lints all certificates in the [Store] (see [Lint]), returning the
+// findings.
+func (s Store) Lint() []LintFinding {
+	var findings []LintFinding
+	for _, cert := range s.Certificates() {
+		findings = append(findings, Lint(cert)...)
+	}
+	return findings
+}
+
+// linter collects the lint findings for a certificate.
+type linter struct {
+	cert     *x509.Certificate
+	findings []LintFinding
+}
+
+// add adds a finding.
+func (l *linter) add(sev LintSeverity, rule, format string, v ...interface{}) {
+	l.findings = append(l.findings, LintFinding{
+		Certificate: l.cert,
+		Severity:    sev,
+		Rule:        rule,
+		Message:     fmt.Sprintf(format, v...),
+	})
+}
+
+// server determines if the certificate is a TLS server certificate.
+func (l *linter) server() bool {
+	return !l.cert.IsCA && (len(l.cert.ExtKeyUsage) == 0 || hasExtKeyUsage(l.cert, x509.ExtKeyUsageServerAuth))
+}
+
+// lintNames lints the subject alternative names.
+func (l *linter) lintNames() {
+	cert := l.cert
+	if l.server() {
+		if len(cert.DNSNames) == 0 && len(cert.IPAddresses) == 0 {
+			l.add(LintError, "leaf-missing-san", "no DNS name or IP address subject alternative names")
+		} else if cn := cert.Subject.CommonName; cn != "" && !containsName(cert, cn) {
+			l.add(LintError, "cn-not-in-san", "common name %q is not a subject alternative name", cn)
+		}
+	}
+	for _, name := range cert.DNSNames {
+		if i := strings.LastIndex(name, "*"); i > 0 || (i == 0 && !strings.HasPrefix(name, "*.")) {
+			l.add(LintError, "invalid-wildcard", "DNS name %q has a wildcard that is not the leftmost label", name)
+		}
+	}
+}
+
+// containsName determines if name is one of the certificate's DNS name or IP
+// address subject alternative names.
+func containsName(cert *x509.Certificate, name string) bool {
+	if ip := net.ParseIP(name); ip != nil {
+		for _, v := range cert.IPAddresses {
+			if v.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range cert.DNSNames {
+		if strings.EqualFold(v, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintValidity lints the validity period.
+func (l *linter) lintValidity() {
+	cert := l.cert
+	switch d := cert.NotAfter.Sub(cert.NotBefore); {
+	case d < 0:
+		l.add(LintError, "invalid-validity", "not after %s is before not before %s", cert.NotAfter.Format(time.RFC3339), cert.NotBefore.Format(time.RFC3339))
+	case l.server() && d > MaxLeafValidity:
+		l.add(LintError, "validity-too-long", "valid for %d days, expected at most %d", d/(24*time.Hour), MaxLeafValidity/(24*time.Hour))
+	}
+}
+
+// lintUsage lints the key usage, extended key usages, and basic constraints.
+func (l *linter) lintUsage() {
+	cert := l.cert
+	if cert.IsCA {
+		if !cert.BasicConstraintsValid {
+			l.add(LintError, "ca-basic-constraints", "CA certificate without basic constraints")
+		}
+		if cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+			l.add(LintError, "ca-missing-cert-sign", "CA certificate without certSign key usage")
+		}
+		return
+	}
+	if cert.KeyUsage&x509.KeyUsageCertSign != 0 {
+		l.add(LintError, "leaf-cert-sign", "leaf certificate with certSign key usage")
+	}
+	switch {
+	case len(cert.ExtKeyUsage) == 0 && len(cert.UnknownExtKeyUsage) == 0:
+		l.add(LintWarning, "leaf-missing-eku", "leaf certificate without extended key usages")
+	case containsExtKeyUsage(cert.ExtKeyUsage, x509.ExtKeyUsageAny):
+		l.add(LintError, "leaf-any-eku", "leaf certificate with anyExtendedKeyUsage")
+	}
+}
+
+// containsExtKeyUsage determines if usages contains the extended key usage.
+func containsExtKeyUsage(usages []x509.ExtKeyUsage, usage x509.ExtKeyUsage) bool {
+	for _, u := range usages {
+		if u == usage {
+			return true
+		}
+	}
+	return false
+}
+
+// lintKey lints the public key.
+func (l *linter) lintKey(pub crypto.PublicKey) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if n := k.N.BitLen(); n < MinRSABitLen {
+			l.add(LintError, "weak-key", "%d bit RSA key, expected at least %d", n, MinRSABitLen)
+		} else if n%8 != 0 {
+			l.add(LintError, "invalid-key", "%d bit RSA key is not a multiple of 8", n)
+		}
+		if k.E < DefaultRSAExponent || k.E%2 == 0 {
+			l.add(LintError, "weak-key", "RSA key with public exponent %d", k.E)
+		}
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			l.add(LintError, "weak-key", "EC key with curve %s", k.Params().Name)
+		}
+	case ed25519.PublicKey:
+		l.add(LintWarning, "unsupported-key", "Ed25519 key is not supported by browsers")
+	case *dsa.PublicKey:
+		l.add(LintError, "weak-key", "DSA key")
+	default:
+		l.add(LintError, "unsupported-key", "unsupported public key type %T", pub)
+	}
+}
@@ -0,0 +1,83 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"crypto/x509"
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestLint(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		tpl func() *x509.Certificate
+		exp []string
+	}{
+		{func() *x509.Certificate { return ServerTemplate("example.com", "127.0.0.1") }, nil},
+		{func() *x509.Certificate { return RootCATemplate("root") }, nil},
+		{func() *x509.Certificate { return IntermediateCATemplate("intermediate", 0) }, nil},
+		{func() *x509.Certificate { return ServerTemplate() }, []string{"leaf-missing-san"}},
+		{func() *x509.Certificate {
+			tpl := ServerTemplate("example.com")
+			tpl.Subject.CommonName = "other.example.com"
+			return tpl
+		}, []string{"cn-not-in-san"}},
+		{func() *x509.Certificate { return ServerTemplate("www.*.example.com", "*example.com", "*.example.com") }, []string{"invalid-wildcard", "invalid-wildcard"}},
+		{func() *x509.Certificate {
+			tpl := ServerTemplate("example.com")
+			tpl.NotAfter = tpl.NotBefore.Add(2 * 365 * 24 * time.Hour)
+			return tpl
+		}, []string{"validity-too-long"}},
+		{func() *x509.Certificate {
+			tpl := ServerTemplate("example.com")
+			tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+			tpl.KeyUsage |= x509.KeyUsageCertSign
+			return tpl
+		}, []string{"leaf-any-eku", "leaf-cert-sign"}},
+		{func() *x509.Certificate {
+			tpl := RootCATemplate("root")
+			tpl.BasicConstraintsValid = false
+			tpl.KeyUsage = x509.KeyUsageDigitalSignature
+			return tpl
+		}, []string{"ca-basic-constraints", "ca-missing-cert-sign"}},
+		{func() *x509.Certificate {
+			tpl := ServerTemplate("example.com")
+			tpl.SerialNumber = big.NewInt(1)
+			return tpl
+		}, []string{"short-serial"}},
+	}
+	for i, test := range tests {
+		var rules []string
+		for _, f := range Lint(test.tpl()) {
+			rules = append(rules, f.Rule)
+		}
+		sort.Strings(rules)
+		if !reflect.DeepEqual(rules, test.exp) {
+			t.Errorf("test %d expected %v, got: %v", i, test.exp, rules)
+		}
+	}
+	// signed
+	if _, err := s.SelfSign(ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if findings := s.Lint(); len(findings) != 0 {
+		t.Errorf("expected no findings, got: %v", findings)
+	}
+	weak, err := GenerateRSAKeySet(1024, WithInsecure())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := weak.SelfSign(ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	findings := weak.Lint()
+	if len(findings) != 1 || findings[0].Rule != "weak-key" || findings[0].Severity != LintError {
+		t.Errorf("expected weak-key error, got: %v", findings)
+	}
+}
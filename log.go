@@ -0,0 +1,42 @@
+package pemutil
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// WithLogger is a decode and load option to log non-sensitive events (files
+// read, block types decoded, and skipped or invalid data) to the logger.
+// Key material is never logged.
+func WithLogger(logger *slog.Logger) LoadOption {
+	return func(opts *loadOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithGenerateLogger is a key generation option to log non-sensitive events
+// (key types, sizes, and generation times) to the logger. Key material is
+// never logged.
+func WithGenerateLogger(logger *slog.Logger) GenerateOption {
+	return func(opts *generateOptions) {
+		opts.logger = logger
+	}
+}
+
+// logEvent logs the event to the logger, if not nil.
+func logEvent(logger *slog.Logger, level slog.Level, msg string, args ...interface{}) {
+	if logger != nil {
+		logger.Log(context.Background(), level, msg, args...)
+	}
+}
+
+// blockTypes returns the sorted block types in the [Store].
+func (s Store) blockTypes() []string {
+	v := make([]string, 0, len(s))
+	for typ := range s {
+		v = append(v, typ.String())
+	}
+	sort.Strings(v)
+	return v
+}
@@ -0,0 +1,40 @@
+package pemutil
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var b bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := LoadFile("testdata/ec256.pem", WithLogger(logger)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	out := b.String()
+	for _, s := range []string{"read file", "found block", "loaded file", "testdata/ec256.pem", "EC PRIVATE KEY"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected log to contain %q, got: %s", s, out)
+		}
+	}
+	b.Reset()
+	if _, err := DecodeBytes([]byte("invalid\n-----BEGIN PUBLIC KEY-----\n"), WithLogger(logger)); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if !strings.Contains(b.String(), "decode failed") {
+		t.Errorf("expected log to contain decode failure, got: %s", b.String())
+	}
+	b.Reset()
+	s, err := GenerateSymmetricKeySet(32, WithGenerateLogger(logger))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.Contains(b.String(), "length=32") {
+		t.Errorf("expected log to contain key length, got: %s", b.String())
+	}
+	if key, _, _ := s.SymmetricKey(); bytes.Contains(b.Bytes(), key) {
+		t.Errorf("expected log to not contain key material")
+	}
+}
@@ -0,0 +1,274 @@
+package pemutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestFile is the file name of a keyset directory manifest.
+const ManifestFile = "MANIFEST.json"
+
+// Manifest is a keyset directory manifest, recording the files in a keyset
+// directory, allowing the directory to be verified (ie, to check that no file
+// has been modified, removed, or added).
+type Manifest struct {
+	// Created is the manifest creation time.
+	Created time.Time `json:"created"`
+	// Files are the manifest entries for the files in the directory.
+	Files []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry is a keyset directory manifest entry for a file.
+type ManifestEntry struct {
+	// Name is the slash separated path of the file, relative to the
+	// directory.
+	Name string `json:"name"`
+	// SHA256 is the hex encoded SHA-256 hash of the file contents.
+	SHA256 string `json:"sha256"`
+	// Fingerprints are the hex encoded SHA-256 fingerprints of the keys (see
+	// [KeyFingerprint]) and certificates (see [Fingerprint]) in the file. Raw
+	// (symmetric) keys are not fingerprinted, as their fingerprint is a hash
+	// of the secret.
+	Fingerprints []string `json:"fingerprints,omitempty"`
+	// Created is the file's modification time when the manifest was created.
+	Created time.Time `json:"created"`
+	// Purpose is the intended purpose of the file.
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// NewManifest creates a manifest for the files in the keyset directory,
+// recording the intended purpose of each file in purposes (by relative file
+// name), if any. Any existing manifest file is not included.
+func NewManifest(dir string, purposes map[string]string) (*Manifest, error) {
+	m := &Manifest{
+		Created: DefaultClock().Now().UTC().Truncate(time.Second),
+	}
+	err := walkManifest(dir, func(name string, buf []byte, fi fs.FileInfo) error {
+		entry := ManifestEntry{
+			Name:    name,
+			SHA256:  sha256Hex(buf),
+			Created: fi.ModTime().UTC(),
+			Purpose: purposes[name],
+		}
+		if s, err := DecodeBytes(buf); err == nil {
+			entry.Fingerprints = storeFingerprints(s)
+		}
+		m.Files = append(m.Files, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WriteManifest creates a manifest for the files in the keyset directory (see
+// [NewManifest]), writing it to the [ManifestFile] in the directory with mode
+// 0600 using the write options (see [WriteOptions]). As the manifest contains
+// the hashes of the files in the directory, including any files containing
+// secrets, it is only readable by its owner.
+func WriteManifest(dir string, purposes map[string]string, opts ...WriteOption) error {
+	m, err := NewManifest(dir, purposes)
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(filepath.Join(dir, ManifestFile), append(buf, '\n'), 0o600, opts...)
+}
+
+// ReadManifest reads the [ManifestFile] in the keyset directory.
+func ReadManifest(dir string) (*Manifest, error) {
+	buf, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := json.Unmarshal(buf, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return m, nil
+}
+
+// VerifyManifest verifies the files in the keyset directory against the
+// [ManifestFile] in the directory, returning a [*ManifestError] when any file
+// was modified, removed, or added.
+func VerifyManifest(dir string) error {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	return m.Verify(dir)
+}
+
+// Verify verifies the files in the keyset directory against the manifest,
+// returning a [*ManifestError] when any file was modified, removed, or added.
+func (m *Manifest) Verify(dir string) error {
+	entries := make(map[string]ManifestEntry, len(m.Files))
+	for _, entry := range m.Files {
+		entries[entry.Name] = entry
+	}
+	merr := new(ManifestError)
+	err := walkManifest(dir, func(name string, buf []byte, _ fs.FileInfo) error {
+		entry, ok := entries[name]
+		switch {
+		case !ok:
+			merr.Added = append(merr.Added, name)
+		case entry.SHA256 != sha256Hex(buf):
+			merr.Modified = append(merr.Modified, name)
+		}
+		delete(entries, name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for name := range entries {
+		merr.Removed = append(merr.Removed, name)
+	}
+	sort.Strings(merr.Removed)
+	if len(merr.Modified) != 0 || len(merr.Removed) != 0 || len(merr.Added) != 0 {
+		return merr
+	}
+	return nil
+}
+
+// ManifestError is a keyset directory manifest verification error.
+type ManifestError struct {
+	// Modified are the files that were modified.
+	Modified []string
+	// Removed are the files that were removed.
+	Removed []string
+	// Added are the files that are not in the manifest.
+	Added []string
+}
+
+// Error satisfies the error interface.
+func (err *ManifestError) Error() string {
+	var v []string
+	for _, z := range []struct {
+		s     string
+		names []string
+	}{
+		{"modified", err.Modified},
+		{"removed", err.Removed},
+		{"added", err.Added},
+	} {
+		if len(z.names) != 0 {
+			v = append(v, z.s+": "+strings.Join(z.names, ", "))
+		}
+	}
+	return "manifest mismatch (" + strings.Join(v, "; ") + ")"
+}
+
+// walkManifest calls f with the relative name, contents, and file info of
+// each regular file in dir, excluding the manifest file, in lexical order.
+func walkManifest(dir string, f func(string, []byte, fs.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case !d.Type().IsRegular():
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if name = filepath.ToSlash(name); name == ManifestFile {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return f(name, buf, fi)
+	})
+}
+
+// storeFingerprints returns the hex encoded fingerprints of the keys and
+// certificates in the store.
+func storeFingerprints(s Store) []string {
+	var v []string
+	add := func(key interface{}) {
+		if fp, err := KeyFingerprint(key); err == nil {
+			if s := hex.EncodeToString(fp[:]); len(v) == 0 || v[len(v)-1] != s {
+				v = append(v, s)
+			}
+		}
+	}
+	if key, ok := s.PrivateKey(); ok {
+		switch key.(type) {
+		case []byte, *SymmetricKey:
+		default:
+			add(key)
+		}
+	}
+	if pub, ok := s.PublicKey(); ok {
+		add(pub)
+	}
+	for _, cert := range s.Certificates() {
+		fp := Fingerprint(cert)
+		v = append(v, hex.EncodeToString(fp[:]))
+	}
+	return v
+}
+
+// sha256Hex returns the hex encoded SHA-256 hash of buf.
+func sha256Hex(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadFile loads the file in the keyset directory, tagging the private key
+// with the entry's purpose, if any (see [Store.SetPurposes]). The entry's
+// purpose must be a comma separated list of key purposes (see
+// [ParsePurposes]). Returns an error when the file is not in the manifest,
+// or when the file was modified.
+func (m *Manifest) LoadFile(dir, name string, opts ...LoadOption) (Store, error) {
+	var entry *ManifestEntry
+	for i := range m.Files {
+		if m.Files[i].Name == name {
+			entry = &m.Files[i]
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%s is not in the manifest", name)
+	}
+	buf, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, err
+	}
+	if sha256Hex(buf) != entry.SHA256 {
+		return nil, fmt.Errorf("%s was modified", name)
+	}
+	s, err := DecodeBytes(buf, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Purpose != "" {
+		purposes, err := ParsePurposes(entry.Purpose)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.PrivateKey(); ok {
+			s.SetPurposes(purposes...)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,94 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SelfSign(ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := s.WriteFiles(dir, "server"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := WriteManifest(dir, map[string]string{"server-cert.pem": "tls"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(m.Files) != 3 {
+		t.Fatalf("expected 3 files, got: %d", len(m.Files))
+	}
+	// private and public key files share the same key fingerprint
+	if m.Files[0].Name != "server-cert.pem" || m.Files[0].Purpose != "tls" || len(m.Files[0].Fingerprints) != 1 {
+		t.Errorf("expected server-cert.pem with purpose and fingerprint, got: %+v", m.Files[0])
+	}
+	if m.Files[1].Fingerprints[0] != m.Files[2].Fingerprints[0] {
+		t.Errorf("expected private and public key fingerprints to match")
+	}
+	if err := VerifyManifest(dir); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fi, err := os.Stat(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected mode 0600, got: %o", perm)
+	}
+	// modify, remove, and add files
+	if err := os.WriteFile(filepath.Join(dir, "server-public.pem"), []byte("swapped"), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "server-cert.pem")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.pem"), []byte("other"), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var merr *ManifestError
+	if err := VerifyManifest(dir); !errors.As(err, &merr) {
+		t.Fatalf("expected manifest error, got: %v", err)
+	}
+	for i, test := range []struct {
+		v   []string
+		exp string
+	}{
+		{merr.Modified, "server-public.pem"},
+		{merr.Removed, "server-cert.pem"},
+		{merr.Added, "other.pem"},
+	} {
+		if len(test.v) != 1 || test.v[0] != test.exp {
+			t.Errorf("test %d expected %s, got: %v", i, test.exp, test.v)
+		}
+	}
+}
+
+func TestManifestSymmetricKey(t *testing.T) {
+	dir := t.TempDir()
+	s, err := GenerateSymmetricKeySet(32)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := s.WriteFile(filepath.Join(dir, "secret.pem")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	m, err := NewManifest(dir, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(m.Files) != 1 || len(m.Files[0].Fingerprints) != 0 {
+		t.Errorf("expected no fingerprints for symmetric key, got: %+v", m.Files)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package pemutil
+
+// mmapFile reads filename, calling f with the data. Memory mapping is not
+// supported on this platform.
+func mmapFile(filename string, f func([]byte) error) error {
+	return readFile(filename, f)
+}
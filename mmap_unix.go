@@ -0,0 +1,32 @@
+//go:build unix
+
+package pemutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile memory maps filename, calling f with the mapped data.
+func mmapFile(filename string, f func([]byte) error) error {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	fi, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	if size == 0 || int64(int(size)) != size {
+		// nothing to map, or too large to map
+		return readFile(filename, f)
+	}
+	buf, err := syscall.Mmap(int(fd.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	defer syscall.Munmap(buf)
+	return f(buf)
+}
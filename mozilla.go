@@ -0,0 +1,296 @@
+package pemutil
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrustBits are the purposes a trust anchor is trusted for.
+type TrustBits uint8
+
+// Trust bits.
+const (
+	// TrustServerAuth is the TLS server authentication ("Websites") trust
+	// bit.
+	TrustServerAuth TrustBits = 1 << iota
+	// TrustEmailProtection is the S/MIME email protection ("Email") trust
+	// bit.
+	TrustEmailProtection
+	// TrustCodeSigning is the code signing ("Code") trust bit.
+	TrustCodeSigning
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (b TrustBits) String() string {
+	var v []string
+	for _, z := range []struct {
+		bit  TrustBits
+		name string
+	}{
+		{TrustServerAuth, "server-auth"},
+		{TrustEmailProtection, "email-protection"},
+		{TrustCodeSigning, "code-signing"},
+	} {
+		if b&z.bit != 0 {
+			v = append(v, z.name)
+		}
+	}
+	if len(v) == 0 {
+		return "none"
+	}
+	return strings.Join(v, ",")
+}
+
+// TrustAnchor is a root certificate and its trust bits, as published in the
+// Mozilla root store.
+type TrustAnchor struct {
+	// Certificate is the certificate.
+	Certificate *x509.Certificate
+	// Label is the certificate's label (name).
+	Label string
+	// Trust are the purposes the certificate is trusted for.
+	Trust TrustBits
+	// ServerDistrustAfter is the time after which certificates issued for
+	// TLS server authentication are no longer trusted, if any.
+	ServerDistrustAfter time.Time
+	// EmailDistrustAfter is the time after which certificates issued for
+	// email protection are no longer trusted, if any.
+	EmailDistrustAfter time.Time
+}
+
+// TrustStore returns a [Store] containing the certificates of the trust
+// anchors trusted for all of the trust bits.
+//
+// Note: distrust after dates are not considered.
+func TrustStore(anchors []TrustAnchor, trust TrustBits) Store {
+	var certs []*x509.Certificate
+	for _, a := range anchors {
+		if a.Trust&trust == trust {
+			certs = append(certs, a.Certificate)
+		}
+	}
+	s := make(Store)
+	if len(certs) != 0 {
+		s[Certificate] = certs
+	}
+	return s
+}
+
+// ParseCertdata parses the trust anchors in the NSS certdata.txt format, as
+// used by the Mozilla root store. Certificates are returned in order, with
+// the trust bits of the corresponding trust objects. Trust objects without a
+// certificate (explicit distrust entries) are ignored.
+func ParseCertdata(r io.Reader) ([]TrustAnchor, error) {
+	objs, err := parseCertdataObjects(r)
+	if err != nil {
+		return nil, err
+	}
+	// index trust objects by issuer and serial
+	trust := make(map[string]map[string]string)
+	for _, obj := range objs {
+		if obj["CKA_CLASS"] == "CKO_NSS_TRUST" {
+			trust[obj["CKA_ISSUER"]+"\x00"+obj["CKA_SERIAL_NUMBER"]] = obj
+		}
+	}
+	var anchors []TrustAnchor
+	for _, obj := range objs {
+		if obj["CKA_CLASS"] != "CKO_CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate([]byte(obj["CKA_VALUE"]))
+		if err != nil {
+			return nil, fmt.Errorf("certificate %q: %w", obj["CKA_LABEL"], err)
+		}
+		a := TrustAnchor{
+			Certificate: cert,
+			Label:       obj["CKA_LABEL"],
+		}
+		if a.ServerDistrustAfter, err = parseDistrustAfter(obj["CKA_NSS_SERVER_DISTRUST_AFTER"]); err != nil {
+			return nil, fmt.Errorf("certificate %q: %w", a.Label, err)
+		}
+		if a.EmailDistrustAfter, err = parseDistrustAfter(obj["CKA_NSS_EMAIL_DISTRUST_AFTER"]); err != nil {
+			return nil, fmt.Errorf("certificate %q: %w", a.Label, err)
+		}
+		if t, ok := trust[obj["CKA_ISSUER"]+"\x00"+obj["CKA_SERIAL_NUMBER"]]; ok {
+			for attr, bit := range map[string]TrustBits{
+				"CKA_TRUST_SERVER_AUTH":      TrustServerAuth,
+				"CKA_TRUST_EMAIL_PROTECTION": TrustEmailProtection,
+				"CKA_TRUST_CODE_SIGNING":     TrustCodeSigning,
+			} {
+				if t[attr] == "CKT_NSS_TRUSTED_DELEGATOR" {
+					a.Trust |= bit
+				}
+			}
+		}
+		anchors = append(anchors, a)
+	}
+	if len(anchors) == 0 {
+		return nil, errors.New("no certificates")
+	}
+	return anchors, nil
+}
+
+// parseCertdataObjects parses the objects in NSS certdata.txt formatted
+// data, returning the attribute values for each object. MULTILINE_OCTAL
+// values are decoded, UTF8 values are unquoted, and other values are
+// returned as-is.
+func parseCertdataObjects(r io.Reader) ([]map[string]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	var objs []map[string]string
+	var line int
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") || s == "BEGINDATA" {
+			continue
+		}
+		fields := strings.SplitN(s, " ", 3)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: invalid attribute", line)
+		}
+		name, typ, value := fields[0], fields[1], ""
+		if len(fields) == 3 {
+			value = fields[2]
+		}
+		if name == "CKA_CLASS" {
+			objs = append(objs, make(map[string]string))
+		}
+		if len(objs) == 0 {
+			return nil, fmt.Errorf("line %d: attribute %s outside of object", line, name)
+		}
+		switch typ {
+		case "MULTILINE_OCTAL":
+			var buf bytes.Buffer
+			for {
+				if !sc.Scan() {
+					return nil, fmt.Errorf("line %d: unterminated %s value", line, name)
+				}
+				line++
+				s := strings.TrimSpace(sc.Text())
+				if s == "END" {
+					break
+				}
+				for _, oct := range strings.Split(s, `\`)[1:] {
+					b, err := strconv.ParseUint(oct, 8, 8)
+					if err != nil {
+						return nil, fmt.Errorf("line %d: invalid octal value %q", line, oct)
+					}
+					buf.WriteByte(byte(b))
+				}
+			}
+			value = buf.String()
+		case "UTF8":
+			var err error
+			if value, err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s value", line, name)
+			}
+		}
+		objs[len(objs)-1][name] = value
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// parseDistrustAfter parses a NSS distrust after value (a UTCTime, or
+// CK_FALSE).
+func parseDistrustAfter(value string) (time.Time, error) {
+	if value == "" || value == "CK_FALSE" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("060102150405Z0700", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid distrust after value %q", value)
+	}
+	return t, nil
+}
+
+// ParseCCADBReport parses the trust anchors in the CCADB
+// IncludedCACertificateReportPEMCSV report format, as published by the
+// Common CA Database for the Mozilla root store.
+func ParseCCADBReport(r io.Reader) ([]TrustAnchor, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := cols["PEM Info"]; !ok {
+		return nil, errors.New("missing PEM Info column")
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	var anchors []TrustAnchor
+	for n := 1; ; n++ {
+		rec, err := cr.Read()
+		switch {
+		case errors.Is(err, io.EOF):
+			if len(anchors) == 0 {
+				return nil, errors.New("no certificates")
+			}
+			return anchors, nil
+		case err != nil:
+			return nil, err
+		}
+		s, err := DecodeBytes([]byte(strings.Trim(field(rec, "PEM Info"), "'")))
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", n, err)
+		}
+		cert, ok := s.Certificate()
+		if !ok {
+			return nil, fmt.Errorf("record %d: no certificate", n)
+		}
+		a := TrustAnchor{
+			Certificate: cert,
+			Label:       field(rec, "Common Name or Certificate Name"),
+		}
+		for _, bit := range strings.Split(field(rec, "Trust Bits"), ";") {
+			switch strings.TrimSpace(bit) {
+			case "Websites":
+				a.Trust |= TrustServerAuth
+			case "Email":
+				a.Trust |= TrustEmailProtection
+			case "Code":
+				a.Trust |= TrustCodeSigning
+			}
+		}
+		if a.ServerDistrustAfter, err = parseCCADBDate(field(rec, "Distrust for TLS After Date")); err != nil {
+			return nil, fmt.Errorf("record %d: %w", n, err)
+		}
+		if a.EmailDistrustAfter, err = parseCCADBDate(field(rec, "Distrust for S/MIME After Date")); err != nil {
+			return nil, fmt.Errorf("record %d: %w", n, err)
+		}
+		anchors = append(anchors, a)
+	}
+}
+
+// parseCCADBDate parses a CCADB report date.
+func parseCCADBDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{"2006.01.02", "2006-01-02", "2006/01/02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,125 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCertdata(t *testing.T) {
+	ca, err := LoadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	godaddy, _ := ca.Certificate()
+	cert := testCertificates(t, 1)[0]
+	var buf bytes.Buffer
+	buf.WriteString("# test certdata\nBEGINDATA\n")
+	writeCertdata(t, &buf, "Go Daddy Root Certificate Authority - G2", godaddy, "CK_FALSE", "CKT_NSS_TRUSTED_DELEGATOR", "CKT_NSS_TRUSTED_DELEGATOR")
+	writeCertdata(t, &buf, "Test", cert, "200630000000Z", "CKT_NSS_MUST_VERIFY_TRUST", "CKT_NSS_TRUSTED_DELEGATOR")
+	anchors, err := ParseCertdata(&buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(anchors) != 2 {
+		t.Fatalf("expected 2 anchors, got: %d", len(anchors))
+	}
+	tests := []struct {
+		label    string
+		cert     *x509.Certificate
+		trust    TrustBits
+		distrust time.Time
+	}{
+		{"Go Daddy Root Certificate Authority - G2", godaddy, TrustServerAuth | TrustEmailProtection, time.Time{}},
+		{"Test", cert, TrustEmailProtection, time.Date(2020, 6, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	for i, test := range tests {
+		a := anchors[i]
+		if a.Label != test.label || !a.Certificate.Equal(test.cert) || a.Trust != test.trust || !a.ServerDistrustAfter.Equal(test.distrust) {
+			t.Errorf("test %d expected %q %s %v, got: %q %s %v", i, test.label, test.trust, test.distrust, a.Label, a.Trust, a.ServerDistrustAfter)
+		}
+	}
+	if certs := TrustStore(anchors, TrustServerAuth).Certificates(); len(certs) != 1 || !certs[0].Equal(godaddy) {
+		t.Errorf("expected server auth trust store to contain only godaddy")
+	}
+	if certs := TrustStore(anchors, TrustEmailProtection).Certificates(); len(certs) != 2 {
+		t.Errorf("expected email protection trust store to contain 2 certificates, got: %d", len(certs))
+	}
+	if _, err := ParseCertdata(strings.NewReader("CKA_CLASS CK_OBJECT_CLASS CKO_CERTIFICATE\nCKA_VALUE MULTILINE_OCTAL\n\\060\n")); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestParseCCADBReport(t *testing.T) {
+	ca, err := LoadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	godaddy, _ := ca.Certificate()
+	pem, err := EncodePrimitive(godaddy)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"\ufeffOwner", "Common Name or Certificate Name", "Trust Bits", "Distrust for TLS After Date", "PEM Info"})
+	_ = w.Write([]string{"GoDaddy", "Go Daddy Root Certificate Authority - G2", "Email;Websites", "2024.11.30", "'" + string(pem) + "'"})
+	w.Flush()
+	anchors, err := ParseCCADBReport(&buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(anchors) != 1 {
+		t.Fatalf("expected 1 anchor, got: %d", len(anchors))
+	}
+	a := anchors[0]
+	if a.Label != "Go Daddy Root Certificate Authority - G2" || !a.Certificate.Equal(godaddy) {
+		t.Errorf("expected godaddy, got: %q", a.Label)
+	}
+	if a.Trust != TrustServerAuth|TrustEmailProtection {
+		t.Errorf("expected server-auth,email-protection, got: %s", a.Trust)
+	}
+	if exp := time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC); !a.ServerDistrustAfter.Equal(exp) {
+		t.Errorf("expected %v, got: %v", exp, a.ServerDistrustAfter)
+	}
+}
+
+// writeCertdata writes the certificate and trust objects for the
+// certificate in the NSS certdata.txt format.
+func writeCertdata(t *testing.T, buf *bytes.Buffer, label string, cert *x509.Certificate, distrust, server, email string) {
+	t.Helper()
+	serial, err := asn1.Marshal(cert.SerialNumber)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	octal := func(name string, b []byte) {
+		fmt.Fprintf(buf, "%s MULTILINE_OCTAL\n", name)
+		for len(b) > 0 {
+			n := min(16, len(b))
+			for _, c := range b[:n] {
+				fmt.Fprintf(buf, "\\%03o", c)
+			}
+			buf.WriteString("\n")
+			b = b[n:]
+		}
+		buf.WriteString("END\n")
+	}
+	fmt.Fprintf(buf, "\nCKA_CLASS CK_OBJECT_CLASS CKO_CERTIFICATE\nCKA_TOKEN CK_BBOOL CK_TRUE\nCKA_LABEL UTF8 %q\n", label)
+	octal("CKA_ISSUER", cert.RawIssuer)
+	octal("CKA_SERIAL_NUMBER", serial)
+	octal("CKA_VALUE", cert.Raw)
+	if distrust == "CK_FALSE" {
+		buf.WriteString("CKA_NSS_SERVER_DISTRUST_AFTER CK_BBOOL CK_FALSE\n")
+	} else {
+		octal("CKA_NSS_SERVER_DISTRUST_AFTER", []byte(distrust))
+	}
+	fmt.Fprintf(buf, "\n# Trust for %q\nCKA_CLASS CK_OBJECT_CLASS CKO_NSS_TRUST\nCKA_LABEL UTF8 %q\n", label, label)
+	octal("CKA_ISSUER", cert.RawIssuer)
+	octal("CKA_SERIAL_NUMBER", serial)
+	fmt.Fprintf(buf, "CKA_TRUST_SERVER_AUTH CK_TRUST %s\nCKA_TRUST_EMAIL_PROTECTION CK_TRUST %s\nCKA_TRUST_CODE_SIGNING CK_TRUST CKT_NSS_MUST_VERIFY_TRUST\n", server, email)
+}
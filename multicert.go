@@ -0,0 +1,200 @@
+package pemutil
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// MultiCertStore is a set of TLS certificates, that selects the certificate
+// for a TLS connection based on the client's requested server name (SNI).
+// Certificates can be replaced (ie, reloaded) while in use.
+//
+// Use [MultiCertStore.GetCertificate] as the [tls.Config.GetCertificate]
+// callback.
+type MultiCertStore struct {
+	files  [][]string
+	logger *slog.Logger
+	clock  Clock
+
+	mu    sync.RWMutex
+	certs []*tls.Certificate
+	mtime time.Time
+}
+
+// NewMultiCertStore creates a multi certificate store for the stores. Each
+// [Store] must contain a private key and its certificate chain.
+func NewMultiCertStore(stores ...Store) (*MultiCertStore, error) {
+	m := new(MultiCertStore)
+	if err := m.Set(stores...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadMultiCertStore creates a multi certificate store by loading each set of
+// files. Each set of files is loaded into a single [Store] (ie, a private key
+// file and a certificate chain file, or a single combined file).
+//
+// Use [MultiCertStore.Reload] or [MultiCertStore.Watch] to reload the files.
+func LoadMultiCertStore(files ...[]string) (*MultiCertStore, error) {
+	m := &MultiCertStore{
+		files: files,
+	}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Set replaces the certificates with the stores.
+func (m *MultiCertStore) Set(stores ...Store) error {
+	if len(stores) == 0 {
+		return errors.New("no stores")
+	}
+	certs := make([]*tls.Certificate, len(stores))
+	for i, s := range stores {
+		tc, err := s.TLSCertificate()
+		if err != nil {
+			return fmt.Errorf("store %d: %w", i, err)
+		}
+		certs[i] = &tc
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.certs = certs
+	return nil
+}
+
+// SetLogger sets the logger used to log reloads (see [WithLogger]). Must be
+// called before [MultiCertStore.Watch].
+func (m *MultiCertStore) SetLogger(logger *slog.Logger) {
+	m.logger = logger
+}
+
+// SetClock sets the clock used to determine the current time when selecting
+// certificates (default [DefaultClock]). Must be called before use.
+func (m *MultiCertStore) SetClock(clock Clock) {
+	m.clock = clock
+}
+
+// Reload reloads the files the multi certificate store was loaded from (see
+// [LoadMultiCertStore]). The current certificates are kept when any of the
+// files cannot be loaded.
+func (m *MultiCertStore) Reload() error {
+	if len(m.files) == 0 {
+		return errors.New("multi certificate store was not loaded from files")
+	}
+	mtime, err := m.modTime()
+	if err != nil {
+		return err
+	}
+	stores := make([]Store, len(m.files))
+	for i, files := range m.files {
+		s := make(Store)
+		for _, filename := range files {
+			if err := s.LoadFile(filename, WithLogger(m.logger)); err != nil {
+				return fmt.Errorf("%s: %w", filename, err)
+			}
+		}
+		stores[i] = s
+	}
+	if err := m.Set(stores...); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mtime = mtime
+	return nil
+}
+
+// Watch checks the files the multi certificate store was loaded from for
+// changes at every interval, reloading them when modified, until the context
+// is closed. The func f (if not nil) is called with any reload error.
+func (m *MultiCertStore) Watch(ctx context.Context, interval time.Duration, f func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		mtime, err := m.modTime()
+		m.mu.RLock()
+		changed := err != nil || mtime.After(m.mtime)
+		m.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err == nil {
+			logEvent(m.logger, slog.LevelInfo, "pemutil: files changed, reloading", "modified", mtime)
+			err = m.Reload()
+		}
+		if err != nil {
+			logEvent(m.logger, slog.LevelError, "pemutil: reload failed", "error", err)
+			if f != nil {
+				f(err)
+			}
+		}
+	}
+}
+
+// modTime returns the latest modification time of the files.
+func (m *MultiCertStore) modTime() (time.Time, error) {
+	var mtime time.Time
+	for _, files := range m.files {
+		for _, filename := range files {
+			fi, err := os.Stat(filename)
+			if err != nil {
+				return time.Time{}, err
+			}
+			if fi.ModTime().After(mtime) {
+				mtime = fi.ModTime()
+			}
+		}
+	}
+	return mtime, nil
+}
+
+// GetCertificate returns the best certificate for the client hello's
+// requested server name (see [Store.CertificateFor]), preferring
+// certificates the client supports. When the client did not request a server
+// name, the first certificate is returned.
+//
+// Satisfies the [tls.Config.GetCertificate] callback.
+func (m *MultiCertStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if hello.ServerName == "" {
+		return m.certs[0], nil
+	}
+	clock := m.clock
+	if clock == nil {
+		clock = DefaultClock()
+	}
+	now := clock.Now()
+	var best *tls.Certificate
+	bestScore := -1
+	for _, tc := range m.certs {
+		score := hostnameScore(tc.Leaf, hello.ServerName, now)
+		if score == -1 {
+			continue
+		}
+		if hello.SupportsCertificate(tc) == nil {
+			score += 4
+		}
+		if score > bestScore || (score == bestScore && tc.Leaf.NotAfter.After(best.Leaf.NotAfter)) {
+			best, bestScore = tc, score
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no certificate for %q", hello.ServerName)
+	}
+	return best, nil
+}
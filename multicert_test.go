@@ -0,0 +1,64 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"crypto/tls"
+	"path/filepath"
+	"testing"
+)
+
+func TestMultiCertStore(t *testing.T) {
+	dir := t.TempDir()
+	gen := func(host string) Store {
+		s, err := GenerateECKeySet(elliptic.P256())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if _, err := s.SelfSign(ServerTemplate(host)); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if err := s.WriteFile(filepath.Join(dir, host+".pem")); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return s
+	}
+	a, b := gen("a.example.com"), gen("*.example.com")
+	m, err := LoadMultiCertStore(
+		[]string{filepath.Join(dir, "a.example.com.pem")},
+		[]string{filepath.Join(dir, "*.example.com.pem")},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	check := func(s Store, names ...string) {
+		t.Helper()
+		cert, _ := s.Certificate()
+		for _, name := range names {
+			tc, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: name})
+			if err != nil {
+				t.Fatalf("%s expected no error, got: %v", name, err)
+			}
+			if !tc.Leaf.Equal(cert) {
+				t.Errorf("%s expected %s, got: %s", name, cert.Subject, tc.Leaf.Subject)
+			}
+		}
+	}
+	check(a, "a.example.com", "")
+	check(b, "b.example.com")
+	if _, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.org"}); err == nil {
+		t.Errorf("expected error")
+	}
+	// reload
+	a = gen("a.example.com")
+	if err := m.Reload(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	check(a, "a.example.com")
+	// set
+	c := gen("c.example.com")
+	if err := m.Set(c, b); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	check(c, "c.example.com", "")
+	check(b, "a.example.com")
+}
@@ -0,0 +1,50 @@
+package pemutil
+
+import (
+	"crypto"
+)
+
+// NonExportable is a private key that is never serialized, such as a signer
+// backed by a KMS or HSM, or a local private key that must not be written
+// out.
+//
+// Non-exportable private keys are skipped by [Store.Bytes], [Store.Encode],
+// [Store.WriteFile], [Store.WriteFiles], and [Store.Export], and are refused by
+// [Store.EncryptedBytes], but otherwise remain usable: [Store.Signer] and
+// [Store.PrivateKey] return the [NonExportable], while the typed accessors
+// (such as [Store.RSAPrivateKey] and [Store.ECPrivateKey]), signing,
+// decryption, and [Import] use the wrapped private key.
+type NonExportable struct {
+	crypto.Signer
+}
+
+// MarkNonExportable marks the private keys in the [Store] as non-exportable
+// (see [NonExportable]).
+func (s Store) MarkNonExportable() {
+	for _, typ := range []BlockType{PrivateKey, RSAPrivateKey, ECPrivateKey} {
+		if signer, ok := s[typ].(crypto.Signer); ok {
+			if _, ok := signer.(NonExportable); !ok {
+				s[typ] = NonExportable{signer}
+			}
+		}
+	}
+}
+
+// exportable returns a [Store] without the non-exportable private keys.
+func (s Store) exportable() Store {
+	z := make(Store, len(s))
+	for typ, v := range s {
+		if _, ok := v.(NonExportable); !ok {
+			z[typ] = v
+		}
+	}
+	return z
+}
+
+// unwrapKey returns the wrapped private key of a [NonExportable], or v.
+func unwrapKey(v interface{}) interface{} {
+	if key, ok := v.(NonExportable); ok {
+		return key.Signer
+	}
+	return v
+}
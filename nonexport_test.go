@@ -0,0 +1,69 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNonExportable(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SelfSign(ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s.MarkNonExportable()
+	if _, ok := s[ECPrivateKey].(NonExportable); !ok {
+		t.Fatalf("expected non-exportable private key, got: %T", s[ECPrivateKey])
+	}
+	if _, ok := s.Signer(); !ok {
+		t.Errorf("expected signer")
+	}
+	if _, err := s.TLSCertificate(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if strings.Contains(string(buf), "PRIVATE KEY") {
+		t.Errorf("expected no private key, got:\n%s", buf)
+	}
+	dir := t.TempDir()
+	if err := s.WriteFiles(dir, "test"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test-private.pem")); !os.IsNotExist(err) {
+		t.Errorf("expected no private key file, got: %v", err)
+	}
+	if _, err := EncodePrimitive(s[ECPrivateKey]); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, err := s.Only(ECPrivateKey).Bytes(); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, err := s.EncryptedBytes([]byte("secret")); err == nil {
+		t.Errorf("expected error")
+	}
+	// typed accessors, signing, and import use the wrapped key
+	if _, ok := s.ECPrivateKey(); !ok {
+		t.Errorf("expected EC private key")
+	}
+	digest := sha256.Sum256([]byte("test"))
+	if _, err := s.Sign(digest[:], crypto.SHA256, WithDeterministic()); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	data, err := Store{Certificate: s[Certificate]}.Export(s)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := Import(s, data); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
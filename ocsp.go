@@ -0,0 +1,61 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// OCSPResponderTemplate returns a delegated OCSP responder certificate
+// template for the common name.
+func OCSPResponderTemplate(commonName string) *x509.Certificate {
+	tpl := newTemplate(commonName, LeafValidity)
+	tpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning}
+	return tpl
+}
+
+// SignOCSPResponse creates a DER encoded OCSP response for the certificate,
+// signed by the certificate and private key in the [Store]. The certificate's
+// status is revoked when its serial number is in the revocations, and good
+// otherwise.
+//
+// When issuer is nil, the certificate in the [Store] is used as the issuer.
+// Otherwise, the certificate in the [Store] is treated as a delegated
+// responder certificate issued by issuer, and is included in the response.
+//
+// The response's this update time is the current time, as determined by the
+// clock (see [WithClock]).
+func (s Store) SignOCSPResponse(cert, issuer *x509.Certificate, revs Revocations, nextUpdate time.Time, opts ...ValidityOption) ([]byte, error) {
+	signer, ok := s.Signer()
+	if !ok {
+		return nil, errors.New("store does not contain a private key")
+	}
+	responder, ok := s.Certificate()
+	if !ok {
+		return nil, errors.New("store does not contain a certificate")
+	}
+	now := newValidityOptions(opts...).clock.Now().UTC()
+	tpl := ocsp.Response{
+		Status:       ocsp.Good,
+		SerialNumber: cert.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   nextUpdate,
+	}
+	for _, entry := range revs {
+		if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			tpl.Status = ocsp.Revoked
+			tpl.RevokedAt = entry.RevocationTime
+			tpl.RevocationReason = entry.ReasonCode
+			break
+		}
+	}
+	if issuer == nil {
+		issuer = responder
+	} else if !responder.Equal(issuer) {
+		tpl.Certificate = responder
+	}
+	return ocsp.CreateResponse(issuer, responder, tpl, signer)
+}
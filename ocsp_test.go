@@ -0,0 +1,103 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestSignOCSPResponse(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	caCert, err := ca.SelfSign(RootCATemplate("root"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// delegated responder
+	responder, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	responderCert, err := ca.Issue(OCSPResponderTemplate("ocsp"), responder[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	responder.addCertificate(responderCert)
+	good, err := ca.Issue(ServerTemplate("good.example.com"), ca[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	revoked, err := ca.Issue(ServerTemplate("revoked.example.com"), ca[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var revs Revocations
+	revs.Revoke(revoked.SerialNumber, ReasonKeyCompromise)
+	tests := []struct {
+		s      Store
+		issuer bool
+		status int
+	}{
+		{ca, false, ocsp.Good},
+		{ca, false, ocsp.Revoked},
+		{responder, true, ocsp.Good},
+		{responder, true, ocsp.Revoked},
+	}
+	for i, test := range tests {
+		cert, issuer := good, caCert
+		if test.status == ocsp.Revoked {
+			cert = revoked
+		}
+		if !test.issuer {
+			issuer = nil
+		}
+		buf, err := test.s.SignOCSPResponse(cert, issuer, revs, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		resp, err := ocsp.ParseResponseForCert(buf, cert, caCert)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if resp.Status != test.status {
+			t.Errorf("test %d expected status %d, got: %d", i, test.status, resp.Status)
+		}
+		if test.status == ocsp.Revoked && resp.RevocationReason != ReasonKeyCompromise {
+			t.Errorf("test %d expected reason %d, got: %d", i, ReasonKeyCompromise, resp.RevocationReason)
+		}
+		if test.issuer != (resp.Certificate != nil) {
+			t.Errorf("test %d expected responder certificate %t", i, test.issuer)
+		}
+	}
+}
+
+func TestSignOCSPResponseClock(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	caCert, err := ca.SelfSign(RootCATemplate("root"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, err := ca.Issue(ServerTemplate("example.com"), ca[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	now := caCert.NotBefore.Add(time.Hour).UTC().Truncate(time.Second)
+	buf, err := ca.SignOCSPResponse(cert, nil, nil, now.Add(time.Hour), WithClock(ClockFunc(func() time.Time { return now })))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	resp, err := ocsp.ParseResponseForCert(buf, cert, caCert)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !resp.ThisUpdate.Equal(now) {
+		t.Errorf("expected this update %v, got: %v", now, resp.ThisUpdate)
+	}
+}
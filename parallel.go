@@ -0,0 +1,34 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+)
+
+// parseCertificates parses the certificate blocks using a pool of workers,
+// returning the certificates and any parse errors in the same order as the
+// blocks.
+func parseCertificates(blocks []*pem.Block, workers int) ([]*x509.Certificate, []error) {
+	certs, errs := make([]*x509.Certificate, len(blocks)), make([]error, len(blocks))
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range ch {
+				certs[j], errs[j] = x509.ParseCertificate(blocks[j].Bytes)
+			}
+		}()
+	}
+	for i := range blocks {
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+	return certs, errs
+}
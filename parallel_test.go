@@ -0,0 +1,44 @@
+package pemutil
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	exp := testCertificates(t, 50)
+	buf, err := Store{Certificate: exp}.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := DecodeBytes(buf, WithParallel(4))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	certs := s.Certificates()
+	if len(certs) != len(exp) {
+		t.Fatalf("expected %d certificates, got: %d", len(exp), len(certs))
+	}
+	for i, cert := range certs {
+		if !cert.Equal(exp[i]) {
+			t.Errorf("certificate %d out of order", i)
+		}
+	}
+}
+
+func BenchmarkDecodeParallel(b *testing.B) {
+	buf, err := Store{Certificate: testCertificates(b, 1000)}.Bytes()
+	if err != nil {
+		b.Fatalf("expected no error, got: %v", err)
+	}
+	for _, workers := range []int{1, runtime.NumCPU()} {
+		b.Run(strconv.Itoa(workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := DecodeBytes(buf, WithParallel(workers)); err != nil {
+					b.Fatalf("expected no error, got: %v", err)
+				}
+			}
+		})
+	}
+}
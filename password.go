@@ -0,0 +1,150 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// PasswordProvider is the interface for password providers, used to obtain
+// the password for encrypted data (see [WithPassword], [DecodePKCS12], and
+// [DecodePPK]), and by the pemutil command's --passin and --passout flags.
+type PasswordProvider interface {
+	// Password returns the password for the encrypted data described by
+	// label (ie, the PEM block type or file name).
+	Password(label string) ([]byte, error)
+}
+
+// PasswordFunc is a [PasswordProvider] func.
+type PasswordFunc func(label string) ([]byte, error)
+
+// Password satisfies the [PasswordProvider] interface.
+func (f PasswordFunc) Password(label string) ([]byte, error) {
+	return f(label)
+}
+
+// StaticPassword returns a [PasswordProvider] for the password.
+func StaticPassword(password string) PasswordProvider {
+	return PasswordFunc(func(string) ([]byte, error) {
+		return []byte(password), nil
+	})
+}
+
+// EnvPassword returns a [PasswordProvider] that reads the password from the
+// environment variable.
+func EnvPassword(name string) PasswordProvider {
+	return PasswordFunc(func(string) ([]byte, error) {
+		password, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return []byte(password), nil
+	})
+}
+
+// FilePassword returns a [PasswordProvider] that reads the password from the
+// first line of the file.
+func FilePassword(filename string) PasswordProvider {
+	return PasswordFunc(func(string) ([]byte, error) {
+		buf, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		password, _, _ := strings.Cut(string(buf), "\n")
+		return []byte(strings.TrimSuffix(password, "\r")), nil
+	})
+}
+
+// PromptPassword returns a [PasswordProvider] that prompts for the password
+// on the terminal, without echoing the input.
+func PromptPassword() PasswordProvider {
+	return PasswordFunc(func(label string) ([]byte, error) {
+		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if err != nil {
+			tty = os.Stdin
+		} else {
+			defer tty.Close()
+		}
+		if !term.IsTerminal(int(tty.Fd())) {
+			return nil, errors.New("cannot prompt for password: not a terminal")
+		}
+		fmt.Fprintf(os.Stderr, "Enter password for %s: ", label)
+		defer fmt.Fprintln(os.Stderr)
+		return term.ReadPassword(int(tty.Fd()))
+	})
+}
+
+// ParsePasswordSource parses a password source, in the form used by OpenSSL,
+// returning a [PasswordProvider]:
+//
+//	pass:<password> -- the password
+//	env:<var>       -- the environment variable
+//	file:<path>     -- the first line of the file
+//	prompt          -- prompt on the terminal
+func ParsePasswordSource(source string) (PasswordProvider, error) {
+	if source == "prompt" {
+		return PromptPassword(), nil
+	}
+	typ, v, ok := strings.Cut(source, ":")
+	switch {
+	case !ok:
+	case typ == "pass":
+		return StaticPassword(v), nil
+	case typ == "env":
+		return EnvPassword(v), nil
+	case typ == "file":
+		return FilePassword(v), nil
+	}
+	return nil, fmt.Errorf("invalid password source %q", source)
+}
+
+// WithPassword is a decode and load option to decrypt encrypted private keys
+// ("ENCRYPTED PRIVATE KEY" blocks, see [DecryptPKCS8PrivateKey], legacy
+// OpenSSL encrypted PEM blocks, and PuTTY private keys, see [DecodePPK]) using
+// the password provider.
+//
+// Legacy OpenSSL encrypted PEM blocks (RFC 1423) are decrypted using the
+// deprecated [x509.DecryptPEMBlock], as the format is insecure by design (it
+// cannot detect incorrect passwords or tampering), and is only supported for
+// reading existing keys. Encrypted keys are always written as "ENCRYPTED
+// PRIVATE KEY" blocks (see [EncryptPKCS8PrivateKey]).
+func WithPassword(p PasswordProvider) LoadOption {
+	return func(opts *loadOptions) {
+		opts.password = p
+	}
+}
+
+// decryptBlock decrypts an encrypted private key block using the password
+// provider, returning the decrypted block.
+func decryptBlock(block *pem.Block, p PasswordProvider) (*pem.Block, error) {
+	switch {
+	case BlockType(block.Type) == EncryptedPrivateKey:
+		password, err := p.Password(block.Type)
+		if err != nil {
+			return nil, err
+		}
+		der, err := decryptPKCS8(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: PrivateKey.String(), Headers: purposeHeaders(block), Bytes: der}, nil
+	case x509.IsEncryptedPEMBlock(block):
+		// legacy encrypted PEM block, decrypted with the deprecated x509
+		// funcs, as the format is read only (see WithPassword)
+		password, err := p.Password(block.Type)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.DecryptPEMBlock(block, password)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: block.Type, Headers: purposeHeaders(block), Bytes: der}, nil
+	}
+	return block, nil
+}
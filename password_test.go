@@ -0,0 +1,115 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithPassword(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := s.EncryptedBytes([]byte("secret"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := DecodeBytes(buf); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, err := DecodeBytes(buf, WithPassword(StaticPassword("wrong"))); err == nil {
+		t.Errorf("expected error")
+	}
+	var labels []string
+	s0, err := DecodeBytes(buf, WithPassword(PasswordFunc(func(label string) ([]byte, error) {
+		labels = append(labels, label)
+		return []byte("secret"), nil
+	})))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(labels) != 1 || labels[0] != EncryptedPrivateKey.String() {
+		t.Errorf("expected password label %q, got: %v", EncryptedPrivateKey, labels)
+	}
+	key, ok := s0.PrivateKey()
+	if !ok || !EqualKeys(key, s[ECPrivateKey]) {
+		t.Errorf("expected decrypted private key to match")
+	}
+	// legacy openssl encrypted pem
+	if _, err := LoadFile("testdata/rsa-legacy-encrypted.key"); err == nil {
+		t.Errorf("expected error")
+	}
+	s1, err := LoadFile("testdata/rsa-legacy-encrypted.key", WithPassword(StaticPassword("secret")))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := s1.RSAPrivateKey(); !ok {
+		t.Errorf("expected RSA private key, got: %v", keys(s1))
+	}
+}
+
+func TestParsePasswordSource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(filename, []byte("file-secret\nignored\n"), 0o600); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	t.Setenv("PEMUTIL_TEST_PASSWORD", "env-secret")
+	tests := []struct {
+		source string
+		exp    string
+	}{
+		{"pass:secret", "secret"},
+		{"env:PEMUTIL_TEST_PASSWORD", "env-secret"},
+		{"file:" + filename, "file-secret"},
+		{"env:PEMUTIL_TEST_MISSING", ""},
+		{"bogus", ""},
+	}
+	for i, test := range tests {
+		p, err := ParsePasswordSource(test.source)
+		if err != nil {
+			if test.exp != "" {
+				t.Errorf("test %d expected no error, got: %v", i, err)
+			}
+			continue
+		}
+		password, err := p.Password("test")
+		switch {
+		case err != nil && test.exp != "":
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		case err == nil && string(password) != test.exp:
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, password)
+		}
+	}
+}
+
+func TestDecodePKCS12(t *testing.T) {
+	buf, err := os.ReadFile("testdata/ec.p12")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := DecodePKCS12(buf, StaticPassword("wrong")); err == nil {
+		t.Errorf("expected error")
+	}
+	fail := errors.New("fail")
+	if _, err := DecodePKCS12(buf, PasswordFunc(func(string) ([]byte, error) { return nil, fail })); !errors.Is(err, fail) {
+		t.Errorf("expected fail error, got: %v", err)
+	}
+	s, err := DecodePKCS12(buf, StaticPassword("secret"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	key, ok := s.ECPrivateKey()
+	if !ok {
+		t.Fatalf("expected EC private key, got: %v", keys(s))
+	}
+	cert, ok := s.Certificate()
+	if !ok {
+		t.Fatalf("expected certificate")
+	}
+	if !EqualKeys(cert.PublicKey, key.Public()) {
+		t.Errorf("expected certificate public key to match private key")
+	}
+}
@@ -13,7 +13,11 @@
 package pemutil
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -21,103 +25,649 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/cloudflare/circl/sign/ed448"
 )
 
+// LoadOption is a decode and load option.
+type LoadOption func(*loadOptions)
+
+// loadOptions are decode and load options.
+type loadOptions struct {
+	lazy     bool
+	mmap     bool
+	workers  int
+	strict   bool
+	salvage  func(*DecodeError)
+	verify   []ed25519.PublicKey
+	secure   bool
+	password PasswordProvider
+	logger   *slog.Logger
+	dupes    bool
+	text     bool
+	source   string
+	origins  map[string]string
+	dedup    bool
+	sources  CertificateSources
+	seen     map[[32]byte]bool
+	checksum string
+	sumsFile string
+	sigFile  string
+	sigKeys  []ed25519.PublicKey
+}
+
+// WithLazyLoad is a decode and load option to index CERTIFICATE blocks
+// without parsing them, deferring parsing of each certificate until it is
+// first accessed. Useful when loading large trust bundles where only a few
+// certificates are used.
+//
+// Lazily decoded certificates that cannot be parsed are not reported as
+// errors when decoding. Use [Store.Resolve] to check for parse errors.
+//
+// Until resolved, the [Certificate] value in the [Store] is an unexported
+// type, and cannot be type asserted as a *x509.Certificate or
+// []*x509.Certificate. Use [Store.Certificate] and [Store.Certificates] to
+// access the certificates, or call [Store.Resolve] to replace the value with
+// the parsed certificates.
+func WithLazyLoad() LoadOption {
+	return func(opts *loadOptions) {
+		opts.lazy = true
+	}
+}
+
+// WithMmap is a load option to memory map files instead of reading them into
+// memory, reducing peak memory use when loading very large files. Only the
+// decoded data is copied. Files are read normally on platforms that do not
+// support memory mapping.
+//
+// Files are also read normally when combined with the [WithChecksum],
+// [WithChecksumFile], or [WithDetachedSignature] options, as a memory mapped
+// file could be modified after its data was checked.
+func WithMmap() LoadOption {
+	return func(opts *loadOptions) {
+		opts.mmap = true
+	}
+}
+
+// WithParallel is a decode and load option to parse CERTIFICATE blocks
+// using a pool of workers. Certificates are stored in the same order as they
+// were encountered. Useful when decoding data containing thousands of
+// certificates.
+func WithParallel(workers int) LoadOption {
+	return func(opts *loadOptions) {
+		opts.workers = workers
+	}
+}
+
+// WithSalvage is a decode and load option to skip over invalid data and
+// blocks that cannot be decoded, continuing with the next BEGIN line instead
+// of returning an error. The func f (if not nil) is called with a
+// [*DecodeError] for each skipped region.
+//
+// Useful when a single corrupted entry should not invalidate a large bundle.
+func WithSalvage(f func(*DecodeError)) LoadOption {
+	return func(opts *loadOptions) {
+		if f == nil {
+			f = func(*DecodeError) {}
+		}
+		opts.salvage = f
+	}
+}
+
+// newLoadOptions builds the decode and load options.
+func newLoadOptions(opts ...LoadOption) loadOptions {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // Decode parses and decodes PEM-encoded data from buf, storing any resulting
 // crypto primitives encountered into the Store. The decoded PEM [BlockType]
 // will be used as the map key for each primitive.
-func Decode(s Store, buf []byte) error {
+//
+// PuTTY private key (PPK) data is decoded as with [DecodePPK].
+func Decode(s Store, buf []byte, opts ...LoadOption) error {
+	o := newLoadOptions(opts...)
+	rawKey := s[PrivateKey]
+	if err := o.checkIntegrity(buf); err != nil {
+		return err
+	}
+	if o.verify != nil {
+		var err error
+		if buf, err = VerifySigned(buf, o.verify...); err != nil {
+			return err
+		}
+	}
+	if isPPK(buf) {
+		if err := s.decodePPK(buf, o.password); err != nil {
+			return err
+		}
+		if o.secure {
+			secureStore(s, rawKey)
+		}
+		return nil
+	}
+	if o.strict {
+		if err := checkStrict(buf); err != nil {
+			return err
+		}
+	}
+	orig := buf
+	// fail returns the decode error, or reports it when salvaging
+	fail := func(pos, end int, err error) error {
+		de := newDecodeError(orig, pos, err)
+		if o.salvage == nil {
+			logEvent(o.logger, slog.LevelError, "pemutil: decode failed", "line", de.Line, "offset", de.Offset, "error", err)
+			return de
+		}
+		de.Length = end - pos
+		logEvent(o.logger, slog.LevelWarn, "pemutil: skipped invalid data", "line", de.Line, "offset", de.Offset, "length", de.Length, "error", err)
+		o.salvage(de)
+		return nil
+	}
 	var block *pem.Block
+	var prev int
+	var certBlocks []*pem.Block
+	var certOffsets, certEnds []int
+	// line returns the line number of pos, counting incrementally
+	var linePos, lineNo int
+	line := func(pos int) func() int {
+		return func() int {
+			lineNo += bytes.Count(orig[linePos:pos], []byte("\n"))
+			linePos = pos
+			return lineNo + 1
+		}
+	}
 	// loop over pem encoded data
 	for len(buf) > 0 {
+		pos, rest := len(orig)-len(buf), buf
 		block, buf = pem.Decode(buf)
 		if block == nil {
-			return errors.New("invalid PEM data")
+			pos += blockOffset(rest, "")
+			if pos == len(orig) {
+				break
+			}
+			// skip to next BEGIN line
+			end := len(orig)
+			if i := bytes.Index(orig[pos+1:], []byte("-----BEGIN ")); i != -1 {
+				end = pos + 1 + i
+			}
+			if err := fail(pos, end, errors.New("invalid PEM data")); err != nil {
+				return err
+			}
+			buf, prev = orig[end:], end
+			continue
+		}
+		start, end := pos+blockOffset(rest, ""), len(orig)-len(buf)
+		pos += blockOffset(rest[:len(rest)-len(buf)], block.Type)
+		if o.salvage != nil && bytes.Contains(orig[start:pos], []byte("-----BEGIN ")) {
+			// report invalid blocks skipped by pem.Decode
+			_ = fail(start, pos, errors.New("invalid PEM data"))
+		}
+		logEvent(o.logger, slog.LevelDebug, "pemutil: found block", "type", block.Type, "offset", pos)
+		text := orig[prev:pos]
+		prev = end
+		switch BlockType(block.Type) {
+		case PrivateKey, RSAPrivateKey, ECPrivateKey, EncryptedPrivateKey:
+			var err error
+			if text, err = s.decodePurposeText(text); err != nil {
+				if err := fail(pos, end, err); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if o.text {
+			s.addText(block, text)
+		}
+		if o.dupes {
+			if err := o.checkDuplicate(s, block, line(pos)()); err != nil {
+				return err
+			}
+		}
+		if o.dedup && BlockType(block.Type) == Certificate && o.isDuplicateCertificate(s, block, line(pos)) {
+			continue
+		}
+		switch {
+		case o.lazy && BlockType(block.Type) == Certificate:
+			s.addLazyCertificate(block)
+			continue
+		case o.workers > 1 && BlockType(block.Type) == Certificate:
+			certBlocks = append(certBlocks, block)
+			certOffsets, certEnds = append(certOffsets, pos), append(certEnds, end)
+			continue
+		}
+		if o.password != nil {
+			var err error
+			if block, err = decryptBlock(block, o.password); err != nil {
+				if err := fail(pos, end, err); err != nil {
+					return err
+				}
+				continue
+			}
 		}
 		if err := s.DecodeBlock(block); err != nil {
-			return err
+			if err := fail(pos, end, err); err != nil {
+				return err
+			}
+		}
+	}
+	if len(certBlocks) != 0 {
+		certs, errs := parseCertificates(certBlocks, o.workers)
+		for i, cert := range certs {
+			if errs[i] != nil {
+				if err := fail(certOffsets[i], certEnds[i], errs[i]); err != nil {
+					return err
+				}
+				continue
+			}
+			s.addCertificate(cert)
 		}
 	}
 	if len(s) == 0 {
 		return errors.New("could not decode any PEM blocks")
 	}
+	logEvent(o.logger, slog.LevelDebug, "pemutil: decoded", "types", s.blockTypes())
+	if o.secure {
+		secureStore(s, rawKey)
+	}
 	return nil
 }
 
-// DecodeBytes decodes the supplied buf into a store.
-func DecodeBytes(buf []byte) (Store, error) {
+// DecodeBytes decodes the supplied buf into a new store. Unlike [Decode], no
+// existing [Store] is modified.
+func DecodeBytes(buf []byte, opts ...LoadOption) (Store, error) {
 	s := Store{}
-	if err := Decode(s, buf); err != nil {
+	if err := Decode(s, buf, opts...); err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
-// EncodePrimitive encodes the crypto primitive p into PEM-encoded data.
-func EncodePrimitive(p interface{}) ([]byte, error) {
+// MustDecodeBytes decodes the supplied buf into a new store (see
+// [DecodeBytes]), panicking on error. Intended for initializing package
+// level variables, such as those generated by the "pemutil embed" command.
+func MustDecodeBytes(buf []byte, opts ...LoadOption) Store {
+	s, err := DecodeBytes(buf, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("pemutil: unable to decode: %v", err))
+	}
+	return s
+}
+
+// EncodePrimitive encodes the crypto primitive p into PEM-encoded data,
+// formatted using the encode options.
+func EncodePrimitive(p interface{}, opts ...EncodeOption) ([]byte, error) {
+	o, err := newEncodeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := primitiveBlocks(p)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, blocksLen(blocks)))
+	if err := o.encodeBlocks(buf, blocks); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// primitiveBlocks returns the PEM blocks for the crypto primitive p.
+func primitiveBlocks(p interface{}) ([]*pem.Block, error) {
 	var err error
 	var typ BlockType
 	var buf []byte
 	switch v := p.(type) {
 	case []byte:
 		typ, buf = PrivateKey, v
+	case *SymmetricKey:
+		return []*pem.Block{{
+			Type:    PrivateKey.String(),
+			Headers: v.headers(),
+			Bytes:   v.Key,
+		}}, nil
 	case *rsa.PrivateKey:
 		typ, buf = RSAPrivateKey, x509.MarshalPKCS1PrivateKey(v)
 	case *ecdsa.PrivateKey:
 		typ = ECPrivateKey
-		buf, err = x509.MarshalECPrivateKey(v)
+		buf, err = MarshalECPrivateKey(v)
+		if err != nil {
+			return nil, err
+		}
+	case ed448.PrivateKey:
+		typ = PrivateKey
+		buf, err = MarshalEd448PrivateKey(v)
+		if err != nil {
+			return nil, err
+		}
+	case ed448.PublicKey:
+		typ = PublicKey
+		buf, err = MarshalEd448PublicKey(v)
+		if err != nil {
+			return nil, err
+		}
+	case ed25519.PrivateKey:
+		typ = PrivateKey
+		buf, err = x509.MarshalPKCS8PrivateKey(v)
 		if err != nil {
 			return nil, err
 		}
-	case *rsa.PublicKey, *ecdsa.PublicKey:
+	case *ecdh.PrivateKey:
+		typ = PrivateKey
+		buf, err = x509.MarshalPKCS8PrivateKey(v)
+		if err != nil {
+			return nil, err
+		}
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, *ecdh.PublicKey:
 		typ = PublicKey
-		buf, err = x509.MarshalPKIXPublicKey(v)
+		buf, err = MarshalPKIXPublicKey(v)
 		if err != nil {
 			return nil, err
 		}
+	case ECHConfigList:
+		typ, buf = ECHConfig, v
+	case SessionTicketKeys:
+		blocks := make([]*pem.Block, len(v))
+		for i, key := range v {
+			blocks[i] = &pem.Block{
+				Type:    SessionTicketKey.String(),
+				Headers: key.headers(),
+				Bytes:   key.Key,
+			}
+		}
+		return blocks, nil
+	case *x509.Certificate:
+		typ, buf = Certificate, v.Raw
+	case []*x509.Certificate:
+		blocks := make([]*pem.Block, len(v))
+		for i, cert := range v {
+			blocks[i] = &pem.Block{
+				Type:  Certificate.String(),
+				Bytes: cert.Raw,
+			}
+		}
+		return blocks, nil
+	case *lazyCertificates:
+		return v.pemBlocks(), nil
+	case NonExportable:
+		return nil, errors.New("private key is not exportable")
+	case *x509.CertificateRequest:
+		typ, buf = CertificateRequest, v.Raw
+	case *x509.RevocationList:
+		typ, buf = RevocationList, v.Raw
 	default:
 		return nil, errors.New("unsupported crypto primitive")
 	}
-	// encode
-	return pem.EncodeToMemory(&pem.Block{
+	return []*pem.Block{{
 		Type:  typ.String(),
 		Bytes: buf,
-	}), nil
+	}}, nil
+}
+
+// encodeBlocks PEM-encodes the blocks to buf.
+func encodeBlocks(buf *bytes.Buffer, blocks []*pem.Block) error {
+	for _, block := range blocks {
+		if err := pem.Encode(buf, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blocksLen returns the approximate PEM-encoded length of the blocks.
+func blocksLen(blocks []*pem.Block) int {
+	var n int
+	for _, block := range blocks {
+		// base64 data with a newline every 64 chars
+		b64 := (len(block.Bytes) + 2) / 3 * 4
+		n += b64 + b64/64 + 1
+		// begin and end lines
+		n += 2*len(block.Type) + len("-----BEGIN -----\n-----END -----\n")
+		for k, v := range block.Headers {
+			n += len(k) + len(v) + 3
+		}
+		if len(block.Headers) != 0 {
+			n++
+		}
+	}
+	return n
 }
 
 // GenerateSymmetricKeySet generates a private key crypto primitive, returning
-// it as a [Store].
-func GenerateSymmetricKeySet(keyLen int) (Store, error) {
+// it as a [Store]. When the [WithTTL] option is passed, the key is stored as a
+// [*SymmetricKey] with an expiry.
+func GenerateSymmetricKeySet(keyLen int, opts ...GenerateOption) (Store, error) {
+	o := newGenerateOptions(opts...)
 	// generate random bytes
 	buf := make([]byte, keyLen)
-	c, err := rand.Read(buf)
-	if err != nil {
-		return nil, err
-	} else if c != keyLen {
-		return nil, fmt.Errorf("could not generate %d random key bits", keyLen)
+	if err := readRand(o.rand, buf); err != nil {
+		return nil, fmt.Errorf("could not generate %d random key bytes: %w", keyLen, err)
+	}
+	logEvent(o.logger, slog.LevelInfo, "pemutil: generated symmetric key", "length", keyLen)
+	if o.ttl != 0 {
+		return Store{
+			PrivateKey: NewSymmetricKey(buf, o.ttl),
+		}, nil
 	}
 	return Store{
 		PrivateKey: buf,
 	}, nil
 }
 
+// MinRSABitLen is the minimum RSA key bit length that [GenerateRSAKeySet]
+// will generate, unless the [WithInsecure] option is passed.
+const MinRSABitLen = 2048
+
+// DefaultRSAExponent is the default RSA public exponent.
+const DefaultRSAExponent = 65537
+
+// GenerateOption is a key generation option.
+type GenerateOption func(*generateOptions)
+
+// generateOptions are key generation options.
+type generateOptions struct {
+	insecure         bool
+	exponent         int
+	progress         func(time.Duration)
+	progressInterval time.Duration
+	ttl              time.Duration
+	logger           *slog.Logger
+	rand             io.Reader
+}
+
+// WithInsecure is a key generation option to allow generating keys with
+// unsafe parameters (ie, RSA keys smaller than [MinRSABitLen], or with a
+// public exponent smaller than [DefaultRSAExponent]).
+func WithInsecure() GenerateOption {
+	return func(opts *generateOptions) {
+		opts.insecure = true
+	}
+}
+
+// WithExponent is a key generation option to set the RSA public exponent
+// (default [DefaultRSAExponent]).
+func WithExponent(exponent int) GenerateOption {
+	return func(opts *generateOptions) {
+		opts.exponent = exponent
+	}
+}
+
+// WithProgress is a key generation option to set a progress func, that is
+// called with the elapsed time at every interval while a key is being
+// generated.
+func WithProgress(interval time.Duration, f func(time.Duration)) GenerateOption {
+	return func(opts *generateOptions) {
+		opts.progress, opts.progressInterval = f, interval
+	}
+}
+
+// WithTTL is a key generation option to set the time to live of a generated
+// symmetric key (see [SymmetricKey]).
+func WithTTL(ttl time.Duration) GenerateOption {
+	return func(opts *generateOptions) {
+		opts.ttl = ttl
+	}
+}
+
+// newGenerateOptions builds the key generation options.
+func newGenerateOptions(opts ...GenerateOption) generateOptions {
+	o := generateOptions{
+		exponent: DefaultRSAExponent,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.rand == nil {
+		o.rand = Rand()
+	}
+	return o
+}
+
 // GenerateRSAKeySet generates a RSA private and public key crypto primitives,
 // returning them as a [Store].
-func GenerateRSAKeySet(bitLen int) (Store, error) {
-	key, err := rsa.GenerateKey(rand.Reader, bitLen)
-	if err != nil {
+//
+// Bit lengths that are not a multiple of 8 are rounded up to the next multiple
+// of 8. Returns an error when the bit length is less than [MinRSABitLen] or
+// the public exponent is less than [DefaultRSAExponent], unless the
+// [WithInsecure] option is passed.
+func GenerateRSAKeySet(bitLen int, opts ...GenerateOption) (Store, error) {
+	return GenerateRSAKeySetContext(context.Background(), bitLen, opts...)
+}
+
+// GenerateRSAKeySetContext generates a RSA private and public key crypto
+// primitives, returning them as a [Store]. Returns the context's error if the
+// context is done before the key has been generated.
+//
+// Note: the key is generated in a separate goroutine. When the context is done
+// before the key has been generated, GenerateRSAKeySetContext returns
+// immediately, but key generation with the default exponent (using
+// [rsa.GenerateKey], which cannot be interrupted) continues in the background
+// until the key has been generated, and the key is then discarded. Key
+// generation with other exponents (see [WithExponent]) stops before the next
+// attempt to generate primes.
+//
+// See [GenerateRSAKeySet] for the bit length and exponent requirements.
+func GenerateRSAKeySetContext(ctx context.Context, bitLen int, opts ...GenerateOption) (Store, error) {
+	if bitLen <= 0 {
+		return nil, fmt.Errorf("invalid RSA key bit length %d", bitLen)
+	}
+	o := newGenerateOptions(opts...)
+	// round to next multiple of 8
+	if rem := bitLen % 8; rem != 0 {
+		bitLen += 8 - rem
+	}
+	switch {
+	case bitLen < MinRSABitLen && !o.insecure:
+		return nil, fmt.Errorf("RSA key bit length %d is less than %d (use WithInsecure to override)", bitLen, MinRSABitLen)
+	case o.exponent < 3 || o.exponent%2 == 0:
+		return nil, fmt.Errorf("invalid RSA exponent %d", o.exponent)
+	case o.exponent < DefaultRSAExponent && !o.insecure:
+		return nil, fmt.Errorf("RSA exponent %d is less than %d (use WithInsecure to override)", o.exponent, DefaultRSAExponent)
+	}
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	return Store{
-		RSAPrivateKey: key,
-		PublicKey:     key.Public(),
-	}, nil
+	type result struct {
+		key *rsa.PrivateKey
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		var res result
+		if o.exponent == DefaultRSAExponent {
+			res.key, res.err = rsa.GenerateKey(o.rand, bitLen)
+		} else {
+			res.key, res.err = generateRSAKey(ctx, o.rand, bitLen, o.exponent)
+		}
+		ch <- res
+	}()
+	// progress ticker
+	var tick <-chan time.Time
+	if o.progress != nil && o.progressInterval > 0 {
+		t := time.NewTicker(o.progressInterval)
+		defer t.Stop()
+		tick = t.C
+	}
+	start := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-tick:
+			o.progress(time.Since(start))
+		case res := <-ch:
+			if res.err != nil {
+				return nil, res.err
+			}
+			logEvent(o.logger, slog.LevelInfo, "pemutil: generated RSA key", "bits", bitLen, "exponent", o.exponent, "elapsed", time.Since(start))
+			return Store{
+				RSAPrivateKey: res.key,
+				PublicKey:     res.key.Public(),
+			}, nil
+		}
+	}
+}
+
+// generateRSAKey generates a two prime RSA private key with the public
+// exponent.
+func generateRSAKey(ctx context.Context, r io.Reader, bitLen, exponent int) (*rsa.PrivateKey, error) {
+	if bitLen < 64 {
+		return nil, fmt.Errorf("invalid RSA key bit length %d", bitLen)
+	}
+	e := big.NewInt(int64(exponent))
+	one := big.NewInt(1)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p, err := rand.Prime(r, bitLen-bitLen/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(r, bitLen/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bitLen {
+			continue
+		}
+		pm1, qm1 := new(big.Int).Sub(p, one), new(big.Int).Sub(q, one)
+		totient := new(big.Int).Mul(pm1, qm1)
+		d := new(big.Int).ModInverse(e, totient)
+		if d == nil {
+			continue
+		}
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{
+				N: n,
+				E: exponent,
+			},
+			D:      d,
+			Primes: []*big.Int{p, q},
+		}
+		if err := key.Validate(); err != nil {
+			return nil, err
+		}
+		key.Precompute()
+		return key, nil
+	}
 }
 
 // GenerateECKeySet generates a EC private and public key crypto primitives,
 // returning them as a [Store].
 func GenerateECKeySet(curve elliptic.Curve) (Store, error) {
-	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	key, err := ecdsa.GenerateKey(curve, Rand())
 	if err != nil {
 		return nil, err
 	}
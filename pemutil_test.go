@@ -1,12 +1,22 @@
 package pemutil
 
 import (
+	"context"
+	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
 	"os"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBlockTypeString(t *testing.T) {
@@ -222,3 +232,438 @@ func keys(s Store) []BlockType {
 	})
 	return k
 }
+
+func TestGenerateRSAKeySetOptions(t *testing.T) {
+	tests := []struct {
+		bitLen int
+		opts   []GenerateOption
+		expLen int
+		expE   int
+		err    bool
+	}{
+		{1024, nil, 0, 0, true},
+		{1024, []GenerateOption{WithInsecure()}, 1024, DefaultRSAExponent, false},
+		{2047, nil, 2048, DefaultRSAExponent, false},
+		{2048, []GenerateOption{WithExponent(65539)}, 2048, 65539, false},
+		{2048, []GenerateOption{WithExponent(3)}, 0, 0, true},
+		{2048, []GenerateOption{WithExponent(3), WithInsecure()}, 2048, 3, false},
+		{2048, []GenerateOption{WithExponent(65536)}, 0, 0, true},
+	}
+	for i, test := range tests {
+		s, err := GenerateRSAKeySet(test.bitLen, test.opts...)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d expected error, got nil", i)
+			continue
+		case test.err:
+			continue
+		case err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+			continue
+		}
+		key, ok := s.RSAPrivateKey()
+		if !ok {
+			t.Fatalf("test %d expected RSA private key", i)
+		}
+		if n := key.N.BitLen(); n != test.expLen {
+			t.Errorf("test %d expected bit length %d, got: %d", i, test.expLen, n)
+		}
+		if key.E != test.expE {
+			t.Errorf("test %d expected exponent %d, got: %d", i, test.expE, key.E)
+		}
+	}
+	// invalid bit lengths are not rounded
+	for i, bitLen := range []int{-4, 0} {
+		_, err := GenerateRSAKeySet(bitLen, WithInsecure())
+		if exp := fmt.Sprintf("invalid RSA key bit length %d", bitLen); err == nil || err.Error() != exp {
+			t.Errorf("test %d expected error %q, got: %v", i, exp, err)
+		}
+	}
+}
+
+func TestGenerateRSAKeySetContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GenerateRSAKeySetContext(ctx, 8192); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled error, got: %v", err)
+	}
+	var called bool
+	_, err := GenerateRSAKeySetContext(context.Background(), 2048, WithProgress(time.Nanosecond, func(time.Duration) {
+		called = true
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !called {
+		t.Errorf("expected progress func to be called")
+	}
+}
+
+func BenchmarkBytes(b *testing.B) {
+	for _, n := range []int{1, 100, 500} {
+		s, err := GenerateECKeySet(elliptic.P256())
+		if err != nil {
+			b.Fatalf("expected no error, got: %v", err)
+		}
+		s[Certificate] = testCertificates(b, n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Bytes(); err != nil {
+					b.Fatalf("expected no error, got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEncodePrimitive(b *testing.B) {
+	cert := testCertificates(b, 1)[0]
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := EncodePrimitive(cert); err != nil {
+			b.Fatalf("expected no error, got: %v", err)
+		}
+	}
+}
+
+// testCertificates generates n self-signed certificates.
+func testCertificates(tb testing.TB, n int) []*x509.Certificate {
+	tb.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatalf("expected no error, got: %v", err)
+	}
+	certs := make([]*x509.Certificate, n)
+	for i := range certs {
+		tpl := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 1)),
+			Subject:      pkix.Name{CommonName: fmt.Sprintf("test %d", i)},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(24 * time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+		if err != nil {
+			tb.Fatalf("expected no error, got: %v", err)
+		}
+		if certs[i], err = x509.ParseCertificate(der); err != nil {
+			tb.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	return certs
+}
+
+func TestLoadFileMmap(t *testing.T) {
+	for i, name := range []string{"rsa.pem", "ec256.pem", "crt-godaddy-g2.pem"} {
+		exp, err := LoadFile("testdata/" + name)
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, name, err)
+		}
+		s, err := LoadFile("testdata/"+name, WithMmap())
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, name, err)
+		}
+		expBuf, err := exp.Bytes()
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, name, err)
+		}
+		buf, err := s.Bytes()
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, name, err)
+		}
+		if string(buf) != string(expBuf) {
+			t.Errorf("test %d (%s) expected mmap loaded store to equal loaded store", i, name)
+		}
+	}
+}
+
+func TestDecodeErrorPosition(t *testing.T) {
+	buf, err := os.ReadFile("testdata/rsa.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	lines := strings.Count(string(buf), "\n")
+	tests := []struct {
+		s     string
+		line  int
+		label string
+	}{
+		{string(buf) + "\n-----BEGIN GARBAGE", lines + 2, "GARBAGE"},
+		{string(buf) + "garbage", lines + 1, "PUBLIC KEY"},
+		{string(buf) + "-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n", lines + 1, "CERTIFICATE"},
+		{string(buf) + "-----BEGIN HEADERS-----\nAAAA\n-----END HEADERS-----\n", lines + 1, "HEADERS"},
+	}
+	for i, test := range tests {
+		_, err := DecodeBytes([]byte(test.s))
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Fatalf("test %d expected *DecodeError, got: %v", i, err)
+		}
+		if de.Line != test.line {
+			t.Errorf("test %d expected line %d, got: %d (%v)", i, test.line, de.Line, err)
+		}
+		if de.Label != test.label {
+			t.Errorf("test %d expected label %q, got: %q (%v)", i, test.label, de.Label, err)
+		}
+	}
+}
+
+func TestSalvage(t *testing.T) {
+	certs := testCertificates(t, 3)
+	var bundle []byte
+	for i, cert := range certs {
+		buf, err := EncodePrimitive(cert)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		bundle = append(bundle, buf...)
+		switch i {
+		case 0:
+			bundle = append(bundle, "-----BEGIN CERTIFICATE-----\n!!!!\n-----END CERTIFICATE-----\n"...)
+		case 1:
+			bundle = append(bundle, "-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"...)
+		}
+	}
+	if _, err := DecodeBytes(bundle); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	for _, workers := range []int{0, 2} {
+		var skipped []*DecodeError
+		s, err := DecodeBytes(bundle, WithParallel(workers), WithSalvage(func(err *DecodeError) {
+			skipped = append(skipped, err)
+		}))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if n := len(s.Certificates()); n != 3 {
+			t.Errorf("expected 3 certificates, got: %d", n)
+		}
+		if len(skipped) != 2 {
+			t.Fatalf("expected 2 skipped regions, got: %d", len(skipped))
+		}
+		if exp := len("-----BEGIN CERTIFICATE-----\n!!!!\n-----END CERTIFICATE-----\n"); skipped[0].Length != exp {
+			t.Errorf("expected skipped length %d, got: %d", exp, skipped[0].Length)
+		}
+		if skipped[1].Label != "CERTIFICATE" {
+			t.Errorf("expected skipped CERTIFICATE, got: %q", skipped[1].Label)
+		}
+	}
+}
+
+func TestStoreSelective(t *testing.T) {
+	s, err := GenerateRSAKeySet(2048)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s[Certificate] = testCertificates(t, 1)[0]
+	if only := s.Only(PublicKey, Certificate); len(only) != 2 || len(s) != 3 {
+		t.Errorf("expected only 2 of 3 entries, got: %d of %d", len(only), len(s))
+	}
+	for _, st := range []Store{s, s.Only(RSAPrivateKey, Certificate)} {
+		buf, err := st.PublicBytes()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if strings.Contains(string(buf), "PRIVATE KEY") {
+			t.Errorf("expected no private key in public bytes")
+		}
+		s0, err := DecodeBytes(buf)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(s0) != 2 {
+			t.Errorf("expected public key and certificate, got: %v", keys(s0))
+		}
+	}
+	// raw key as the public key
+	if _, err := (Store{PublicKey: []byte("secret")}).PublicBytes(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	s.Remove(RSAPrivateKey, Certificate)
+	if len(s) != 1 {
+		t.Errorf("expected 1 entry after remove, got: %d", len(s))
+	}
+}
+
+func TestWriteFilesLoadPair(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s[Certificate] = testCertificates(t, 2)
+	dir := t.TempDir()
+	if err := s.WriteFiles(dir, "ec"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for name, mode := range map[string]os.FileMode{
+		"ec-private.pem": 0o600,
+		"ec-public.pem":  0o644,
+		"ec-cert.pem":    0o644,
+	} {
+		fi, err := os.Stat(path.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if fi.Mode().Perm()&mode != fi.Mode().Perm() {
+			t.Errorf("%s expected mode %o, got: %o", name, mode, fi.Mode().Perm())
+		}
+	}
+	s0, err := LoadPair(dir, "ec")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(s0) != 3 || len(s0.Certificates()) != 2 {
+		t.Errorf("expected private key, public key, and 2 certificates, got: %v", keys(s0))
+	}
+	if _, err := LoadPair(dir, "missing"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestEqualKeys(t *testing.T) {
+	a, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	c, err := GenerateEd448KeySet()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// decode to obtain distinct, but equal, values
+	buf, err := a.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	a0, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		a, b interface{}
+		exp  bool
+	}{
+		{a[ECPrivateKey], a0[ECPrivateKey], true},
+		{a[PublicKey], a0[PublicKey], true},
+		{a[ECPrivateKey], b[ECPrivateKey], false},
+		{a[PublicKey], b[PublicKey], false},
+		{a[ECPrivateKey], a[PublicKey], false},
+		{c[PrivateKey], c[PrivateKey], true},
+		{c[PublicKey], c[PublicKey], true},
+		{c[PrivateKey], a[ECPrivateKey], false},
+		{[]byte("secret"), []byte("secret"), true},
+		{[]byte("secret"), []byte("secreT"), false},
+		{[]byte("secret"), []byte("secret!"), false},
+		{[]byte("secret"), &SymmetricKey{Key: []byte("secret")}, true},
+		{&SymmetricKey{Key: []byte("secret")}, []byte("secret"), true},
+		{&SymmetricKey{Key: []byte("secret")}, &SymmetricKey{Key: []byte("secreT")}, false},
+		{[]byte("secret"), a[ECPrivateKey], false},
+		{nil, nil, false},
+	}
+	for i, test := range tests {
+		if v := EqualKeys(test.a, test.b); v != test.exp {
+			t.Errorf("test %d expected %t, got: %t", i, test.exp, v)
+		}
+	}
+}
+
+func TestCanonicalPublicKey(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	key, _ := s.ECPrivateKey()
+	pub := &key.PublicKey
+	ecdhPub, err := pub.ECDH()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	der, err := MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, err := s.SelfSign(ServerTemplate("example.com"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp, err := KeyFingerprint(pub)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, v := range []interface{}{pub, *pub, ecdhPub, der, key, cert, s} {
+		z, err := CanonicalPublicKey(v)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !EqualKeys(z, pub) {
+			t.Errorf("test %d expected equal public key", i)
+		}
+		if fp, err := KeyFingerprint(v); err != nil || fp != exp {
+			t.Errorf("test %d expected fingerprint %x, got: %x (%v)", i, exp, fp, err)
+		}
+	}
+	if _, err := CanonicalPublicKey("invalid"); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestDecodeNew(t *testing.T) {
+	certBuf, err := os.ReadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	keyBuf, err := os.ReadFile("testdata/ec256-private.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, opts := range [][]LoadOption{nil, {WithLazyLoad()}} {
+		s, err := DecodeBytes(certBuf, opts...)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		n := len(s.Certificates())
+		z, err := s.DecodeNew(append(append([]byte(nil), certBuf...), keyBuf...), opts...)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if len(s) != 1 || len(s.Certificates()) != n {
+			t.Errorf("test %d expected original store to be unmodified, got: %v", i, s.blockTypes())
+		}
+		if _, ok := z.PrivateKey(); !ok || len(z.Certificates()) != 2*n {
+			t.Errorf("test %d expected private key and %d certificates, got: %d", i, 2*n, len(z.Certificates()))
+		}
+		// a failed decode leaves the store unmodified
+		if _, err := s.DecodeNew(append(append([]byte(nil), keyBuf...), keyBuf...), opts...); err == nil {
+			t.Errorf("test %d expected error, got nil", i)
+		}
+		if _, ok := s.PrivateKey(); ok {
+			t.Errorf("test %d expected original store to be unmodified", i)
+		}
+		z, err = s.LoadFileNew("testdata/ec256-private.pem", opts...)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if _, ok := z.PrivateKey(); !ok || len(s) != 1 {
+			t.Errorf("test %d expected private key in new store only", i)
+		}
+	}
+}
+
+func TestMustDecodeBytes(t *testing.T) {
+	buf, err := os.ReadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := MustDecodeBytes(buf); len(s.Certificates()) != 1 {
+		t.Errorf("expected 1 certificate, got: %d", len(s.Certificates()))
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+	MustDecodeBytes([]byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"))
+}
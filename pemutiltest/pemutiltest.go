@@ -0,0 +1,86 @@
+// Package pemutiltest provides utilities for testing with crypto primitives
+// generated by pemutil.
+package pemutiltest
+
+import (
+	"crypto/elliptic"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kenshaw/pemutil"
+)
+
+// Hosts are the hosts the server certificate is issued for.
+var Hosts = []string{"127.0.0.1", "::1", "localhost", "example.com"}
+
+// NewTLSServer starts a TLS [httptest.Server] for the handler, using a
+// server certificate issued by a generated CA, and returns the server and a
+// [http.Client] that trusts the CA. The server is closed when the test
+// finishes.
+func NewTLSServer(t testing.TB, h http.Handler) (*httptest.Server, *http.Client) {
+	t.Helper()
+	srv, client, _ := NewTLSServerStores(t, h)
+	return srv, client
+}
+
+// NewTLSServerStores is the same as [NewTLSServer], but additionally returns
+// the generated CA [pemutil.Store] (containing the CA private key and
+// certificate), for issuing further certificates.
+func NewTLSServerStores(t testing.TB, h http.Handler) (*httptest.Server, *http.Client, pemutil.Store) {
+	t.Helper()
+	ca, err := CA()
+	if err != nil {
+		t.Fatalf("unable to generate CA: %v", err)
+	}
+	tc, err := ServerCertificate(ca, Hosts...)
+	if err != nil {
+		t.Fatalf("unable to issue server certificate: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(h)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{tc},
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs: ca.CertPool(),
+			},
+		},
+	}
+	t.Cleanup(client.CloseIdleConnections)
+	return srv, client, ca
+}
+
+// CA generates a P-256 keyset with a self-signed root CA certificate.
+func CA() (pemutil.Store, error) {
+	ca, err := pemutil.GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ca.SelfSign(pemutil.RootCATemplate("pemutiltest CA")); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// ServerCertificate generates a P-256 keyset with a server certificate for
+// the hosts issued by the CA, returning it as a TLS certificate.
+func ServerCertificate(ca pemutil.Store, hosts ...string) (tls.Certificate, error) {
+	s, err := pemutil.GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	pub, _ := s.PublicKey()
+	cert, err := ca.Issue(pemutil.ServerTemplate(hosts...), pub)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	caCert, _ := ca.Certificate()
+	s[pemutil.Certificate] = []*x509.Certificate{cert, caCert}
+	return s.TLSCertificate()
+}
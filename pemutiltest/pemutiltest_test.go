@@ -0,0 +1,29 @@
+package pemutiltest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestNewTLSServer(t *testing.T) {
+	srv, client := NewTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer res.Body.Close()
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if string(buf) != "ok" {
+		t.Errorf("expected %q, got: %q", "ok", string(buf))
+	}
+	// the root certificate is not sent
+	if res.TLS == nil || len(res.TLS.PeerCertificates) != 1 {
+		t.Errorf("expected TLS connection with leaf certificate")
+	}
+}
@@ -0,0 +1,54 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+)
+
+// SPKIPin returns the base64 encoded SHA-256 hash of the public key's PKIX
+// encoded subject public key info, as used by HTTP public key pinning (RFC
+// 7469) and Android's network security config pin sets. pub can be any
+// value accepted by [CanonicalPublicKey].
+func SPKIPin(pub crypto.PublicKey) (string, error) {
+	if cert, ok := pub.(*x509.Certificate); ok {
+		h := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		return base64.StdEncoding.EncodeToString(h[:]), nil
+	}
+	h, err := KeyFingerprint(pub)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h[:]), nil
+}
+
+// SPKIPins returns the SPKI pins (see [SPKIPin]) for the certificates and
+// public key in the [Store], in order, without duplicates. The certificate
+// chain's pins are returned first, followed by the public key's pin, when not
+// the same as a certificate's.
+func (s Store) SPKIPins() ([]string, error) {
+	var pins []string
+	seen := make(map[string]bool)
+	add := func(pub crypto.PublicKey) error {
+		pin, err := SPKIPin(pub)
+		if err != nil {
+			return err
+		}
+		if !seen[pin] {
+			pins, seen[pin] = append(pins, pin), true
+		}
+		return nil
+	}
+	for _, cert := range s.Certificates() {
+		if err := add(cert); err != nil {
+			return nil, err
+		}
+	}
+	if pub, ok := s.PublicKey(); ok {
+		if err := add(pub); err != nil {
+			return nil, err
+		}
+	}
+	return pins, nil
+}
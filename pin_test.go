@@ -0,0 +1,29 @@
+package pemutil
+
+import (
+	"testing"
+)
+
+func TestSPKIPins(t *testing.T) {
+	s, err := LoadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	const exp = "Ko8tivDrEjiY90yGasP6ZpBU4jwXvHqVvQI0GS3GNdA="
+	pins, err := s.SPKIPins()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(pins) != 1 || pins[0] != exp {
+		t.Errorf("expected [%s], got: %v", exp, pins)
+	}
+	// public key pin matches certificate pin
+	cert, _ := s.Certificate()
+	pin, err := SPKIPin(cert.PublicKey)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if pin != exp {
+		t.Errorf("expected %q, got: %q", exp, pin)
+	}
+}
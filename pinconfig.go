@@ -0,0 +1,178 @@
+package pemutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"time"
+)
+
+// PinConfigOption is a mobile pinning config option.
+type PinConfigOption func(*pinConfigOptions)
+
+// pinConfigOptions are mobile pinning config options.
+type pinConfigOptions struct {
+	subdomains bool
+	expiration time.Time
+}
+
+// WithIncludeSubdomains is a mobile pinning config option to apply the pins
+// to subdomains of the domains.
+func WithIncludeSubdomains() PinConfigOption {
+	return func(opts *pinConfigOptions) {
+		opts.subdomains = true
+	}
+}
+
+// WithPinExpiration is a mobile pinning config option to set the date after
+// which the pins are no longer enforced. Only supported by Android.
+func WithPinExpiration(expiration time.Time) PinConfigOption {
+	return func(opts *pinConfigOptions) {
+		opts.expiration = expiration
+	}
+}
+
+// newPinConfigOptions builds the mobile pinning config options.
+func newPinConfigOptions(domains []string, opts ...PinConfigOption) (pinConfigOptions, error) {
+	var o pinConfigOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(domains) == 0 {
+		return pinConfigOptions{}, errors.New("no domains")
+	}
+	return o, nil
+}
+
+// androidConfig is an Android network security config.
+type androidConfig struct {
+	XMLName      xml.Name `xml:"network-security-config"`
+	DomainConfig struct {
+		Domains []androidDomain `xml:"domain"`
+		PinSet  struct {
+			Expiration string       `xml:"expiration,attr,omitempty"`
+			Pins       []androidPin `xml:"pin"`
+		} `xml:"pin-set"`
+	} `xml:"domain-config"`
+}
+
+// androidDomain is an Android network security config domain.
+type androidDomain struct {
+	IncludeSubdomains bool   `xml:"includeSubdomains,attr"`
+	Name              string `xml:",chardata"`
+}
+
+// androidPin is an Android network security config pin.
+type androidPin struct {
+	Digest string `xml:"digest,attr"`
+	Pin    string `xml:",chardata"`
+}
+
+// AndroidNetworkSecurityConfig returns an Android network_security_config.xml
+// pinning the domains to the SPKI pins (see [Store.SPKIPins]) of the
+// certificates and public key in the [Store].
+func (s Store) AndroidNetworkSecurityConfig(domains []string, opts ...PinConfigOption) ([]byte, error) {
+	o, err := newPinConfigOptions(domains, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := s.SPKIPins()
+	if err != nil {
+		return nil, err
+	}
+	if len(pins) == 0 {
+		return nil, errors.New("store does not contain certificates or a public key")
+	}
+	var v androidConfig
+	dc := &v.DomainConfig
+	for _, domain := range domains {
+		dc.Domains = append(dc.Domains, androidDomain{o.subdomains, domain})
+	}
+	if !o.expiration.IsZero() {
+		dc.PinSet.Expiration = o.expiration.UTC().Format("2006-01-02")
+	}
+	for _, pin := range pins {
+		dc.PinSet.Pins = append(dc.PinSet.Pins, androidPin{"SHA-256", pin})
+	}
+	buf, err := xml.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(xml.Header), buf...), '\n'), nil
+}
+
+// ATSPinnedDomains returns an iOS App Transport Security "NSPinnedDomains"
+// Info.plist fragment pinning the domains to the SPKI pins (see
+// [Store.SPKIPins]) of the certificates and public key in the [Store].
+// Pins for CA certificates are listed as NSPinnedCAIdentities, and all other
+// pins as NSPinnedLeafIdentities.
+func (s Store) ATSPinnedDomains(domains []string, opts ...PinConfigOption) ([]byte, error) {
+	o, err := newPinConfigOptions(domains, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var leaf, ca []string
+	seen := make(map[string]bool)
+	for _, cert := range s.Certificates() {
+		pin, err := SPKIPin(cert)
+		if err != nil {
+			return nil, err
+		}
+		if seen[pin] {
+			continue
+		}
+		seen[pin] = true
+		if cert.IsCA {
+			ca = append(ca, pin)
+		} else {
+			leaf = append(leaf, pin)
+		}
+	}
+	if pub, ok := s.PublicKey(); ok {
+		pin, err := SPKIPin(pub)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[pin] {
+			leaf = append(leaf, pin)
+		}
+	}
+	if len(leaf) == 0 && len(ca) == 0 {
+		return nil, errors.New("store does not contain certificates or a public key")
+	}
+	var buf bytes.Buffer
+	key := func(indent, k string) {
+		buf.WriteString(indent + "<key>")
+		_ = xml.EscapeText(&buf, []byte(k))
+		buf.WriteString("</key>\n")
+	}
+	identities := func(name string, pins []string) {
+		if len(pins) == 0 {
+			return
+		}
+		key("\t\t", name)
+		buf.WriteString("\t\t<array>\n")
+		for _, pin := range pins {
+			buf.WriteString("\t\t\t<dict>\n")
+			key("\t\t\t\t", "SPKI-SHA256-BASE64")
+			buf.WriteString("\t\t\t\t<string>" + pin + "</string>\n")
+			buf.WriteString("\t\t\t</dict>\n")
+		}
+		buf.WriteString("\t\t</array>\n")
+	}
+	key("", "NSPinnedDomains")
+	buf.WriteString("<dict>\n")
+	for _, domain := range domains {
+		key("\t", domain)
+		buf.WriteString("\t<dict>\n")
+		if o.subdomains {
+			key("\t\t", "NSIncludesSubdomains")
+			buf.WriteString("\t\t<true/>\n")
+		}
+		identities("NSPinnedCAIdentities", ca)
+		identities("NSPinnedLeafIdentities", leaf)
+		buf.WriteString("\t</dict>\n")
+	}
+	buf.WriteString("</dict>\n")
+	return buf.Bytes(), nil
+}
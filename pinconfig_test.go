@@ -0,0 +1,78 @@
+package pemutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPinConfig(t *testing.T) {
+	const caPin = "Ko8tivDrEjiY90yGasP6ZpBU4jwXvHqVvQI0GS3GNdA="
+	ca, err := LoadFile("testdata/crt-godaddy-g2.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := LoadFile("testdata/ec256-private.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leaf, err := s.SelfSign(ServerTemplate("example.com"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := s.AddX509(ca.Certificates()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leafPin, err := SPKIPin(leaf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	domains := []string{"example.com", "api.example.com"}
+	// android
+	buf, err := s.AndroidNetworkSecurityConfig(domains, WithIncludeSubdomains(), WithPinExpiration(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var v androidConfig
+	if err := xml.Unmarshal(buf, &v); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := len(v.DomainConfig.Domains); n != 2 || !v.DomainConfig.Domains[1].IncludeSubdomains || v.DomainConfig.Domains[1].Name != "api.example.com" {
+		t.Errorf("expected 2 domains including subdomains, got: %+v", v.DomainConfig.Domains)
+	}
+	if exp := "2030-01-02"; v.DomainConfig.PinSet.Expiration != exp {
+		t.Errorf("expected expiration %s, got: %s", exp, v.DomainConfig.PinSet.Expiration)
+	}
+	pins := v.DomainConfig.PinSet.Pins
+	if len(pins) != 2 || pins[0].Pin != leafPin || pins[1].Pin != caPin || pins[0].Digest != "SHA-256" {
+		t.Errorf("expected leaf and ca pins, got: %+v", pins)
+	}
+	// ios
+	buf, err = s.ATSPinnedDomains(domains)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.HasPrefix(buf, []byte("<key>NSPinnedDomains</key>\n<dict>\n\t<key>example.com</key>\n")) {
+		t.Errorf("expected NSPinnedDomains fragment, got:\n%s", buf)
+	}
+	for i, exp := range []string{
+		"\t\t<key>NSPinnedCAIdentities</key>\n\t\t<array>\n\t\t\t<dict>\n\t\t\t\t<key>SPKI-SHA256-BASE64</key>\n\t\t\t\t<string>" + caPin + "</string>\n",
+		"\t\t<key>NSPinnedLeafIdentities</key>\n\t\t<array>\n\t\t\t<dict>\n\t\t\t\t<key>SPKI-SHA256-BASE64</key>\n\t\t\t\t<string>" + leafPin + "</string>\n",
+	} {
+		if n := strings.Count(string(buf), exp); n != 2 {
+			t.Errorf("test %d expected %q for each domain, got: %d", i, exp, n)
+		}
+	}
+	if bytes.Contains(buf, []byte("NSIncludesSubdomains")) {
+		t.Errorf("expected no NSIncludesSubdomains")
+	}
+	// well-formed
+	if err := xml.Unmarshal(append(append([]byte("<plist>"), buf...), "</plist>"...), new(struct{})); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if _, err := s.ATSPinnedDomains(nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
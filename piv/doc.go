@@ -0,0 +1,15 @@
+// Package piv provides interoperability between pemutil and PIV smart cards
+// (such as YubiKeys), allowing certificates to be imported from PIV slots,
+// keys held in PIV slots to be used as [crypto.Signer]s in a
+// [pemutil.Store], and certificates to be written to PIV slots.
+//
+// The package is a separate module, so that programs using pemutil do not
+// depend on github.com/go-piv/piv-go:
+//
+//	go get github.com/kenshaw/pemutil/piv
+//
+// The package requires cgo and PC/SC (on Linux, libpcsclite), and is only
+// built with the piv build tag:
+//
+//	go build -tags piv
+package piv
@@ -0,0 +1,17 @@
+module github.com/kenshaw/pemutil/piv
+
+go 1.22.0
+
+require (
+	github.com/go-piv/piv-go v1.11.0
+	github.com/kenshaw/pemutil v0.0.0
+)
+
+require (
+	github.com/cloudflare/circl v1.6.3 // indirect
+	golang.org/x/crypto v0.30.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/term v0.27.0 // indirect
+)
+
+replace github.com/kenshaw/pemutil => ../
@@ -0,0 +1,131 @@
+//go:build piv
+
+package piv
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-piv/piv-go/piv"
+	"github.com/kenshaw/pemutil"
+)
+
+// Slots are the standard PIV key slots.
+var Slots = []piv.Slot{
+	piv.SlotAuthentication,
+	piv.SlotSignature,
+	piv.SlotKeyManagement,
+	piv.SlotCardAuthentication,
+}
+
+// Open opens the first PIV smart card whose name contains name (case
+// insensitive). An empty name opens the first available card.
+func Open(name string) (*piv.YubiKey, error) {
+	cards, err := piv.Cards()
+	if err != nil {
+		return nil, err
+	}
+	for _, card := range cards {
+		if strings.Contains(strings.ToLower(card), strings.ToLower(name)) {
+			return piv.Open(card)
+		}
+	}
+	if name != "" {
+		return nil, fmt.Errorf("no smart card matching %q", name)
+	}
+	return nil, errors.New("no smart cards available")
+}
+
+// Certificates returns a [pemutil.Store] containing the certificates in the
+// standard PIV slots (see [Slots]), in slot order. Empty slots are skipped.
+func Certificates(yk *piv.YubiKey) (pemutil.Store, error) {
+	s := make(pemutil.Store)
+	for _, slot := range Slots {
+		cert, err := yk.Certificate(slot)
+		switch {
+		case errors.Is(err, piv.ErrNotFound):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("slot %s: %w", slot, err)
+		}
+		if err := s.AddX509(cert); err != nil {
+			return nil, err
+		}
+	}
+	if len(s) == 0 {
+		return nil, errors.New("smart card does not contain any certificates")
+	}
+	return s, nil
+}
+
+// Load returns a [pemutil.Store] containing the certificate (if any), public
+// key, and private key held in the slot. The private key is a
+// [pemutil.NonExportable] signer that performs operations on the card,
+// using auth to provide the PIN when required.
+//
+// The public key is taken from the slot's certificate, or from the slot's
+// attestation when the slot does not have a certificate.
+func Load(yk *piv.YubiKey, slot piv.Slot, auth piv.KeyAuth) (pemutil.Store, error) {
+	s := make(pemutil.Store)
+	var pub crypto.PublicKey
+	switch cert, err := yk.Certificate(slot); {
+	case err == nil:
+		s[pemutil.Certificate], pub = cert, cert.PublicKey
+	case errors.Is(err, piv.ErrNotFound):
+		att, err := yk.Attest(slot)
+		if err != nil {
+			return nil, fmt.Errorf("slot %s: %w", slot, err)
+		}
+		pub = att.PublicKey
+	default:
+		return nil, fmt.Errorf("slot %s: %w", slot, err)
+	}
+	key, err := yk.PrivateKey(slot, pub, auth)
+	if err != nil {
+		return nil, fmt.Errorf("slot %s: %w", slot, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("slot %s: key is not a signer", slot)
+	}
+	typ, err := keyType(pub)
+	if err != nil {
+		return nil, err
+	}
+	s[typ], s[pemutil.PublicKey] = pemutil.NonExportable{Signer: signer}, pub
+	return s, nil
+}
+
+// keyType returns the private key block type for the public key.
+func keyType(pub crypto.PublicKey) (pemutil.BlockType, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return pemutil.RSAPrivateKey, nil
+	case *ecdsa.PublicKey:
+		return pemutil.ECPrivateKey, nil
+	case ed25519.PublicKey:
+		return pemutil.PrivateKey, nil
+	}
+	return "", fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// WriteCertificate writes the first certificate in the [pemutil.Store] to
+// the slot, using the management key (usually [piv.DefaultManagementKey]).
+// Returns an error when the slot's attested key does not match the
+// certificate's public key.
+func WriteCertificate(yk *piv.YubiKey, managementKey [24]byte, slot piv.Slot, s pemutil.Store) error {
+	cert, ok := s.Certificate()
+	if !ok {
+		return errors.New("store does not contain a certificate")
+	}
+	// attestation is not supported by all cards
+	if att, err := yk.Attest(slot); err == nil && !pemutil.EqualKeys(att.PublicKey, cert.PublicKey) {
+		return fmt.Errorf("slot %s: certificate does not match the slot's key", slot)
+	}
+	return yk.SetCertificate(managementKey, slot, cert)
+}
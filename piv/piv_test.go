@@ -0,0 +1,35 @@
+//go:build piv
+
+package piv
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/kenshaw/pemutil"
+)
+
+func TestKeyType(t *testing.T) {
+	tests := []struct {
+		pub interface{}
+		exp pemutil.BlockType
+	}{
+		{&rsa.PublicKey{}, pemutil.RSAPrivateKey},
+		{&ecdsa.PublicKey{}, pemutil.ECPrivateKey},
+		{ed25519.PublicKey{}, pemutil.PrivateKey},
+	}
+	for i, test := range tests {
+		typ, err := keyType(test.pub)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if typ != test.exp {
+			t.Errorf("test %d expected %s, got: %s", i, test.exp, typ)
+		}
+	}
+	if _, err := keyType([]byte{}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,60 @@
+package pemutil
+
+import (
+	"crypto/rsa"
+	"errors"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// DecodePKCS12 decodes the private keys and certificates in PKCS#12 (PFX)
+// encoded data, using the password provider for the password.
+//
+// BER encoded outer PFX structures (as produced by some Java tools) are
+// re-encoded as DER (see [BERToDER]). The encapsulated content is not
+// re-encoded, as doing so would invalidate the MAC.
+func DecodePKCS12(buf []byte, p PasswordProvider) (Store, error) {
+	password, err := p.Password("PKCS#12")
+	if err != nil {
+		return nil, err
+	}
+	if der, err := BERToDER(buf); err == nil {
+		buf = der
+	}
+	blocks, err := pkcs12.ToPEM(buf, string(password))
+	if err != nil {
+		return nil, err
+	}
+	s := make(Store)
+	for _, block := range blocks {
+		if BlockType(block.Type) != PrivateKey {
+			if err := s.DecodeBlock(block); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if attrs := headerAttributes(block.Headers); len(attrs) != 0 {
+			if err := s.add(KeyAttributes, attrs); err != nil {
+				return nil, err
+			}
+		}
+		// private keys are PKCS1 or SEC1 encoded
+		if key, err := ParsePKCSPrivateKey(block.Bytes); err == nil {
+			if _, ok := key.(*rsa.PrivateKey); ok {
+				if err := s.add(RSAPrivateKey, key); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		key, err := ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.New("unsupported PKCS#12 private key")
+		}
+		if err := s.add(ECPrivateKey, key); err != nil {
+			return nil, err
+		}
+	}
+	s.AddPublicKeys()
+	return s, nil
+}
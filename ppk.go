@@ -0,0 +1,369 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// ppkPrefix is the prefix of PuTTY private key (PPK) data.
+const ppkPrefix = "PuTTY-User-Key-File-"
+
+// Maximum Argon2 parameters accepted when decrypting PPK version 3 data, to
+// prevent crafted data from using excessive memory or time.
+const (
+	maxPPKArgon2Memory = 1 << 20 // KiB
+	maxPPKArgon2Passes = 1000
+)
+
+// DecodePPK decodes the private key in PuTTY private key (PPK) version 2 or 3
+// encoded data, using the password provider for the password of encrypted
+// keys. The password provider may be nil when the key is not encrypted.
+//
+// RSA, ECDSA (NIST P-256, P-384, and P-521), Ed25519, and Ed448 keys are
+// supported. The key's comment is stored as the friendlyName attribute (see
+// [Attributes.FriendlyName]).
+//
+// PPK data is also decoded by [Decode] (and [LoadFile]), using the password
+// provider passed with [WithPassword].
+func DecodePPK(buf []byte, p PasswordProvider) (Store, error) {
+	s := make(Store)
+	if err := s.decodePPK(buf, p); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// isPPK determines if buf is PPK encoded data.
+func isPPK(buf []byte) bool {
+	return bytes.HasPrefix(buf, []byte(ppkPrefix))
+}
+
+// decodePPK decodes the private key in the PPK encoded data, adding it to the
+// [Store].
+func (s Store) decodePPK(buf []byte, p PasswordProvider) error {
+	f, err := parsePPK(buf)
+	if err != nil {
+		return err
+	}
+	priv := f.private
+	var password []byte
+	if f.encryption != "none" {
+		if p == nil {
+			return errors.New("encrypted PPK private key requires a password (see WithPassword)")
+		}
+		if password, err = p.Password("PPK"); err != nil {
+			return err
+		}
+	}
+	key, iv, macKey, newMAC, err := f.keys(password)
+	if err != nil {
+		return err
+	}
+	if f.encryption != "none" {
+		if len(priv)%aes.BlockSize != 0 {
+			return errors.New("invalid PPK private key length")
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		priv = make([]byte, len(f.private))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(priv, f.private)
+	}
+	// check mac
+	var b cryptobyte.Builder
+	for _, v := range [][]byte{[]byte(f.algorithm), []byte(f.encryption), []byte(f.comment), f.public, priv} {
+		b.AddUint32LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(v)
+		})
+	}
+	mac := hmac.New(newMAC, macKey)
+	mac.Write(b.BytesOrPanic())
+	if !hmac.Equal(mac.Sum(nil), f.mac) {
+		if f.encryption != "none" {
+			return errors.New("invalid PPK private key MAC (incorrect password?)")
+		}
+		return errors.New("invalid PPK private key MAC")
+	}
+	k, err := parsePPKKey(f.algorithm, f.public, priv)
+	if err != nil {
+		return err
+	}
+	typ := PrivateKey
+	switch k.(type) {
+	case *rsa.PrivateKey:
+		typ = RSAPrivateKey
+	case *ecdsa.PrivateKey:
+		typ = ECPrivateKey
+	}
+	if err := s.add(typ, k); err != nil {
+		return err
+	}
+	if f.comment != "" {
+		var attrs Attributes
+		attrs.SetFriendlyName(f.comment)
+		if err := s.add(KeyAttributes, attrs); err != nil {
+			return err
+		}
+	}
+	s.AddPublicKeys()
+	return nil
+}
+
+// ppkFile is a parsed PPK file.
+type ppkFile struct {
+	version    int
+	algorithm  string
+	encryption string
+	comment    string
+	public     []byte
+	private    []byte
+	mac        []byte
+	// argon2 parameters (version 3)
+	kdf         string
+	memory      uint32
+	passes      uint32
+	parallelism uint8
+	salt        []byte
+}
+
+// parsePPK parses PPK version 2 or 3 encoded data.
+func parsePPK(buf []byte) (*ppkFile, error) {
+	lines := strings.Split(strings.ReplaceAll(string(buf), "\r\n", "\n"), "\n")
+	fields := make(map[string]string)
+	var order []string
+	var public, private []byte
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		v = strings.TrimPrefix(v, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid PPK line %d", i+1)
+		}
+		if _, ok := fields[k]; ok {
+			return nil, fmt.Errorf("duplicate PPK field %q", k)
+		}
+		fields[k] = v
+		order = append(order, k)
+		if k != "Public-Lines" && k != "Private-Lines" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > len(lines)-i-1 {
+			return nil, fmt.Errorf("invalid PPK %s %q", k, v)
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.Join(lines[i+1:i+1+n], ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PPK %s data: %w", strings.TrimSuffix(k, "-Lines"), err)
+		}
+		if k == "Public-Lines" {
+			public = data
+		} else {
+			private = data
+		}
+		i += n
+	}
+	if len(order) == 0 || !strings.HasPrefix(order[0], ppkPrefix) {
+		return nil, errors.New("invalid PPK data")
+	}
+	f := &ppkFile{
+		algorithm:  fields[order[0]],
+		encryption: fields["Encryption"],
+		comment:    fields["Comment"],
+		public:     public,
+		private:    private,
+	}
+	switch v := strings.TrimPrefix(order[0], ppkPrefix); v {
+	case "2", "3":
+		f.version = int(v[0] - '0')
+	default:
+		return nil, fmt.Errorf("unsupported PPK version %q", v)
+	}
+	switch f.encryption {
+	case "none", "aes256-cbc":
+	default:
+		return nil, fmt.Errorf("unsupported PPK encryption %q", f.encryption)
+	}
+	for _, k := range []string{"Encryption", "Comment", "Public-Lines", "Private-Lines", "Private-MAC"} {
+		if _, ok := fields[k]; !ok {
+			return nil, fmt.Errorf("PPK data is missing %s", k)
+		}
+	}
+	var err error
+	if f.mac, err = hex.DecodeString(fields["Private-MAC"]); err != nil {
+		return nil, errors.New("invalid PPK Private-MAC")
+	}
+	if f.version == 2 || f.encryption == "none" {
+		return f, nil
+	}
+	// argon2 parameters
+	f.kdf = fields["Key-Derivation"]
+	memory, err := strconv.ParseUint(fields["Argon2-Memory"], 10, 32)
+	if err != nil || memory == 0 || memory > maxPPKArgon2Memory {
+		return nil, fmt.Errorf("invalid PPK Argon2-Memory %q", fields["Argon2-Memory"])
+	}
+	passes, err := strconv.ParseUint(fields["Argon2-Passes"], 10, 32)
+	if err != nil || passes == 0 || passes > maxPPKArgon2Passes {
+		return nil, fmt.Errorf("invalid PPK Argon2-Passes %q", fields["Argon2-Passes"])
+	}
+	parallelism, err := strconv.ParseUint(fields["Argon2-Parallelism"], 10, 8)
+	if err != nil || parallelism == 0 {
+		return nil, fmt.Errorf("invalid PPK Argon2-Parallelism %q", fields["Argon2-Parallelism"])
+	}
+	if f.salt, err = hex.DecodeString(fields["Argon2-Salt"]); err != nil {
+		return nil, errors.New("invalid PPK Argon2-Salt")
+	}
+	f.memory, f.passes, f.parallelism = uint32(memory), uint32(passes), uint8(parallelism)
+	return f, nil
+}
+
+// keys derives the cipher key, cipher iv, and mac key for the PPK data from
+// the password, returning the hash used for the mac.
+func (f *ppkFile) keys(password []byte) ([]byte, []byte, []byte, func() hash.Hash, error) {
+	if f.version == 2 {
+		var key []byte
+		for i := byte(0); i < 2; i++ {
+			h := sha1.New()
+			h.Write([]byte{0, 0, 0, i})
+			h.Write(password)
+			key = h.Sum(key)
+		}
+		h := sha1.New()
+		h.Write([]byte("putty-private-key-file-mac-key"))
+		h.Write(password)
+		return key[:32], make([]byte, aes.BlockSize), h.Sum(nil), sha1.New, nil
+	}
+	if f.encryption == "none" {
+		return nil, nil, nil, sha256.New, nil
+	}
+	var buf []byte
+	switch f.kdf {
+	case "Argon2id":
+		buf = argon2.IDKey(password, f.salt, f.passes, f.memory, f.parallelism, 80)
+	case "Argon2i":
+		buf = argon2.Key(password, f.salt, f.passes, f.memory, f.parallelism, 80)
+	default:
+		return nil, nil, nil, nil, fmt.Errorf("unsupported PPK key derivation %q", f.kdf)
+	}
+	return buf[:32], buf[32:48], buf[48:], sha256.New, nil
+}
+
+// parsePPKKey parses the PPK public and private key blobs for the algorithm.
+func parsePPKKey(algorithm string, public, private []byte) (interface{}, error) {
+	pub, priv := cryptobyte.String(public), cryptobyte.String(private)
+	var alg []byte
+	if !readSSHString(&pub, &alg) || string(alg) != algorithm {
+		return nil, errors.New("invalid PPK public key")
+	}
+	switch algorithm {
+	case "ssh-rsa":
+		var e, n, d, p, q, iqmp big.Int
+		if !readSSHMPInt(&pub, &e) || !readSSHMPInt(&pub, &n) || !e.IsInt64() {
+			return nil, errors.New("invalid PPK public key")
+		}
+		if !readSSHMPInt(&priv, &d) || !readSSHMPInt(&priv, &p) || !readSSHMPInt(&priv, &q) || !readSSHMPInt(&priv, &iqmp) {
+			return nil, errors.New("invalid PPK private key")
+		}
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{
+				N: &n,
+				E: int(e.Int64()),
+			},
+			D:      &d,
+			Primes: []*big.Int{&p, &q},
+		}
+		if err := key.Validate(); err != nil {
+			return nil, err
+		}
+		key.Precompute()
+		return key, nil
+	case "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521":
+		curve := map[string]elliptic.Curve{
+			"nistp256": elliptic.P256(),
+			"nistp384": elliptic.P384(),
+			"nistp521": elliptic.P521(),
+		}[strings.TrimPrefix(algorithm, "ecdsa-sha2-")]
+		var name, point []byte
+		var d big.Int
+		if !readSSHString(&pub, &name) || string(name) != strings.TrimPrefix(algorithm, "ecdsa-sha2-") ||
+			!readSSHString(&pub, &point) {
+			return nil, errors.New("invalid PPK public key")
+		}
+		if !readSSHMPInt(&priv, &d) || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+			return nil, errors.New("invalid PPK private key")
+		}
+		key := &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: curve},
+			D:         &d,
+		}
+		key.X, key.Y = curve.ScalarBaseMult(d.Bytes())
+		if !bytes.Equal(elliptic.Marshal(curve, key.X, key.Y), point) {
+			return nil, errors.New("PPK public key does not match private key")
+		}
+		return key, nil
+	case "ssh-ed25519", "ssh-ed448":
+		var pubKey, seed []byte
+		if !readSSHString(&pub, &pubKey) {
+			return nil, errors.New("invalid PPK public key")
+		}
+		if !readSSHString(&priv, &seed) {
+			return nil, errors.New("invalid PPK private key")
+		}
+		var key interface{}
+		var keyPub []byte
+		switch {
+		case algorithm == "ssh-ed25519" && len(seed) == ed25519.SeedSize:
+			k := ed25519.NewKeyFromSeed(seed)
+			key, keyPub = k, k.Public().(ed25519.PublicKey)
+		case algorithm == "ssh-ed448" && len(seed) == ed448.SeedSize:
+			k := ed448.NewKeyFromSeed(seed)
+			key, keyPub = k, k.Public().(ed448.PublicKey)
+		default:
+			return nil, errors.New("invalid PPK private key")
+		}
+		if !bytes.Equal(keyPub, pubKey) {
+			return nil, errors.New("PPK public key does not match private key")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported PPK key algorithm %q", algorithm)
+}
+
+// readSSHString reads a SSH string into v.
+func readSSHString(s *cryptobyte.String, v *[]byte) bool {
+	var n uint32
+	return s.ReadUint32(&n) && s.ReadBytes(v, int(n))
+}
+
+// readSSHMPInt reads a non-negative SSH mpint into v.
+func readSSHMPInt(s *cryptobyte.String, v *big.Int) bool {
+	var buf []byte
+	if !readSSHString(s, &buf) || len(buf) != 0 && buf[0]&0x80 != 0 {
+		return false
+	}
+	v.SetBytes(buf)
+	return true
+}
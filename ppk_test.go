@@ -0,0 +1,226 @@
+package pemutil
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+func TestDecodePPK(t *testing.T) {
+	tests := []struct {
+		name     string
+		exp      string
+		password string
+		comment  string
+	}{
+		{"ppk-rsa-v2.ppk", "rsa.pem", "secret", "rsa-key-20260101"},
+		{"ppk-ec256-v2.ppk", "ppk-ec256.key", "", ""},
+		{"ppk-ed25519-v3.ppk", "ppk-ed25519.key", "", "eddsa-key-20260101"},
+	}
+	for i, test := range tests {
+		exp, err := LoadFile("testdata/" + test.exp)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		expKey, _ := exp.Signer()
+		var opts []LoadOption
+		if test.password != "" {
+			if _, err := LoadFile("testdata/" + test.name); err == nil {
+				t.Errorf("test %d expected error, got: nil", i)
+			}
+			if _, err := LoadFile("testdata/"+test.name, WithPassword(StaticPassword("wrong"))); err == nil {
+				t.Errorf("test %d expected error, got: nil", i)
+			}
+			opts = append(opts, WithPassword(StaticPassword(test.password)))
+		}
+		s, err := LoadFile("testdata/"+test.name, opts...)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		key, ok := s.Signer()
+		if !ok {
+			t.Fatalf("test %d expected private key, got: %v", i, keys(s))
+		}
+		if !EqualKeys(expKey, key) {
+			t.Errorf("test %d expected equal private keys", i)
+		}
+		if pub, _ := s.PublicKey(); !EqualKeys(key.Public(), pub) {
+			t.Errorf("test %d expected public key", i)
+		}
+		attrs, _ := s.Attributes()
+		if name := attrs.FriendlyName(); name != test.comment {
+			t.Errorf("test %d expected comment %q, got: %q", i, test.comment, name)
+		}
+	}
+}
+
+func TestDecodePPKv3(t *testing.T) {
+	rsaKey, err := GenerateRSAKeySet(2048)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ecKey, err := GenerateECKeySet(elliptic.P384())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(Rand())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ed448Key, err := GenerateEd448KeySet()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		key      interface{}
+		kdf      string
+		password string
+	}{
+		{rsaKey[RSAPrivateKey], "Argon2id", "secret"},
+		{ecKey[ECPrivateKey], "Argon2i", "secret"},
+		{edKey, "Argon2id", "secret"},
+		{ed448Key[PrivateKey], "Argon2id", ""},
+	}
+	for i, test := range tests {
+		buf := testPPK(t, test.key, test.kdf, test.password, 8192)
+		var p PasswordProvider
+		if test.password != "" {
+			p = StaticPassword(test.password)
+		}
+		s, err := DecodePPK(buf, p)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		key, _ := s.Signer()
+		if !EqualKeys(test.key, key) {
+			t.Errorf("test %d expected equal private keys", i)
+		}
+		if test.password == "" {
+			continue
+		}
+		if _, err := DecodePPK(buf, StaticPassword("wrong")); err == nil || !strings.Contains(err.Error(), "MAC") {
+			t.Errorf("test %d expected MAC error, got: %v", i, err)
+		}
+	}
+	// excessive argon2 memory
+	buf := testPPK(t, edKey, "Argon2id", "secret", maxPPKArgon2Memory+1)
+	if _, err := DecodePPK(buf, StaticPassword("secret")); err == nil || !strings.Contains(err.Error(), "Argon2-Memory") {
+		t.Errorf("expected Argon2-Memory error, got: %v", err)
+	}
+	// tampered
+	buf, err = os.ReadFile("testdata/ppk-ed25519-v3.ppk")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf = []byte(strings.Replace(string(buf), "Comment: eddsa", "Comment: EdDSA", 1))
+	if _, err := DecodePPK(buf, nil); err == nil || !strings.Contains(err.Error(), "MAC") {
+		t.Errorf("expected MAC error, got: %v", err)
+	}
+}
+
+// testPPK encodes the private key as PPK version 3 data.
+func testPPK(t *testing.T, k interface{}, kdf, password string, memory uint32) []byte {
+	t.Helper()
+	var alg string
+	var pub, priv cryptobyte.Builder
+	str := func(b *cryptobyte.Builder, v []byte) {
+		b.AddUint32LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(v)
+		})
+	}
+	mpint := func(b *cryptobyte.Builder, v *big.Int) {
+		str(b, append([]byte{0}, v.Bytes()...))
+	}
+	switch k := k.(type) {
+	case *rsa.PrivateKey:
+		alg = "ssh-rsa"
+		str(&pub, []byte(alg))
+		mpint(&pub, big.NewInt(int64(k.E)))
+		mpint(&pub, k.N)
+		mpint(&priv, k.D)
+		mpint(&priv, k.Primes[0])
+		mpint(&priv, k.Primes[1])
+		mpint(&priv, k.Precomputed.Qinv)
+	case *ecdsa.PrivateKey:
+		name := fmt.Sprintf("nistp%d", k.Curve.Params().BitSize)
+		alg = "ecdsa-sha2-" + name
+		str(&pub, []byte(alg))
+		str(&pub, []byte(name))
+		str(&pub, elliptic.Marshal(k.Curve, k.X, k.Y))
+		mpint(&priv, k.D)
+	case ed25519.PrivateKey:
+		alg = "ssh-ed25519"
+		str(&pub, []byte(alg))
+		str(&pub, k.Public().(ed25519.PublicKey))
+		str(&priv, k.Seed())
+	case ed448.PrivateKey:
+		alg = "ssh-ed448"
+		str(&pub, []byte(alg))
+		str(&pub, k.Public().(ed448.PublicKey))
+		str(&priv, k.Seed())
+	default:
+		t.Fatalf("unsupported key %T", k)
+	}
+	pubBuf, privBuf := pub.BytesOrPanic(), priv.BytesOrPanic()
+	encryption, comment := "none", "test-key"
+	var macKey, key, iv []byte
+	var kdfLines string
+	if password != "" {
+		encryption = "aes256-cbc"
+		salt := make([]byte, 16)
+		if err := readRand(Rand(), salt); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		kdfLines = fmt.Sprintf("Key-Derivation: %s\nArgon2-Memory: %d\nArgon2-Passes: 2\nArgon2-Parallelism: 1\nArgon2-Salt: %x\n", kdf, memory, salt)
+		buf := make([]byte, 80)
+		switch {
+		case memory > maxPPKArgon2Memory:
+		case kdf == "Argon2i":
+			buf = argon2.Key([]byte(password), salt, 2, memory, 1, 80)
+		default:
+			buf = argon2.IDKey([]byte(password), salt, 2, memory, 1, 80)
+		}
+		key, iv, macKey = buf[:32], buf[32:48], buf[48:]
+		privBuf = append(privBuf, make([]byte, (aes.BlockSize-len(privBuf)%aes.BlockSize)%aes.BlockSize)...)
+	}
+	var b cryptobyte.Builder
+	for _, v := range [][]byte{[]byte(alg), []byte(encryption), []byte(comment), pubBuf, privBuf} {
+		str(&b, v)
+	}
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(b.BytesOrPanic())
+	if password != "" {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(privBuf, privBuf)
+	}
+	lines := func(buf []byte) string {
+		s := base64.StdEncoding.EncodeToString(buf)
+		var v []string
+		for len(s) > 64 {
+			v, s = append(v, s[:64]), s[64:]
+		}
+		v = append(v, s)
+		return fmt.Sprintf("%d\n%s\n", len(v), strings.Join(v, "\n"))
+	}
+	return []byte(fmt.Sprintf("PuTTY-User-Key-File-3: %s\nEncryption: %s\nComment: %s\nPublic-Lines: %s%sPrivate-Lines: %sPrivate-MAC: %s\n",
+		alg, encryption, comment, lines(pubBuf), kdfLines, lines(privBuf), hex.EncodeToString(mac.Sum(nil))))
+}
@@ -0,0 +1,214 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// PublicStore is a read-only set of public keys and certificates, for use by
+// services that only verify signatures (such as JWT authentication
+// middleware). A public store never holds private keys.
+//
+// Keys are identified by their key id, the SHA-256 JWK thumbprint (see
+// [NewJWK]), matching the key ids served by [JWKSHandler].
+type PublicStore struct {
+	keys  []publicKey
+	byKID map[string]int
+}
+
+// publicKey is a public key in a public store.
+type publicKey struct {
+	kid  string
+	pub  crypto.PublicKey
+	cert *x509.Certificate
+}
+
+// NewPublicStore creates a public store containing the public keys and
+// certificates in the stores. The public keys of private keys are added, but
+// the private keys are not retained. Duplicate keys are skipped.
+func NewPublicStore(stores ...Store) (*PublicStore, error) {
+	p := &PublicStore{
+		byKID: make(map[string]int),
+	}
+	add := func(pub crypto.PublicKey, cert *x509.Certificate) error {
+		key, err := NewJWK(pub)
+		if err != nil {
+			return err
+		}
+		if i, ok := p.byKID[key.Kid]; ok {
+			if p.keys[i].cert == nil {
+				p.keys[i].cert = cert
+			}
+			return nil
+		}
+		pub, err = CanonicalPublicKey(pub)
+		if err != nil {
+			return err
+		}
+		p.byKID[key.Kid] = len(p.keys)
+		p.keys = append(p.keys, publicKey{kid: key.Kid, pub: pub, cert: cert})
+		return nil
+	}
+	for _, s := range stores {
+		for _, cert := range s.Certificates() {
+			if err := add(cert.PublicKey, cert); err != nil {
+				return nil, err
+			}
+		}
+		if pub, ok := s.PublicKey(); ok {
+			if err := add(pub, nil); err != nil {
+				return nil, err
+			}
+		} else if signer, ok := s.Signer(); ok {
+			if err := add(signer.Public(), nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(p.keys) == 0 {
+		return nil, errors.New("no public keys")
+	}
+	return p, nil
+}
+
+// KIDs returns the key ids of the public keys, in order.
+func (p *PublicStore) KIDs() []string {
+	kids := make([]string, len(p.keys))
+	for i, key := range p.keys {
+		kids[i] = key.kid
+	}
+	return kids
+}
+
+// PublicKey returns the public key for the key id.
+func (p *PublicStore) PublicKey(kid string) (crypto.PublicKey, bool) {
+	if i, ok := p.byKID[kid]; ok {
+		return p.keys[i].pub, true
+	}
+	return nil, false
+}
+
+// Certificate returns the certificate for the key id, if any.
+func (p *PublicStore) Certificate(kid string) (*x509.Certificate, bool) {
+	if i, ok := p.byKID[kid]; ok && p.keys[i].cert != nil {
+		return p.keys[i].cert, true
+	}
+	return nil, false
+}
+
+// Verifier is the interface for a JWS (RFC 7515) signature verifier.
+type Verifier interface {
+	// Verify verifies the JWS signature of the signing input.
+	Verify(signingInput, sig []byte) error
+}
+
+// VerifierFor returns a [Verifier] for the key id and JWS algorithm (such
+// as "RS256", "PS256", "ES256", or "EdDSA"). When kid is empty, the public
+// store must contain exactly one public key usable with the algorithm.
+//
+// Returns an error when the algorithm is not a supported asymmetric
+// signature algorithm, or is not usable with the public key, preventing
+// algorithm confusion (such as "none" or "HS256").
+func (p *PublicStore) VerifierFor(kid, alg string) (Verifier, error) {
+	if kid != "" {
+		i, ok := p.byKID[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return newJWSVerifier(p.keys[i].pub, alg)
+	}
+	var v Verifier
+	for _, key := range p.keys {
+		z, err := newJWSVerifier(key.pub, alg)
+		if err != nil {
+			continue
+		}
+		if v != nil {
+			return nil, fmt.Errorf("multiple keys for algorithm %s (key id required)", alg)
+		}
+		v = z
+	}
+	if v == nil {
+		return nil, fmt.Errorf("no key for algorithm %s", alg)
+	}
+	return v, nil
+}
+
+// jwsVerifier is a JWS signature verifier.
+type jwsVerifier struct {
+	pub  crypto.PublicKey
+	hash crypto.Hash
+	pss  bool
+}
+
+// newJWSVerifier creates a JWS signature verifier for the public key and
+// algorithm.
+func newJWSVerifier(pub crypto.PublicKey, alg string) (Verifier, error) {
+	hash := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+	var ok bool
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if len(alg) == 5 && (alg[:2] == "RS" || alg[:2] == "PS") {
+			if h, ok := hash[alg[2:]]; ok {
+				return &jwsVerifier{pub: pub, hash: h, pss: alg[0] == 'P'}, nil
+			}
+		}
+	case *ecdsa.PublicKey:
+		var crv string
+		if crv, ok = map[string]string{
+			"ES256":  "P-256",
+			"ES384":  "P-384",
+			"ES512":  "P-521",
+			"ES256K": "secp256k1",
+		}[alg]; ok {
+			if z, err := jwkCurve(k); err == nil && z == crv {
+				return &jwsVerifier{pub: pub, hash: hash[alg[2:5]]}, nil
+			}
+		}
+	case ed25519.PublicKey, ed448.PublicKey:
+		if alg == "EdDSA" {
+			return &jwsVerifier{pub: pub}, nil
+		}
+	}
+	return nil, fmt.Errorf("algorithm %q is not supported for %T keys", alg, pub)
+}
+
+// Verify satisfies the [Verifier] interface.
+func (v *jwsVerifier) Verify(signingInput, sig []byte) error {
+	var digest []byte
+	if v.hash != 0 {
+		h := v.hash.New()
+		_, _ = h.Write(signingInput)
+		digest = h.Sum(nil)
+	}
+	var ok bool
+	switch k := v.pub.(type) {
+	case *rsa.PublicKey:
+		if v.pss {
+			return rsa.VerifyPSS(k, v.hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(k, v.hash, digest, sig)
+	case *ecdsa.PublicKey:
+		n := (k.Params().BitSize + 7) / 8
+		if len(sig) == 2*n {
+			r, s := new(big.Int).SetBytes(sig[:n]), new(big.Int).SetBytes(sig[n:])
+			ok = ecdsa.Verify(k, digest, r, s)
+		}
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, signingInput, sig)
+	case ed448.PublicKey:
+		ok = ed448.Verify(k, signingInput, sig, "")
+	}
+	if !ok {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestPublicStore(t *testing.T) {
+	rsaStore, err := LoadFile("testdata/rsa.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ecStore, err := LoadFile("testdata/ec256-private.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	edStore := Store{PrivateKey: edKey}
+	p, err := NewPublicStore(rsaStore, ecStore, edStore, ecStore.Only(PublicKey))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	kids := p.KIDs()
+	if len(kids) != 3 {
+		t.Fatalf("expected 3 keys, got: %d", len(kids))
+	}
+	for i, key := range p.keys {
+		switch key.pub.(type) {
+		case crypto.Signer, crypto.Decrypter:
+			t.Errorf("test %d expected public key, got: %T", i, key.pub)
+		}
+	}
+	input := []byte("header.payload")
+	digest := sha256.Sum256(input)
+	rsaKey, _ := rsaStore.RSAPrivateKey()
+	ecKey, _ := ecStore.ECPrivateKey()
+	rs256, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ps256, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	es256 := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	eddsa := ed25519.Sign(edKey, input)
+	tests := []struct {
+		kid string
+		alg string
+		sig []byte
+	}{
+		{kids[0], "RS256", rs256},
+		{kids[0], "PS256", ps256},
+		{kids[1], "ES256", es256},
+		{kids[2], "EdDSA", eddsa},
+		{"", "RS256", rs256},
+		{"", "ES256", es256},
+		{"", "EdDSA", eddsa},
+	}
+	for i, test := range tests {
+		v, err := p.VerifierFor(test.kid, test.alg)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if err := v.Verify(input, test.sig); err != nil {
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		}
+		if err := v.Verify([]byte("header.other"), test.sig); err == nil {
+			t.Errorf("test %d expected error, got nil", i)
+		}
+	}
+	for i, test := range []struct {
+		kid string
+		alg string
+	}{
+		{kids[0], "HS256"},
+		{kids[0], "none"},
+		{kids[0], "ES256"},
+		{kids[1], "ES384"},
+		{kids[2], "RS256"},
+		{"unknown", "RS256"},
+		{"", "ES512"},
+	} {
+		if _, err := p.VerifierFor(test.kid, test.alg); err == nil {
+			t.Errorf("test %d expected error, got nil", i)
+		}
+	}
+	// ambiguous without a key id
+	other, err := LoadFile("testdata/ecx256.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	p, err = NewPublicStore(ecStore, other)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := p.VerifierFor("", "ES256"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
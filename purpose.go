@@ -0,0 +1,208 @@
+package pemutil
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// KeyPurposeHeader is the label used to record the intended purposes of a
+// private key, written as a "Key-Purpose: signing,ca" line of explanatory
+// text (RFC 7468) immediately preceding the private key block. As the line is
+// outside the PEM block, the private key remains readable by tools that
+// reject unknown PEM headers, such as OpenSSL.
+//
+// Private key blocks with a "Key-Purpose" PEM header, as written by previous
+// versions, are still decoded.
+const KeyPurposeHeader = "Key-Purpose"
+
+// Purpose is an intended key purpose.
+type Purpose string
+
+// Key purposes.
+const (
+	// PurposeSigning is the signing purpose (see [Store.Sign]).
+	PurposeSigning Purpose = "signing"
+	// PurposeEncryption is the encryption purpose (see [Store.Decrypt]).
+	PurposeEncryption Purpose = "encryption"
+	// PurposeTLSServer is the TLS server purpose (see [Store.TLSCertificate]).
+	PurposeTLSServer Purpose = "tls-server"
+	// PurposeTLSClient is the TLS client purpose (see [Store.TLSCertificate]).
+	PurposeTLSClient Purpose = "tls-client"
+	// PurposeCA is the certificate authority purpose (see [Store.Issue] and
+	// [GenerateCRL]).
+	PurposeCA Purpose = "ca"
+)
+
+// Purposes are the intended purposes of a private key.
+type Purposes []Purpose
+
+// ParsePurposes parses a comma separated list of key purposes.
+func ParsePurposes(s string) (Purposes, error) {
+	var purposes Purposes
+	for _, v := range strings.Split(s, ",") {
+		switch p := Purpose(strings.TrimSpace(v)); p {
+		case PurposeSigning, PurposeEncryption, PurposeTLSServer, PurposeTLSClient, PurposeCA:
+			if !purposes.Has(p) {
+				purposes = append(purposes, p)
+			}
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown key purpose %q", p)
+		}
+	}
+	return purposes, nil
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (p Purposes) String() string {
+	v := make([]string, len(p))
+	for i, purpose := range p {
+		v[i] = string(purpose)
+	}
+	return strings.Join(v, ",")
+}
+
+// Has determines if the purposes contain the purpose.
+func (p Purposes) Has(purpose Purpose) bool {
+	for _, z := range p {
+		if z == purpose {
+			return true
+		}
+	}
+	return false
+}
+
+// PurposeError is a key purpose error, returned when a private key is used
+// outside of its intended purposes.
+type PurposeError struct {
+	// Purposes are the purposes the private key was used for.
+	Purposes Purposes
+	// Allowed are the intended purposes of the private key.
+	Allowed Purposes
+}
+
+// Error satisfies the error interface.
+func (err *PurposeError) Error() string {
+	return fmt.Sprintf("private key purposes %s do not include %s", err.Allowed, strings.ReplaceAll(err.Purposes.String(), ",", " or "))
+}
+
+// SetPurposes tags the private key in the [Store] with the intended
+// purposes, stored as [KeyPurposes]. The purposes are persisted by
+// [Store.Bytes] as a [KeyPurposeHeader] line preceding the private key block.
+// Passing no purposes removes the tag.
+//
+// Once tagged, [Store.Sign], [Store.Decrypt], [Store.TLSCertificate],
+// [Store.Issue], and [GenerateCRL] refuse to use the private key outside of
+// its intended purposes.
+func (s Store) SetPurposes(purposes ...Purpose) {
+	if len(purposes) == 0 {
+		delete(s, KeyPurposes)
+		return
+	}
+	s[KeyPurposes] = append(Purposes(nil), purposes...)
+}
+
+// Purposes returns the intended purposes of the private key in the [Store].
+func (s Store) Purposes() (Purposes, bool) {
+	p, ok := s[KeyPurposes].(Purposes)
+	return p, ok
+}
+
+// CheckPurpose checks that the private key in the [Store] may be used for
+// any of the purposes, returning a [*PurposeError] when not. Untagged private
+// keys may be used for any purpose.
+func (s Store) CheckPurpose(purposes ...Purpose) error {
+	allowed, ok := s.Purposes()
+	if !ok {
+		return nil
+	}
+	for _, p := range purposes {
+		if allowed.Has(p) {
+			return nil
+		}
+	}
+	return &PurposeError{Purposes: purposes, Allowed: allowed}
+}
+
+// purposeBlock returns a copy of the private key block with the
+// [KeyPurposeHeader] header set for the purposes in the [Store]. Only used for
+// the binary encoding (see [Store.MarshalBinary]), as PEM headers on private
+// key blocks are rejected by other tools.
+func (s Store) purposeBlock(block *pem.Block) *pem.Block {
+	purposes, ok := s.Purposes()
+	if !ok {
+		return block
+	}
+	headers := make(map[string]string, len(block.Headers)+1)
+	for k, v := range block.Headers {
+		headers[k] = v
+	}
+	headers[KeyPurposeHeader] = purposes.String()
+	return &pem.Block{
+		Type:    block.Type,
+		Headers: headers,
+		Bytes:   block.Bytes,
+	}
+}
+
+// encodeText returns the explanatory text in the [Store], with the
+// [KeyPurposeHeader] line for the purposes in the [Store] following any text
+// for the private key.
+func (s Store) encodeText() explanatoryText {
+	text, _ := s[ExplanatoryText].(explanatoryText)
+	purposes, ok := s.Purposes()
+	if !ok {
+		return text
+	}
+	m := make(explanatoryText, len(text)+1)
+	for k, v := range text {
+		m[k] = v
+	}
+	key := PrivateKey.String()
+	m[key] = append(append([]byte(nil), m[key]...), KeyPurposeHeader+": "+purposes.String()+"\n"...)
+	return m
+}
+
+// decodePurposeText decodes the [KeyPurposeHeader] line at the end of the
+// explanatory text preceding a private key block, adding the purposes to the
+// [Store]. Returns the text without the line.
+func (s Store) decodePurposeText(text []byte) ([]byte, error) {
+	trimmed := bytes.TrimRight(text, "\r\n")
+	i := bytes.LastIndexByte(trimmed, '\n') + 1
+	v, ok := bytes.CutPrefix(trimmed[i:], []byte(KeyPurposeHeader+":"))
+	if !ok {
+		return text, nil
+	}
+	purposes, err := ParsePurposes(string(v))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.add(KeyPurposes, purposes); err != nil {
+		return nil, err
+	}
+	return trimmed[:i], nil
+}
+
+// decodePurposes decodes the [KeyPurposeHeader] header of a private key
+// block, adding the purposes to the [Store].
+func (s Store) decodePurposes(block *pem.Block) error {
+	v, ok := block.Headers[KeyPurposeHeader]
+	if !ok {
+		return nil
+	}
+	purposes, err := ParsePurposes(v)
+	if err != nil {
+		return err
+	}
+	return s.add(KeyPurposes, purposes)
+}
+
+// purposeHeaders returns the [KeyPurposeHeader] header of the block, if any.
+func purposeHeaders(block *pem.Block) map[string]string {
+	if v, ok := block.Headers[KeyPurposeHeader]; ok {
+		return map[string]string{KeyPurposeHeader: v}
+	}
+	return nil
+}
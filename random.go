@@ -0,0 +1,100 @@
+package pemutil
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrRandExhausted is the error returned by a size-bounded random source
+// (see [LimitRand]) when its limit has been reached.
+var ErrRandExhausted = errors.New("random source exhausted")
+
+// randSource is the package's random source.
+var randSource struct {
+	sync.RWMutex
+	r io.Reader
+}
+
+// SetRand sets the random source used by the package for key generation,
+// encryption, signing, and certificate issuance, such as a hardware RNG or a
+// DRBG. Passing nil restores the default ([crypto/rand.Reader]). The random
+// source must be safe for concurrent use.
+//
+// The random source can be overridden for a single key generation with
+// [WithRand], or for a single signature with [WithSignRand].
+//
+// The random source is always used for symmetric keys and session ticket
+// keys, Ed25519 and Ed448 key generation, certificate serial numbers, RSA-PSS
+// salts, RSA-OAEP encryption, and the nonces and content encryption keys of
+// [Store.Export] and [EncryptPKCS8PrivateKey].
+//
+// Warning: since Go 1.26, the standard library ignores the random source
+// (using its own secure source instead) for RSA, ECDSA, and ECDH (including
+// X25519) key generation, ECDSA signing, RSA PKCS#1 v1.5 encryption, and
+// prime generation, unless the program is run with GODEBUG
+// cryptocustomrand=1. Even when honored, the standard library intentionally
+// does not produce deterministic output for these operations, even when given
+// a deterministic random source. Use [WithDeterministic] for deterministic
+// ECDSA signatures.
+func SetRand(r io.Reader) {
+	randSource.Lock()
+	defer randSource.Unlock()
+	randSource.r = r
+}
+
+// Rand returns the random source used by the package (see [SetRand]).
+func Rand() io.Reader {
+	randSource.RLock()
+	defer randSource.RUnlock()
+	if randSource.r == nil {
+		return rand.Reader
+	}
+	return randSource.r
+}
+
+// WithRand is a key generation option to set the random source used by
+// [GenerateSymmetricKeySet] and [GenerateRSAKeySet] (default [Rand]).
+//
+// Warning: since Go 1.26, the random source is ignored for RSA key generation
+// unless the program is run with GODEBUG cryptocustomrand=1 (see [SetRand]).
+func WithRand(r io.Reader) GenerateOption {
+	return func(opts *generateOptions) {
+		opts.rand = r
+	}
+}
+
+// LimitRand returns a size-bounded random source that reads at most n bytes
+// from r, returning [ErrRandExhausted] once the limit has been reached. Useful
+// for bounding the entropy drawn from a metered source (such as a hardware
+// RNG), and for ensuring tests using deterministic sources fail instead of
+// silently drawing more randomness than expected.
+func LimitRand(r io.Reader, n int64) io.Reader {
+	return &limitRand{r: r, n: n}
+}
+
+// limitRand is a size-bounded random source.
+type limitRand struct {
+	mu sync.Mutex
+	r  io.Reader
+	n  int64
+}
+
+// Read satisfies the [io.Reader] interface.
+func (r *limitRand) Read(buf []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if int64(len(buf)) > r.n {
+		return 0, ErrRandExhausted
+	}
+	n, err := io.ReadFull(r.r, buf)
+	r.n -= int64(n)
+	return n, err
+}
+
+// readRand fills buf from the random source.
+func readRand(r io.Reader, buf []byte) error {
+	_, err := io.ReadFull(r, buf)
+	return err
+}
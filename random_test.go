@@ -0,0 +1,42 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"errors"
+	"testing"
+)
+
+func TestWithRand(t *testing.T) {
+	src := bytes.Repeat([]byte{0x2a}, 32)
+	s, err := GenerateSymmetricKeySet(32, WithRand(bytes.NewReader(src)))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if key, _ := s[PrivateKey].([]byte); !bytes.Equal(key, src) {
+		t.Errorf("expected key from random source, got: %x", key)
+	}
+	if _, err := GenerateSymmetricKeySet(32, WithRand(LimitRand(bytes.NewReader(src), 16))); !errors.Is(err, ErrRandExhausted) {
+		t.Errorf("expected random source exhausted error, got: %v", err)
+	}
+	// rsa key generation with a non-default exponent reads from the source
+	if _, err := GenerateRSAKeySet(512, WithInsecure(), WithExponent(3), WithRand(LimitRand(bytes.NewReader(src), 0))); !errors.Is(err, ErrRandExhausted) {
+		t.Errorf("expected random source exhausted error, got: %v", err)
+	}
+}
+
+func TestSetRand(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	SetRand(LimitRand(Rand(), 0))
+	defer SetRand(nil)
+	if _, err := s.EncryptedBytes([]byte("secret")); !errors.Is(err, ErrRandExhausted) {
+		t.Errorf("expected random source exhausted error, got: %v", err)
+	}
+	SetRand(nil)
+	if _, err := s.EncryptedBytes([]byte("secret")); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
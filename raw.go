@@ -0,0 +1,81 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// LoadRawKey creates a [Store] from a bare Ed25519 or X25519 private key (as
+// stored by libsodium, or used for JWT secrets), for the algorithm
+// ("ed25519" or "x25519").
+//
+// The key data can be raw bytes, or hex or base64 (standard or URL) encoded.
+// Ed25519 keys can be either the 32 byte seed, or the 64 byte seed and public
+// key (as used by libsodium and [ed25519.PrivateKey]). X25519 keys must be 32
+// bytes.
+//
+// Ed25519 keys are stored as a [PrivateKey] ed25519.PrivateKey, and X25519
+// keys as a [PrivateKey] *ecdh.PrivateKey, with the corresponding
+// [PublicKey].
+func LoadRawKey(algorithm string, data []byte) (Store, error) {
+	buf := decodeRawKey(data)
+	var key interface {
+		Public() crypto.PublicKey
+	}
+	switch alg := strings.ToLower(algorithm); alg {
+	case "ed25519":
+		switch len(buf) {
+		case ed25519.SeedSize:
+			key = ed25519.NewKeyFromSeed(buf)
+		case ed25519.PrivateKeySize:
+			k := ed25519.NewKeyFromSeed(buf[:ed25519.SeedSize])
+			if !bytes.Equal(k[ed25519.SeedSize:], buf[ed25519.SeedSize:]) {
+				return nil, fmt.Errorf("invalid ed25519 private key: public key does not match seed")
+			}
+			key = k
+		default:
+			return nil, fmt.Errorf("invalid ed25519 private key length %d", len(buf))
+		}
+	case "x25519":
+		k, err := ecdh.X25519().NewPrivateKey(buf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x25519 private key: %w", err)
+		}
+		key = k
+	default:
+		return nil, fmt.Errorf("unsupported raw key algorithm %q", algorithm)
+	}
+	return Store{
+		PrivateKey: key,
+		PublicKey:  key.Public(),
+	}, nil
+}
+
+// decodeRawKey decodes hex or base64 encoded raw key data, returning the data
+// unchanged when it is not encoded.
+func decodeRawKey(data []byte) []byte {
+	s := strings.TrimSpace(string(data))
+	valid := func(buf []byte) bool {
+		return len(buf) == 32 || len(buf) == 64
+	}
+	if buf, err := hex.DecodeString(s); err == nil && valid(buf) {
+		return buf
+	}
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	} {
+		if buf, err := enc.DecodeString(s); err == nil && valid(buf) {
+			return buf
+		}
+	}
+	return data
+}
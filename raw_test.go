@@ -0,0 +1,74 @@
+package pemutil
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestLoadRawKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	tests := []struct {
+		alg  string
+		data []byte
+	}{
+		{"ed25519", seed},
+		{"ed25519", []byte(priv)},
+		{"Ed25519", []byte(hex.EncodeToString(seed) + "\n")},
+		{"ed25519", []byte(base64.StdEncoding.EncodeToString(priv))},
+		{"ed25519", []byte(base64.RawURLEncoding.EncodeToString(seed))},
+		{"x25519", seed},
+		{"x25519", []byte(base64.StdEncoding.EncodeToString(seed))},
+	}
+	for i, test := range tests {
+		s, err := LoadRawKey(test.alg, test.data)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		switch key := s[PrivateKey].(type) {
+		case ed25519.PrivateKey:
+			if !key.Equal(priv) {
+				t.Errorf("test %d expected ed25519 keys to be equal", i)
+			}
+		case *ecdh.PrivateKey:
+			if string(key.Bytes()) != string(seed) {
+				t.Errorf("test %d expected x25519 keys to be equal", i)
+			}
+		default:
+			t.Fatalf("test %d unexpected key type %T", i, key)
+		}
+		// round trip
+		buf, err := s.Bytes()
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		z, err := DecodeBytes(buf)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !EqualKeys(s[PrivateKey], z[PrivateKey]) || !EqualKeys(s[PublicKey], z[PublicKey]) {
+			t.Errorf("test %d expected round tripped keys to be equal", i)
+		}
+	}
+	bad := append([]byte(nil), priv...)
+	bad[63] ^= 1
+	for i, test := range []struct {
+		alg  string
+		data []byte
+	}{
+		{"ed25519", bad},
+		{"ed25519", seed[:16]},
+		{"x25519", priv},
+		{"rsa", seed},
+	} {
+		if _, err := LoadRawKey(test.alg, test.data); err == nil {
+			t.Errorf("test %d expected error, got nil", i)
+		}
+	}
+}
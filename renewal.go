@@ -0,0 +1,133 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RenewalDelta is a report of what changed between an old and new
+// certificate for the same service, as returned by [RenewalDiff].
+type RenewalDelta struct {
+	// Rekeyed is whether the new certificate has a different public key.
+	Rekeyed bool `json:"rekeyed"`
+	// SubjectChanged is whether the subject changed.
+	SubjectChanged bool `json:"subjectChanged"`
+	// OldSubject is the old certificate's subject.
+	OldSubject string `json:"oldSubject"`
+	// NewSubject is the new certificate's subject.
+	NewSubject string `json:"newSubject"`
+	// IssuerChanged is whether the issuer changed.
+	IssuerChanged bool `json:"issuerChanged"`
+	// OldIssuer is the old certificate's issuer.
+	OldIssuer string `json:"oldIssuer"`
+	// NewIssuer is the new certificate's issuer.
+	NewIssuer string `json:"newIssuer"`
+	// AddedNames are the subject alternative names added, formatted as
+	// "DNS:<name>", "IP:<address>", "email:<address>", or "URI:<uri>".
+	AddedNames []string `json:"addedNames,omitempty"`
+	// RemovedNames are the subject alternative names removed.
+	RemovedNames []string `json:"removedNames,omitempty"`
+	// OldNotBefore is the old certificate's validity start.
+	OldNotBefore time.Time `json:"oldNotBefore"`
+	// OldNotAfter is the old certificate's validity end.
+	OldNotAfter time.Time `json:"oldNotAfter"`
+	// NewNotBefore is the new certificate's validity start.
+	NewNotBefore time.Time `json:"newNotBefore"`
+	// NewNotAfter is the new certificate's validity end.
+	NewNotAfter time.Time `json:"newNotAfter"`
+}
+
+// RenewalDiff reports what changed between the leaf certificates (see
+// [Store.Leaf]) in the old and new [Store] for the same service.
+func RenewalDiff(old, new Store) (*RenewalDelta, error) {
+	oldCert, ok := old.Leaf()
+	if !ok {
+		return nil, errors.New("old store does not contain a leaf certificate")
+	}
+	newCert, ok := new.Leaf()
+	if !ok {
+		return nil, errors.New("new store does not contain a leaf certificate")
+	}
+	d := &RenewalDelta{
+		Rekeyed:      !EqualKeys(oldCert.PublicKey, newCert.PublicKey),
+		OldSubject:   oldCert.Subject.String(),
+		NewSubject:   newCert.Subject.String(),
+		OldIssuer:    oldCert.Issuer.String(),
+		NewIssuer:    newCert.Issuer.String(),
+		OldNotBefore: oldCert.NotBefore,
+		OldNotAfter:  oldCert.NotAfter,
+		NewNotBefore: newCert.NotBefore,
+		NewNotAfter:  newCert.NotAfter,
+	}
+	d.SubjectChanged = d.OldSubject != d.NewSubject
+	d.IssuerChanged = d.OldIssuer != d.NewIssuer
+	oldNames, newNames := certificateNames(oldCert), certificateNames(newCert)
+	for name := range newNames {
+		if !oldNames[name] {
+			d.AddedNames = append(d.AddedNames, name)
+		}
+	}
+	for name := range oldNames {
+		if !newNames[name] {
+			d.RemovedNames = append(d.RemovedNames, name)
+		}
+	}
+	sort.Strings(d.AddedNames)
+	sort.Strings(d.RemovedNames)
+	return d, nil
+}
+
+// Changed returns true when anything other than the validity window changed.
+func (d *RenewalDelta) Changed() bool {
+	return d.Rekeyed || d.SubjectChanged || d.IssuerChanged || len(d.AddedNames) != 0 || len(d.RemovedNames) != 0
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (d *RenewalDelta) String() string {
+	var sb strings.Builder
+	if d.Rekeyed {
+		sb.WriteString("Key: rekeyed\n")
+	} else {
+		sb.WriteString("Key: unchanged\n")
+	}
+	if d.SubjectChanged {
+		fmt.Fprintf(&sb, "Subject: %s -> %s\n", d.OldSubject, d.NewSubject)
+	}
+	if d.IssuerChanged {
+		fmt.Fprintf(&sb, "Issuer: %s -> %s\n", d.OldIssuer, d.NewIssuer)
+	}
+	for _, name := range d.AddedNames {
+		fmt.Fprintf(&sb, "Added: %s\n", name)
+	}
+	for _, name := range d.RemovedNames {
+		fmt.Fprintf(&sb, "Removed: %s\n", name)
+	}
+	fmt.Fprintf(&sb, "Validity: %s - %s -> %s - %s\n",
+		d.OldNotBefore.UTC().Format(time.RFC3339), d.OldNotAfter.UTC().Format(time.RFC3339),
+		d.NewNotBefore.UTC().Format(time.RFC3339), d.NewNotAfter.UTC().Format(time.RFC3339),
+	)
+	return sb.String()
+}
+
+// certificateNames returns the subject alternative names of the
+// certificate.
+func certificateNames(cert *x509.Certificate) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range cert.DNSNames {
+		names["DNS:"+strings.ToLower(name)] = true
+	}
+	for _, ip := range cert.IPAddresses {
+		names["IP:"+ip.String()] = true
+	}
+	for _, email := range cert.EmailAddresses {
+		names["email:"+email] = true
+	}
+	for _, u := range cert.URIs {
+		names["URI:"+u.String()] = true
+	}
+	return names
+}
@@ -0,0 +1,72 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"reflect"
+	"testing"
+)
+
+func TestRenewalDiff(t *testing.T) {
+	old, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := old.SelfSign(ServerTemplate("example.com", "www.example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// same key
+	renewed := old.Only(PrivateKey, ECPrivateKey, PublicKey)
+	if _, err := renewed.SelfSign(ServerTemplate("example.com", "api.example.com", "10.0.0.1")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	d, err := RenewalDiff(old, renewed)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if d.Rekeyed || d.SubjectChanged || d.IssuerChanged {
+		t.Errorf("expected same key, subject, and issuer, got: %+v", d)
+	}
+	if exp := []string{"DNS:api.example.com", "IP:10.0.0.1"}; !reflect.DeepEqual(d.AddedNames, exp) {
+		t.Errorf("expected added %v, got: %v", exp, d.AddedNames)
+	}
+	if exp := []string{"DNS:www.example.com"}; !reflect.DeepEqual(d.RemovedNames, exp) {
+		t.Errorf("expected removed %v, got: %v", exp, d.RemovedNames)
+	}
+	if !d.Changed() {
+		t.Errorf("expected changed")
+	}
+	// rekeyed, issued by a CA
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ca.SelfSign(RootCATemplate("root")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rekeyed, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, err := ca.Issue(ServerTemplate("example.com", "www.example.com"), rekeyed[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// ca certificate first
+	z := Store{Certificate: ca.Certificates()}
+	if err := z.AddX509(cert); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	d, err = RenewalDiff(old, z)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !d.Rekeyed || !d.IssuerChanged || d.SubjectChanged || len(d.AddedNames) != 0 || len(d.RemovedNames) != 0 {
+		t.Errorf("expected rekey and issuer change, got: %+v", d)
+	}
+	if d.NewIssuer != "CN=root" {
+		t.Errorf("expected issuer CN=root, got: %q", d.NewIssuer)
+	}
+	if _, err := RenewalDiff(old, Store{}); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
@@ -0,0 +1,195 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// Certificate expiry buckets, ordered from soonest to latest (see
+// [ExpiryBuckets]).
+const (
+	ExpiryExpired  = "expired"
+	ExpiryWeek     = "<7d"
+	ExpiryMonth    = "<30d"
+	ExpiryQuarter  = "<90d"
+	ExpiryYear     = "<1y"
+	ExpiryLongTerm = ">=1y"
+)
+
+// ExpiryBuckets are the certificate expiry buckets, in order.
+var ExpiryBuckets = []string{
+	ExpiryExpired,
+	ExpiryWeek,
+	ExpiryMonth,
+	ExpiryQuarter,
+	ExpiryYear,
+	ExpiryLongTerm,
+}
+
+// Report is a summary of crypto primitives, such as for inventorying the
+// keys and certificates used across an organization (see [Store.Report]).
+// Multiple stores can be aggregated in a single report with
+// [Report.AddStore].
+type Report struct {
+	// Stores is the number of reported stores.
+	Stores int `json:"stores"`
+	// Blocks is the number of crypto primitives by block type, with each
+	// certificate counted separately.
+	Blocks map[BlockType]int `json:"blocks"`
+	// Keys is the number of distinct keys (compared by [KeyFingerprint]),
+	// including the keys of certificates and certificate requests.
+	Keys int `json:"keys"`
+	// Algorithms is the number of distinct keys by algorithm (ie, "RSA",
+	// "ECDSA", "Ed25519").
+	Algorithms map[string]int `json:"algorithms"`
+	// KeySizes is the number of distinct keys by algorithm and size in bits
+	// (ie, "RSA 2048", "ECDSA 256").
+	KeySizes map[string]int `json:"key_sizes"`
+	// Curves is the number of distinct EC and ECDH keys by curve name.
+	Curves map[string]int `json:"curves"`
+	// Signatures is the number of certificates by signature algorithm.
+	Signatures map[string]int `json:"signatures"`
+	// Expiry is the number of certificates by expiry bucket (see
+	// [ExpiryBuckets]).
+	Expiry map[string]int `json:"expiry"`
+
+	seen map[[32]byte]bool
+}
+
+// NewReport creates an empty report.
+func NewReport() *Report {
+	return &Report{
+		Blocks:     make(map[BlockType]int),
+		Algorithms: make(map[string]int),
+		KeySizes:   make(map[string]int),
+		Curves:     make(map[string]int),
+		Signatures: make(map[string]int),
+		Expiry:     make(map[string]int),
+		seen:       make(map[[32]byte]bool),
+	}
+}
+
+// Report returns a report summarizing the crypto primitives in the [Store].
+// Certificate expiry is determined using the current time ([DefaultClock], or
+// the clock set with [WithClock]).
+func (s Store) Report(opts ...ValidityOption) *Report {
+	o := newValidityOptions(opts...)
+	r := NewReport()
+	r.AddStore(s, o.clock.Now())
+	return r
+}
+
+// AddStore adds the crypto primitives in the [Store] to the report,
+// determining certificate expiry at now. Keys already added to the report
+// from other stores are not counted again. The report must have been created
+// with [NewReport].
+func (r *Report) AddStore(s Store, now time.Time) {
+	r.Stores++
+	for typ, v := range s {
+		switch {
+		case typ.IsMetadata():
+		case typ == Certificate:
+			r.Blocks[typ] += len(s.Certificates())
+		case typ == SessionTicketKey:
+			if keys, ok := v.(SessionTicketKeys); ok {
+				r.Blocks[typ] += len(keys)
+			}
+		default:
+			r.Blocks[typ]++
+		}
+	}
+	if key, ok := s.PrivateKey(); ok {
+		if k, ok := key.(interface{ Public() crypto.PublicKey }); ok {
+			r.addKey(k.Public())
+		}
+	}
+	if pub, ok := s.PublicKey(); ok {
+		r.addKey(pub)
+	}
+	if key, _, ok := s.SymmetricKey(); ok {
+		r.Algorithms["symmetric"]++
+		r.KeySizes[fmt.Sprintf("symmetric %d", 8*len(key))]++
+		r.Keys++
+	}
+	if req, ok := s.CertificateRequest(); ok {
+		r.addKey(req.PublicKey)
+	}
+	for _, cert := range s.Certificates() {
+		r.addKey(cert.PublicKey)
+		r.Signatures[cert.SignatureAlgorithm.String()]++
+		r.Expiry[expiryBucket(cert.NotAfter.Sub(now))]++
+	}
+}
+
+// addKey adds the public key to the report, when not previously added.
+func (r *Report) addKey(pub crypto.PublicKey) {
+	if k, err := CanonicalPublicKey(pub); err == nil {
+		pub = k
+	}
+	if fp, err := KeyFingerprint(pub); err == nil {
+		if r.seen[fp] {
+			return
+		}
+		r.seen[fp] = true
+	}
+	alg, size, curve := keyDescription(pub)
+	r.Keys++
+	r.Algorithms[alg]++
+	if size != 0 {
+		r.KeySizes[fmt.Sprintf("%s %d", alg, size)]++
+	}
+	if curve != "" {
+		r.Curves[curve]++
+	}
+}
+
+// keyDescription returns the algorithm, size in bits, and curve name of the
+// public key.
+func keyDescription(pub crypto.PublicKey) (string, int, string) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return "RSA", k.N.BitLen(), ""
+	case *ecdsa.PublicKey:
+		name := k.Params().Name
+		if nc, ok := curveByCurve(k.Curve); ok {
+			name = curveName(nc)
+		}
+		return "ECDSA", k.Params().BitSize, name
+	case ed25519.PublicKey:
+		return "Ed25519", 256, ""
+	case ed448.PublicKey:
+		return "Ed448", 456, ""
+	case *ecdh.PublicKey:
+		if k.Curve() == ecdh.X25519() {
+			return "X25519", 256, ""
+		}
+		return "ECDH", 0, fmt.Sprint(k.Curve())
+	}
+	return "unknown", 0, ""
+}
+
+// expiryBucket returns the expiry bucket for the time until a certificate
+// expires.
+func expiryBucket(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d < 0:
+		return ExpiryExpired
+	case d < 7*day:
+		return ExpiryWeek
+	case d < 30*day:
+		return ExpiryMonth
+	case d < 90*day:
+		return ExpiryQuarter
+	case d < 365*day:
+		return ExpiryYear
+	}
+	return ExpiryLongTerm
+}
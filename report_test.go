@@ -0,0 +1,69 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	now := time.Now()
+	ec, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tpl := ServerTemplate("example.com")
+	tpl.NotBefore, tpl.NotAfter = now.Add(-time.Hour), now.Add(10*24*time.Hour)
+	if _, err := ec.SelfSign(tpl); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ed, err := GenerateEd448KeySet()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	sym, err := GenerateSymmetricKeySet(32)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// public key of ec, counted once
+	signer, _ := ec.Signer()
+	pub := Store{PublicKey: signer.Public()}
+	r := NewReport()
+	for _, s := range []Store{ec, ed, sym, pub} {
+		r.AddStore(s, now)
+	}
+	if r.Stores != 4 {
+		t.Errorf("expected 4 stores, got: %d", r.Stores)
+	}
+	if r.Keys != 3 {
+		t.Errorf("expected 3 keys, got: %d", r.Keys)
+	}
+	tests := []struct {
+		m   map[string]int
+		k   string
+		exp int
+	}{
+		{r.Algorithms, "ECDSA", 1},
+		{r.Algorithms, "Ed448", 1},
+		{r.Algorithms, "symmetric", 1},
+		{r.KeySizes, "ECDSA 256", 1},
+		{r.KeySizes, "symmetric 256", 1},
+		{r.Curves, "P-256", 1},
+		{r.Signatures, "ECDSA-SHA256", 1},
+		{r.Expiry, ExpiryMonth, 1},
+	}
+	for i, test := range tests {
+		if n := test.m[test.k]; n != test.exp {
+			t.Errorf("test %d expected %s count %d, got: %d", i, test.k, test.exp, n)
+		}
+	}
+	if n := r.Blocks[Certificate]; n != 1 {
+		t.Errorf("expected 1 certificate, got: %d", n)
+	}
+	if n := r.Blocks[PublicKey]; n != 3 {
+		t.Errorf("expected 3 public keys, got: %d", n)
+	}
+	if r := ec.Report(); r.Keys != 1 || r.Expiry[ExpiryMonth] != 1 {
+		t.Errorf("expected 1 key expiring within 30 days, got: %+v", r)
+	}
+}
@@ -0,0 +1,171 @@
+package pemutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// XML Signature namespaces.
+const (
+	// XMLDSigNamespace is the XML Signature namespace.
+	XMLDSigNamespace = "http://www.w3.org/2000/09/xmldsig#"
+	// XMLDSig11Namespace is the XML Signature 1.1 namespace.
+	XMLDSig11Namespace = "http://www.w3.org/2009/xmldsig11#"
+	// SAMLMetadataNamespace is the SAML 2.0 metadata namespace.
+	SAMLMetadataNamespace = "urn:oasis:names:tc:SAML:2.0:metadata"
+)
+
+// X509CertificateValue returns the content of a ds:X509Certificate element for
+// the certificate, the unwrapped base64 encoding of its DER.
+func X509CertificateValue(cert *x509.Certificate) string {
+	return base64.StdEncoding.EncodeToString(cert.Raw)
+}
+
+// ParseX509CertificateValue parses the content of a ds:X509Certificate
+// element, ignoring any whitespace (as found in wrapped SAML metadata).
+func ParseX509CertificateValue(s string) (*x509.Certificate, error) {
+	buf, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid X509Certificate: %w", err)
+	}
+	return x509.ParseCertificate(buf)
+}
+
+// X509CertificateValue returns the content of a ds:X509Certificate element for
+// the leaf certificate in the [Store] (see [Store.Leaf]).
+func (s Store) X509CertificateValue() (string, error) {
+	cert, ok := s.Leaf()
+	if !ok {
+		return "", errors.New("store does not contain a certificate")
+	}
+	return X509CertificateValue(cert), nil
+}
+
+// KeyInfo returns a ds:KeyInfo XML fragment for the [Store]. When the store
+// contains a leaf certificate (see [Store.Leaf]), the fragment contains a
+// ds:X509Data element with the leaf certificate, otherwise it contains a
+// ds:KeyValue element with the RSA or EC public key.
+func (s Store) KeyInfo() ([]byte, error) {
+	v, err := s.keyInfo()
+	if err != nil {
+		return nil, err
+	}
+	return xml.MarshalIndent(v, "", "  ")
+}
+
+// SAMLKeyDescriptor returns a md:KeyDescriptor XML fragment for SAML 2.0
+// metadata, for the use ("signing", "encryption", or "" for both), containing
+// the ds:KeyInfo for the [Store] (see [Store.KeyInfo]).
+func (s Store) SAMLKeyDescriptor(use string) ([]byte, error) {
+	switch use {
+	case "", "signing", "encryption":
+	default:
+		return nil, fmt.Errorf("invalid SAML key descriptor use %q", use)
+	}
+	ki, err := s.keyInfo()
+	if err != nil {
+		return nil, err
+	}
+	return xml.MarshalIndent(samlKeyDescriptor{
+		XMLNS:   SAMLMetadataNamespace,
+		Use:     use,
+		KeyInfo: ki,
+	}, "", "  ")
+}
+
+// keyInfo builds the ds:KeyInfo for the [Store].
+func (s Store) keyInfo() (*xmlKeyInfo, error) {
+	ki := &xmlKeyInfo{
+		XMLNS: XMLDSigNamespace,
+	}
+	if cert, ok := s.Leaf(); ok {
+		ki.X509Data = &xmlX509Data{
+			Certificates: []string{X509CertificateValue(cert)},
+		}
+		return ki, nil
+	}
+	pub, err := CanonicalPublicKey(s)
+	if err != nil {
+		return nil, err
+	}
+	b64 := base64.StdEncoding.EncodeToString
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		ki.KeyValue = &xmlKeyValue{
+			RSAKeyValue: &xmlRSAKeyValue{
+				Modulus:  b64(k.N.Bytes()),
+				Exponent: b64(big.NewInt(int64(k.E)).Bytes()),
+			},
+		}
+	case *ecdsa.PublicKey:
+		c, ok := curveByCurve(k.Curve)
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %s", k.Params().Name)
+		}
+		n := (k.Params().BitSize + 7) / 8
+		point := append([]byte{4}, k.X.FillBytes(make([]byte, n))...)
+		point = append(point, k.Y.FillBytes(make([]byte, n))...)
+		ki.KeyValue = &xmlKeyValue{
+			ECKeyValue: &xmlECKeyValue{
+				XMLNS:      XMLDSig11Namespace,
+				NamedCurve: xmlNamedCurve{URI: "urn:oid:" + c.oid.String()},
+				PublicKey:  b64(point),
+			},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported KeyInfo public key type %T", pub)
+	}
+	return ki, nil
+}
+
+// samlKeyDescriptor is a SAML 2.0 metadata md:KeyDescriptor element.
+type samlKeyDescriptor struct {
+	XMLName xml.Name    `xml:"md:KeyDescriptor"`
+	XMLNS   string      `xml:"xmlns:md,attr"`
+	Use     string      `xml:"use,attr,omitempty"`
+	KeyInfo *xmlKeyInfo `xml:"ds:KeyInfo"`
+}
+
+// xmlKeyInfo is a XML Signature ds:KeyInfo element.
+type xmlKeyInfo struct {
+	XMLName  xml.Name     `xml:"ds:KeyInfo"`
+	XMLNS    string       `xml:"xmlns:ds,attr"`
+	X509Data *xmlX509Data `xml:"ds:X509Data,omitempty"`
+	KeyValue *xmlKeyValue `xml:"ds:KeyValue,omitempty"`
+}
+
+// xmlX509Data is a XML Signature ds:X509Data element.
+type xmlX509Data struct {
+	Certificates []string `xml:"ds:X509Certificate"`
+}
+
+// xmlKeyValue is a XML Signature ds:KeyValue element.
+type xmlKeyValue struct {
+	RSAKeyValue *xmlRSAKeyValue `xml:"ds:RSAKeyValue,omitempty"`
+	ECKeyValue  *xmlECKeyValue  `xml:"dsig11:ECKeyValue,omitempty"`
+}
+
+// xmlRSAKeyValue is a XML Signature ds:RSAKeyValue element.
+type xmlRSAKeyValue struct {
+	Modulus  string `xml:"ds:Modulus"`
+	Exponent string `xml:"ds:Exponent"`
+}
+
+// xmlECKeyValue is a XML Signature 1.1 dsig11:ECKeyValue element.
+type xmlECKeyValue struct {
+	XMLNS      string        `xml:"xmlns:dsig11,attr"`
+	NamedCurve xmlNamedCurve `xml:"dsig11:NamedCurve"`
+	PublicKey  string        `xml:"dsig11:PublicKey"`
+}
+
+// xmlNamedCurve is a XML Signature 1.1 dsig11:NamedCurve element.
+type xmlNamedCurve struct {
+	URI string `xml:"URI,attr"`
+}
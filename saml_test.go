@@ -0,0 +1,71 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/xml"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestKeyInfo(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), Rand())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(Rand(), tpl, tpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := Store{ECPrivateKey: key, Certificate: []*x509.Certificate{cert}}
+	buf, err := s.SAMLKeyDescriptor("signing")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var v struct {
+		Use  string `xml:"use,attr"`
+		Cert string `xml:"KeyInfo>X509Data>X509Certificate"`
+	}
+	if err := xml.Unmarshal(buf, &v); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if v.Use != "signing" {
+		t.Errorf("expected use signing, got: %q", v.Use)
+	}
+	z, err := ParseX509CertificateValue(v.Cert[:10] + "\n  " + v.Cert[10:])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !z.Equal(cert) {
+		t.Errorf("expected certificate to round trip")
+	}
+	if !bytes.Contains(buf, []byte(`<md:KeyDescriptor xmlns:md="`+SAMLMetadataNamespace+`" use="signing">`)) ||
+		!bytes.Contains(buf, []byte(`<ds:KeyInfo xmlns:ds="`+XMLDSigNamespace+`">`)) {
+		t.Errorf("expected namespaced elements, got:\n%s", buf)
+	}
+	// public key only
+	buf, err = Store{PublicKey: key.Public()}.KeyInfo()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.Contains(buf, []byte(`<dsig11:NamedCurve URI="urn:oid:1.2.840.10045.3.1.7">`)) {
+		t.Errorf("expected named curve, got:\n%s", buf)
+	}
+	if _, err := s.SAMLKeyDescriptor("other"); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,137 @@
+package pemutil
+
+import (
+	"os"
+	"sync"
+)
+
+// WithSecureMemory is a decode and load option to keep raw (symmetric) keys
+// in locked memory that will not be swapped to disk, where supported by the
+// platform. When loading files, the file data is also read into locked memory,
+// and is wiped after decoding. Use [Store.Wipe] to wipe and release the locked
+// memory when the keys are no longer needed.
+//
+// Parsed private keys (ie, RSA and ECDSA keys) are not kept in locked memory.
+// When memory cannot be locked (for example, because of resource limits, or
+// because the platform does not support it), regular memory is used.
+func WithSecureMemory() LoadOption {
+	return func(opts *loadOptions) {
+		opts.secure = true
+	}
+}
+
+// lockedMu guards the locked buffers.
+var lockedMu sync.Mutex
+
+// locked are the locked buffers, by address of their first byte.
+var locked = make(map[*byte][]byte)
+
+// secureAlloc allocates a buffer of length n in locked memory, falling back
+// to regular memory when memory cannot be locked.
+func secureAlloc(n int) []byte {
+	if n == 0 {
+		return nil
+	}
+	buf, err := lockedAlloc(n)
+	if err != nil {
+		return make([]byte, n)
+	}
+	lockedMu.Lock()
+	defer lockedMu.Unlock()
+	locked[&buf[0]] = buf
+	return buf
+}
+
+// secureCopy copies buf to locked memory, wiping buf. Returns buf when it is
+// already locked memory.
+func secureCopy(buf []byte) []byte {
+	if len(buf) == 0 {
+		return buf
+	}
+	lockedMu.Lock()
+	_, ok := locked[&buf[0]]
+	lockedMu.Unlock()
+	if ok {
+		return buf
+	}
+	z := secureAlloc(len(buf))
+	copy(z, buf)
+	wipe(buf)
+	return z
+}
+
+// secureFree wipes buf, releasing it when it is locked memory.
+func secureFree(buf []byte) {
+	wipe(buf)
+	if len(buf) == 0 {
+		return
+	}
+	lockedMu.Lock()
+	z, ok := locked[&buf[0]]
+	delete(locked, &buf[0])
+	lockedMu.Unlock()
+	if ok {
+		lockedFree(z)
+	}
+}
+
+// wipe zeroes buf.
+func wipe(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
+
+// secureReadFile reads filename into locked memory, calling f with the data,
+// and then wiping the data.
+func secureReadFile(filename string, f func([]byte) error) error {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	fi, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+	buf := secureAlloc(int(fi.Size()))
+	defer secureFree(buf)
+	n, err := fd.ReadAt(buf, 0)
+	if err != nil && n != len(buf) {
+		return err
+	}
+	return f(buf[:n])
+}
+
+// secureStore moves the raw keys decoded into the store to locked memory,
+// wiping the decoded keys. The raw key in the store before decoding (prev) is
+// not owned by the decoder (ie, it may be shared with another [Store]), and
+// is left as-is.
+func secureStore(s Store, prev interface{}) {
+	switch v := s[PrivateKey].(type) {
+	case []byte:
+		if p, ok := prev.([]byte); ok && len(p) == len(v) && (len(v) == 0 || &p[0] == &v[0]) {
+			return
+		}
+		s[PrivateKey] = secureCopy(v)
+	case *SymmetricKey:
+		if p, ok := prev.(*SymmetricKey); ok && p == v {
+			return
+		}
+		v.Key = secureCopy(v.Key)
+	}
+}
+
+// Wipe zeroes the raw (symmetric) keys in the [Store], releasing any locked
+// memory used by them (see [WithSecureMemory]), and removes all private keys
+// from the [Store]. Raw keys previously retrieved from the [Store] must not be
+// used after calling Wipe.
+func (s Store) Wipe() {
+	switch v := s[PrivateKey].(type) {
+	case []byte:
+		secureFree(v)
+	case *SymmetricKey:
+		secureFree(v.Key)
+	}
+	s.Remove(PrivateKey, RSAPrivateKey, ECPrivateKey)
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package pemutil
+
+import (
+	"errors"
+)
+
+// lockedAlloc allocates a buffer of length n in locked memory. Locking memory
+// is not supported on this platform.
+func lockedAlloc(n int) ([]byte, error) {
+	return nil, errors.New("locked memory is not supported")
+}
+
+// lockedFree releases a buffer allocated with lockedAlloc.
+func lockedFree(buf []byte) {}
@@ -0,0 +1,75 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecureMemory(t *testing.T) {
+	s, err := GenerateSymmetricKeySet(32)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := append([]byte(nil), s[PrivateKey].([]byte)...)
+	filename := filepath.Join(t.TempDir(), "key.pem")
+	if err := s.WriteFile(filename); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s0, err := LoadFile(filename, WithSecureMemory())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	key, _, ok := s0.SymmetricKey()
+	if !ok || !bytes.Equal(key, exp) {
+		t.Fatalf("expected loaded key to match")
+	}
+	// loading again must not reallocate the locked key
+	if err := Decode(s0, []byte{}, WithSecureMemory()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if k, _, _ := s0.SymmetricKey(); &k[0] != &key[0] {
+		t.Errorf("expected same key buffer")
+	}
+	s0.Wipe()
+	if _, _, ok := s0.SymmetricKey(); ok {
+		t.Errorf("expected key to be removed")
+	}
+	if len(s0) != 0 {
+		t.Errorf("expected empty store, got: %v", keys(s0))
+	}
+}
+
+func TestSecureMemoryDecodeNew(t *testing.T) {
+	s, err := GenerateSymmetricKeySet(32)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := append([]byte(nil), s[PrivateKey].([]byte)...)
+	ec, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := ec.Only(PublicKey).Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z, err := s.DecodeNew(buf, WithSecureMemory())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z.Wipe()
+	if key, _, ok := s.SymmetricKey(); !ok || !bytes.Equal(key, exp) {
+		t.Errorf("expected original key to be unmodified")
+	}
+	// keys added to the store are not wiped
+	key := append([]byte(nil), exp...)
+	z = Store{PrivateKey: key}
+	if err := z.Decode(buf, WithSecureMemory()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.Equal(key, exp) {
+		t.Errorf("expected key to be unmodified")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package pemutil
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// lockedAlloc allocates a buffer of length n in locked memory.
+func lockedAlloc(n int) ([]byte, error) {
+	buf, err := unix.Mmap(-1, 0, n, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Mlock(buf); err != nil {
+		_ = unix.Munmap(buf)
+		return nil, err
+	}
+	return buf, nil
+}
+
+// lockedFree releases a buffer allocated with lockedAlloc.
+func lockedFree(buf []byte) {
+	_ = unix.Munlock(buf)
+	_ = unix.Munmap(buf)
+}
@@ -0,0 +1,169 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// SignOption is a signing option.
+type SignOption func(*signOptions)
+
+// signOptions are signing options.
+type signOptions struct {
+	deterministic bool
+	rand          io.Reader
+}
+
+// WithDeterministic is a signing option to use deterministic nonces (RFC
+// 6979) when signing with ECDSA private keys, as required by some blockchain
+// and firmware signing ecosystems. Has no effect for other key types. See
+// [SignDeterministic] for the side-channel safety of the implementation.
+func WithDeterministic() SignOption {
+	return func(opts *signOptions) {
+		opts.deterministic = true
+	}
+}
+
+// WithSignRand is a signing option to set the random source used when
+// signing (default [Rand]).
+//
+// Warning: since Go 1.26, the random source is ignored for ECDSA signing
+// unless the program is run with GODEBUG cryptocustomrand=1 (see [SetRand]).
+func WithSignRand(r io.Reader) SignOption {
+	return func(opts *signOptions) {
+		opts.rand = r
+	}
+}
+
+// Sign signs the digest with the private key in the [Store] (see
+// [Store.Signer]), using the hash in opts. For Ed25519 and Ed448 keys, the
+// digest is the unhashed message, and opts.HashFunc() must be zero.
+func (s Store) Sign(digest []byte, opts crypto.SignerOpts, sopts ...SignOption) ([]byte, error) {
+	signer, ok := s.Signer()
+	if !ok {
+		return nil, errors.New("store does not contain a private key")
+	}
+	if err := s.CheckPurpose(PurposeSigning); err != nil {
+		return nil, err
+	}
+	o := signOptions{
+		rand: Rand(),
+	}
+	for _, opt := range sopts {
+		opt(&o)
+	}
+	if o.deterministic {
+		if key, ok := unwrapKey(signer).(*ecdsa.PrivateKey); ok {
+			return SignDeterministic(key, digest, opts.HashFunc())
+		}
+		if _, ok := signer.Public().(*ecdsa.PublicKey); ok {
+			return nil, errors.New("deterministic signing requires a local ECDSA private key")
+		}
+	}
+	return signer.Sign(o.rand, digest, opts)
+}
+
+// SignDeterministic signs the digest with the ECDSA private key using a
+// deterministic nonce (RFC 6979) derived using HMAC with the hash, returning
+// the ASN.1 encoded signature.
+//
+// When built with Go 1.24 or later, keys on the NIST curves (P-224, P-256,
+// P-384, and P-521) are signed using the standard library's constant-time
+// implementation. Otherwise (such as for curves added via [RegisterCurve]),
+// signing uses variable-time [math/big] arithmetic that is not side-channel
+// safe, and can leak information about the private key through timing.
+func SignDeterministic(key *ecdsa.PrivateKey, digest []byte, h crypto.Hash) ([]byte, error) {
+	if !h.Available() {
+		return nil, fmt.Errorf("hash %v is not available", h)
+	}
+	if sig, ok, err := signDeterministicStd(key, digest, h); ok {
+		return sig, err
+	}
+	c := key.Curve
+	n := c.Params().N
+	e := bits2int(digest, n.BitLen())
+	next := rfc6979Nonces(key.D, digest, n, h)
+	for k := next(); k != nil; k = next() {
+		x, _ := c.ScalarBaseMult(k.FillBytes(make([]byte, (n.BitLen()+7)/8)))
+		r := new(big.Int).Mod(x, n)
+		if r.Sign() == 0 {
+			continue
+		}
+		// s = k^-1 (e + r*d) mod n
+		sig := new(big.Int).Mul(r, key.D)
+		sig.Add(sig, e)
+		sig.Mul(sig, new(big.Int).ModInverse(k, n))
+		sig.Mod(sig, n)
+		if sig.Sign() == 0 {
+			continue
+		}
+		var b cryptobyte.Builder
+		b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1BigInt(r)
+			b.AddASN1BigInt(sig)
+		})
+		return b.Bytes()
+	}
+	return nil, errors.New("unable to generate nonce")
+}
+
+// rfc6979Nonces returns a func returning the successive RFC 6979 (section
+// 3.2) nonce candidates for the private key and digest, or nil when the
+// (practically unreachable) retry limit is reached.
+func rfc6979Nonces(d *big.Int, digest []byte, n *big.Int, h crypto.Hash) func() *big.Int {
+	qlen := n.BitLen()
+	rolen := (qlen + 7) / 8
+	x := d.FillBytes(make([]byte, rolen))
+	hb := new(big.Int).Mod(bits2int(digest, qlen), n).FillBytes(make([]byte, rolen))
+	mac := func(key []byte, data ...[]byte) []byte {
+		m := hmac.New(h.New, key)
+		for _, b := range data {
+			m.Write(b)
+		}
+		return m.Sum(nil)
+	}
+	v, k := bytes.Repeat([]byte{1}, h.Size()), make([]byte, h.Size())
+	k = mac(k, v, []byte{0}, x, hb)
+	v = mac(k, v)
+	k = mac(k, v, []byte{1}, x, hb)
+	v = mac(k, v)
+	first, tries := true, 0
+	return func() *big.Int {
+		for ; tries < 64; tries++ {
+			if !first {
+				k = mac(k, v, []byte{0})
+				v = mac(k, v)
+			}
+			first = false
+			var t []byte
+			for len(t) < rolen {
+				v = mac(k, v)
+				t = append(t, v...)
+			}
+			if c := bits2int(t, qlen); c.Sign() > 0 && c.Cmp(n) < 0 {
+				tries++
+				return c
+			}
+		}
+		return nil
+	}
+}
+
+// bits2int converts the bytes to an integer of at most qlen bits (RFC 6979,
+// section 2.3.2).
+func bits2int(b []byte, qlen int) *big.Int {
+	x := new(big.Int).SetBytes(b)
+	if blen := len(b) * 8; blen > qlen {
+		x.Rsh(x, uint(blen-qlen))
+	}
+	return x
+}
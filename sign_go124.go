@@ -0,0 +1,23 @@
+//go:build go1.24
+
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+)
+
+// signDeterministicStd signs the digest using the standard library's
+// constant-time RFC 6979 implementation, when supported for the key's curve
+// (the NIST curves). Returns false when not supported.
+func signDeterministicStd(key *ecdsa.PrivateKey, digest []byte, h crypto.Hash) ([]byte, bool, error) {
+	switch key.Curve {
+	case elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521():
+		if len(digest) == h.Size() {
+			sig, err := key.Sign(nil, digest, h)
+			return sig, true, err
+		}
+	}
+	return nil, false, nil
+}
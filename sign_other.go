@@ -0,0 +1,13 @@
+//go:build !go1.24
+
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+)
+
+// signDeterministicStd is not supported before Go 1.24.
+func signDeterministicStd(*ecdsa.PrivateKey, []byte, crypto.Hash) ([]byte, bool, error) {
+	return nil, false, nil
+}
@@ -0,0 +1,97 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestSignDeterministic(t *testing.T) {
+	// RFC 6979, appendix A.2.5 and A.2.7
+	d, _ := new(big.Int).SetString("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721", 16)
+	key := &ecdsa.PrivateKey{D: d}
+	key.Curve = elliptic.P256()
+	key.X, key.Y = key.Curve.ScalarBaseMult(d.Bytes())
+	s := Store{ECPrivateKey: key}
+	sha256Digest, sha512Digest := sha256.Sum256([]byte("sample")), sha512.Sum512([]byte("test"))
+	tests := []struct {
+		digest []byte
+		h      crypto.Hash
+		r, s   string
+	}{
+		{
+			sha256Digest[:], crypto.SHA256,
+			"EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+			"F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+		},
+		{
+			sha512Digest[:], crypto.SHA512,
+			"461D93F31B6540894788FD206C07CFA0CC35F46FA3C91816FFF1040AD1581A04",
+			"39AF9F15DE0DB8D97E72719C74820D304CE5226E32DEDAE67519E840D1194E55",
+		},
+	}
+	for i, test := range tests {
+		buf, err := s.Sign(test.digest, test.h, WithDeterministic())
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		var sig struct {
+			R, S *big.Int
+		}
+		if _, err := asn1.Unmarshal(buf, &sig); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if r := sig.R.Text(16); r != strings.ToLower(test.r) {
+			t.Errorf("test %d expected r %s, got: %s", i, strings.ToLower(test.r), r)
+		}
+		if s := sig.S.Text(16); s != strings.ToLower(test.s) {
+			t.Errorf("test %d expected s %s, got: %s", i, strings.ToLower(test.s), s)
+		}
+		if !ecdsa.VerifyASN1(&key.PublicKey, test.digest, buf) {
+			t.Errorf("test %d expected signature to verify", i)
+		}
+	}
+	// non-deterministic
+	a, err := s.Sign(sha256Digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b, err := s.Sign(sha256Digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if string(a) == string(b) {
+		t.Errorf("expected signatures to differ")
+	}
+}
+
+func TestSignDeterministicCustomCurve(t *testing.T) {
+	curve := secp256k1()
+	RegisterCurve(OIDSecp256k1, curve)
+	s, err := GenerateECKeySet(curve)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	digest := sha256.Sum256([]byte("sample"))
+	a, err := s.Sign(digest[:], crypto.SHA256, WithDeterministic())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b, err := s.Sign(digest[:], crypto.SHA256, WithDeterministic())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if string(a) != string(b) {
+		t.Errorf("expected signatures to be equal")
+	}
+	key, _ := s.ECPrivateKey()
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], a) {
+		t.Errorf("expected signature to verify")
+	}
+}
@@ -0,0 +1,79 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+)
+
+// KeyFingerprintHeader is the PEM header used to record the fingerprint (see
+// [KeyFingerprint]) of the key that created a signature.
+const KeyFingerprintHeader = "Key-Fingerprint"
+
+// SignedBytes returns the crypto primitives in the [Store] as PEM-encoded data
+// (see [Store.Bytes]), followed by a detached Ed25519 signature of the data
+// as a "SIGNATURE" block.
+//
+// Use [WithVerify] to verify the signature when decoding or loading the data.
+func (s Store) SignedBytes(key ed25519.PrivateKey) ([]byte, error) {
+	buf, err := s.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := DetachedSignature(buf, key)
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, sig...), nil
+}
+
+// DetachedSignature returns a "SIGNATURE" block containing a detached
+// Ed25519 signature of buf, such as for storing alongside a file and
+// verifying with [WithDetachedSignature].
+func DetachedSignature(buf []byte, key ed25519.PrivateKey) ([]byte, error) {
+	fp, err := KeyFingerprint(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type: Signature.String(),
+		Headers: map[string]string{
+			KeyFingerprintHeader: hex.EncodeToString(fp[:]),
+		},
+		Bytes: ed25519.Sign(key, buf),
+	}), nil
+}
+
+// WithVerify is a decode and load option to require that the PEM-encoded
+// data ends with a "SIGNATURE" block (see [Store.SignedBytes]) containing a
+// valid signature of the preceding data by one of the Ed25519 public keys.
+// The signature block is not added to the [Store].
+func WithVerify(pubs ...ed25519.PublicKey) LoadOption {
+	return func(opts *loadOptions) {
+		opts.verify = append([]ed25519.PublicKey{}, pubs...)
+	}
+}
+
+// VerifySigned verifies that buf ends with a "SIGNATURE" block containing a
+// valid signature of the preceding data by one of the Ed25519 public keys,
+// returning the signed data.
+func VerifySigned(buf []byte, pubs ...ed25519.PublicKey) ([]byte, error) {
+	const begin = "-----BEGIN " + string(Signature) + "-----"
+	i := bytes.LastIndex(buf, []byte(begin))
+	if i == -1 || (i != 0 && buf[i-1] != '\n') {
+		return nil, errors.New("missing signature")
+	}
+	block, rest := pem.Decode(buf[i:])
+	if block == nil || block.Type != Signature.String() || len(bytes.TrimSpace(rest)) != 0 {
+		return nil, errors.New("invalid signature block")
+	}
+	data := buf[:i]
+	for _, pub := range pubs {
+		if ed25519.Verify(pub, data, block.Bytes) {
+			return data, nil
+		}
+	}
+	return nil, errors.New("invalid signature")
+}
@@ -0,0 +1,58 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignedBytes(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := Store{Certificate: testCertificates(t, 2)}
+	buf, err := s.SignedBytes(key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	filename := filepath.Join(t.TempDir(), "bundle.pem")
+	if err := os.WriteFile(filename, buf, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s0, err := LoadFile(filename, WithVerify(other, pub))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(s0) != 1 || len(s0.Certificates()) != 2 {
+		t.Errorf("expected 2 certificates, got: %v", keys(s0))
+	}
+	// tampered
+	tampered := bytes.Replace(buf, []byte("CERTIFICATE-----\n"), []byte("CERTIFICATE-----\n\n"), 1)
+	unsigned, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		buf  []byte
+		pubs []ed25519.PublicKey
+	}{
+		{buf, []ed25519.PublicKey{other}},
+		{buf, nil},
+		{tampered, []ed25519.PublicKey{pub}},
+		{unsigned, []ed25519.PublicKey{pub}},
+		{append(buf, unsigned...), []ed25519.PublicKey{pub}},
+	}
+	for i, test := range tests {
+		if _, err := DecodeBytes(test.buf, WithVerify(test.pubs...)); err == nil {
+			t.Errorf("test %d expected error", i)
+		}
+	}
+}
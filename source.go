@@ -0,0 +1,169 @@
+package pemutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Source is a source of PEM-encoded crypto primitives.
+type Source interface {
+	// Load loads the crypto primitives from the source, returning an error
+	// wrapping [fs.ErrNotExist] when the source is not present.
+	Load(opts ...LoadOption) (Store, error)
+	// String returns a description of the source.
+	String() string
+}
+
+// FileSource returns a [Source] for the file.
+func FileSource(filename string) Source {
+	return fileSource(filename)
+}
+
+// fileSource is a file source.
+type fileSource string
+
+// Load satisfies the [Source] interface.
+func (src fileSource) Load(opts ...LoadOption) (Store, error) {
+	return LoadFile(string(src), opts...)
+}
+
+// String satisfies the [Source] interface.
+func (src fileSource) String() string {
+	return "file " + string(src)
+}
+
+// EnvSource returns a [Source] for the environment variable containing
+// PEM-encoded data.
+func EnvSource(name string) Source {
+	return envSource(name)
+}
+
+// envSource is a environment variable source.
+type envSource string
+
+// Load satisfies the [Source] interface.
+func (src envSource) Load(opts ...LoadOption) (Store, error) {
+	v, ok := os.LookupEnv(string(src))
+	if !ok || strings.TrimSpace(v) == "" {
+		return nil, fmt.Errorf("environment variable %s is not set: %w", string(src), fs.ErrNotExist)
+	}
+	s, err := DecodeBytes([]byte(v), opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.AddPublicKeys()
+	return s, nil
+}
+
+// String satisfies the [Source] interface.
+func (src envSource) String() string {
+	return "env " + string(src)
+}
+
+// BytesSource returns a [Source] for the PEM-encoded data (such as a default
+// embedded in the binary), with the name used as its description.
+func BytesSource(name string, buf []byte) Source {
+	return bytesSource{name: name, buf: buf}
+}
+
+// bytesSource is a bytes source.
+type bytesSource struct {
+	name string
+	buf  []byte
+}
+
+// Load satisfies the [Source] interface.
+func (src bytesSource) Load(opts ...LoadOption) (Store, error) {
+	if len(src.buf) == 0 {
+		return nil, fmt.Errorf("%s is empty: %w", src.name, fs.ErrNotExist)
+	}
+	s, err := DecodeBytes(src.buf, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.AddPublicKeys()
+	return s, nil
+}
+
+// String satisfies the [Source] interface.
+func (src bytesSource) String() string {
+	return src.name
+}
+
+// LoadChain loads crypto primitives from the first present source in the
+// chain, returning the [Store] and the source it was loaded from. For
+// example, a chain of a mounted secret file, an environment variable, and a
+// default embedded in the binary:
+//
+//	s, src, err := pemutil.LoadChain([]pemutil.Source{
+//		pemutil.FileSource("/run/secrets/key.pem"),
+//		pemutil.EnvSource("APP_KEY"),
+//		pemutil.BytesSource("embedded dev key", devKey),
+//	})
+//
+// Sources that are not present are skipped. A source that is present but
+// cannot be loaded stops the chain, so that a damaged secret is never
+// silently replaced by a later source. Returns a [*ChainError] reporting the
+// error for each source tried when no source could be loaded.
+func LoadChain(sources []Source, opts ...LoadOption) (Store, Source, error) {
+	cerr := new(ChainError)
+	for _, src := range sources {
+		s, err := src.Load(opts...)
+		if err == nil {
+			return s, src, nil
+		}
+		cerr.Errors = append(cerr.Errors, &SourceError{Source: src.String(), Err: err})
+		if !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+	if len(cerr.Errors) == 0 {
+		return nil, nil, errors.New("no sources")
+	}
+	return nil, nil, cerr
+}
+
+// SourceError is a source load error.
+type SourceError struct {
+	// Source is the source description.
+	Source string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (err *SourceError) Error() string {
+	return err.Source + ": " + err.Err.Error()
+}
+
+// Unwrap satisfies the [errors.Unwrap] interface.
+func (err *SourceError) Unwrap() error {
+	return err.Err
+}
+
+// ChainError is a source chain load error, containing the error for each
+// source tried.
+type ChainError struct {
+	Errors []*SourceError
+}
+
+// Error satisfies the error interface.
+func (err *ChainError) Error() string {
+	v := make([]string, len(err.Errors))
+	for i, e := range err.Errors {
+		v[i] = e.Error()
+	}
+	return "unable to load from any source: " + strings.Join(v, "; ")
+}
+
+// Unwrap returns the source errors.
+func (err *ChainError) Unwrap() []error {
+	v := make([]error, len(err.Errors))
+	for i, e := range err.Errors {
+		v[i] = e
+	}
+	return v
+}
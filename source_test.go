@@ -0,0 +1,72 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadChain(t *testing.T) {
+	a, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	abuf, err := a.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	bbuf, err := b.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := t.TempDir()
+	missing, invalid := filepath.Join(dir, "missing.pem"), filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("invalid"), 0o600); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	t.Setenv("PEMUTIL_TEST_KEY", string(abuf))
+	// env used when file is missing
+	s, src, err := LoadChain([]Source{
+		FileSource(missing),
+		EnvSource("PEMUTIL_TEST_KEY"),
+		BytesSource("default", bbuf),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if src.String() != "env PEMUTIL_TEST_KEY" || !EqualKeys(s[ECPrivateKey], a[ECPrivateKey]) {
+		t.Errorf("expected key from env, got: %s", src)
+	}
+	// default used when file and env are missing
+	s, src, err = LoadChain([]Source{
+		FileSource(missing),
+		EnvSource("PEMUTIL_TEST_MISSING"),
+		BytesSource("default", bbuf),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if src.String() != "default" || !EqualKeys(s[ECPrivateKey], b[ECPrivateKey]) {
+		t.Errorf("expected default key, got: %s", src)
+	}
+	// invalid file stops the chain
+	var cerr *ChainError
+	_, _, err = LoadChain([]Source{
+		FileSource(missing),
+		FileSource(invalid),
+		BytesSource("default", bbuf),
+	})
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected chain error, got: %v", err)
+	}
+	if len(cerr.Errors) != 2 || !errors.Is(cerr.Errors[0], fs.ErrNotExist) || errors.Is(cerr.Errors[1], fs.ErrNotExist) {
+		t.Errorf("expected missing and invalid errors, got: %v", err)
+	}
+}
@@ -3,23 +3,65 @@ package pemutil
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdh"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cloudflare/circl/sign/ed448"
 )
 
 // Store is a store containing crypto primitives.
 //
 // A store can contain any of the following crypto primitives:
 //
-//	[]byte 								 -- raw key
+//	[]byte                               -- raw key
+//	*SymmetricKey                        -- raw key with expiry
 //	*rsa.PrivateKey, *ecdsa.PrivateKey   -- rsa / ecdsa private key
 //	*rsa.PublicKey, *ecdsa.PublicKey     -- rsa / ecdsa public key
+//	ed25519.PrivateKey, ed25519.PublicKey -- ed25519 private / public key
+//	ed448.PrivateKey, ed448.PublicKey    -- ed448 private / public key
+//	*ecdh.PrivateKey, *ecdh.PublicKey    -- x25519 private / public key
 //	*x509.Certificate                    -- x509 certificate
+//	[]*x509.Certificate                  -- x509 certificate bundle / chain
+//	*x509.CertificateRequest             -- x509 certificate request
+//	*x509.RevocationList                 -- x509 certificate revocation list
+//	NonExportable                        -- non-exportable private key
+//	SessionTicketKeys                    -- tls session ticket keys
+//	ECHConfigList                        -- tls encrypted client hello configs
+//
+// A store can also contain metadata for its crypto primitives, stored under
+// pseudo block types that are not PEM block types (see [BlockType.IsMetadata]):
+//
+//	KeyAttributes                        -- private key attributes, as [Attributes]
+//	KeyPurposes                          -- private key purposes, as [Purposes]
+//	ExplanatoryText                      -- explanatory text (see [WithExplanatoryText])
+//
+// Metadata is written with the crypto primitives it belongs to by
+// [Store.Bytes], and is never written as a PEM block. Code iterating over a
+// store's block types should skip the metadata block types.
+//
+// A single certificate is stored as a *x509.Certificate. When more than one
+// CERTIFICATE block is decoded (or added with [Store.AddX509]), the
+// certificates are stored in decoded order as a []*x509.Certificate. When
+// decoded with [WithLazyLoad], certificates are stored as an unexported type
+// until resolved (see [Store.Resolve]).
+//
+// Note: previous versions returned a "block type CERTIFICATE already present"
+// error when decoding more than one certificate. Code that type asserts the
+// [Certificate] value directly (ie, s[Certificate].(*x509.Certificate)) fails
+// for bundles and chains, and should instead use [Store.Certificate] for the
+// first certificate, or [Store.Certificates] for all certificates, which
+// handle every form.
 type Store map[BlockType]interface{}
 
 // encOrder is the standard encode order for a [Store].
@@ -29,28 +71,168 @@ var encOrder = []BlockType{
 	ECPrivateKey,
 	PublicKey,
 	Certificate,
+	CertificateRequest,
+	RevocationList,
+	SessionTicketKey,
+	ECHConfig,
+}
+
+// bufPool is a pool of encode buffers.
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
 }
 
+// maxPoolBufLen is the maximum capacity of a encode buffer returned to the
+// pool.
+const maxPoolBufLen = 16 << 20
+
 // Bytes returns all crypto primitives in the [Store] as a single byte slice
-// containing the PEM-encoded versions of the crypto primitives.
+// containing the PEM-encoded versions of the crypto primitives. Non-exportable
+// private keys (see [NonExportable]) are skipped. When the [Store] contains
+// [KeyAttributes], the private key is encoded as a PKCS#8 "PRIVATE KEY" block
+// with the attributes. When the [Store] contains [ExplanatoryText], the text
+// is written before the corresponding blocks.
 func (s Store) Bytes() ([]byte, error) {
+	return s.Encode()
+}
+
+// Encode returns all crypto primitives in the [Store] as a single byte slice
+// containing the PEM-encoded versions of the crypto primitives (see
+// [Store.Bytes]), formatted using the encode options.
+func (s Store) Encode(opts ...EncodeOption) ([]byte, error) {
+	o, err := newEncodeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
 	if len(s) == 0 {
 		return nil, errors.New("store is empty")
 	}
+	if !o.java {
+		o.text = s.encodeText()
+	}
+	blocks, err := s.blocks(o.java)
+	if err != nil {
+		return nil, err
+	}
 	// encode
-	var res bytes.Buffer
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPoolBufLen {
+			buf.Reset()
+			bufPool.Put(buf)
+		}
+	}()
+	buf.Grow(blocksLen(blocks))
+	if err := o.encodeBlocks(buf, blocks); err != nil {
+		return nil, err
+	}
+	return append(make([]byte, 0, buf.Len()), buf.Bytes()...), nil
+}
+
+// blocks returns the PEM blocks for the exportable crypto primitives in the
+// [Store], in encoding order. When java is true, the blocks are converted to
+// the forms expected by Java (see [WithJavaCompat]).
+func (s Store) blocks(java bool) ([]*pem.Block, error) {
+	var blocks []*pem.Block
 	for _, k := range encOrder {
 		if p, ok := s[k]; ok {
-			buf, err := EncodePrimitive(p)
-			if err != nil {
-				return nil, err
+			if _, ok := p.(NonExportable); ok {
+				continue
+			}
+			if java {
+				b, err := javaBlocks(p)
+				if err != nil {
+					return nil, err
+				}
+				blocks = append(blocks, b...)
+				continue
+			}
+			if attrs, ok := s.Attributes(); ok && isPKCS8Key(p) {
+				b, err := attributesBlock(p, attrs)
+				if err != nil {
+					return nil, err
+				}
+				blocks = append(blocks, b)
+				continue
 			}
-			if _, err = res.Write(buf); err != nil {
+			b, err := primitiveBlocks(p)
+			if err != nil {
 				return nil, err
 			}
+			blocks = append(blocks, b...)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, errNoExportable
+	}
+	return blocks, nil
+}
+
+// errNoExportable is the error returned when a [Store] does not contain
+// exportable crypto primitives.
+var errNoExportable = errors.New("store does not contain exportable crypto primitives")
+
+// PublicBytes returns the public crypto primitives (public keys and
+// certificates) in the [Store] as a single byte slice containing the
+// PEM-encoded versions of the crypto primitives. When the [Store] does not
+// contain a public key, the public key for the private key is used.
+//
+// Useful for safely sharing the public subset of a keyset. Returns an error
+// when the [PublicKey] value is not a public key (such as a raw key), instead
+// of encoding it.
+func (s Store) PublicBytes() ([]byte, error) {
+	p := s.Only(PublicKey, Certificate, ExplanatoryText)
+	if _, ok := p[PublicKey]; !ok {
+		if key, ok := s.Signer(); ok {
+			p[PublicKey] = key.Public()
+		}
+	}
+	if v, ok := p[PublicKey]; ok {
+		switch v.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, ed448.PublicKey, *ecdh.PublicKey:
+		default:
+			return nil, fmt.Errorf("store contains a %T as the public key", v)
+		}
+	}
+	return p.Bytes()
+}
+
+// Remove removes the crypto primitives for the block types from the [Store].
+func (s Store) Remove(blockTypes ...BlockType) {
+	for _, typ := range blockTypes {
+		delete(s, typ)
+	}
+}
+
+// Only returns a new [Store] containing only the crypto primitives for the
+// block types.
+func (s Store) Only(blockTypes ...BlockType) Store {
+	z := make(Store)
+	for _, typ := range blockTypes {
+		if v, ok := s[typ]; ok {
+			z[typ] = v
+		}
+	}
+	return z
+}
+
+// Clone returns a copy of the [Store]. Slice and map values (such as raw
+// keys, Ed25519 keys, and certificate bundles) are copied, so that modifying
+// or wiping the clone (see [Store.Wipe]) does not modify the original. Parsed
+// crypto primitives (such as *rsa.PrivateKey and *x509.Certificate) are
+// shared.
+func (s Store) Clone() Store {
+	z := make(Store, len(s))
+	for typ, v := range s {
+		if l, ok := v.(*lazyCertificates); ok {
+			z[typ] = l.clone()
+			continue
 		}
+		z[typ] = copyValue(v)
 	}
-	return res.Bytes(), nil
+	return z
 }
 
 // AddPublicKeys adds the public keys for a [RSAPrivateKey] or [ECPrivateKey]
@@ -76,24 +258,69 @@ func (s Store) AddPublicKeys() {
 // Decode parses and decodes PEM-encoded data from buf, storing any resulting
 // crypto primitives encountered into the [Store]. The decoded PEM [BlockType]
 // will be used as the map key for each primitive.
-func (s Store) Decode(buf []byte) error {
-	return Decode(s, buf)
+//
+// On error, the [Store] may contain some of the decoded crypto primitives.
+// Use [Store.DecodeNew] to leave the [Store] unmodified.
+func (s Store) Decode(buf []byte, opts ...LoadOption) error {
+	return Decode(s, buf, opts...)
+}
+
+// DecodeNew parses and decodes PEM-encoded data from buf, returning a new
+// [Store] containing the crypto primitives in the [Store] and any crypto
+// primitives encountered. The [Store] is not modified, including when an
+// error is returned.
+//
+// See [DecodeBytes] for decoding into an empty [Store].
+func (s Store) DecodeNew(buf []byte, opts ...LoadOption) (Store, error) {
+	z := s.Clone()
+	if err := Decode(z, buf, opts...); err != nil {
+		return nil, err
+	}
+	return z, nil
 }
 
 // DecodeBlock decodes PEM block data, adding any crypto primitive encountered
 // in the [Store].
 func (s Store) DecodeBlock(block *pem.Block) error {
+	// legacy encrypted PEM blocks are only decrypted with WithPassword (using
+	// the deprecated x509 funcs)
+	if x509.IsEncryptedPEMBlock(block) {
+		return errors.New("encrypted PEM block requires a password (see WithPassword)")
+	}
+	switch BlockType(block.Type) {
+	case PrivateKey, RSAPrivateKey, ECPrivateKey:
+		if err := s.decodePurposes(block); err != nil {
+			return err
+		}
+	}
 	switch BlockType(block.Type) {
+	case EncryptedPrivateKey:
+		return errors.New("encrypted private key requires a password (see WithPassword)")
 	case PrivateKey:
 		// try pkcs1 and then pkcs8 decoding
 		key, err := ParsePKCSPrivateKey(block.Bytes)
+		if err == nil {
+			if attrs, err := ParsePKCS8Attributes(block.Bytes); err == nil && len(attrs) != 0 {
+				if err := s.add(KeyAttributes, Attributes(attrs)); err != nil {
+					return err
+				}
+			}
+		}
+		switch key.(type) {
+		case ed25519.PrivateKey, ed448.PrivateKey, *ecdh.PrivateKey:
+			return s.add(PrivateKey, key)
+		}
 		if err == nil {
 			return s.add(RSAPrivateKey, key)
 		}
 		// must be a raw key (ie, use decoded b64 value as key)
-		return s.add(PrivateKey, block.Bytes)
+		key, err = parseSymmetricKey(block.Bytes, block.Headers)
+		if err != nil {
+			return err
+		}
+		return s.add(PrivateKey, key)
 	case PublicKey:
-		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		key, err := ParsePKIXPublicKey(block.Bytes)
 		if err != nil {
 			// use the raw b64 decoded bytes
 			key = block.Bytes
@@ -107,7 +334,7 @@ func (s Store) DecodeBlock(block *pem.Block) error {
 		}
 		return s.add(RSAPrivateKey, key)
 	case ECPrivateKey:
-		key, err := x509.ParseECPrivateKey(block.Bytes)
+		key, err := ParseECPrivateKey(block.Bytes)
 		if err != nil {
 			return err
 		}
@@ -117,7 +344,28 @@ func (s Store) DecodeBlock(block *pem.Block) error {
 		if err != nil {
 			return err
 		}
-		return s.add(Certificate, cert)
+		s.addCertificate(cert)
+		return nil
+	case CertificateRequest:
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			return err
+		}
+		return s.add(CertificateRequest, csr)
+	case RevocationList:
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return err
+		}
+		return s.add(RevocationList, crl)
+	case SessionTicketKey:
+		return s.addSessionTicketKey(block.Bytes, block.Headers)
+	case ECHConfig:
+		list := ECHConfigList(block.Bytes)
+		if _, err := list.Configs(); err != nil {
+			return err
+		}
+		return s.add(ECHConfig, list)
 	}
 	return fmt.Errorf("unknown block type %s", block.Type)
 }
@@ -132,6 +380,46 @@ func (s Store) add(typ BlockType, v interface{}) error {
 	return nil
 }
 
+// AddX509 adds a x509 certificate (*x509.Certificate or
+// []*x509.Certificate), certificate request (*x509.CertificateRequest), or
+// revocation list (*x509.RevocationList) to the [Store]. Certificates are
+// appended to any certificates already present.
+func (s Store) AddX509(v interface{}) error {
+	switch z := v.(type) {
+	case *x509.Certificate:
+		s.addCertificate(z)
+	case []*x509.Certificate:
+		for _, cert := range z {
+			s.addCertificate(cert)
+		}
+	case *x509.CertificateRequest:
+		return s.add(CertificateRequest, z)
+	case *x509.RevocationList:
+		return s.add(RevocationList, z)
+	default:
+		return fmt.Errorf("unsupported x509 type %T", v)
+	}
+	return nil
+}
+
+// addLazyCertificate adds a certificate block to the [Store] that will be
+// parsed on first access, appending to any certificates already present.
+func (s Store) addLazyCertificate(block *pem.Block) {
+	var l *lazyCertificates
+	switch v := s[Certificate].(type) {
+	case *x509.Certificate:
+		l = newLazyCertificates(v)
+	case []*x509.Certificate:
+		l = newLazyCertificates(v...)
+	case *lazyCertificates:
+		l = v
+	default:
+		l = newLazyCertificates()
+	}
+	l.add(block)
+	s[Certificate] = l
+}
+
 // PublicKey returns the public key contained within the [Store].
 func (s Store) PublicKey() (crypto.PublicKey, bool) {
 	v, ok := s[PublicKey]
@@ -170,7 +458,7 @@ func (s Store) RSAPrivateKey() (*rsa.PrivateKey, bool) {
 	if !ok {
 		return nil, false
 	}
-	z, ok := v.(*rsa.PrivateKey)
+	z, ok := unwrapKey(v).(*rsa.PrivateKey)
 	return z, ok
 }
 
@@ -190,27 +478,107 @@ func (s Store) ECPrivateKey() (*ecdsa.PrivateKey, bool) {
 	if !ok {
 		return nil, false
 	}
-	z, ok := v.(*ecdsa.PrivateKey)
+	z, ok := unwrapKey(v).(*ecdsa.PrivateKey)
+	return z, ok
+}
+
+// Ed448PublicKey returns the Ed448 public key contained within the [Store].
+func (s Store) Ed448PublicKey() (ed448.PublicKey, bool) {
+	v, ok := s[PublicKey]
+	if !ok {
+		return nil, false
+	}
+	z, ok := v.(ed448.PublicKey)
+	return z, ok
+}
+
+// Ed448PrivateKey returns the Ed448 private key contained within the [Store].
+func (s Store) Ed448PrivateKey() (ed448.PrivateKey, bool) {
+	v, ok := s[PrivateKey]
+	if !ok {
+		return nil, false
+	}
+	z, ok := unwrapKey(v).(ed448.PrivateKey)
+	return z, ok
+}
+
+// Signer returns the private key contained within the [Store] as a
+// [crypto.Signer].
+func (s Store) Signer() (crypto.Signer, bool) {
+	v, ok := s.PrivateKey()
+	if !ok {
+		return nil, false
+	}
+	z, ok := v.(crypto.Signer)
 	return z, ok
 }
 
-// Certificate returns the X509 certificate contained within the [Store].
-func (s Store) Certificate() (*x509.Certificate, bool) {
-	v, ok := s[Certificate]
+// CertificateRequest returns the X509 certificate request contained within
+// the [Store].
+func (s Store) CertificateRequest() (*x509.CertificateRequest, bool) {
+	v, ok := s[CertificateRequest]
 	if !ok {
 		return nil, false
 	}
-	z, ok := v.(*x509.Certificate)
+	z, ok := v.(*x509.CertificateRequest)
+	return z, ok
+}
+
+// RevocationList returns the X509 certificate revocation list contained
+// within the [Store].
+func (s Store) RevocationList() (*x509.RevocationList, bool) {
+	v, ok := s[RevocationList]
+	if !ok {
+		return nil, false
+	}
+	z, ok := v.(*x509.RevocationList)
 	return z, ok
 }
 
 // LoadFile loads crypto primitives from PEM encoded data stored in filename.
-func (s Store) LoadFile(filename string) error {
+func (s Store) LoadFile(filename string, opts ...LoadOption) error {
+	o := newLoadOptions(opts...)
+	read := readFile
+	switch {
+	case o.secure:
+		read = secureReadFile
+	case o.mmap && !o.checksIntegrity():
+		// mapped data can be modified after being checked
+		read = mmapFile
+	}
+	err := read(filename, func(buf []byte) error {
+		logEvent(o.logger, slog.LevelDebug, "pemutil: read file", "file", filename, "size", len(buf))
+		return Decode(s, buf, append(opts[:len(opts):len(opts)], withSource(filename))...)
+	})
+	if err != nil {
+		logEvent(o.logger, slog.LevelError, "pemutil: load failed", "file", filename, "error", err)
+		return err
+	}
+	logEvent(o.logger, slog.LevelInfo, "pemutil: loaded file", "file", filename, "types", s.blockTypes())
+	return nil
+}
+
+// LoadFileNew loads crypto primitives from PEM encoded data stored in
+// filename, returning a new [Store] containing the crypto primitives in the
+// [Store] and those loaded. The [Store] is not modified, including when an
+// error is returned.
+//
+// See [LoadFile] for loading into an empty [Store].
+func (s Store) LoadFileNew(filename string, opts ...LoadOption) (Store, error) {
+	z := s.Clone()
+	if err := z.LoadFile(filename, opts...); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// readFile reads filename, calling f with the data.
+func readFile(filename string, f func([]byte) error) error {
 	buf, err := os.ReadFile(filename)
 	if err != nil {
 		return err
 	}
-	return Decode(s, buf)
+	return f(buf)
 }
 
 // LoadFile creates a store and loads any crypto primitives in the PEM encoded
@@ -219,9 +587,9 @@ func (s Store) LoadFile(filename string) error {
 // Note: calls [Store.AddPublicKeys] after successfully loading a file. If that
 // behavior is not desired, please manually create the [Store] and call
 // [Decode], or [DecodeBlock].
-func LoadFile(filename string) (Store, error) {
+func LoadFile(filename string, opts ...LoadOption) (Store, error) {
 	s := make(Store)
-	if err := s.LoadFile(filename); err != nil {
+	if err := s.LoadFile(filename, opts...); err != nil {
 		return nil, err
 	}
 	s.AddPublicKeys()
@@ -229,11 +597,78 @@ func LoadFile(filename string) (Store, error) {
 }
 
 // WriteFile writes the crypto primitives in the [Store] to filename with mode
-// 0600.
-func (s Store) WriteFile(filename string) error {
+// 0600, using the write options (see [WriteOptions]).
+func (s Store) WriteFile(filename string, opts ...WriteOption) error {
 	buf, err := s.Bytes()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, buf, 0o600)
+	return WriteFile(filename, buf, 0o600, opts...)
+}
+
+// WriteFiles writes the crypto primitives in the [Store] to separate files in
+// dir, using the conventional layout:
+//
+//	<baseName>-private.pem -- private keys (mode 0600)
+//	<baseName>-public.pem  -- public key (mode 0644)
+//	<baseName>-cert.pem    -- certificates (mode 0644)
+//
+// Files are only written for the crypto primitives present in the [Store],
+// and non-exportable private keys (see [NonExportable]) are skipped.
+func (s Store) WriteFiles(dir, baseName string, opts ...WriteOption) error {
+	if len(s) == 0 {
+		return errors.New("store is empty")
+	}
+	o := newWriteOptions(opts...)
+	for _, f := range pairFiles {
+		z := s.exportable().Only(f.types...)
+		if len(z) == 0 {
+			continue
+		}
+		buf, err := z.Bytes()
+		if err != nil {
+			return err
+		}
+		if err := o.writeFile(filepath.Join(dir, baseName+f.suffix), buf, f.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadPair creates a store and loads any crypto primitives in the files in
+// dir using the conventional layout written by [Store.WriteFiles]. Files
+// that do not exist are skipped, but at least one file must exist.
+//
+// Note: calls [Store.AddPublicKeys] after successfully loading the files.
+func LoadPair(dir, baseName string, opts ...LoadOption) (Store, error) {
+	s := make(Store)
+	opts = append(opts[:len(opts):len(opts)], withOrigins(make(map[string]string)))
+	var found bool
+	for _, f := range pairFiles {
+		switch err := s.LoadFile(filepath.Join(dir, baseName+f.suffix), opts...); {
+		case errors.Is(err, fs.ErrNotExist):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("no %s-{private,public,cert}.pem files in %s", baseName, dir)
+	}
+	s.AddPublicKeys()
+	return s, nil
+}
+
+// pairFiles are the conventional file layout for [Store.WriteFiles] and
+// [LoadPair].
+var pairFiles = []struct {
+	suffix string
+	mode   os.FileMode
+	types  []BlockType
+}{
+	{"-private.pem", 0o600, []BlockType{PrivateKey, RSAPrivateKey, ECPrivateKey, KeyAttributes, KeyPurposes}},
+	{"-public.pem", 0o644, []BlockType{PublicKey}},
+	{"-cert.pem", 0o644, []BlockType{Certificate}},
 }
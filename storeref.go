@@ -0,0 +1,67 @@
+package pemutil
+
+import (
+	"sync/atomic"
+)
+
+// StoreRef is a reference to a [Store] that can be atomically replaced while
+// in use, allowing a new keyset to be published (such as after a reload or a
+// key rotation) to readers without locking.
+//
+// Stores published to a StoreRef must be treated as read-only snapshots. Use
+// [StoreRef.Update] to make copy-on-write changes.
+//
+// The zero value is a reference to a nil [Store].
+type StoreRef struct {
+	p atomic.Pointer[Store]
+}
+
+// NewStoreRef creates a reference to the [Store].
+func NewStoreRef(s Store) *StoreRef {
+	r := new(StoreRef)
+	r.Store(s)
+	return r
+}
+
+// Load returns the referenced [Store].
+func (r *StoreRef) Load() Store {
+	if p := r.p.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// Store replaces the referenced [Store].
+func (r *StoreRef) Store(s Store) {
+	r.p.Store(&s)
+}
+
+// Swap replaces the referenced [Store], returning the previous [Store].
+func (r *StoreRef) Swap(s Store) Store {
+	if p := r.p.Swap(&s); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// Update applies f to a clone (see [Store.Clone]) of the referenced [Store]
+// and publishes the result. When the reference is concurrently replaced, f is
+// applied again to a clone of the new [Store]. When f returns an error, the
+// referenced [Store] is not changed.
+func (r *StoreRef) Update(f func(Store) error) error {
+	for {
+		p := r.p.Load()
+		var s Store
+		if p != nil {
+			s = (*p).Clone()
+		} else {
+			s = make(Store)
+		}
+		if err := f(s); err != nil {
+			return err
+		}
+		if r.p.CompareAndSwap(p, &s) {
+			return nil
+		}
+	}
+}
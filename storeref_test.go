@@ -0,0 +1,67 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestStoreRef(t *testing.T) {
+	var r StoreRef
+	if s := r.Load(); s != nil {
+		t.Errorf("expected nil store, got: %v", s)
+	}
+	a, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	r.Store(a)
+	if old := r.Swap(b); !EqualKeys(old[ECPrivateKey], a[ECPrivateKey]) {
+		t.Errorf("expected previous store to be returned")
+	}
+	// copy-on-write
+	snapshot := r.Load()
+	if err := r.Update(func(s Store) error {
+		s.Remove(ECPrivateKey)
+		return nil
+	}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := snapshot[ECPrivateKey]; !ok {
+		t.Errorf("expected snapshot to be unmodified")
+	}
+	if _, ok := r.Load()[ECPrivateKey]; ok {
+		t.Errorf("expected private key to be removed")
+	}
+	// error
+	if err := r.Update(func(s Store) error {
+		s.Remove(PublicKey)
+		return errors.New("failed")
+	}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, ok := r.Load()[PublicKey]; !ok {
+		t.Errorf("expected public key to be present")
+	}
+	// concurrent updates
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = r.Update(func(s Store) error {
+				s[BlockType(string(rune('A'+i)))] = []byte{byte(i)}
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	if n := len(r.Load()); n != 17 {
+		t.Errorf("expected 17 entries, got: %d", n)
+	}
+}
@@ -0,0 +1,147 @@
+package pemutil
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// StrictError is a RFC 7468 strict decoding violation.
+type StrictError struct {
+	// Line is the 1-based line number of the violation.
+	Line int
+	// Label is the label of the enclosing block, if any.
+	Label string
+	// Reason is the violation.
+	Reason string
+}
+
+// Error satisfies the error interface.
+func (err *StrictError) Error() string {
+	if err.Label != "" {
+		return fmt.Sprintf("line %d: %s: %s", err.Line, err.Label, err.Reason)
+	}
+	return fmt.Sprintf("line %d: %s", err.Line, err.Reason)
+}
+
+// WithStrict is a decode and load option to enforce the RFC 7468 strict
+// textual encoding rules, returning a [*StrictError] describing the first
+// violation encountered. Specifically:
+//
+//   - labels must be well-formed, and BEGIN and END labels must match
+//   - blocks must not contain headers, blank lines, or trailing whitespace
+//   - base64 lines must be exactly 64 characters, except for the last line
+//   - base64 padding may only appear at the end of the last line
+func WithStrict() LoadOption {
+	return func(opts *loadOptions) {
+		opts.strict = true
+	}
+}
+
+// checkStrict checks that buf conforms to the RFC 7468 strict textual
+// encoding rules.
+func checkStrict(buf []byte) error {
+	const (
+		pre  = "-----BEGIN "
+		post = "-----END "
+		tail = "-----"
+	)
+	var label string
+	var inside, padded bool
+	var start, prev, n int
+	lines := bytes.Split(buf, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		lineNo := i + 1
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		violation := func(reason string, v ...interface{}) error {
+			return &StrictError{Line: lineNo, Label: label, Reason: fmt.Sprintf(reason, v...)}
+		}
+		switch {
+		case !inside && bytes.HasPrefix(line, []byte(pre)):
+			if !bytes.HasSuffix(line, []byte(tail)) || len(line) < len(pre)+len(tail) {
+				return violation("malformed BEGIN line")
+			}
+			label = string(line[len(pre) : len(line)-len(tail)])
+			if !validLabel(label) {
+				return violation("invalid label %q", label)
+			}
+			inside, padded, start, prev, n = true, false, lineNo, 0, 0
+		case !inside:
+			// explanatory text between blocks
+		case bytes.HasPrefix(line, []byte(post)):
+			if !bytes.HasSuffix(line, []byte(tail)) || len(line) < len(post)+len(tail) {
+				return violation("malformed END line")
+			}
+			if end := string(line[len(post) : len(line)-len(tail)]); end != label {
+				return violation("END label %q does not match BEGIN label", end)
+			}
+			if n%4 != 0 {
+				return violation("base64 data length %d is not a multiple of 4", n)
+			}
+			inside, label = false, ""
+		case len(line) == 0:
+			return violation("blank line")
+		case bytes.IndexByte(line, ':') != -1:
+			return violation("headers are not permitted")
+		case line[len(line)-1] == ' ' || line[len(line)-1] == '\t':
+			return violation("trailing whitespace")
+		default:
+			if prev != 0 && prev != 64 {
+				return violation("previous base64 line length %d is not 64", prev)
+			}
+			if padded {
+				return violation("base64 data after padding")
+			}
+			if len(line) > 64 {
+				return violation("base64 line length %d exceeds 64", len(line))
+			}
+			for j, c := range line {
+				switch {
+				case c == '=':
+					for _, d := range line[j:] {
+						if d != '=' {
+							return violation("base64 data after padding")
+						}
+					}
+					if len(line)-j > 2 {
+						return violation("too much base64 padding")
+					}
+					padded = true
+				case !isBase64(c):
+					return violation("invalid base64 character %q", c)
+				}
+				if padded {
+					break
+				}
+			}
+			prev, n = len(line), n+len(line)
+		}
+	}
+	if inside {
+		return &StrictError{Line: start, Label: label, Reason: "block is not terminated"}
+	}
+	return nil
+}
+
+// validLabel determines if s is a valid RFC 7468 label.
+func validLabel(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '-' || c == ' ':
+			// must be followed by a labelchar
+			if i == 0 || i == len(s)-1 || s[i+1] == '-' || s[i+1] == ' ' {
+				return false
+			}
+		case c < 0x21 || c > 0x7e:
+			return false
+		}
+	}
+	return true
+}
+
+// isBase64 determines if c is a base64 character.
+func isBase64(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '+' || c == '/'
+}
@@ -0,0 +1,56 @@
+package pemutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.pem")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, file := range files {
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if err := checkStrict(buf); err != nil {
+			t.Errorf("%s expected no error, got: %v", file, err)
+		}
+	}
+	line64 := strings.Repeat("A", 64)
+	tests := []struct {
+		s    string
+		line int
+	}{
+		{"-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE----", 0},
+		{"-----BEGIN CERTIFICATE-----\nAAAA\n-----END X509 CRL-----\n", 3},
+		{"-----BEGIN -CERT-----\nAAAA\n-----END -CERT-----\n", 1},
+		{"-----BEGIN CERTIFICATE-----\nHeader: 1\n\nAAAA\n-----END CERTIFICATE-----\n", 2},
+		{"-----BEGIN CERTIFICATE-----\nAAAA\n\n-----END CERTIFICATE-----\n", 3},
+		{"-----BEGIN CERTIFICATE-----\nAAAA \n-----END CERTIFICATE-----\n", 2},
+		{"-----BEGIN CERTIFICATE-----\nAAAA\nAAAA\n-----END CERTIFICATE-----\n", 3},
+		{"-----BEGIN CERTIFICATE-----\n" + line64 + "A\n-----END CERTIFICATE-----\n", 2},
+		{"-----BEGIN CERTIFICATE-----\nAA==AA\n-----END CERTIFICATE-----\n", 2},
+		{"-----BEGIN CERTIFICATE-----\nAA!A\n-----END CERTIFICATE-----\n", 2},
+		{"-----BEGIN CERTIFICATE-----\nAAA\n-----END CERTIFICATE-----\n", 3},
+		{"-----BEGIN CERTIFICATE-----\nAAAA\n", 1},
+	}
+	for i, test := range tests {
+		err := checkStrict([]byte(test.s))
+		var serr *StrictError
+		switch {
+		case !errors.As(err, &serr):
+			t.Errorf("test %d expected *StrictError, got: %v", i, err)
+		case test.line != 0 && serr.Line != test.line:
+			t.Errorf("test %d expected line %d, got: %v", i, test.line, err)
+		}
+	}
+	if err := checkStrict([]byte("text\n-----BEGIN CERTIFICATE-----\r\n" + line64 + "\r\nAA==\r\n-----END CERTIFICATE-----\r\n")); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package pemutil
+
+// Algorithm describes a key generation algorithm supported by the package.
+type Algorithm struct {
+	// Name is the algorithm name.
+	Name string `json:"name"`
+	// Description is a short description of the algorithm.
+	Description string `json:"description"`
+	// KeySizes are the suggested key sizes (in bytes for symmetric keys, and
+	// bits for RSA keys).
+	KeySizes []int `json:"key_sizes,omitempty"`
+	// MinKeySize is the minimum key size, or 0 when there is no minimum.
+	MinKeySize int `json:"min_key_size,omitempty"`
+	// Curves are the supported curve names.
+	Curves []string `json:"curves,omitempty"`
+	// Encrypt indicates the generated private key can be encrypted with a
+	// password (see [Store.EncryptedBytes]).
+	Encrypt bool `json:"encrypt"`
+}
+
+// BlockTypeInfo describes a PEM block type supported by the package.
+type BlockTypeInfo struct {
+	// Type is the block type.
+	Type BlockType `json:"type"`
+	// Description is a short description of the block type.
+	Description string `json:"description"`
+	// Private indicates the block contains private key material.
+	Private bool `json:"private"`
+	// Encrypted indicates the block contents are encrypted.
+	Encrypted bool `json:"encrypted"`
+}
+
+// SupportedAlgorithms returns the supported key generation algorithms. The
+// returned EC curves include curves registered via [RegisterCurve].
+func SupportedAlgorithms() []Algorithm {
+	var names []string
+	for _, nc := range curves() {
+		names = append(names, curveName(nc))
+	}
+	return []Algorithm{
+		{
+			Name:        "sym",
+			Description: "symmetric key",
+			KeySizes:    []int{16, 24, 32, 64},
+			MinKeySize:  1,
+		},
+		{
+			Name:        "rsa",
+			Description: "RSA private key",
+			KeySizes:    []int{2048, 3072, 4096},
+			MinKeySize:  MinRSABitLen,
+			Encrypt:     true,
+		},
+		{
+			Name:        "ecc",
+			Description: "ECDSA private key",
+			Curves:      names,
+			Encrypt:     true,
+		},
+		{
+			Name:        "ed448",
+			Description: "Ed448 private key",
+			Encrypt:     true,
+		},
+	}
+}
+
+// SupportedBlockTypes returns the supported PEM block types.
+func SupportedBlockTypes() []BlockTypeInfo {
+	return []BlockTypeInfo{
+		{PrivateKey, "PKCS#8 private key", true, false},
+		{RSAPrivateKey, "PKCS#1 RSA private key", true, false},
+		{ECPrivateKey, "SEC1 EC private key", true, false},
+		{EncryptedPrivateKey, "PKCS#8 password encrypted private key", true, true},
+		{PublicKey, "PKIX public key", false, false},
+		{Certificate, "X.509 certificate", false, false},
+		{CertificateRequest, "PKCS#10 certificate request", false, false},
+		{RevocationList, "X.509 certificate revocation list", false, false},
+		{EncryptedKeyset, "password encrypted keyset", true, true},
+		{Signature, "detached signature", false, false},
+		{SessionTicketKey, "TLS session ticket key", true, false},
+		{ECHConfig, "Encrypted Client Hello config list", false, false},
+	}
+}
+
+// curveName returns the display name of the named curve.
+func curveName(nc namedCurve) string {
+	if !nc.std {
+		if name, ok := curveNames[nc.oid.String()]; ok {
+			return name
+		}
+	}
+	if params := nc.curve.Params(); params != nil && params.Name != "" {
+		return params.Name
+	}
+	return nc.oid.String()
+}
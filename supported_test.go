@@ -0,0 +1,41 @@
+package pemutil
+
+import (
+	"testing"
+)
+
+func TestSupportedAlgorithms(t *testing.T) {
+	algs := SupportedAlgorithms()
+	m := make(map[string]Algorithm)
+	for _, alg := range algs {
+		m[alg.Name] = alg
+	}
+	for i, name := range []string{"sym", "rsa", "ecc", "ed448"} {
+		if _, ok := m[name]; !ok {
+			t.Errorf("test %d expected algorithm %q", i, name)
+		}
+	}
+	if m["rsa"].MinKeySize != MinRSABitLen || !m["rsa"].Encrypt {
+		t.Errorf("expected rsa min key size %d and encrypt, got: %+v", MinRSABitLen, m["rsa"])
+	}
+	if m["sym"].Encrypt {
+		t.Errorf("expected sym to not be encrypt-capable")
+	}
+	// every listed curve should be resolvable
+	for i, name := range m["ecc"].Curves {
+		if _, err := CurveByName(name); err != nil {
+			t.Errorf("test %d (%s) expected no error, got: %v", i, name, err)
+		}
+	}
+}
+
+func TestSupportedBlockTypes(t *testing.T) {
+	for i, info := range SupportedBlockTypes() {
+		if info.Encrypted && !info.Private {
+			t.Errorf("test %d (%s) expected encrypted block type to be private", i, info.Type)
+		}
+		if info.Type == KeyAttributes {
+			t.Errorf("test %d expected %s to not be listed", i, KeyAttributes)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package pemutil
+
+import (
+	"time"
+)
+
+// NotAfterHeader is the PEM header used to record the expiry of a symmetric
+// key.
+const NotAfterHeader = "Not-After"
+
+// SymmetricKey is a raw (symmetric) key with an expiry. Raw keys decoded from
+// a "PRIVATE KEY" block with a [NotAfterHeader] header are stored as a
+// *SymmetricKey, and raw keys without an expiry are stored as a []byte.
+type SymmetricKey struct {
+	// Key is the raw key.
+	Key []byte
+	// NotAfter is the time after which the key should no longer be used.
+	NotAfter time.Time
+}
+
+// NewSymmetricKey creates a symmetric key that expires after the time to
+// live.
+func NewSymmetricKey(key []byte, ttl time.Duration) *SymmetricKey {
+	return &SymmetricKey{
+		Key:      key,
+		NotAfter: DefaultClock().Now().Add(ttl).UTC().Truncate(time.Second),
+	}
+}
+
+// headers returns the PEM headers for the symmetric key.
+func (k *SymmetricKey) headers() map[string]string {
+	if k.NotAfter.IsZero() {
+		return nil
+	}
+	return map[string]string{
+		NotAfterHeader: k.NotAfter.UTC().Format(time.RFC3339),
+	}
+}
+
+// parseSymmetricKey parses a raw key with the PEM headers.
+func parseSymmetricKey(key []byte, headers map[string]string) (interface{}, error) {
+	v, ok := headers[NotAfterHeader]
+	if !ok {
+		return key, nil
+	}
+	notAfter, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return &SymmetricKey{
+		Key:      key,
+		NotAfter: notAfter,
+	}, nil
+}
+
+// SymmetricKey returns the raw (symmetric) key contained within the [Store],
+// and its expiry (the zero time when the key does not expire).
+func (s Store) SymmetricKey() ([]byte, time.Time, bool) {
+	switch v := s[PrivateKey].(type) {
+	case []byte:
+		return v, time.Time{}, true
+	case *SymmetricKey:
+		return v.Key, v.NotAfter, true
+	}
+	return nil, time.Time{}, false
+}
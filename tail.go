@@ -0,0 +1,108 @@
+package pemutil
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Tailer incrementally decodes PEM blocks appended to a file, such as a
+// log-style file of certificates written by a CT monitor. Only complete
+// blocks are decoded: a partially written block at the end of the file is
+// decoded by a later update, once complete.
+type Tailer struct {
+	filename string
+	opts     []LoadOption
+	mu       sync.Mutex
+	offset   int64
+}
+
+// NewTailer creates a tailer for the file, starting at offset (0 to decode
+// the file from the start), using the decode and load options.
+func NewTailer(filename string, offset int64, opts ...LoadOption) *Tailer {
+	return &Tailer{
+		filename: filename,
+		opts:     append(opts[:len(opts):len(opts)], withSource(filename)),
+		offset:   offset,
+	}
+}
+
+// Offset returns the offset following the last complete block decoded, for
+// resuming with [NewTailer].
+func (t *Tailer) Offset() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.offset
+}
+
+// Update decodes the complete blocks appended to the file since the last
+// update into the [Store], returning the number of blocks decoded. When the
+// file is smaller than the last offset (ie, it was truncated or replaced),
+// the file is decoded from the start.
+func (t *Tailer) Update(s Store) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, err := os.Open(t.filename)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if fi.Size() < t.offset {
+		t.offset = 0
+	}
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	buf, err := io.ReadAll(f)
+	if err != nil {
+		return 0, err
+	}
+	// determine the end of the last complete block
+	var n, end int
+	for rest := buf; ; n++ {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		end = len(buf) - len(rest)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if err := Decode(s, buf[:end], t.opts...); err != nil {
+		return 0, err
+	}
+	t.offset += int64(end)
+	return n, nil
+}
+
+// Follow polls the file for appended blocks every interval until the context
+// is canceled, calling f with a [Store] containing the newly decoded blocks
+// after each update that decoded at least one block. Returns the context's
+// error, or the first error encountered.
+func (t *Tailer) Follow(ctx context.Context, interval time.Duration, f func(Store)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s := make(Store)
+		n, err := t.Update(s)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			f(s)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
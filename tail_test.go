@@ -0,0 +1,89 @@
+package pemutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTailer(t *testing.T) {
+	certs := testCertificates(t, 3)
+	var bufs [][]byte
+	for _, cert := range certs {
+		buf, err := EncodePrimitive(cert)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		bufs = append(bufs, buf)
+	}
+	filename := filepath.Join(t.TempDir(), "log.pem")
+	appendFile := func(buf []byte) {
+		f, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		defer f.Close()
+		if _, err := f.Write(buf); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	appendFile(bufs[0])
+	// partially written block
+	appendFile(bufs[1][:len(bufs[1])/2])
+	tailer := NewTailer(filename, 0)
+	s := make(Store)
+	tests := []struct {
+		append []byte
+		exp    int
+		total  int
+	}{
+		{nil, 1, 1},
+		{nil, 0, 1},
+		{bufs[1][len(bufs[1])/2:], 1, 2},
+		{bufs[2], 1, 3},
+	}
+	for i, test := range tests {
+		appendFile(test.append)
+		n, err := tailer.Update(s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if n != test.exp {
+			t.Errorf("test %d expected %d blocks, got: %d", i, test.exp, n)
+		}
+		if total := len(s.Certificates()); total != test.total {
+			t.Errorf("test %d expected %d certificates, got: %d", i, test.total, total)
+		}
+	}
+	if offset, exp := tailer.Offset(), int64(len(bufs[0])+len(bufs[1])+len(bufs[2])); offset != exp {
+		t.Errorf("expected offset %d, got: %d", exp, offset)
+	}
+	// resume from offset
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tailer = NewTailer(filename, tailer.Offset())
+	ch := make(chan Store, 1)
+	go func() {
+		_ = tailer.Follow(ctx, 10*time.Millisecond, func(s Store) {
+			ch <- s
+		})
+	}()
+	appendFile(bufs[0])
+	select {
+	case z := <-ch:
+		if v := z.Certificates(); len(v) != 1 || !v[0].Equal(certs[0]) {
+			t.Errorf("expected 1 new certificate, got: %d", len(v))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected update")
+	}
+	// truncated file
+	if err := os.WriteFile(filename, bufs[2], 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n, err := NewTailer(filename, 1<<20).Update(make(Store)); err != nil || n != 1 {
+		t.Errorf("expected 1 block, got: %d %v", n, err)
+	}
+}
@@ -0,0 +1,97 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// explanatoryText is the explanatory text preceding PEM blocks, keyed by
+// [textKey].
+type explanatoryText map[string][]byte
+
+// WithExplanatoryText is a decode and load option to preserve the
+// explanatory text (RFC 7468) preceding each PEM block, such as the
+// human-readable certificate text written by "openssl x509 -text". The text
+// is stored in the [Store] as [ExplanatoryText], and is re-emitted before the
+// corresponding block by [Store.Bytes] and [Store.Encode]. Encoding fails when
+// the text contains "-----" or control characters other than tabs and line
+// endings, so that the text cannot add or alter PEM blocks.
+func WithExplanatoryText() LoadOption {
+	return func(opts *loadOptions) {
+		opts.text = true
+	}
+}
+
+// addText adds the explanatory text preceding the block to the [Store].
+func (s Store) addText(block *pem.Block, text []byte) {
+	text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+	text = bytes.Trim(text, "\n")
+	if len(bytes.TrimSpace(text)) == 0 || checkText(text) != nil {
+		return
+	}
+	m, ok := s[ExplanatoryText].(explanatoryText)
+	if !ok {
+		m = make(explanatoryText)
+		s[ExplanatoryText] = m
+	}
+	m[textKey(block)] = append(text, '\n')
+}
+
+// text returns the explanatory text for the block.
+func (m explanatoryText) text(block *pem.Block) []byte {
+	if m == nil {
+		return nil
+	}
+	return m[textKey(block)]
+}
+
+// textKey returns the key used to associate explanatory text with a block.
+// Private and public keys are keyed by their block type class, as their
+// encoding may change when re-encoded (ie, from a "PRIVATE KEY" to a "RSA
+// PRIVATE KEY" block).
+func textKey(block *pem.Block) string {
+	switch typ := BlockType(block.Type); typ {
+	case PrivateKey, RSAPrivateKey, ECPrivateKey, EncryptedPrivateKey:
+		return PrivateKey.String()
+	case PublicKey:
+		return PublicKey.String()
+	default:
+		return fmt.Sprintf("%s %x", typ, sha256.Sum256(block.Bytes))
+	}
+}
+
+// escapeText escapes the control characters (such as CR and LF) in an
+// untrusted value, such as a certificate subject, for writing as a single
+// line of explanatory text.
+func escapeText(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			sb.WriteString(strings.Trim(strconv.QuoteRune(r), "'"))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// checkText checks that the explanatory text cannot be decoded as part of a
+// PEM block (ie, contains no encapsulation boundary), and contains no control
+// characters other than tabs and line endings.
+func checkText(text []byte) error {
+	if bytes.Contains(text, []byte("-----")) {
+		return errors.New("explanatory text contains an encapsulation boundary")
+	}
+	for _, r := range string(text) {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return fmt.Errorf("explanatory text contains control character %q", r)
+		}
+	}
+	return nil
+}
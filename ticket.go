@@ -0,0 +1,123 @@
+package pemutil
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SessionTicketKeys are TLS session ticket keys, stored in a [Store] as
+// [SessionTicketKey]. The first key is the current key, used to encrypt new
+// session tickets, and the remaining keys are previous keys, used only to
+// decrypt session tickets.
+//
+// Keys are either 32 bytes (the native size used by [crypto/tls]) or 48
+// bytes (the size used by OpenSSL based servers, such as nginx's
+// ssl_session_ticket_key).
+type SessionTicketKeys []*SymmetricKey
+
+// GenerateSessionTicketKey generates a TLS session ticket key of size 32 or
+// 48 bytes, expiring after the time to live (or never, when ttl is 0).
+func GenerateSessionTicketKey(size int, ttl time.Duration) (*SymmetricKey, error) {
+	if size != 32 && size != 48 {
+		return nil, fmt.Errorf("invalid session ticket key size %d", size)
+	}
+	key := make([]byte, size)
+	if err := readRand(Rand(), key); err != nil {
+		return nil, err
+	}
+	if ttl == 0 {
+		return &SymmetricKey{Key: key}, nil
+	}
+	return NewSymmetricKey(key, ttl), nil
+}
+
+// SessionTicketKeys returns the TLS session ticket keys in the [Store].
+func (s Store) SessionTicketKeys() SessionTicketKeys {
+	keys, _ := s[SessionTicketKey].(SessionTicketKeys)
+	return keys
+}
+
+// RotateSessionTicketKeys generates a new TLS session ticket key (see
+// [GenerateSessionTicketKey]) and makes it the current key in the [Store],
+// retaining at most keep keys (including the new key), and removing any
+// expired keys. The new key's expiry and expired keys are determined using
+// the current time ([DefaultClock], or the clock set with [WithClock]).
+func (s Store) RotateSessionTicketKeys(size int, ttl time.Duration, keep int, opts ...ValidityOption) error {
+	if keep < 1 {
+		return fmt.Errorf("invalid number of session ticket keys to keep %d", keep)
+	}
+	key, err := GenerateSessionTicketKey(size, ttl)
+	if err != nil {
+		return err
+	}
+	now := newValidityOptions(opts...).clock.Now()
+	if ttl != 0 {
+		key.NotAfter = now.Add(ttl).UTC().Truncate(time.Second)
+	}
+	keys := SessionTicketKeys{key}
+	for _, k := range s.SessionTicketKeys() {
+		if len(keys) == keep {
+			break
+		}
+		if k.NotAfter.IsZero() || now.Before(k.NotAfter) {
+			keys = append(keys, k)
+		}
+	}
+	s[SessionTicketKey] = keys
+	return nil
+}
+
+// TLSKeys returns the unexpired session ticket keys in the form used by
+// [tls.Config.SetSessionTicketKeys]. 48 byte keys are converted to 32 byte
+// keys using SHA-256, so that servers sharing the same keys derive the same
+// keys. Expired keys are determined using the current time ([DefaultClock], or
+// the clock set with [WithClock]).
+func (keys SessionTicketKeys) TLSKeys(opts ...ValidityOption) [][32]byte {
+	now := newValidityOptions(opts...).clock.Now()
+	var v [][32]byte
+	for _, k := range keys {
+		if !k.NotAfter.IsZero() && !now.Before(k.NotAfter) {
+			continue
+		}
+		switch len(k.Key) {
+		case 32:
+			v = append(v, [32]byte(k.Key))
+		case 48:
+			v = append(v, sha256.Sum256(k.Key))
+		}
+	}
+	return v
+}
+
+// SetSessionTicketKeys sets the TLS server config's session ticket keys to
+// the unexpired session ticket keys in the [Store] (see
+// [SessionTicketKeys.TLSKeys]).
+func (s Store) SetSessionTicketKeys(cfg *tls.Config, opts ...ValidityOption) error {
+	keys := s.SessionTicketKeys().TLSKeys(opts...)
+	if len(keys) == 0 {
+		return errors.New("store does not contain an unexpired session ticket key")
+	}
+	cfg.SetSessionTicketKeys(keys)
+	return nil
+}
+
+// addSessionTicketKey adds a session ticket key block to the [Store],
+// appending to any session ticket keys already present.
+func (s Store) addSessionTicketKey(buf []byte, headers map[string]string) error {
+	if len(buf) != 32 && len(buf) != 48 {
+		return fmt.Errorf("invalid session ticket key size %d", len(buf))
+	}
+	v, err := parseSymmetricKey(buf, headers)
+	if err != nil {
+		return err
+	}
+	key, ok := v.(*SymmetricKey)
+	if !ok {
+		key = &SymmetricKey{Key: buf}
+	}
+	s[SessionTicketKey] = append(s.SessionTicketKeys(), key)
+	return nil
+}
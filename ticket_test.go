@@ -0,0 +1,67 @@
+package pemutil
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+)
+
+func TestSessionTicketKeys(t *testing.T) {
+	s := Store{}
+	if err := s.SetSessionTicketKeys(&tls.Config{}); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	for i, size := range []int{32, 48, 32, 32} {
+		if err := s.RotateSessionTicketKeys(size, time.Hour, 3); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+	}
+	keys := s.SessionTicketKeys()
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys, got: %d", len(keys))
+	}
+	if len(keys[0].Key) != 32 || len(keys[2].Key) != 48 {
+		t.Errorf("expected newest key first")
+	}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	decoded := z.SessionTicketKeys()
+	if len(decoded) != 3 {
+		t.Fatalf("expected 3 keys, got: %d", len(decoded))
+	}
+	for i, key := range decoded {
+		if string(key.Key) != string(keys[i].Key) || !key.NotAfter.Equal(keys[i].NotAfter) {
+			t.Errorf("test %d expected key to round trip", i)
+		}
+	}
+	tlsKeys := decoded.TLSKeys()
+	if len(tlsKeys) != 3 || tlsKeys[0] != [32]byte(keys[0].Key) {
+		t.Errorf("expected 3 TLS keys, got: %d", len(tlsKeys))
+	}
+	if err := z.SetSessionTicketKeys(&tls.Config{}); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	// expired keys are dropped
+	decoded[1].NotAfter = time.Now().Add(-time.Minute)
+	if n := len(decoded.TLSKeys()); n != 2 {
+		t.Errorf("expected 2 TLS keys, got: %d", n)
+	}
+	if err := z.RotateSessionTicketKeys(32, 0, 5); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := len(z.SessionTicketKeys()); n != 3 {
+		t.Errorf("expected 3 keys, got: %d", n)
+	}
+	if _, err := GenerateSessionTicketKey(16, 0); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	if _, err := DecodeBytes([]byte("-----BEGIN TLS SESSION TICKET KEY-----\nAAAA\n-----END TLS SESSION TICKET KEY-----\n")); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
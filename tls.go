@@ -0,0 +1,307 @@
+package pemutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// FromTLSCertificate creates a [Store] containing the private key,
+// public key, and certificate chain of the TLS certificate.
+func FromTLSCertificate(tc tls.Certificate) (Store, error) {
+	s := make(Store)
+	for _, der := range tc.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		s.addCertificate(cert)
+	}
+	switch key := tc.PrivateKey.(type) {
+	case nil:
+	case *rsa.PrivateKey:
+		s[RSAPrivateKey] = key
+	case *ecdsa.PrivateKey:
+		s[ECPrivateKey] = key
+	case ed25519.PrivateKey, ed448.PrivateKey:
+		s[PrivateKey] = key
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", tc.PrivateKey)
+	}
+	if cert, ok := s.Certificate(); ok {
+		s[PublicKey] = cert.PublicKey
+	}
+	s.AddPublicKeys()
+	if len(s) == 0 {
+		return nil, errors.New("empty tls certificate")
+	}
+	return s, nil
+}
+
+// TLSCertificate creates a TLS certificate from the private key and
+// certificates in the [Store]. The leaf certificate is the certificate whose
+// public key matches the private key, and is followed by its issuing
+// intermediate certificates contained in the [Store] (see
+// [Store.Intermediates]). Root certificates are not included.
+func (s Store) TLSCertificate() (tls.Certificate, error) {
+	if err := s.CheckPurpose(PurposeTLSServer, PurposeTLSClient); err != nil {
+		return tls.Certificate{}, err
+	}
+	signer, chain, err := s.fullChain()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tc := tls.Certificate{
+		PrivateKey: signer,
+		Leaf:       chain[0],
+	}
+	for _, c := range chain {
+		tc.Certificate = append(tc.Certificate, c.Raw)
+	}
+	return tc, nil
+}
+
+// fullChain returns the private key in the [Store] and the certificate chain
+// for its certificate, starting with the leaf certificate, followed by its
+// issuing intermediate certificates (see [Store.Intermediates]). Root
+// certificates are not included.
+func (s Store) fullChain() (crypto.Signer, []*x509.Certificate, error) {
+	signer, ok := s.Signer()
+	if !ok {
+		return nil, nil, errors.New("store does not contain a private key")
+	}
+	for _, cert := range s.Certificates() {
+		if EqualKeys(signer.Public(), cert.PublicKey) {
+			return signer, buildChain(cert, s.Intermediates()), nil
+		}
+	}
+	return nil, nil, errors.New("store does not contain a certificate for the private key")
+}
+
+// Leaf returns the leaf certificate in the [Store]: the certificate whose
+// public key matches the private key in the [Store], or the first non-CA
+// certificate when the [Store] does not contain a private key.
+func (s Store) Leaf() (*x509.Certificate, bool) {
+	certs := s.Certificates()
+	if signer, ok := s.Signer(); ok {
+		for _, cert := range certs {
+			if EqualKeys(signer.Public(), cert.PublicKey) {
+				return cert, true
+			}
+		}
+		return nil, false
+	}
+	for _, cert := range certs {
+		if !cert.IsCA {
+			return cert, true
+		}
+	}
+	return nil, false
+}
+
+// Intermediates returns the intermediate CA certificates in the [Store]: the
+// CA certificates (see [x509.Certificate.BasicConstraintsValid]) that are not
+// self-issued.
+func (s Store) Intermediates() []*x509.Certificate {
+	var certs []*x509.Certificate
+	for _, cert := range s.Certificates() {
+		if cert.IsCA && !selfIssued(cert) {
+			certs = append(certs, cert)
+		}
+	}
+	return certs
+}
+
+// Roots returns the root CA certificates in the [Store]: the self-issued CA
+// certificates.
+func (s Store) Roots() []*x509.Certificate {
+	var certs []*x509.Certificate
+	for _, cert := range s.Certificates() {
+		if cert.IsCA && selfIssued(cert) {
+			certs = append(certs, cert)
+		}
+	}
+	return certs
+}
+
+// selfIssued determines if the certificate's issuer and subject are the
+// same.
+func selfIssued(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject)
+}
+
+// CertificateFor returns the certificate chain for the best matching leaf
+// certificate in the [Store] for the hostname, using the standard X509
+// hostname verification rules (see [x509.Certificate.VerifyHostname]).
+//
+// Currently valid certificates are preferred over expired or not yet valid
+// certificates, exact matches are preferred over wildcard matches, and then
+// the certificate with the latest expiry is preferred. The returned chain
+// starts with the leaf certificate, followed by its issuers contained in the
+// [Store]. Validity is determined using the current time ([DefaultClock], or
+// the clock set with [WithClock]).
+func (s Store) CertificateFor(hostname string, opts ...ValidityOption) ([]*x509.Certificate, bool) {
+	certs := s.Certificates()
+	now := newValidityOptions(opts...).clock.Now()
+	var best *x509.Certificate
+	bestScore := -1
+	for _, cert := range certs {
+		if score := hostnameScore(cert, hostname, now); score > bestScore || (score != -1 && score == bestScore && cert.NotAfter.After(best.NotAfter)) {
+			best, bestScore = cert, score
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+	return buildChain(best, certs), true
+}
+
+// hostnameScore returns a score for how well the leaf certificate matches the
+// hostname at the time, or -1 when the certificate does not match.
+func hostnameScore(cert *x509.Certificate, hostname string, now time.Time) int {
+	if cert.IsCA || cert.VerifyHostname(hostname) != nil {
+		return -1
+	}
+	score := 0
+	if checkValidity(cert, now, 0) == nil {
+		score += 2
+	}
+	if exactHostname(cert, hostname) {
+		score++
+	}
+	return score
+}
+
+// exactHostname determines if the hostname is an IP address or exactly
+// matches one of the certificate's DNS names.
+func exactHostname(cert *x509.Certificate, hostname string) bool {
+	if net.ParseIP(strings.Trim(hostname, "[]")) != nil {
+		return true
+	}
+	hostname = strings.TrimSuffix(hostname, ".")
+	for _, name := range cert.DNSNames {
+		if strings.EqualFold(name, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildChain builds the certificate chain for the leaf certificate from the
+// issuing CA certificates in certs.
+func buildChain(leaf *x509.Certificate, certs []*x509.Certificate) []*x509.Certificate {
+	chain := []*x509.Certificate{leaf}
+	for cert := leaf; !selfIssued(cert); {
+		var parent *x509.Certificate
+		for _, c := range certs {
+			if c.IsCA && !containsCertificate(chain, c) && bytes.Equal(c.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(c) == nil {
+				parent = c
+				break
+			}
+		}
+		if parent == nil {
+			break
+		}
+		chain, cert = append(chain, parent), parent
+	}
+	return chain
+}
+
+// containsCertificate determines if the certificate is in certs.
+func containsCertificate(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
+
+// CertPool creates a certificate pool containing the certificates in the
+// [Store].
+func (s Store) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range s.Certificates() {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// RootPool creates a certificate pool containing the root CA certificates in
+// the [Store] (see [Store.Roots]), for use as [x509.VerifyOptions.Roots] or
+// [tls.Config.RootCAs].
+func (s Store) RootPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range s.Roots() {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// IntermediatePool creates a certificate pool containing the intermediate CA
+// certificates in the [Store] (see [Store.Intermediates]), for use as
+// [x509.VerifyOptions.Intermediates].
+func (s Store) IntermediatePool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range s.Intermediates() {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// ConfigureClientAuth configures the TLS server config to require and verify
+// client certificates issued by the CA certificates in the [Store]. Only
+// certificates with valid basic constraints marking them as a CA are trusted:
+// leaf certificates in the [Store] are not added to the client CA pool.
+func (s Store) ConfigureClientAuth(cfg *tls.Config) error {
+	pool := x509.NewCertPool()
+	var n int
+	for _, cert := range s.Certificates() {
+		if cert.BasicConstraintsValid && cert.IsCA {
+			pool.AddCert(cert)
+			n++
+		}
+	}
+	if n == 0 {
+		return errors.New("store does not contain a CA certificate")
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return nil
+}
+
+// FromConnectionState creates a [Store] containing the peer's certificate
+// chain and public key from the TLS connection state, for use in
+// authorization decisions. The verified chain is used when available.
+//
+// Returns an error when the peer did not provide a certificate, or when the
+// peer's leaf certificate is not currently valid (see [CheckValidity]).
+func FromConnectionState(cs tls.ConnectionState, opts ...ValidityOption) (Store, error) {
+	certs := cs.PeerCertificates
+	if len(cs.VerifiedChains) != 0 {
+		certs = cs.VerifiedChains[0]
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("peer did not provide a certificate")
+	}
+	if err := CheckValidity(certs[0], opts...); err != nil {
+		return nil, err
+	}
+	s := make(Store)
+	for _, cert := range certs {
+		s.addCertificate(cert)
+	}
+	s[PublicKey] = certs[0].PublicKey
+	return s, nil
+}
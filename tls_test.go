@@ -0,0 +1,351 @@
+package pemutil
+
+import (
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFromTLSCertificate(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, pub, key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s, err := FromTLSCertificate(tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(s) != 3 {
+		t.Fatalf("expected private key, public key, and certificate, got: %v", keys(s))
+	}
+	// add csr and crl
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "test"},
+	}, key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cert, _ := s.Certificate()
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(time.Hour),
+	}, &x509.Certificate{
+		Subject:      cert.Subject,
+		KeyUsage:     x509.KeyUsageCRLSign,
+		SubjectKeyId: []byte{1},
+	}, key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	crl, err := x509.ParseRevocationList(crlDER)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, v := range []interface{}{csr, crl} {
+		if err := s.AddX509(v); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	// round trip
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s0, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if k, ok := s0.PrivateKey(); !ok || !key.Equal(k) {
+		t.Errorf("expected ed25519 private key")
+	}
+	if _, ok := s0.CertificateRequest(); !ok {
+		t.Errorf("expected certificate request")
+	}
+	if _, ok := s0.RevocationList(); !ok {
+		t.Errorf("expected revocation list")
+	}
+}
+
+func TestCertificateFor(t *testing.T) {
+	root, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rootCert, err := root.SelfSign(RootCATemplate("root"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	inter, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	interCert, err := root.Issue(IntermediateCATemplate("intermediate", 0), inter[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	inter.addCertificate(interCert)
+	s := Store{}
+	var leafs []*x509.Certificate
+	for _, tpl := range []*x509.Certificate{
+		ServerTemplate("*.example.com"),
+		ServerTemplate("api.example.com", "10.0.0.1"),
+		ServerTemplate("api.example.com"),
+	} {
+		if len(leafs) == 2 {
+			tpl.NotBefore, tpl.NotAfter = time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+		}
+		cert, err := inter.Issue(tpl, inter[PublicKey])
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		leafs = append(leafs, cert)
+		s.addCertificate(cert)
+	}
+	s.addCertificate(rootCert)
+	s.addCertificate(interCert)
+	tests := []struct {
+		hostname string
+		exp      *x509.Certificate
+	}{
+		{"api.example.com", leafs[1]},
+		{"API.example.com.", leafs[1]},
+		{"www.example.com", leafs[0]},
+		{"10.0.0.1", leafs[1]},
+		{"example.com", nil},
+		{"root", nil},
+	}
+	for i, test := range tests {
+		chain, ok := s.CertificateFor(test.hostname)
+		if test.exp == nil {
+			if ok {
+				t.Errorf("test %d expected no match, got: %v", i, chain[0].Subject)
+			}
+			continue
+		}
+		if !ok {
+			t.Fatalf("test %d expected match", i)
+		}
+		if len(chain) != 3 || chain[0] != test.exp || chain[1] != interCert || chain[2] != rootCert {
+			t.Errorf("test %d expected leaf, intermediate, and root chain", i)
+		}
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ca.SelfSign(RootCATemplate("root")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	server, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	serverCert, err := ca.Issue(ServerTemplate("localhost"), server[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	server.addCertificate(serverCert)
+	client, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	clientCert, err := ca.Issue(ClientTemplate("client"), client[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	client.addCertificate(clientCert)
+	serverTC, err := server.TLSCertificate()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	clientTC, err := client.TLSCertificate()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	serverCfg := &tls.Config{Certificates: []tls.Certificate{serverTC}}
+	if err := ca.ConfigureClientAuth(serverCfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+	errc := make(chan error, 1)
+	go func() {
+		conn := tls.Client(c, &tls.Config{
+			ServerName:   "localhost",
+			RootCAs:      ca.CertPool(),
+			Certificates: []tls.Certificate{clientTC},
+		})
+		errc <- conn.Handshake()
+	}()
+	conn := tls.Server(s, serverCfg)
+	if err := conn.Handshake(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	peer, err := FromConnectionState(conn.ConnectionState())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if certs := peer.Certificates(); len(certs) != 2 || !certs[0].Equal(clientCert) {
+		t.Errorf("expected client certificate chain, got: %d certificates", len(certs))
+	}
+	if _, err := FromConnectionState(tls.ConnectionState{}); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestClientAuthLeaf(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := ca.SelfSign(RootCATemplate("root")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	server, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	serverCert, err := ca.Issue(ServerTemplate("localhost"), server[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	server.addCertificate(serverCert)
+	// self-signed leaf, present in the ca store
+	client, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	clientCert, err := client.SelfSign(ClientTemplate("client"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := client.ConfigureClientAuth(&tls.Config{}); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	ca.addCertificate(clientCert)
+	serverTC, err := server.TLSCertificate()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	clientTC, err := client.TLSCertificate()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	serverCfg := &tls.Config{Certificates: []tls.Certificate{serverTC}}
+	if err := ca.ConfigureClientAuth(serverCfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+	go func() {
+		conn := tls.Client(c, &tls.Config{
+			ServerName:   "localhost",
+			RootCAs:      ca.RootPool(),
+			Certificates: []tls.Certificate{clientTC},
+		})
+		_ = conn.Handshake()
+		c.Close()
+	}()
+	if err := tls.Server(s, serverCfg).Handshake(); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
+
+func TestLeafIntermediatesRoots(t *testing.T) {
+	root, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rootCert, err := root.SelfSign(RootCATemplate("root"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	inter, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	interCert, err := root.Issue(IntermediateCATemplate("intermediate", 0), inter[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	inter.addCertificate(interCert)
+	leaf, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	leafCert, err := inter.Issue(ServerTemplate("example.com"), leaf[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// unordered
+	leaf.addCertificate(rootCert)
+	leaf.addCertificate(interCert)
+	leaf.addCertificate(leafCert)
+	if cert, ok := leaf.Leaf(); !ok || cert != leafCert {
+		t.Errorf("expected leaf certificate, got: %v", cert)
+	}
+	if certs := leaf.Intermediates(); len(certs) != 1 || certs[0] != interCert {
+		t.Errorf("expected intermediate certificate, got: %d", len(certs))
+	}
+	if certs := leaf.Roots(); len(certs) != 1 || certs[0] != rootCert {
+		t.Errorf("expected root certificate, got: %d", len(certs))
+	}
+	// without private key
+	if cert, ok := leaf.Only(Certificate).Leaf(); !ok || cert != leafCert {
+		t.Errorf("expected leaf certificate, got: %v", cert)
+	}
+	if _, ok := root.Leaf(); !ok {
+		t.Errorf("expected self-signed certificate for private key")
+	}
+	if _, ok := root.Only(Certificate).Leaf(); ok {
+		t.Errorf("expected no leaf certificate")
+	}
+	// root is excluded from the TLS chain
+	tc, err := leaf.TLSCertificate()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(tc.Certificate) != 2 {
+		t.Errorf("expected leaf and intermediate certificates, got: %d", len(tc.Certificate))
+	}
+	opts := x509.VerifyOptions{
+		Roots:         leaf.RootPool(),
+		Intermediates: leaf.IntermediatePool(),
+	}
+	if _, err := leafCert.Verify(opts); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
@@ -1,7 +1,17 @@
 package pemutil
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/subtle"
 	"crypto/x509"
+	"fmt"
+
+	"github.com/cloudflare/circl/sign/ed448"
 )
 
 // BlockType is a PEM block type.
@@ -12,6 +22,17 @@ func (bt BlockType) String() string {
 	return string(bt)
 }
 
+// IsMetadata determines if the block type is one of the pseudo block types
+// used to store metadata in a [Store] ([KeyAttributes], [ExplanatoryText],
+// and [KeyPurposes]), rather than a PEM block type.
+func (bt BlockType) IsMetadata() bool {
+	switch bt {
+	case KeyAttributes, ExplanatoryText, KeyPurposes:
+		return true
+	}
+	return false
+}
+
 const (
 	// PrivateKey is the "PRIVATE KEY" block type.
 	PrivateKey BlockType = "PRIVATE KEY"
@@ -22,20 +43,165 @@ const (
 	// ECPrivateKey is the "EC PRIVATE KEY" block type.
 	ECPrivateKey BlockType = "EC PRIVATE KEY"
 
+	// EncryptedPrivateKey is the "ENCRYPTED PRIVATE KEY" block type.
+	EncryptedPrivateKey BlockType = "ENCRYPTED PRIVATE KEY"
+
 	// PublicKey is the "PUBLIC KEY" block type.
 	PublicKey BlockType = "PUBLIC KEY"
 
 	// Certificate is the "CERTIFICATE" block type.
 	Certificate BlockType = "CERTIFICATE"
+
+	// CertificateRequest is the "CERTIFICATE REQUEST" block type.
+	CertificateRequest BlockType = "CERTIFICATE REQUEST"
+
+	// RevocationList is the "X509 CRL" block type.
+	RevocationList BlockType = "X509 CRL"
+
+	// EncryptedKeyset is the "ENCRYPTED KEYSET" block type.
+	EncryptedKeyset BlockType = "ENCRYPTED KEYSET"
+
+	// Signature is the "SIGNATURE" block type.
+	Signature BlockType = "SIGNATURE"
+
+	// SessionTicketKey is the "TLS SESSION TICKET KEY" block type (see
+	// [SessionTicketKeys]).
+	SessionTicketKey BlockType = "TLS SESSION TICKET KEY"
+
+	// ECHConfig is the "ECHCONFIG" block type, containing a serialized
+	// Encrypted Client Hello config list (see [ECHConfigList]).
+	ECHConfig BlockType = "ECHCONFIG"
+
+	// KeyAttributes is the block type used to store a private key's PKCS#8
+	// attributes (see [Attributes]) in a [Store]. Not a PEM block type.
+	KeyAttributes BlockType = "KEY ATTRIBUTES"
+
+	// ExplanatoryText is the block type used to store the explanatory text
+	// preceding PEM blocks in a [Store] (see [WithExplanatoryText]). Not a
+	// PEM block type.
+	ExplanatoryText BlockType = "EXPLANATORY TEXT"
+
+	// KeyPurposes is the block type used to store a private key's intended
+	// purposes (see [Purposes]) in a [Store]. Not a PEM block type.
+	KeyPurposes BlockType = "KEY PURPOSES"
 )
 
 // ParsePKCSPrivateKey attempts to decode a RSA private key first using PKCS1
 // encoding, and then PKCS8 encoding.
+//
+// PKCS8 encoded EC private keys with explicit curve parameters are converted
+// to their named-curve form, and PKCS8 encoded EC private keys for curves added
+// via [RegisterCurve] are supported, as are PKCS8 encoded Ed448 private keys.
+// BER encoded keys are re-encoded as DER (see [BERToDER]).
 func ParsePKCSPrivateKey(buf []byte) (interface{}, error) {
 	// attempt PKCS1 parsing
 	if key, err := x509.ParsePKCS1PrivateKey(buf); err == nil {
 		return key, nil
 	}
 	// attempt PKCS8 parsing
-	return x509.ParsePKCS8PrivateKey(buf)
+	key, err := x509.ParsePKCS8PrivateKey(buf)
+	if err == nil {
+		return key, nil
+	}
+	// attempt PKCS8 parsing with explicit or registered EC parameters
+	if key, eerr := parsePKCS8EC(buf); eerr == nil {
+		return key, nil
+	}
+	// attempt PKCS8 Ed448 parsing
+	if key, eerr := ParseEd448PrivateKey(buf); eerr == nil {
+		return key, nil
+	}
+	// attempt parsing BER encoded data
+	if der, berr := BERToDER(buf); berr == nil && !bytes.Equal(der, buf) {
+		return ParsePKCSPrivateKey(der)
+	}
+	return nil, err
+}
+
+// EqualKeys determines if a and b are equal private keys, public keys, or raw
+// (symmetric) keys, of any supported type. Private and raw keys are compared
+// in constant time.
+func EqualKeys(a, b interface{}) bool {
+	if x, ok := rawKey(a); ok {
+		y, ok := rawKey(b)
+		return ok && subtle.ConstantTimeCompare(x, y) == 1
+	}
+	switch x := a.(type) {
+	case nil:
+		return false
+	case interface{ Equal(crypto.PrivateKey) bool }:
+		return x.Equal(b)
+	case interface{ Equal(crypto.PublicKey) bool }:
+		return x.Equal(b)
+	}
+	return false
+}
+
+// rawKey returns the key of a raw key ([]byte) or a [*SymmetricKey].
+func rawKey(v interface{}) ([]byte, bool) {
+	switch k := v.(type) {
+	case []byte:
+		return k, true
+	case *SymmetricKey:
+		if k != nil {
+			return k.Key, true
+		}
+	}
+	return nil, false
+}
+
+// CanonicalPublicKey returns the canonical form of the public key v, allowing
+// public keys from different representations to be consistently compared
+// and fingerprinted. The canonical form is one of *rsa.PublicKey,
+// *ecdsa.PublicKey, ed25519.PublicKey, ed448.PublicKey, or *ecdh.PublicKey
+// (for X25519 keys).
+//
+// v can be a public key (including non-pointer RSA and ECDSA public keys, and
+// ECDH public keys for the NIST curves, which are converted to ECDSA public
+// keys), a PKIX or PKCS1 DER encoded public key, a private key, a
+// *x509.Certificate, a *x509.CertificateRequest, or a [Store].
+func CanonicalPublicKey(v interface{}) (crypto.PublicKey, error) {
+	switch k := v.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, ed448.PublicKey:
+		return k, nil
+	case rsa.PublicKey:
+		return &k, nil
+	case ecdsa.PublicKey:
+		return &k, nil
+	case *ecdh.PublicKey:
+		if k.Curve() == ecdh.X25519() {
+			return k, nil
+		}
+		der, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return x509.ParsePKIXPublicKey(der)
+	case []byte:
+		if pub, err := ParsePKIXPublicKey(k); err == nil {
+			return CanonicalPublicKey(pub)
+		}
+		if pub, err := x509.ParsePKCS1PublicKey(k); err == nil {
+			return pub, nil
+		}
+		return nil, fmt.Errorf("invalid public key")
+	case *x509.Certificate:
+		return CanonicalPublicKey(k.PublicKey)
+	case *x509.CertificateRequest:
+		return CanonicalPublicKey(k.PublicKey)
+	case Store:
+		if key, ok := k.Signer(); ok {
+			return CanonicalPublicKey(key.Public())
+		}
+		if pub, ok := k.PublicKey(); ok {
+			return CanonicalPublicKey(pub)
+		}
+		if cert, ok := k.Certificate(); ok {
+			return CanonicalPublicKey(cert.PublicKey)
+		}
+		return nil, fmt.Errorf("store does not contain a public key")
+	case interface{ Public() crypto.PublicKey }:
+		return CanonicalPublicKey(k.Public())
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", v)
 }
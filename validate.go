@@ -0,0 +1,141 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidationError is a validation profile error, containing all problems
+// found when validating a [Store] against a profile (see
+// [Store.ValidateForServer]).
+type ValidationError struct {
+	// Profile is the validation profile.
+	Profile string
+	// Errs are the problems found.
+	Errs []error
+}
+
+// Error satisfies the error interface.
+func (err *ValidationError) Error() string {
+	v := make([]string, len(err.Errs))
+	for i, e := range err.Errs {
+		v[i] = e.Error()
+	}
+	return fmt.Sprintf("%s validation failed: %s", err.Profile, strings.Join(v, "; "))
+}
+
+// Unwrap satisfies the [errors.Unwrap] interface.
+func (err *ValidationError) Unwrap() []error {
+	return err.Errs
+}
+
+// ValidateForServer validates the [Store] for use by a TLS server for the
+// hostname, checking that:
+//
+//   - the [Store] contains a private key and its leaf certificate (see
+//     [Store.Leaf])
+//   - the leaf certificate covers the hostname (see
+//     [x509.Certificate.VerifyHostname])
+//   - the leaf certificate has the serverAuth extended key usage
+//   - the leaf and chain certificates are currently valid (see
+//     [CheckValidity])
+//   - the chain is complete: the leaf certificate chains to a root
+//     certificate in the [Store] through the intermediate certificates in the
+//     [Store], or to a system root when the [Store] does not contain a root
+//     certificate
+//
+// Returns a [*ValidationError] containing all problems found.
+func (s Store) ValidateForServer(hostname string, opts ...ValidityOption) error {
+	return s.validate("server", x509.ExtKeyUsageServerAuth, hostname, opts...)
+}
+
+// ValidateForClientAuth validates the [Store] for use as a TLS client
+// identity, checking that:
+//
+//   - the [Store] contains a private key and its leaf certificate (see
+//     [Store.Leaf])
+//   - the leaf certificate has the clientAuth extended key usage
+//   - the leaf and chain certificates are currently valid (see
+//     [CheckValidity])
+//   - the chain is complete (see [Store.ValidateForServer])
+//
+// Returns a [*ValidationError] containing all problems found.
+func (s Store) ValidateForClientAuth(opts ...ValidityOption) error {
+	return s.validate("client auth", x509.ExtKeyUsageClientAuth, "", opts...)
+}
+
+// validate validates the [Store] for the profile.
+func (s Store) validate(profile string, usage x509.ExtKeyUsage, hostname string, opts ...ValidityOption) error {
+	o := newValidityOptions(opts...)
+	now := o.clock.Now()
+	verr := &ValidationError{Profile: profile}
+	leaf, ok := s.Leaf()
+	switch _, hasKey := s.Signer(); {
+	case !hasKey:
+		verr.Errs = append(verr.Errs, errors.New("store does not contain a private key"))
+		if !ok {
+			return verr
+		}
+	case !ok:
+		verr.Errs = append(verr.Errs, errors.New("store does not contain a certificate for the private key"))
+		return verr
+	}
+	if hostname != "" {
+		if err := leaf.VerifyHostname(hostname); err != nil {
+			verr.Errs = append(verr.Errs, err)
+		}
+	}
+	if !hasExtKeyUsage(leaf, usage) {
+		verr.Errs = append(verr.Errs, fmt.Errorf("certificate %q does not have the %s extended key usage", leaf.Subject, extKeyUsageName(usage)))
+	}
+	var invalid bool
+	for _, cert := range buildChain(leaf, s.Intermediates()) {
+		if err := checkValidity(cert, now, o.skew); err != nil {
+			verr.Errs, invalid = append(verr.Errs, err), true
+		}
+	}
+	vopts := x509.VerifyOptions{
+		Intermediates: s.IntermediatePool(),
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	switch {
+	case selfIssued(leaf):
+		vopts.Roots = x509.NewCertPool()
+		vopts.Roots.AddCert(leaf)
+	case len(s.Roots()) != 0:
+		vopts.Roots = s.RootPool()
+	}
+	// validity problems are reported above
+	var cerr x509.CertificateInvalidError
+	if _, err := leaf.Verify(vopts); err != nil && !(invalid && errors.As(err, &cerr) && cerr.Reason == x509.Expired) {
+		verr.Errs = append(verr.Errs, fmt.Errorf("incomplete certificate chain: %w", err))
+	}
+	if len(verr.Errs) != 0 {
+		return verr
+	}
+	return nil
+}
+
+// hasExtKeyUsage determines if the certificate has the extended key usage.
+func hasExtKeyUsage(cert *x509.Certificate, usage x509.ExtKeyUsage) bool {
+	for _, u := range cert.ExtKeyUsage {
+		if u == usage || u == x509.ExtKeyUsageAny {
+			return true
+		}
+	}
+	return false
+}
+
+// extKeyUsageName returns the name of the extended key usage.
+func extKeyUsageName(usage x509.ExtKeyUsage) string {
+	switch usage {
+	case x509.ExtKeyUsageServerAuth:
+		return "serverAuth"
+	case x509.ExtKeyUsageClientAuth:
+		return "clientAuth"
+	}
+	return fmt.Sprintf("%d", usage)
+}
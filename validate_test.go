@@ -0,0 +1,138 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateForServer(t *testing.T) {
+	root, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rootCert, err := root.SelfSign(RootCATemplate("root"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	inter, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	interCert, err := root.Issue(IntermediateCATemplate("intermediate", 0), inter[PublicKey])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	inter.addCertificate(interCert)
+	issue := func(tpl *x509.Certificate, certs ...*x509.Certificate) Store {
+		t.Helper()
+		s, err := GenerateECKeySet(elliptic.P256())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cert, err := inter.Issue(tpl, s[PublicKey])
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		s.addCertificate(cert)
+		for _, c := range certs {
+			s.addCertificate(c)
+		}
+		return s
+	}
+	expired := ServerTemplate("example.com")
+	expired.NotBefore, expired.NotAfter = time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+	other, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		s        Store
+		hostname string
+		errs     int
+	}{
+		{issue(ServerTemplate("example.com"), interCert, rootCert), "example.com", 0},
+		{issue(ServerTemplate("*.example.com"), interCert, rootCert), "www.example.com", 0},
+		{issue(ServerTemplate("example.com"), interCert, rootCert), "example.org", 1},
+		{issue(ClientTemplate("example.com"), interCert, rootCert), "", 1},
+		{issue(ServerTemplate("example.com"), rootCert), "example.com", 1},
+		{issue(expired, interCert, rootCert), "example.com", 1},
+		{issue(ServerTemplate("example.com"), interCert, rootCert).Only(Certificate), "example.com", 1},
+		{Store{PrivateKey: other[ECPrivateKey], Certificate: issue(ServerTemplate("example.com")).Certificates()}, "example.com", 1},
+	}
+	for i, test := range tests {
+		err := test.s.ValidateForServer(test.hostname)
+		var verr *ValidationError
+		switch {
+		case test.errs == 0 && err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		case test.errs != 0 && !errors.As(err, &verr):
+			t.Errorf("test %d expected validation error, got: %v", i, err)
+		case test.errs != 0 && len(verr.Errs) != test.errs:
+			t.Errorf("test %d expected %d errors, got: %v", i, test.errs, err)
+		}
+	}
+	// self-signed
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SelfSign(ServerTemplate("localhost")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := s.ValidateForServer("localhost"); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	// expired certificates are a validity error
+	err = issue(expired, interCert, rootCert).ValidateForServer("example.com")
+	var vErr *ValidityError
+	if !errors.As(err, &vErr) || !vErr.Expired() {
+		t.Errorf("expected expired validity error, got: %v", err)
+	}
+}
+
+func TestValidateForClientAuth(t *testing.T) {
+	ca, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	caCert, err := ca.SelfSign(RootCATemplate("root"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		tpl  *x509.Certificate
+		ca   bool
+		errs int
+	}{
+		{ClientTemplate("client"), true, 0},
+		{ServerTemplate("example.com"), true, 1},
+		{ClientTemplate("client"), false, 1},
+	}
+	for i, test := range tests {
+		s, err := GenerateECKeySet(elliptic.P256())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cert, err := ca.Issue(test.tpl, s[PublicKey])
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		s.addCertificate(cert)
+		if test.ca {
+			s.addCertificate(caCert)
+		}
+		err = s.ValidateForClientAuth(WithClock(ClockFunc(time.Now)))
+		var verr *ValidationError
+		switch {
+		case test.errs == 0 && err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		case test.errs != 0 && !errors.As(err, &verr):
+			t.Errorf("test %d expected validation error, got: %v", i, err)
+		case test.errs != 0 && len(verr.Errs) != test.errs:
+			t.Errorf("test %d expected %d errors, got: %v", i, test.errs, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package pemutil
+
+import (
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultClockSkew is the default clock skew tolerated when checking
+// certificate validity.
+const DefaultClockSkew = 5 * time.Minute
+
+// Clock is the interface for a clock that provides the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is a [Clock] func.
+type ClockFunc func() time.Time
+
+// Now satisfies the [Clock] interface.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the system [Clock].
+var SystemClock Clock = ClockFunc(time.Now)
+
+// clockSource is the package's default clock.
+var clockSource struct {
+	sync.RWMutex
+	clock Clock
+}
+
+// SetDefaultClock sets the [Clock] used to determine the current time when a
+// clock is not otherwise specified, such as for certificate template validity
+// periods, expiry checks, revocation and update times, symmetric key
+// expiration, and certificate selection. Passing nil restores the default
+// ([SystemClock]).
+//
+// Useful to compensate for a system clock known to be inaccurate, or to test
+// behavior at a different time. The clock can be overridden for a single
+// operation with [WithClock].
+func SetDefaultClock(clock Clock) {
+	clockSource.Lock()
+	defer clockSource.Unlock()
+	clockSource.clock = clock
+}
+
+// DefaultClock returns the [Clock] used by the package (see
+// [SetDefaultClock]).
+func DefaultClock() Clock {
+	clockSource.RLock()
+	defer clockSource.RUnlock()
+	if clockSource.clock == nil {
+		return SystemClock
+	}
+	return clockSource.clock
+}
+
+// ValidityOption is a certificate validity check and clock option.
+type ValidityOption func(*validityOptions)
+
+// validityOptions are certificate validity check options.
+type validityOptions struct {
+	clock Clock
+	skew  time.Duration
+}
+
+// WithClock is a certificate validity check and clock option to set the clock
+// used to determine the current time (default [DefaultClock]). Also used with
+// [Store.Report], [Store.CertificateFor], [Store.RotateSessionTicketKeys],
+// [Store.SetSessionTicketKeys], [SessionTicketKeys.TLSKeys],
+// [Store.SignOCSPResponse], [Revocations.Revoke], and [GenerateCRL].
+func WithClock(clock Clock) ValidityOption {
+	return func(opts *validityOptions) {
+		opts.clock = clock
+	}
+}
+
+// WithClockSkew is a certificate validity check option to set the clock skew
+// tolerated on either side of a certificate's validity period (default
+// [DefaultClockSkew]).
+func WithClockSkew(skew time.Duration) ValidityOption {
+	return func(opts *validityOptions) {
+		opts.skew = skew
+	}
+}
+
+// newValidityOptions builds the certificate validity check options.
+func newValidityOptions(opts ...ValidityOption) validityOptions {
+	o := validityOptions{
+		clock: DefaultClock(),
+		skew:  DefaultClockSkew,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// ValidityError is a certificate or symmetric key validity error.
+type ValidityError struct {
+	// Certificate is the certificate, or nil for a symmetric key.
+	Certificate *x509.Certificate
+	// Subject is the certificate subject.
+	Subject string
+	// NotBefore is the start of the certificate's validity period.
+	NotBefore time.Time
+	// NotAfter is the end of the certificate's validity period.
+	NotAfter time.Time
+	// Now is the time the certificate was checked.
+	Now time.Time
+}
+
+// Expired returns true when the certificate has expired, and false when it is
+// not yet valid.
+func (err *ValidityError) Expired() bool {
+	return err.Now.After(err.NotAfter)
+}
+
+// Error satisfies the error interface.
+func (err *ValidityError) Error() string {
+	if err.Certificate == nil {
+		return fmt.Sprintf("symmetric key expired at %s (now %s)", err.NotAfter.Format(time.RFC3339), err.Now.Format(time.RFC3339))
+	}
+	if err.Expired() {
+		return fmt.Sprintf("certificate %q expired at %s (now %s)", err.Subject, err.NotAfter.Format(time.RFC3339), err.Now.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("certificate %q not valid until %s (now %s)", err.Subject, err.NotBefore.Format(time.RFC3339), err.Now.Format(time.RFC3339))
+}
+
+// CheckValidity checks that the current time is within the certificate's
+// validity period, tolerating the clock skew. Returns a [*ValidityError] when
+// the certificate is expired or not yet valid.
+func CheckValidity(cert *x509.Certificate, opts ...ValidityOption) error {
+	o := newValidityOptions(opts...)
+	return checkValidity(cert, o.clock.Now(), o.skew)
+}
+
+// checkValidity checks that now is within the certificate's validity period,
+// tolerating the clock skew.
+func checkValidity(cert *x509.Certificate, now time.Time, skew time.Duration) error {
+	if now.Add(skew).Before(cert.NotBefore) || now.Add(-skew).After(cert.NotAfter) {
+		return &ValidityError{
+			Certificate: cert,
+			Subject:     cert.Subject.String(),
+			NotBefore:   cert.NotBefore,
+			NotAfter:    cert.NotAfter,
+			Now:         now,
+		}
+	}
+	return nil
+}
+
+// CheckValidity checks the validity of all certificates in the [Store] (see
+// [CheckValidity]), and that the symmetric key in the [Store] (if any) has not
+// expired (see [SymmetricKey]).
+func (s Store) CheckValidity(opts ...ValidityOption) error {
+	o := newValidityOptions(opts...)
+	now := o.clock.Now()
+	if _, notAfter, ok := s.SymmetricKey(); ok && !notAfter.IsZero() && now.Add(-o.skew).After(notAfter) {
+		return &ValidityError{
+			NotAfter: notAfter,
+			Now:      now,
+		}
+	}
+	for _, cert := range s.Certificates() {
+		if err := checkValidity(cert, now, o.skew); err != nil {
+			return err
+		}
+	}
+	return nil
+}
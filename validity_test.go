@@ -0,0 +1,180 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckValidity(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	now := time.Now()
+	tpl := ServerTemplate("example.com")
+	tpl.NotBefore, tpl.NotAfter = now, now.Add(time.Hour)
+	cert, err := s.SelfSign(tpl)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		now     time.Time
+		skew    time.Duration
+		valid   bool
+		expired bool
+	}{
+		{now, 0, true, false},
+		{now.Add(-time.Minute), 0, false, false},
+		{now.Add(-time.Minute), DefaultClockSkew, true, false},
+		{now.Add(-time.Hour), DefaultClockSkew, false, false},
+		{now.Add(time.Hour + time.Minute), 0, false, true},
+		{now.Add(time.Hour + time.Minute), DefaultClockSkew, true, false},
+		{now.Add(2 * time.Hour), DefaultClockSkew, false, true},
+	}
+	for i, test := range tests {
+		clock := ClockFunc(func() time.Time { return test.now })
+		for j, err := range []error{
+			CheckValidity(cert, WithClock(clock), WithClockSkew(test.skew)),
+			s.CheckValidity(WithClock(clock), WithClockSkew(test.skew)),
+		} {
+			if test.valid {
+				if err != nil {
+					t.Errorf("test %d/%d expected no error, got: %v", i, j, err)
+				}
+				continue
+			}
+			var ve *ValidityError
+			switch {
+			case !errors.As(err, &ve):
+				t.Errorf("test %d/%d expected validity error, got: %v", i, j, err)
+			case ve.Expired() != test.expired:
+				t.Errorf("test %d/%d expected expired %t, got: %t", i, j, test.expired, ve.Expired())
+			}
+		}
+	}
+}
+
+func TestSymmetricKeyExpiry(t *testing.T) {
+	s, err := GenerateSymmetricKeySet(32, WithTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := s.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.Contains(string(buf), NotAfterHeader+": ") {
+		t.Errorf("expected %s header, got:\n%s", NotAfterHeader, buf)
+	}
+	s0, err := DecodeBytes(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	key, notAfter, ok := s0.SymmetricKey()
+	if !ok || notAfter.IsZero() {
+		t.Fatalf("expected symmetric key with expiry")
+	}
+	if !EqualKeys(s[PrivateKey], s0[PrivateKey]) || len(key) != 32 {
+		t.Errorf("expected equal keys")
+	}
+	if err := s0.CheckValidity(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	clock := ClockFunc(func() time.Time { return notAfter.Add(time.Hour) })
+	var ve *ValidityError
+	if err := s0.CheckValidity(WithClock(clock)); !errors.As(err, &ve) || ve.Certificate != nil || !ve.Expired() {
+		t.Errorf("expected expired symmetric key error, got: %v", err)
+	}
+	// keys without expiry are stored as raw bytes
+	s1, err := GenerateSymmetricKeySet(32)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := s1[PrivateKey].([]byte); !ok {
+		t.Errorf("expected []byte, got: %T", s1[PrivateKey])
+	}
+	if err := s1.CheckValidity(WithClock(clock)); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestDefaultClock(t *testing.T) {
+	now := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	SetDefaultClock(ClockFunc(func() time.Time { return now }))
+	t.Cleanup(func() { SetDefaultClock(nil) })
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tpl := ServerTemplate("example.com")
+	if exp := now.Add(-backdate); !tpl.NotBefore.Equal(exp) {
+		t.Errorf("expected not before %v, got: %v", exp, tpl.NotBefore)
+	}
+	if exp := now.Add(LeafValidity); !tpl.NotAfter.Equal(exp) {
+		t.Errorf("expected not after %v, got: %v", exp, tpl.NotAfter)
+	}
+	if _, err := s.SelfSign(tpl); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := s.CheckValidity(); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if err := s.CheckValidity(WithClock(SystemClock)); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, ok := s.CertificateFor("example.com"); !ok {
+		t.Errorf("expected certificate")
+	}
+	if k := NewSymmetricKey(make([]byte, 32), time.Hour); !k.NotAfter.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected not after %v, got: %v", now.Add(time.Hour), k.NotAfter)
+	}
+}
+
+func TestWithClockOptions(t *testing.T) {
+	now := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	clock := WithClock(ClockFunc(func() time.Time { return now }))
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tpl := ServerTemplate("example.com")
+	SetTemplateValidity(tpl, time.Hour, clock)
+	if exp := now.Add(-backdate); !tpl.NotBefore.Equal(exp) {
+		t.Errorf("expected not before %v, got: %v", exp, tpl.NotBefore)
+	}
+	if exp := now.Add(time.Hour); !tpl.NotAfter.Equal(exp) {
+		t.Errorf("expected not after %v, got: %v", exp, tpl.NotAfter)
+	}
+	if _, err := s.SelfSign(tpl); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// the certificate is expired using the system clock
+	if r := s.Report(); r.Expiry[ExpiryExpired] != 1 {
+		t.Errorf("expected expired certificate, got: %v", r.Expiry)
+	}
+	if r := s.Report(clock); r.Expiry[ExpiryExpired] != 0 {
+		t.Errorf("expected no expired certificates, got: %v", r.Expiry)
+	}
+	if err := s.RotateSessionTicketKeys(32, time.Hour, 2, clock); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	keys := s.SessionTicketKeys()
+	if len(keys) != 1 || !keys[0].NotAfter.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected key expiring at %v, got: %v", now.Add(time.Hour), keys)
+	}
+	if n := len(keys.TLSKeys()); n != 0 {
+		t.Errorf("expected 0 keys, got: %d", n)
+	}
+	if n := len(keys.TLSKeys(clock)); n != 1 {
+		t.Errorf("expected 1 key, got: %d", n)
+	}
+	if err := s.RotateSessionTicketKeys(32, time.Hour, 2); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := len(s.SessionTicketKeys()); n != 1 {
+		t.Errorf("expected expired key to be removed, got: %d keys", n)
+	}
+}
@@ -0,0 +1,186 @@
+package pemutil
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"slices"
+	"sort"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// View is a read-only view of a [Store], restricted to a set of block types
+// (see [Store.View]). A view does not copy the [Store], and reflects later
+// changes to the [Store] for the view's block types.
+//
+// Slice and map values returned by [View.Get] (such as raw keys and
+// certificate bundles) are copies, but the crypto primitives they contain are
+// shared with the [Store], and must not be modified.
+type View struct {
+	s     Store
+	types map[BlockType]bool
+}
+
+// View returns a read-only view of the [Store] restricted to the block types,
+// such as for handing a "certificates only" view to code that must not see or
+// modify the private key.
+func (s Store) View(types ...BlockType) View {
+	m := make(map[BlockType]bool, len(types))
+	for _, typ := range types {
+		m[typ] = true
+	}
+	return View{s: s, types: m}
+}
+
+// PublicView returns a read-only view of the [Store] restricted to the
+// public block types (see [SupportedBlockTypes]), such as certificates and
+// public keys.
+func (s Store) PublicView() View {
+	var types []BlockType
+	for _, info := range SupportedBlockTypes() {
+		if !info.Private {
+			types = append(types, info.Type)
+		}
+	}
+	return s.View(types...)
+}
+
+// Get returns the crypto primitive for the block type, when the block type
+// is in the view. Slice and map values are returned as copies, and lazily
+// parsed certificates (see [WithLazyLoad]) are returned as a
+// []*x509.Certificate.
+func (v View) Get(typ BlockType) (interface{}, bool) {
+	if !v.types[typ] {
+		return nil, false
+	}
+	p, ok := v.s[typ]
+	if _, lazy := p.(*lazyCertificates); lazy {
+		return v.Certificates(), ok
+	}
+	return copyValue(p), ok
+}
+
+// Types returns the sorted block types present in the view.
+func (v View) Types() []BlockType {
+	var types []BlockType
+	for typ := range v.types {
+		if _, ok := v.s[typ]; ok {
+			types = append(types, typ)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// Len returns the number of block types present in the view.
+func (v View) Len() int {
+	return len(v.Types())
+}
+
+// Certificates returns the certificates in the view (see
+// [Store.Certificates]). The returned slice can be modified without
+// affecting the [Store].
+func (v View) Certificates() []*x509.Certificate {
+	if !v.types[Certificate] {
+		return nil
+	}
+	return append([]*x509.Certificate(nil), v.s.Certificates()...)
+}
+
+// Certificate returns the first certificate in the view (see
+// [Store.Certificate]).
+func (v View) Certificate() (*x509.Certificate, bool) {
+	if !v.types[Certificate] {
+		return nil, false
+	}
+	return v.s.Certificate()
+}
+
+// CertPool creates a certificate pool containing the certificates in the
+// view (see [Store.CertPool]).
+func (v View) CertPool() *x509.CertPool {
+	return v.store().CertPool()
+}
+
+// PublicKey returns the public key in the view (see [Store.PublicKey]).
+func (v View) PublicKey() (crypto.PublicKey, bool) {
+	return v.store().PublicKey()
+}
+
+// Signer returns the private key in the view as a [crypto.Signer] (see
+// [Store.Signer]). Only available when the view includes the private key's
+// block type.
+func (v View) Signer() (crypto.Signer, bool) {
+	return v.store().Signer()
+}
+
+// Bytes returns the crypto primitives in the view as PEM-encoded data (see
+// [Store.Bytes]).
+func (v View) Bytes() ([]byte, error) {
+	return v.store().Bytes()
+}
+
+// Clone returns a copy of the crypto primitives in the view as a new
+// [Store] (see [Store.Clone]).
+func (v View) Clone() Store {
+	return v.store().Clone()
+}
+
+// store returns a [Store] containing the view's crypto primitives. The
+// returned [Store] shares the crypto primitives, and must not be modified.
+func (v View) store() Store {
+	z := make(Store, len(v.types))
+	for typ := range v.types {
+		if p, ok := v.s[typ]; ok {
+			z[typ] = p
+		}
+	}
+	return z
+}
+
+// copyValue returns a copy of the value when it is a slice or map (or
+// contains one), such that modifying the copy does not modify the [Store].
+// Other values are returned as-is.
+func copyValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []byte:
+		return slices.Clone(x)
+	case ed25519.PrivateKey:
+		return slices.Clone(x)
+	case ed25519.PublicKey:
+		return slices.Clone(x)
+	case ed448.PrivateKey:
+		return slices.Clone(x)
+	case ed448.PublicKey:
+		return slices.Clone(x)
+	case ECHConfigList:
+		return slices.Clone(x)
+	case []*x509.Certificate:
+		return slices.Clone(x)
+	case Attributes:
+		return slices.Clone(x)
+	case Purposes:
+		return slices.Clone(x)
+	case *SymmetricKey:
+		if x == nil {
+			return x
+		}
+		return &SymmetricKey{Key: slices.Clone(x.Key), NotAfter: x.NotAfter}
+	case SessionTicketKeys:
+		keys := make(SessionTicketKeys, len(x))
+		for i, key := range x {
+			keys[i] = copyValue(key).(*SymmetricKey)
+		}
+		return keys
+	case explanatoryText:
+		m := make(explanatoryText, len(x))
+		for k, text := range x {
+			m[k] = slices.Clone(text)
+		}
+		return m
+	}
+	return v
+}
@@ -0,0 +1,108 @@
+package pemutil
+
+import (
+	"crypto/elliptic"
+	"crypto/x509"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SelfSign(ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	v := s.View(Certificate)
+	if exp := []BlockType{Certificate}; !reflect.DeepEqual(v.Types(), exp) {
+		t.Errorf("expected %v, got: %v", exp, v.Types())
+	}
+	if _, ok := v.Get(ECPrivateKey); ok {
+		t.Errorf("expected no private key")
+	}
+	if _, ok := v.Signer(); ok {
+		t.Errorf("expected no signer")
+	}
+	if _, ok := v.PublicKey(); ok {
+		t.Errorf("expected no public key")
+	}
+	if _, ok := v.Certificate(); !ok {
+		t.Errorf("expected certificate")
+	}
+	// modifying the returned certificates does not modify the store
+	certs := v.Certificates()
+	certs[0] = nil
+	if s.Certificates()[0] == nil {
+		t.Errorf("expected store to be unmodified")
+	}
+	// changes to the store are reflected
+	if _, err := s.SelfSign(ServerTemplate("www.example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := len(v.Certificates()); n != 2 {
+		t.Errorf("expected 2 certificates, got: %d", n)
+	}
+	// public view
+	pub := s.PublicView()
+	if exp := []BlockType{Certificate, PublicKey}; !reflect.DeepEqual(pub.Types(), exp) {
+		t.Errorf("expected %v, got: %v", exp, pub.Types())
+	}
+	buf, err := pub.Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if strings.Contains(string(buf), "PRIVATE KEY") {
+		t.Errorf("expected no private key, got:\n%s", buf)
+	}
+	z := pub.Clone()
+	delete(z, Certificate)
+	if len(s.Certificates()) != 2 || pub.Len() != 2 {
+		t.Errorf("expected clone to not modify the store")
+	}
+	// private view
+	if _, ok := s.View(ECPrivateKey).Signer(); !ok {
+		t.Errorf("expected signer")
+	}
+}
+
+func TestViewGetCopies(t *testing.T) {
+	s, err := GenerateECKeySet(elliptic.P256())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SelfSign(ServerTemplate("example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SelfSign(ServerTemplate("www.example.com")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s[PrivateKey] = []byte("secret")
+	v := s.View(Certificate, PrivateKey)
+	// modifying returned values does not modify the store
+	p, _ := v.Get(PrivateKey)
+	p.([]byte)[0] = 'x'
+	if string(s[PrivateKey].([]byte)) != "secret" {
+		t.Errorf("expected store to be unmodified")
+	}
+	p, _ = v.Get(Certificate)
+	p.([]*x509.Certificate)[0] = nil
+	if s.Certificates()[0] == nil {
+		t.Errorf("expected store to be unmodified")
+	}
+	// lazy certificates are returned as a []*x509.Certificate
+	buf, err := s.Only(Certificate).Bytes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z, err := DecodeBytes(buf, WithLazyLoad())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	p, _ = z.View(Certificate).Get(Certificate)
+	if certs, ok := p.([]*x509.Certificate); !ok || len(certs) != 2 {
+		t.Errorf("expected 2 certificates, got: %T", p)
+	}
+}
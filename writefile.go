@@ -0,0 +1,187 @@
+package pemutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// BackupTimeFormat is the time format used in backup file names (see
+// [WithBackup]).
+const BackupTimeFormat = "20060102T150405Z"
+
+// WriteOptions are file write options, used by [WriteFile], [Store.WriteFile],
+// [Store.WriteFiles], [Store.WriteHAProxyFile], [Store.WriteNginxFiles], and
+// [WriteManifest].
+type WriteOptions struct {
+	// DryRun reports the files that would be written (see Report), without
+	// writing any files.
+	DryRun bool
+	// Backup copies existing files to a timestamped backup file before they
+	// are overwritten, named <filename>.<timestamp>.bak, with the timestamp
+	// formatted using [BackupTimeFormat].
+	Backup bool
+	// Clock is the clock used for backup timestamps. Defaults to
+	// [DefaultClock].
+	Clock Clock
+	// Report, when not nil, is called for each file written (or that would
+	// be written, when DryRun is set).
+	Report func(WriteReport)
+}
+
+// WriteReport is a report of a file write.
+type WriteReport struct {
+	// Filename is the name of the written file.
+	Filename string
+	// Mode is the file's mode.
+	Mode os.FileMode
+	// Size is the size of the written data.
+	Size int
+	// Exists is whether the file existed before being written.
+	Exists bool
+	// Backup is the name of the backup file, if any.
+	Backup string
+	// DryRun is whether the file was not actually written.
+	DryRun bool
+}
+
+// WriteOption is a file write option.
+type WriteOption func(*WriteOptions)
+
+// WithWriteOptions is a file write option to set all write options.
+func WithWriteOptions(o WriteOptions) WriteOption {
+	return func(opts *WriteOptions) {
+		*opts = o
+	}
+}
+
+// WithDryRun is a file write option to report the files that would be
+// written, without writing any files.
+func WithDryRun() WriteOption {
+	return func(opts *WriteOptions) {
+		opts.DryRun = true
+	}
+}
+
+// WithBackup is a file write option to copy existing files to a timestamped
+// backup file before they are overwritten.
+func WithBackup() WriteOption {
+	return func(opts *WriteOptions) {
+		opts.Backup = true
+	}
+}
+
+// WithWriteReport is a file write option to set a func called for each file
+// written (or that would be written).
+func WithWriteReport(f func(WriteReport)) WriteOption {
+	return func(opts *WriteOptions) {
+		opts.Report = f
+	}
+}
+
+// newWriteOptions creates the write options.
+func newWriteOptions(opts ...WriteOption) WriteOptions {
+	o := WriteOptions{
+		Clock: DefaultClock(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Clock == nil {
+		o.Clock = DefaultClock()
+	}
+	return o
+}
+
+// WriteFile writes buf to filename with the file mode, using the write
+// options. Existing files are backed up when [WithBackup] is passed, and no
+// files are written when [WithDryRun] is passed.
+//
+// Files are replaced atomically, by writing to a temporary file in the same
+// directory that is renamed to filename, so that a failed write (or a crash)
+// never leaves a truncated file.
+func WriteFile(filename string, buf []byte, mode os.FileMode, opts ...WriteOption) error {
+	o := newWriteOptions(opts...)
+	return o.writeFile(filename, buf, mode)
+}
+
+// writeFile writes buf to filename with the file mode.
+func (o WriteOptions) writeFile(filename string, buf []byte, mode os.FileMode) error {
+	r := WriteReport{
+		Filename: filename,
+		Mode:     mode,
+		Size:     len(buf),
+		DryRun:   o.DryRun,
+	}
+	orig, err := os.ReadFile(filename)
+	switch {
+	case err == nil:
+		r.Exists = true
+	case !os.IsNotExist(err):
+		return err
+	}
+	if r.Exists && o.Backup {
+		r.Backup = filename + "." + o.Clock.Now().UTC().Format(BackupTimeFormat) + ".bak"
+	}
+	if !o.DryRun {
+		if r.Backup != "" {
+			if err := writeBackup(r.Backup, orig, filename); err != nil {
+				return err
+			}
+		}
+		if err := replaceFile(filename, buf, mode); err != nil {
+			return err
+		}
+	}
+	if o.Report != nil {
+		o.Report(r)
+	}
+	return nil
+}
+
+// replaceFile atomically writes buf to filename with the file mode, by
+// writing and syncing a temporary file in the same directory, and renaming it
+// to filename. Readers see either the original file or the complete new file,
+// and the original file is left intact if the write fails.
+func replaceFile(filename string, buf []byte, mode os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	err = f.Chmod(mode)
+	if err == nil {
+		_, err = f.Write(buf)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(name, filename)
+	}
+	if err != nil {
+		os.Remove(name)
+		return err
+	}
+	return nil
+}
+
+// writeBackup writes the original contents of filename to the backup file,
+// with the original file's mode. Never overwrites an existing file.
+func writeBackup(backup string, buf []byte, filename string) error {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
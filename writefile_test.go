@@ -0,0 +1,69 @@
+package pemutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "key.pem")
+	clock := ClockFunc(func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	})
+	var reports []WriteReport
+	report := WithWriteReport(func(r WriteReport) {
+		reports = append(reports, r)
+	})
+	// dry run does not create the file
+	if err := WriteFile(name, []byte("one"), 0o600, WithDryRun(), report); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected file to not exist, got: %v", err)
+	}
+	if err := WriteFile(name, []byte("one"), 0o600, report); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	opts := []WriteOption{
+		WithWriteOptions(WriteOptions{Backup: true, Clock: clock}),
+		report,
+	}
+	if err := WriteFile(name, []byte("two"), 0o600, opts...); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	backup := name + ".20240102T030405Z.bak"
+	if buf, err := os.ReadFile(backup); err != nil || string(buf) != "one" {
+		t.Errorf("expected backup with original contents, got: %q, %v", buf, err)
+	}
+	if buf, err := os.ReadFile(name); err != nil || string(buf) != "two" {
+		t.Errorf("expected new contents, got: %q, %v", buf, err)
+	}
+	// backups are never overwritten
+	if err := WriteFile(name, []byte("three"), 0o600, opts...); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	if len(reports) != 3 {
+		t.Fatalf("expected 3 reports, got: %d", len(reports))
+	}
+	if r := reports[0]; !r.DryRun || r.Exists || r.Size != 3 {
+		t.Errorf("expected dry run report, got: %+v", r)
+	}
+	if r := reports[2]; r.DryRun || !r.Exists || r.Backup != backup {
+		t.Errorf("expected backup report, got: %+v", r)
+	}
+	// files are replaced, without leaving temporary files
+	if err := WriteFile(name, []byte("four"), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkFile(t, name, []byte("four"), 0o644)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 files, got: %v", entries)
+	}
+}